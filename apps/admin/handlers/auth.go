@@ -2,12 +2,20 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kaifa/game-platform/pkg/services"
 	"github.com/kaifa/game-platform/pkg/utils"
 )
 
+var loginLimiter *services.LoginLimiter
+
+// SetLoginLimiter 设置登录失败限制器
+func SetLoginLimiter(limiter *services.LoginLimiter) {
+	loginLimiter = limiter
+}
+
 // Login 管理员登录
 func Login(c *gin.Context) {
 	var req struct {
@@ -24,10 +32,24 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// 防暴力破解：连续失败达到阈值后锁定
+	if loginLimiter != nil {
+		if locked, remaining := loginLimiter.IsLocked(c.Request.Context(), req.Username); locked {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"code":    429,
+				"message": loginLimiter.LockedMessage(remaining),
+			})
+			return
+		}
+	}
+
 	// 调用服务登录
 	adminService := services.NewAdminService()
 	admin, err := adminService.Login(req.Username, req.Password, c.ClientIP())
 	if err != nil {
+		if loginLimiter != nil {
+			loginLimiter.RecordFailure(c.Request.Context(), req.Username)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
 			"message": err.Error(),
@@ -35,6 +57,11 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// 登录成功，清除失败计数
+	if loginLimiter != nil {
+		loginLimiter.Reset(c.Request.Context(), req.Username)
+	}
+
 	// 获取管理员权限
 	permissions, err := adminService.GetAdminWithPermissions(admin.ID)
 	if err != nil {
@@ -72,6 +99,37 @@ func Login(c *gin.Context) {
 	})
 }
 
+// UnlockAdmin 强制解锁被登录限制锁定的管理员账号
+func UnlockAdmin(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的管理员ID",
+		})
+		return
+	}
+
+	adminService := services.NewAdminService()
+	admin, err := adminService.GetAdminByID(uint(adminID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "管理员不存在",
+		})
+		return
+	}
+
+	if loginLimiter != nil {
+		loginLimiter.Reset(c.Request.Context(), admin.Username)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "解锁成功",
+	})
+}
+
 // Logout 管理员退出登录
 func Logout(c *gin.Context) {
 	// JWT是无状态的，客户端删除Token即可
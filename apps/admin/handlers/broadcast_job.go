@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// broadcastBatchSize 每批插入的用户消息数量
+const broadcastBatchSize = 1000
+
+// startBroadcastJob 创建批量发送任务并启动后台发送
+func startBroadcastJob(job *models.MessageBroadcastJob) error {
+	if err := database.DB.Create(job).Error; err != nil {
+		return err
+	}
+	go runBroadcastJob(job.ID)
+	return nil
+}
+
+// runBroadcastJob 执行批量发送任务：按批插入用户消息，
+// 每批的插入和进度更新在同一个事务内完成，失败或重启后从已发送位置续发。
+func runBroadcastJob(jobID uint) {
+	var job models.MessageBroadcastJob
+	if err := database.DB.First(&job, jobID).Error; err != nil {
+		logger.Logger.Error("加载批量消息任务失败", zap.Uint("job_id", jobID), zap.Error(err))
+		return
+	}
+	if job.Status >= 2 {
+		return // 已完成或已失败的任务不再执行
+	}
+
+	// 解析目标用户（按ID升序，保证续发时偏移量稳定）
+	userIDs, err := resolveBroadcastTargets(job.TargetUsers)
+	if err != nil {
+		failBroadcastJob(job.ID, err)
+		return
+	}
+
+	total := int64(len(userIDs))
+	if err := database.DB.Model(&models.MessageBroadcastJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{"total": total, "status": 1}).Error; err != nil {
+		logger.Logger.Error("更新批量消息任务状态失败", zap.Uint("job_id", job.ID), zap.Error(err))
+		return
+	}
+
+	// 从上次进度处续发
+	for job.Sent < total {
+		start := job.Sent
+		end := start + broadcastBatchSize
+		if end > total {
+			end = total
+		}
+
+		now := time.Now().Unix()
+		batch := userIDs[start:end]
+		messages := make([]models.UserMessage, 0, len(batch))
+		for _, userID := range batch {
+			messages = append(messages, models.UserMessage{
+				UserID:    userID,
+				Type:      job.Type,
+				Title:     job.Title,
+				Content:   job.Content,
+				RelatedID: job.RelatedID,
+				IsRead:    false,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+
+		// 单个事务内插入本批消息并推进进度，保证sent计数与落库消息一致
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.CreateInBatches(messages, len(messages)).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.MessageBroadcastJob{}).
+				Where("id = ?", job.ID).
+				Update("sent", end).Error
+		})
+		if err != nil {
+			failBroadcastJob(job.ID, err)
+			return
+		}
+		job.Sent = end
+	}
+
+	database.DB.Model(&models.MessageBroadcastJob{}).
+		Where("id = ?", job.ID).
+		Update("status", 2)
+
+	logger.Logger.Info("批量消息任务完成",
+		zap.Uint("job_id", job.ID),
+		zap.Int64("total", total),
+	)
+}
+
+// resolveBroadcastTargets 解析任务的目标用户列表
+func resolveBroadcastTargets(targetUsers string) ([]uint, error) {
+	var userIDs []uint
+
+	if targetUsers == "all" {
+		if err := database.DB.Model(&models.User{}).
+			Order("id ASC").
+			Pluck("id", &userIDs).Error; err != nil {
+			return nil, err
+		}
+		return userIDs, nil
+	}
+
+	for _, idStr := range strings.Split(targetUsers, ",") {
+		idStr = strings.TrimSpace(idStr)
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil && id > 0 {
+			userIDs = append(userIDs, uint(id))
+		}
+	}
+	return userIDs, nil
+}
+
+// failBroadcastJob 标记任务失败并记录原因
+func failBroadcastJob(jobID uint, cause error) {
+	logger.Logger.Error("批量消息任务失败", zap.Uint("job_id", jobID), zap.Error(cause))
+
+	errMsg := cause.Error()
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500]
+	}
+	database.DB.Model(&models.MessageBroadcastJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{"status": 3, "error": errMsg})
+}
+
+// ResumeBroadcastJobs 恢复未完成的批量消息任务（服务重启后从进度处续发）
+func ResumeBroadcastJobs() {
+	var jobs []models.MessageBroadcastJob
+	if err := database.DB.Where("status IN ?", []int{0, 1}).Find(&jobs).Error; err != nil {
+		logger.Logger.Error("查询未完成的批量消息任务失败", zap.Error(err))
+		return
+	}
+
+	for i := range jobs {
+		logger.Logger.Info("续发批量消息任务",
+			zap.Uint("job_id", jobs[i].ID),
+			zap.Int64("sent", jobs[i].Sent),
+			zap.Int64("total", jobs[i].Total),
+		)
+		go runBroadcastJob(jobs[i].ID)
+	}
+}
+
+// GetBroadcastJob 查询批量消息任务进度
+func GetBroadcastJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的任务ID",
+		})
+		return
+	}
+
+	var job models.MessageBroadcastJob
+	if err := database.DB.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "任务不存在",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "查询任务失败: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": job,
+	})
+}
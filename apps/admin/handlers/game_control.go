@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/logger"
+	gamesvc "github.com/kaifa/game-platform/internal/service/game"
+	"go.uber.org/zap"
+)
+
+// gameManager 游戏管理器（Redis不可用时为nil，强制结束功能不可用）
+var gameManager *gamesvc.Manager
+
+// SetGameManager 注入游戏管理器（用于强制结束游戏）
+func SetGameManager(manager *gamesvc.Manager) {
+	gameManager = manager
+}
+
+// roomNotifyURL 游戏服务器的房间通知地址（为空时不推送，强制结束仅落库）
+var roomNotifyURL string
+
+// SetRoomNotifyURL 设置游戏服务器的房间通知地址
+func SetRoomNotifyURL(url string) {
+	roomNotifyURL = url
+}
+
+// ForceEndGame 强制结束进行中的游戏（运营工具）。
+// mode 为 settle 时按当前牌面结算；为 void 时作废本局（不产生输赢）。
+// 操作人信息由操作日志中间件记录到审计日志。
+func ForceEndGame(c *gin.Context) {
+	if gameManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "游戏管理器未初始化（Redis不可用）",
+		})
+		return
+	}
+
+	roomID := c.Param("roomId")
+
+	var req struct {
+		Mode string `json:"mode" binding:"required,oneof=settle void"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	adminID, _ := c.Get("admin_id")
+
+	settlement, err := gameManager.ForceEndGame(c.Request.Context(), roomID, req.Mode == "void")
+	if err != nil {
+		logger.Logger.Warn("强制结束游戏失败",
+			zap.Any("admin_id", adminID),
+			zap.String("room_id", roomID),
+			zap.String("mode", req.Mode),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	logger.Logger.Info("管理员强制结束游戏",
+		zap.Any("admin_id", adminID),
+		zap.String("room_id", roomID),
+		zap.String("mode", req.Mode),
+	)
+
+	// 通知游戏服务器广播 game_end（尽力而为，失败不影响已完成的结算）
+	go notifyGameEnd(roomID, req.Mode, settlement)
+
+	data := gin.H{
+		"room_id": roomID,
+		"mode":    req.Mode,
+	}
+	if settlement != nil {
+		data["settlement"] = settlement
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "游戏已强制结束",
+		"data":    data,
+	})
+}
+
+// notifyGameEnd 通过游戏服务器向房间内客户端广播游戏结束
+func notifyGameEnd(roomID, mode string, settlement *gamesvc.GameSettlement) {
+	if roomNotifyURL == "" {
+		return
+	}
+
+	roomData := map[string]interface{}{
+		"forced": true,
+		"mode":   mode,
+	}
+	if settlement != nil {
+		roomData["settlement"] = settlement
+	}
+
+	payload := map[string]interface{}{
+		"room_id":   roomID,
+		"action":    "game_end",
+		"room_data": roomData,
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", roomNotifyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Logger.Warn("游戏结束通知推送失败",
+			zap.String("room_id", roomID),
+			zap.Error(err),
+		)
+		return
+	}
+	resp.Body.Close()
+}
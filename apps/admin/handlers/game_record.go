@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	esClient "github.com/kaifa/game-platform/internal/elasticsearch"
+)
+
+// buildGameRecordFilters 从请求参数构建游戏记录查询过滤条件（搜索和聚合共用）
+func buildGameRecordFilters(c *gin.Context) map[string]interface{} {
+	filters := make(map[string]interface{})
+
+	if roomID := c.Query("room_id"); roomID != "" {
+		filters["room_id"] = roomID
+	}
+	if gameType := c.Query("game_type"); gameType != "" {
+		filters["game_type"] = gameType
+	}
+	if dateStart := c.Query("date_start"); dateStart != "" {
+		if ts, err := strconv.ParseInt(dateStart, 10, 64); err == nil {
+			filters["start_time"] = time.Unix(ts, 0).Format(time.RFC3339)
+		}
+	}
+	if dateEnd := c.Query("date_end"); dateEnd != "" {
+		if ts, err := strconv.ParseInt(dateEnd, 10, 64); err == nil {
+			filters["end_time"] = time.Unix(ts, 0).Format(time.RFC3339)
+		}
+	}
+
+	return filters
+}
+
+// SearchGameRecords 搜索游戏对局记录（基于 Elasticsearch，分析用）
+func SearchGameRecords(c *gin.Context) {
+	if esClient.GetClient() == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "Elasticsearch 不可用，游戏记录分析暂不支持",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := esClient.BuildGameRecordQuery(buildGameRecordFilters(c))
+
+	records, total, err := esClient.SearchGameRecords(query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询游戏记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"list":  records,
+			"total": total,
+		},
+	})
+}
+
+// GetGameRecordAggregations 游戏记录聚合统计。
+// agg_type 支持 by_game_type/daily，过滤条件与搜索一致。
+func GetGameRecordAggregations(c *gin.Context) {
+	if esClient.GetClient() == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "Elasticsearch 不可用，聚合统计暂不支持",
+		})
+		return
+	}
+
+	aggType := c.DefaultQuery("agg_type", "by_game_type")
+
+	query := esClient.BuildGameRecordQuery(buildGameRecordFilters(c))
+
+	buckets, err := esClient.AggregateGameRecords(query, aggType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "聚合统计失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"agg_type": aggType,
+			"buckets":  buckets,
+		},
+	})
+}
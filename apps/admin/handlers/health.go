@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/bootstrap"
+	"github.com/kaifa/game-platform/internal/cache"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/elasticsearch"
+)
+
+// Ready 就绪检查：探测 MySQL/Redis/Elasticsearch，必需依赖不可用时返回 503。
+// ES 是可降级依赖：不可用时操作日志读写退化到 MySQL，不影响接收流量；
+// 与 /health 的存活检查不同，/ready 用于 Kubernetes 判断实例能否接收流量。
+func Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	statuses := make(map[string]bootstrap.DependencyStatus)
+	ready := true
+
+	// MySQL（必需）
+	mysqlStatus := bootstrap.DependencyStatus{Status: "ok", Required: true}
+	if database.DB == nil {
+		mysqlStatus.Status = "error"
+		mysqlStatus.Error = "未初始化"
+	} else if sqlDB, err := database.DB.DB(); err != nil {
+		mysqlStatus.Status = "error"
+		mysqlStatus.Error = err.Error()
+	} else if err := sqlDB.PingContext(ctx); err != nil {
+		mysqlStatus.Status = "error"
+		mysqlStatus.Error = err.Error()
+	}
+	if mysqlStatus.Status != "ok" {
+		ready = false
+	}
+	statuses["mysql"] = mysqlStatus
+
+	// Redis（可降级）
+	redisStatus := bootstrap.DependencyStatus{Status: "ok", Required: false}
+	if cache.RDB == nil {
+		redisStatus.Status = "disabled"
+	} else if err := cache.RDB.Ping(ctx).Err(); err != nil {
+		redisStatus.Status = "error"
+		redisStatus.Error = err.Error()
+	}
+	statuses["redis"] = redisStatus
+
+	// Elasticsearch（可降级：不可用时操作日志退化到 MySQL）
+	esStatus := bootstrap.DependencyStatus{Status: "ok", Required: false}
+	if elasticsearch.GetClient() == nil {
+		esStatus.Status = "disabled"
+	} else if err := elasticsearch.Ping(ctx); err != nil {
+		esStatus.Status = "error"
+		esStatus.Error = err.Error()
+	}
+	statuses["elasticsearch"] = esStatus
+
+	status := "ready"
+	code := http.StatusOK
+	if !ready {
+		status = "not ready"
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":       status,
+		"dependencies": statuses,
+		"time":         time.Now().Format(time.RFC3339),
+	})
+}
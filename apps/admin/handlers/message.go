@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,7 +11,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/logger"
 	"github.com/kaifa/game-platform/pkg/models"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -164,6 +169,48 @@ func CreateAnnouncement(c *gin.Context) {
 	})
 }
 
+// announcementUpdateRequest 更新公告请求。
+// Priority/Status 使用指针以区分“未传字段”和“显式设为0”，
+// 否则优先级无法改回0（普通）。
+type announcementUpdateRequest struct {
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Priority    *int   `json:"priority"`
+	Status      *int   `json:"status"`
+	StartTime   *int64 `json:"start_time"`
+	EndTime     *int64 `json:"end_time"`
+	TargetUsers string `json:"target_users"`
+}
+
+// applyAnnouncementUpdate 把请求中提供的字段合并到公告上（未传的字段保持原值）
+func applyAnnouncementUpdate(announcement *models.Announcement, req *announcementUpdateRequest) {
+	if req.Title != "" {
+		announcement.Title = req.Title
+	}
+	if req.Content != "" {
+		announcement.Content = req.Content
+	}
+	if req.Type != "" {
+		announcement.Type = req.Type
+	}
+	if req.Priority != nil {
+		announcement.Priority = *req.Priority
+	}
+	if req.Status != nil {
+		announcement.Status = *req.Status
+	}
+	if req.StartTime != nil {
+		announcement.StartTime = req.StartTime
+	}
+	if req.EndTime != nil {
+		announcement.EndTime = req.EndTime
+	}
+	if req.TargetUsers != "" {
+		announcement.TargetUsers = req.TargetUsers
+	}
+}
+
 // UpdateAnnouncement 更新公告
 func UpdateAnnouncement(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -191,17 +238,7 @@ func UpdateAnnouncement(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Title       string `json:"title"`
-		Content     string `json:"content"`
-		Type        string `json:"type"`
-		Priority    int    `json:"priority"`
-		Status      int    `json:"status"`
-		StartTime   *int64 `json:"start_time"`
-		EndTime     *int64 `json:"end_time"`
-		TargetUsers string `json:"target_users"`
-	}
-
+	var req announcementUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
@@ -212,31 +249,7 @@ func UpdateAnnouncement(c *gin.Context) {
 
 	oldStatus := announcement.Status
 
-	// 更新字段
-	if req.Title != "" {
-		announcement.Title = req.Title
-	}
-	if req.Content != "" {
-		announcement.Content = req.Content
-	}
-	if req.Type != "" {
-		announcement.Type = req.Type
-	}
-	if req.Priority != 0 || req.Priority == -1 {
-		announcement.Priority = req.Priority
-	}
-	if req.Status != 0 {
-		announcement.Status = req.Status
-	}
-	if req.StartTime != nil {
-		announcement.StartTime = req.StartTime
-	}
-	if req.EndTime != nil {
-		announcement.EndTime = req.EndTime
-	}
-	if req.TargetUsers != "" {
-		announcement.TargetUsers = req.TargetUsers
-	}
+	applyAnnouncementUpdate(&announcement, &req)
 	announcement.UpdatedAt = time.Now().Unix()
 
 	if err := database.DB.Save(&announcement).Error; err != nil {
@@ -285,59 +298,145 @@ func DeleteAnnouncement(c *gin.Context) {
 }
 
 // sendAnnouncementToUsers 发送公告给目标用户
-func sendAnnouncementToUsers(announcement *models.Announcement) {
+func sendAnnouncementToUsers(announcement *models.Announcement) bool {
 	now := time.Now().Unix()
 
 	// 检查时间范围
 	if announcement.StartTime != nil && *announcement.StartTime > now {
-		return // 未到开始时间
+		return false // 未到开始时间，由定时任务到点发送
 	}
 	if announcement.EndTime != nil && *announcement.EndTime < now {
-		return // 已过结束时间
-	}
-
-	var userIDs []uint
-
-	if announcement.TargetUsers == "all" {
-		// 发送给所有用户
-		database.DB.Model(&models.User{}).Pluck("id", &userIDs)
-	} else {
-		// 发送给指定用户
-		ids := strings.Split(announcement.TargetUsers, ",")
-		for _, idStr := range ids {
-			idStr = strings.TrimSpace(idStr)
-			if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
-				userIDs = append(userIDs, uint(id))
-			}
-		}
+		return false // 已过结束时间
+	}
+
+	// 用户消息交给批量发送任务后台处理（按批记录进度，重启后可续发）
+	job := &models.MessageBroadcastJob{
+		Type:        "system",
+		Title:       announcement.Title,
+		Content:     announcement.Content,
+		TargetUsers: announcement.TargetUsers,
+		CreatedBy:   announcement.CreatedBy,
+	}
+	if err := startBroadcastJob(job); err != nil {
+		logger.Logger.Error("创建公告发送任务失败",
+			zap.Uint("announcement_id", announcement.ID),
+			zap.Error(err),
+		)
+		return false
 	}
 
-	if len(userIDs) == 0 {
+	markAnnouncementPublished(announcement, now)
+	return true
+}
+
+// markAnnouncementPublished 记录公告的实际发送时间，并向在线用户实时推送
+func markAnnouncementPublished(announcement *models.Announcement, now int64) {
+	announcement.PublishedAt = &now
+	database.DB.Model(&models.Announcement{}).
+		Where("id = ?", announcement.ID).
+		Update("published_at", now)
+
+	go notifyAnnouncementOnline(announcement)
+}
+
+// announcementNotifyURL 游戏服务器的公告推送地址（为空时不推送，公告仅落库）
+var announcementNotifyURL string
+
+// SetAnnouncementNotifyURL 设置游戏服务器的公告推送地址
+func SetAnnouncementNotifyURL(url string) {
+	announcementNotifyURL = url
+}
+
+// notifyAnnouncementOnline 通过游戏服务器向在线用户实时推送公告（尽力而为，
+// 推送失败不影响已落库的用户消息）
+func notifyAnnouncementOnline(announcement *models.Announcement) {
+	if announcementNotifyURL == "" {
 		return
 	}
 
-	// 批量创建用户消息
-	messages := make([]models.UserMessage, 0, len(userIDs))
-	for _, userID := range userIDs {
-		messages = append(messages, models.UserMessage{
-			UserID:    userID,
-			Type:      "system",
-			Title:     announcement.Title,
-			Content:   announcement.Content,
-			IsRead:    false,
-			CreatedAt: now,
-			UpdatedAt: now,
-		})
+	payload := map[string]interface{}{
+		"id":           announcement.ID,
+		"title":        announcement.Title,
+		"content":      announcement.Content,
+		"type":         announcement.Type,
+		"priority":     announcement.Priority,
+		"target_users": announcement.TargetUsers,
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return
 	}
 
-	// 分批插入（每批1000条）
-	batchSize := 1000
-	for i := 0; i < len(messages); i += batchSize {
-		end := i + batchSize
-		if end > len(messages) {
-			end = len(messages)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", announcementNotifyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Logger.Warn("公告实时推送失败，用户消息已落库",
+			zap.Uint("announcement_id", announcement.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	resp.Body.Close()
+}
+
+// StartAnnouncementScheduler 启动公告定时任务：
+// 到达开始时间的已发布公告补发用户消息，超过结束时间的公告自动下架。
+func StartAnnouncementScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			publishDueAnnouncements()
+			expireAnnouncements()
+			<-ticker.C
 		}
-		database.DB.CreateInBatches(messages[i:end], batchSize)
+	}()
+}
+
+// publishDueAnnouncements 发送已到开始时间但尚未实际发送的公告
+func publishDueAnnouncements() {
+	now := time.Now().Unix()
+
+	var due []models.Announcement
+	err := database.DB.
+		Where("status = ? AND published_at IS NULL", 1).
+		Where("(start_time IS NULL OR start_time <= ?) AND (end_time IS NULL OR end_time >= ?)", now, now).
+		Find(&due).Error
+	if err != nil {
+		logger.Logger.Warn("查询待发送公告失败", zap.Error(err))
+		return
+	}
+
+	for i := range due {
+		if sendAnnouncementToUsers(&due[i]) {
+			logger.Logger.Info("定时发送公告", zap.Uint("announcement_id", due[i].ID), zap.String("title", due[i].Title))
+		}
+	}
+}
+
+// expireAnnouncements 将已过结束时间的公告下架
+func expireAnnouncements() {
+	now := time.Now().Unix()
+
+	result := database.DB.Model(&models.Announcement{}).
+		Where("status = ? AND end_time IS NOT NULL AND end_time < ?", 1, now).
+		Update("status", 2)
+	if result.Error != nil {
+		logger.Logger.Warn("下架过期公告失败", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		logger.Logger.Info("已下架过期公告", zap.Int64("count", result.RowsAffected))
 	}
 }
 
@@ -414,43 +513,38 @@ func SendUserMessage(c *gin.Context) {
 		req.Type = "info"
 	}
 
-	now := time.Now().Unix()
-	messages := make([]models.UserMessage, 0, len(req.UserIDs))
-
+	// 目标用户列表序列化为任务的target_users字段
+	targets := make([]string, 0, len(req.UserIDs))
 	for _, userID := range req.UserIDs {
-		messages = append(messages, models.UserMessage{
-			UserID:    userID,
-			Type:      req.Type,
-			Title:     req.Title,
-			Content:   req.Content,
-			RelatedID: req.RelatedID,
-			IsRead:    false,
-			CreatedAt: now,
-			UpdatedAt: now,
-		})
+		targets = append(targets, strconv.FormatUint(uint64(userID), 10))
 	}
 
-	// 分批插入
-	batchSize := 1000
-	for i := 0; i < len(messages); i += batchSize {
-		end := i + batchSize
-		if end > len(messages) {
-			end = len(messages)
-		}
-		if err := database.DB.CreateInBatches(messages[i:end], batchSize).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code":    500,
-				"message": "发送消息失败: " + err.Error(),
-			})
-			return
-		}
+	adminID, _ := c.Get("admin_id")
+
+	// 创建批量发送任务，后台按批插入（进度可通过任务接口查询）
+	job := &models.MessageBroadcastJob{
+		Type:        req.Type,
+		Title:       req.Title,
+		Content:     req.Content,
+		RelatedID:   req.RelatedID,
+		TargetUsers: strings.Join(targets, ","),
+		Total:       int64(len(req.UserIDs)),
+		CreatedBy:   adminID.(uint),
+	}
+	if err := startBroadcastJob(job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建发送任务失败: " + err.Error(),
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "发送成功",
+		"message": "发送任务已创建",
 		"data": gin.H{
-			"count": len(messages),
+			"job_id": job.ID,
+			"total":  len(req.UserIDs),
 		},
 	})
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/kaifa/game-platform/pkg/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestApplyAnnouncementUpdatePriorityZero(t *testing.T) {
+	announcement := models.Announcement{Priority: 2, Status: 1}
+
+	applyAnnouncementUpdate(&announcement, &announcementUpdateRequest{Priority: intPtr(0)})
+
+	if announcement.Priority != 0 {
+		t.Errorf("优先级应被设为0，实际: %d", announcement.Priority)
+	}
+	if announcement.Status != 1 {
+		t.Errorf("未传status不应被修改，实际: %d", announcement.Status)
+	}
+}
+
+func TestApplyAnnouncementUpdateStatusZero(t *testing.T) {
+	announcement := models.Announcement{Priority: 1, Status: 1}
+
+	applyAnnouncementUpdate(&announcement, &announcementUpdateRequest{Status: intPtr(0)})
+
+	if announcement.Status != 0 {
+		t.Errorf("status应被设为0，实际: %d", announcement.Status)
+	}
+	if announcement.Priority != 1 {
+		t.Errorf("未传priority不应被修改，实际: %d", announcement.Priority)
+	}
+}
+
+func TestApplyAnnouncementUpdateOmittedFieldsKept(t *testing.T) {
+	start := int64(100)
+	announcement := models.Announcement{
+		Title:       "原标题",
+		Content:     "原内容",
+		Type:        "info",
+		Priority:    1,
+		Status:      2,
+		StartTime:   &start,
+		TargetUsers: "all",
+	}
+
+	applyAnnouncementUpdate(&announcement, &announcementUpdateRequest{
+		Title:  "新标题",
+		Status: intPtr(1),
+	})
+
+	if announcement.Title != "新标题" {
+		t.Errorf("标题应被更新，实际: %s", announcement.Title)
+	}
+	if announcement.Status != 1 {
+		t.Errorf("status应被更新为1，实际: %d", announcement.Status)
+	}
+	if announcement.Content != "原内容" || announcement.Type != "info" ||
+		announcement.Priority != 1 || announcement.TargetUsers != "all" {
+		t.Errorf("未传字段不应被修改: %+v", announcement)
+	}
+	if announcement.StartTime == nil || *announcement.StartTime != start {
+		t.Errorf("未传start_time不应被修改")
+	}
+}
@@ -57,6 +57,22 @@ func getLogsFromES(c *gin.Context) ([]map[string]interface{}, int64, error) {
 	from := (page - 1) * pageSize
 
 	// 构建查询条件
+	filters := buildLogFilters(c)
+
+	// 构建查询
+	query := esClient.BuildQuery(filters)
+
+	// 执行搜索
+	logs, total, err := esClient.SearchOperationLogs(query, from, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// buildLogFilters 从请求参数构建 ES 查询过滤条件（列表和聚合共用）
+func buildLogFilters(c *gin.Context) map[string]interface{} {
 	filters := make(map[string]interface{})
 
 	if adminID := c.Query("admin_id"); adminID != "" {
@@ -99,16 +115,79 @@ func getLogsFromES(c *gin.Context) ([]map[string]interface{}, int64, error) {
 		}
 	}
 
-	// 构建查询
-	query := esClient.BuildQuery(filters)
+	return filters
+}
 
-	// 执行搜索
-	logs, total, err := esClient.SearchOperationLogs(query, from, pageSize)
+// CleanLogIndices 清理过期的 ES 操作日志索引。
+// dry_run 为 true 时只返回将被删除的索引，不实际删除。
+func CleanLogIndices(c *gin.Context) {
+	var req struct {
+		Days   int  `json:"days"`
+		DryRun bool `json:"dry_run"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Days < 1 {
+		req.Days = 30 // 默认保留30天
+	}
+
+	indices, err := esClient.CleanupOldLogIndices(req.Days, req.DryRun)
 	if err != nil {
-		return nil, 0, err
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "清理索引失败: " + err.Error(),
+		})
+		return
 	}
 
-	return logs, total, nil
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "清理成功",
+		"data": gin.H{
+			"dry_run": req.DryRun,
+			"indices": indices,
+		},
+	})
+}
+
+// GetOperationLogAggregations 操作日志聚合统计（审计看板用）。
+// agg_type 支持 by_admin/by_module/daily，过滤条件与列表查询一致。
+func GetOperationLogAggregations(c *gin.Context) {
+	if esClient.GetClient() == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "Elasticsearch 不可用，聚合统计暂不支持",
+		})
+		return
+	}
+
+	aggType := c.DefaultQuery("agg_type", "daily")
+
+	query := esClient.BuildQuery(buildLogFilters(c))
+
+	buckets, err := esClient.AggregateOperationLogs(query, aggType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "聚合统计失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"agg_type": aggType,
+			"buckets":  buckets,
+		},
+	})
 }
 
 // getLogsFromMySQL 从 MySQL 查询日志（降级方案）
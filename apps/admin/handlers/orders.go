@@ -44,6 +44,38 @@ func GetRechargeOrders(c *gin.Context) {
 	})
 }
 
+// GetMismatchedRecharges 获取到账金额与订单金额不一致的充值订单
+// 用于人工核对多付/少付的充值
+func GetMismatchedRecharges(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	chainType := c.Query("chain_type")
+
+	var orders []models.RechargeOrder
+	query := database.DB.Model(&models.RechargeOrder{}).
+		Where("received_amount > 0 AND ABS(received_amount - amount) > 0.009")
+
+	if chainType != "" {
+		query = query.Where("chain_type = ?", chainType)
+	}
+
+	offset := (page - 1) * pageSize
+	var total int64
+	query.Count(&total)
+	query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&orders)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"list":       orders,
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
 // GetWithdrawOrders 获取提现订单列表
 func GetWithdrawOrders(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
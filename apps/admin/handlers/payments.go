@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kaifa/game-platform/internal/database"
@@ -112,3 +113,195 @@ func BatchCollectUSDT(c *gin.Context) {
 		"message": "批量归集成功",
 	})
 }
+
+// GetWebhookDeliveries 获取回调推送记录列表
+func GetWebhookDeliveries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	status := c.Query("status")
+	orderID := c.Query("order_id")
+
+	var deliveries []models.WebhookDelivery
+	query := database.DB.Model(&models.WebhookDelivery{})
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if orderID != "" {
+		query = query.Where("order_id = ?", orderID)
+	}
+
+	offset := (page - 1) * pageSize
+	var total int64
+	query.Count(&total)
+	query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&deliveries)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"list":       deliveries,
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// ResendWebhookDelivery 重新推送失败的回调
+func ResendWebhookDelivery(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误",
+		})
+		return
+	}
+
+	delivery, err := services.ResendWebhookDelivery(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已重新提交推送",
+		"data":    delivery,
+	})
+}
+
+// GetCollectionRecords 获取归集记录列表
+func GetCollectionRecords(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	chainType := c.Query("chain_type")
+	status := c.Query("status")
+
+	var records []models.CollectionRecord
+	query := database.DB.Model(&models.CollectionRecord{})
+
+	if chainType != "" {
+		query = query.Where("chain_type = ?", chainType)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	offset := (page - 1) * pageSize
+	var total int64
+	query.Count(&total)
+	query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&records)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"list":       records,
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetReconciliationReport 获取支付对账报告
+// 汇总账本中成功的充值/提现交易，与主钱包链上余额对比，
+// 并列出卡在待支付/转账中状态超过阈值的订单
+func GetReconciliationReport(c *gin.Context) {
+	chainType := c.Query("chain_type")
+	if chainType != "trc20" && chainType != "erc20" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "链类型必须是trc20或erc20",
+		})
+		return
+	}
+
+	stuckThreshold, _ := strconv.ParseInt(c.DefaultQuery("stuck_threshold", "3600"), 10, 64)
+	if stuckThreshold <= 0 {
+		stuckThreshold = 3600
+	}
+
+	channel := "usdt_" + chainType
+
+	// 账本汇总（成功的充值/提现交易）
+	var totalRecharge, totalWithdraw float64
+	database.DB.Model(&models.Transaction{}).
+		Where("type = ? AND status = ? AND channel = ?", "recharge", 2, channel).
+		Select("COALESCE(SUM(amount), 0)").Scan(&totalRecharge)
+	database.DB.Model(&models.Transaction{}).
+		Where("type = ? AND status = ? AND channel = ?", "withdraw", 2, channel).
+		Select("COALESCE(SUM(amount), 0)").Scan(&totalWithdraw)
+
+	// 每用户小计
+	type userSubtotal struct {
+		UserID   uint    `json:"user_id"`
+		Recharge float64 `json:"recharge"`
+		Withdraw float64 `json:"withdraw"`
+	}
+	var subtotals []userSubtotal
+	database.DB.Model(&models.Transaction{}).
+		Select("user_id, "+
+			"COALESCE(SUM(CASE WHEN type = 'recharge' THEN amount ELSE 0 END), 0) AS recharge, "+
+			"COALESCE(SUM(CASE WHEN type = 'withdraw' THEN amount ELSE 0 END), 0) AS withdraw").
+		Where("status = ? AND channel = ?", 2, channel).
+		Group("user_id").
+		Scan(&subtotals)
+
+	// 已归集总额
+	var totalCollected float64
+	database.DB.Model(&models.CollectionRecord{}).
+		Where("chain_type = ? AND status = ?", chainType, 2).
+		Select("COALESCE(SUM(amount), 0)").Scan(&totalCollected)
+
+	// 主钱包链上余额
+	paymentService := services.NewPaymentService()
+	masterAddr, masterBalance, balanceErr := paymentService.GetMasterWalletBalance(chainType)
+	balanceNote := ""
+	if balanceErr != nil {
+		balanceNote = balanceErr.Error()
+	}
+
+	// 账本净额 vs 链上资产（主钱包余额 + 未归集的充值）
+	ledgerNet := totalRecharge - totalWithdraw
+	uncollected := totalRecharge - totalCollected
+	if uncollected < 0 {
+		uncollected = 0
+	}
+	discrepancy := (masterBalance + uncollected) - ledgerNet
+
+	// 卡单检查（超过阈值仍未到达最终状态的订单）
+	cutoff := time.Now().Unix() - stuckThreshold
+
+	var stuckRecharges []models.RechargeOrder
+	database.DB.Where("chain_type = ? AND status = ? AND created_at < ?", chainType, 1, cutoff).
+		Order("created_at ASC").Limit(100).Find(&stuckRecharges)
+
+	var stuckWithdraws []models.WithdrawOrder
+	database.DB.Where("chain_type = ? AND status IN (?) AND created_at < ?", chainType, []int8{1, 4}, cutoff).
+		Order("created_at ASC").Limit(100).Find(&stuckWithdraws)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"chain_type":      chainType,
+			"total_recharge":  totalRecharge,
+			"total_withdraw":  totalWithdraw,
+			"ledger_net":      ledgerNet,
+			"total_collected": totalCollected,
+			"uncollected":     uncollected,
+			"master_address":  masterAddr,
+			"master_balance":  masterBalance,
+			"balance_error":   balanceNote,
+			"discrepancy":     discrepancy,
+			"user_subtotals":  subtotals,
+			"stuck_recharges": stuckRecharges,
+			"stuck_withdraws": stuckWithdraws,
+		},
+	})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -281,9 +283,7 @@ func DeleteRole(c *gin.Context) {
 		return
 	}
 
-	// 删除权限关联
-	database.DB.Where("role_id = ?", id).Delete(&models.RolePermissionRelation{})
-	// 删除角色
+	// 软删除角色（DeletedAt），权限关联保留以便从回收站恢复
 	database.DB.Delete(&role)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -292,6 +292,189 @@ func DeleteRole(c *gin.Context) {
 	})
 }
 
+// GetRoleAdmins 查询持有指定角色的管理员列表
+func GetRoleAdmins(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的角色ID",
+		})
+		return
+	}
+
+	var role models.AdminRole
+	if err := database.DB.First(&role, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "角色不存在",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "获取角色失败: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	var admins []models.Admin
+	if err := database.DB.
+		Joins("JOIN admin_role_relations ON admin_role_relations.admin_id = admins.id").
+		Where("admin_role_relations.role_id = ?", id).
+		Order("admins.id ASC").
+		Find(&admins).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询管理员失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"role": role,
+			"list": admins,
+		},
+	})
+}
+
+// BatchAssignRole 批量给管理员分配角色（已持有该角色的跳过），整体在事务中执行
+func BatchAssignRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的角色ID",
+		})
+		return
+	}
+
+	var req struct {
+		AdminIDs []uint `json:"admin_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	var role models.AdminRole
+	if err := database.DB.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "角色不存在",
+		})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, adminID := range req.AdminIDs {
+			var admin models.Admin
+			if err := tx.First(&admin, adminID).Error; err != nil {
+				return fmt.Errorf("管理员%d不存在", adminID)
+			}
+
+			// 已持有该角色的跳过，避免唯一索引冲突
+			var count int64
+			tx.Model(&models.AdminRoleRelation{}).
+				Where("admin_id = ? AND role_id = ?", adminID, id).
+				Count(&count)
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(&models.AdminRoleRelation{
+				AdminID:   adminID,
+				RoleID:    uint(id),
+				CreatedAt: time.Now().Unix(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "批量分配失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "分配成功",
+	})
+}
+
+// BatchUnassignRole 批量移除管理员的角色，整体在事务中执行。
+// 安全保护：不能移除自己的最后一个角色，避免把自己锁在系统外
+func BatchUnassignRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的角色ID",
+		})
+		return
+	}
+
+	var req struct {
+		AdminIDs []uint `json:"admin_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	currentAdminID := c.GetUint("admin_id")
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, adminID := range req.AdminIDs {
+			if adminID == currentAdminID {
+				var holds int64
+				tx.Model(&models.AdminRoleRelation{}).
+					Where("admin_id = ? AND role_id = ?", adminID, id).
+					Count(&holds)
+				var total int64
+				tx.Model(&models.AdminRoleRelation{}).
+					Where("admin_id = ?", adminID).
+					Count(&total)
+				if holds > 0 && total <= 1 {
+					return errors.New("不能移除自己的最后一个角色")
+				}
+			}
+
+			if err := tx.Where("admin_id = ? AND role_id = ?", adminID, id).
+				Delete(&models.AdminRoleRelation{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "批量移除失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "移除成功",
+	})
+}
+
 // GetAllPermissions 获取所有权限列表
 func GetAllPermissions(c *gin.Context) {
 	var permissions []models.AdminPermission
@@ -311,9 +494,6 @@ func GetAllPermissions(c *gin.Context) {
 
 // GetAdmins 获取管理员列表
 func GetAdmins(c *gin.Context) {
-	var admins []models.Admin
-	var total int64
-
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
@@ -326,18 +506,10 @@ func GetAdmins(c *gin.Context) {
 
 	offset := (page - 1) * pageSize
 
-	query := database.DB.Model(&models.Admin{})
-
-	// 搜索
-	if search := c.Query("search"); search != "" {
-		query = query.Where("username LIKE ? OR nickname LIKE ?", "%"+search+"%", "%"+search+"%")
-	}
-
-	// 获取总数
-	query.Count(&total)
-
-	// 获取列表
-	if err := query.Offset(offset).Limit(pageSize).Order("id DESC").Find(&admins).Error; err != nil {
+	admins, total, err := queryAdminsPage(database.DB,
+		c.Query("search"), c.Query("status"), c.Query("role_id"),
+		offset, pageSize)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "获取管理员列表失败: " + err.Error(),
@@ -345,11 +517,6 @@ func GetAdmins(c *gin.Context) {
 		return
 	}
 
-	// 加载每个管理员的角色（使用 GORM 的 Preload）
-	for i := range admins {
-		database.DB.Preload("Roles").Find(&admins[i])
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"data": gin.H{
@@ -359,6 +526,45 @@ func GetAdmins(c *gin.Context) {
 	})
 }
 
+// queryAdminsPage 按搜索/状态/角色条件分页查询管理员，
+// 并用Preload一次性加载整页的角色，避免逐行查询的N+1问题
+func queryAdminsPage(db *gorm.DB, search, status, roleID string, offset, limit int) ([]models.Admin, int64, error) {
+	query := db.Model(&models.Admin{})
+
+	// 搜索
+	if search != "" {
+		query = query.Where("username LIKE ? OR nickname LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// 状态筛选
+	if status != "" {
+		query = query.Where("admins.status = ?", status)
+	}
+
+	// 角色筛选：只看持有指定角色的管理员
+	if roleID != "" {
+		query = query.
+			Joins("JOIN admin_role_relations ON admin_role_relations.admin_id = admins.id").
+			Where("admin_role_relations.role_id = ?", roleID)
+	}
+
+	// 获取总数
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取列表（整页Preload角色）
+	var admins []models.Admin
+	if err := query.Preload("Roles").
+		Offset(offset).Limit(limit).
+		Order("admins.id DESC").
+		Find(&admins).Error; err != nil {
+		return nil, 0, err
+	}
+	return admins, total, nil
+}
+
 // GetAdmin 获取管理员详情
 func GetAdmin(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -593,9 +799,7 @@ func DeleteAdmin(c *gin.Context) {
 		return
 	}
 
-	// 删除角色关联
-	database.DB.Where("admin_id = ?", admin.ID).Delete(&models.AdminRoleRelation{})
-	// 删除管理员
+	// 软删除管理员（DeletedAt），角色关联保留以便从回收站恢复
 	database.DB.Delete(&admin)
 
 	c.JSON(http.StatusOK, gin.H{
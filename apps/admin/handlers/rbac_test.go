@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/kaifa/game-platform/pkg/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newRBACTestDB 创建内存sqlite库并建好管理员/角色相关的表
+func newRBACTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("打开内存数据库失败: %v", err)
+	}
+	// :memory: 每个连接是独立的库，限制为单连接避免建表后查不到
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取底层连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(
+		&models.Admin{},
+		&models.AdminRole{},
+		&models.AdminRoleRelation{},
+	); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return db
+}
+
+func seedAdminWithRoles(t *testing.T, db *gorm.DB, username string, roleIDs ...uint) *models.Admin {
+	t.Helper()
+
+	admin := &models.Admin{
+		Username: username,
+		Password: "x",
+		Nickname: username,
+		Status:   1,
+	}
+	if err := db.Create(admin).Error; err != nil {
+		t.Fatalf("创建管理员失败: %v", err)
+	}
+	for _, roleID := range roleIDs {
+		if err := db.Create(&models.AdminRoleRelation{
+			AdminID: admin.ID,
+			RoleID:  roleID,
+		}).Error; err != nil {
+			t.Fatalf("创建角色关联失败: %v", err)
+		}
+	}
+	return admin
+}
+
+func TestQueryAdminsPagePreloadsRoles(t *testing.T) {
+	db := newRBACTestDB(t)
+
+	roleA := &models.AdminRole{RoleName: "运营", RoleCode: "ops", Status: 1}
+	roleB := &models.AdminRole{RoleName: "客服", RoleCode: "support", Status: 1}
+	for _, role := range []*models.AdminRole{roleA, roleB} {
+		if err := db.Create(role).Error; err != nil {
+			t.Fatalf("创建角色失败: %v", err)
+		}
+	}
+
+	seedAdminWithRoles(t, db, "alice", roleA.ID, roleB.ID)
+	seedAdminWithRoles(t, db, "bob", roleB.ID)
+	seedAdminWithRoles(t, db, "carol", roleA.ID)
+
+	admins, total, err := queryAdminsPage(db, "", "", "", 0, 20)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if total != 3 || len(admins) != 3 {
+		t.Fatalf("期望整页3个管理员, got total=%d len=%d", total, len(admins))
+	}
+	// 整页每个管理员的角色都应被Preload填充
+	for _, admin := range admins {
+		if len(admin.Roles) == 0 {
+			t.Errorf("管理员 %s 的角色未被Preload", admin.Username)
+		}
+	}
+	for _, admin := range admins {
+		if admin.Username == "alice" && len(admin.Roles) != 2 {
+			t.Errorf("alice 应有2个角色, got %d", len(admin.Roles))
+		}
+	}
+}
+
+func TestQueryAdminsPageFilters(t *testing.T) {
+	db := newRBACTestDB(t)
+
+	role := &models.AdminRole{RoleName: "运营", RoleCode: "ops", Status: 1}
+	if err := db.Create(role).Error; err != nil {
+		t.Fatalf("创建角色失败: %v", err)
+	}
+
+	seedAdminWithRoles(t, db, "alice", role.ID)
+	seedAdminWithRoles(t, db, "bob")
+	disabled := seedAdminWithRoles(t, db, "carol")
+	if err := db.Model(disabled).Update("status", 2).Error; err != nil {
+		t.Fatalf("更新状态失败: %v", err)
+	}
+
+	// 角色筛选：只有alice持有该角色
+	admins, total, err := queryAdminsPage(db, "", "", "1", 0, 20)
+	if err != nil {
+		t.Fatalf("角色筛选查询失败: %v", err)
+	}
+	if total != 1 || len(admins) != 1 || admins[0].Username != "alice" {
+		t.Fatalf("角色筛选应只返回alice, got total=%d admins=%+v", total, admins)
+	}
+
+	// 状态筛选：carol已禁用
+	admins, total, err = queryAdminsPage(db, "", "1", "", 0, 20)
+	if err != nil {
+		t.Fatalf("状态筛选查询失败: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("状态筛选应返回2个正常状态的管理员, got %d", total)
+	}
+	for _, admin := range admins {
+		if admin.Username == "carol" {
+			t.Errorf("禁用的管理员不应出现在状态筛选结果中")
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/pkg/models"
+)
+
+// recycleModel 回收站支持的实体类型 → 模型实例
+// （软删除的数据从正常查询中过滤，这里统一查看与恢复）
+func recycleModel(entity string) interface{} {
+	switch entity {
+	case "roles":
+		return &models.AdminRole{}
+	case "admins":
+		return &models.Admin{}
+	case "announcements":
+		return &models.Announcement{}
+	case "user-messages":
+		return &models.UserMessage{}
+	default:
+		return nil
+	}
+}
+
+// ListDeletedEntities 查看回收站（软删除的角色/管理员/公告/用户消息）
+func ListDeletedEntities(c *gin.Context) {
+	entity := c.Param("type")
+
+	query := database.DB.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("id DESC").
+		Limit(200)
+
+	var (
+		list interface{}
+		err  error
+	)
+	switch entity {
+	case "roles":
+		var items []models.AdminRole
+		err = query.Find(&items).Error
+		list = items
+	case "admins":
+		var items []models.Admin
+		err = query.Find(&items).Error
+		list = items
+	case "announcements":
+		var items []models.Announcement
+		err = query.Find(&items).Error
+		list = items
+	case "user-messages":
+		var items []models.UserMessage
+		err = query.Find(&items).Error
+		list = items
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "不支持的回收站类型",
+		})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询回收站失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"list": list,
+		},
+	})
+}
+
+// RestoreDeletedEntity 从回收站恢复软删除的实体
+func RestoreDeletedEntity(c *gin.Context) {
+	entity := c.Param("type")
+	model := recycleModel(entity)
+	if model == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "不支持的回收站类型",
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的ID",
+		})
+		return
+	}
+
+	result := database.DB.Unscoped().
+		Model(model).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "恢复失败: " + result.Error.Error(),
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "回收站中不存在该数据",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "恢复成功",
+	})
+}
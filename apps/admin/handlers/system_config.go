@@ -1,34 +1,61 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kaifa/game-platform/internal/database"
+	systemconfigrepo "github.com/kaifa/game-platform/internal/repository/systemconfig"
 	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/kaifa/game-platform/pkg/services"
 	"gorm.io/gorm"
 )
 
-// GetSystemConfigs 获取系统配置列表
-func GetSystemConfigs(c *gin.Context) {
-	var configs []models.SystemConfig
+var systemConfigRepo systemconfigrepo.Repository
 
-	query := database.DB.Model(&models.SystemConfig{})
-
-	// 按分组筛选
-	if groupName := c.Query("group_name"); groupName != "" {
-		query = query.Where("group_name = ?", groupName)
-	}
+// SetSystemConfigRepository 设置系统配置仓储
+func SetSystemConfigRepository(repo systemconfigrepo.Repository) {
+	systemConfigRepo = repo
+}
 
-	// 只获取公开配置
-	if c.Query("public") == "true" {
-		query = query.Where("is_public = ?", true)
+// validateConfigValue 按配置类型校验配置值
+func validateConfigValue(configType, value string) error {
+	switch configType {
+	case "int":
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("配置值不是有效的整数: %s", value)
+		}
+	case "float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("配置值不是有效的浮点数: %s", value)
+		}
+	case "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("配置值不是有效的布尔值: %s", value)
+		}
+	case "json":
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("配置值不是有效的JSON")
+		}
+	case "string", "":
+		// 字符串不需要校验
+	default:
+		return fmt.Errorf("不支持的配置类型: %s（支持 string/int/float/bool/json）", configType)
 	}
+	return nil
+}
 
-	if err := query.Order("group_name ASC, config_key ASC").Find(&configs).Error; err != nil {
+// ListSystemConfigs 获取系统配置列表（支持按分组和公开标记筛选）
+func ListSystemConfigs(c *gin.Context) {
+	configs, err := systemConfigRepo.List(
+		c.Request.Context(),
+		c.Query("group_name"),
+		c.Query("public") == "true",
+	)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "获取配置失败: " + err.Error(),
@@ -46,8 +73,8 @@ func GetSystemConfigs(c *gin.Context) {
 func GetSystemConfig(c *gin.Context) {
 	configKey := c.Param("key")
 
-	var config models.SystemConfig
-	if err := database.DB.Where("config_key = ?", configKey).First(&config).Error; err != nil {
+	config, err := systemConfigRepo.GetByKey(c.Request.Context(), configKey)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"code":    404,
@@ -68,15 +95,18 @@ func GetSystemConfig(c *gin.Context) {
 	})
 }
 
-// CreateSystemConfig 创建系统配置
-func CreateSystemConfig(c *gin.Context) {
+// UpsertSystemConfig 新增或更新系统配置。
+// 按 config_type 校验值的合法性，更新成功后发布 config-changed 事件，
+// 各业务实例收到事件后失效本地缓存。
+func UpsertSystemConfig(c *gin.Context) {
+	configKey := c.Param("key")
+
 	var req struct {
-		ConfigKey   string `json:"config_key" binding:"required"`
 		ConfigValue string `json:"config_value"`
-		ConfigType  string `json:"config_type" binding:"required"`
+		ConfigType  string `json:"config_type"`
 		GroupName   string `json:"group_name"`
 		Description string `json:"description"`
-		IsPublic    bool   `json:"is_public"`
+		IsPublic    *bool  `json:"is_public"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -87,108 +117,32 @@ func CreateSystemConfig(c *gin.Context) {
 		return
 	}
 
-	// 检查配置键是否已存在
-	var existConfig models.SystemConfig
-	if err := database.DB.Where("config_key = ?", req.ConfigKey).First(&existConfig).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "配置键已存在",
-		})
-		return
-	}
-
-	if req.GroupName == "" {
-		req.GroupName = "default"
-	}
-	if req.ConfigType == "" {
-		req.ConfigType = "string"
-	}
-
-	now := time.Now().Unix()
-	config := models.SystemConfig{
-		ConfigKey:   req.ConfigKey,
-		ConfigValue: req.ConfigValue,
-		ConfigType:  req.ConfigType,
-		GroupName:   req.GroupName,
-		Description: req.Description,
-		IsPublic:    req.IsPublic,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	if err := database.DB.Create(&config).Error; err != nil {
+	// 读取已有配置（不存在则新建）
+	config, err := systemConfigRepo.GetByKey(c.Request.Context(), configKey)
+	if err != nil && err != gorm.ErrRecordNotFound {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
-			"message": "创建配置失败: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "创建成功",
-		"data":    config,
-	})
-}
-
-// UpdateSystemConfig 更新系统配置
-func UpdateSystemConfig(c *gin.Context) {
-	configKey := c.Param("key")
-
-	var req struct {
-		ConfigValue interface{} `json:"config_value"` // 使用 interface{} 接受字符串或数字
-		Description string      `json:"description"`
-		IsPublic    *bool       `json:"is_public"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "参数错误: " + err.Error(),
+			"message": "获取配置失败: " + err.Error(),
 		})
 		return
 	}
 
-	var config models.SystemConfig
-	if err := database.DB.Where("config_key = ?", configKey).First(&config).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"code":    404,
-				"message": "配置不存在",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code":    500,
-				"message": "获取配置失败: " + err.Error(),
-			})
+	if config == nil {
+		now := time.Now().Unix()
+		config = &models.SystemConfig{
+			ConfigKey:  configKey,
+			ConfigType: "string",
+			GroupName:  "default",
+			CreatedAt:  now,
+			UpdatedAt:  now,
 		}
-		return
 	}
 
-	// 更新字段
-	if req.ConfigValue != nil {
-		// 将 config_value 转换为字符串
-		var configValueStr string
-		switch v := req.ConfigValue.(type) {
-		case string:
-			configValueStr = v
-		case float64:
-			// JSON 数字通常解析为 float64
-			configValueStr = strconv.FormatFloat(v, 'f', -1, 64)
-		case int:
-			configValueStr = strconv.Itoa(v)
-		case int64:
-			configValueStr = strconv.FormatInt(v, 10)
-		case bool:
-			if v {
-				configValueStr = "true"
-			} else {
-				configValueStr = "false"
-			}
-		default:
-			configValueStr = fmt.Sprintf("%v", v)
-		}
-		config.ConfigValue = configValueStr
+	if req.ConfigType != "" {
+		config.ConfigType = req.ConfigType
+	}
+	if req.GroupName != "" {
+		config.GroupName = req.GroupName
 	}
 	if req.Description != "" {
 		config.Description = req.Description
@@ -196,19 +150,31 @@ func UpdateSystemConfig(c *gin.Context) {
 	if req.IsPublic != nil {
 		config.IsPublic = *req.IsPublic
 	}
-	config.UpdatedAt = time.Now().Unix()
+	config.ConfigValue = req.ConfigValue
+
+	// 类型校验
+	if err := validateConfigValue(config.ConfigType, config.ConfigValue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
 
-	if err := database.DB.Save(&config).Error; err != nil {
+	if err := systemConfigRepo.Upsert(c.Request.Context(), config); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
-			"message": "更新配置失败: " + err.Error(),
+			"message": "保存配置失败: " + err.Error(),
 		})
 		return
 	}
 
+	// 通知各实例失效缓存
+	_ = services.GetSystemConfigService().PublishConfigChanged(c.Request.Context(), configKey)
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "更新成功",
+		"message": "保存成功",
 		"data":    config,
 	})
 }
@@ -217,7 +183,7 @@ func UpdateSystemConfig(c *gin.Context) {
 func DeleteSystemConfig(c *gin.Context) {
 	configKey := c.Param("key")
 
-	if err := database.DB.Where("config_key = ?", configKey).Delete(&models.SystemConfig{}).Error; err != nil {
+	if err := systemConfigRepo.DeleteByKey(c.Request.Context(), configKey); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "删除配置失败: " + err.Error(),
@@ -225,6 +191,9 @@ func DeleteSystemConfig(c *gin.Context) {
 		return
 	}
 
+	// 通知各实例失效缓存
+	_ = services.GetSystemConfigService().PublishConfigChanged(c.Request.Context(), configKey)
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "删除成功",
@@ -233,12 +202,14 @@ func DeleteSystemConfig(c *gin.Context) {
 
 // GetSystemConfigGroups 获取配置分组列表
 func GetSystemConfigGroups(c *gin.Context) {
-	var groups []string
-
-	database.DB.Model(&models.SystemConfig{}).
-		Select("DISTINCT group_name").
-		Order("group_name ASC").
-		Pluck("group_name", &groups)
+	groups, err := systemConfigRepo.ListGroups(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取分组失败: " + err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
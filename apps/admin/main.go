@@ -10,12 +10,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/apps/admin/handlers"
 	"github.com/kaifa/game-platform/apps/admin/router"
 	"github.com/kaifa/game-platform/internal/cache"
 	"github.com/kaifa/game-platform/internal/config"
 	"github.com/kaifa/game-platform/internal/database"
 	"github.com/kaifa/game-platform/internal/elasticsearch"
+	"github.com/kaifa/game-platform/internal/lock"
 	"github.com/kaifa/game-platform/internal/logger"
+	mysqlrepo "github.com/kaifa/game-platform/internal/repository/mysql"
+	gamesvc "github.com/kaifa/game-platform/internal/service/game"
+	leaderboardsrv "github.com/kaifa/game-platform/internal/service/leaderboard"
+	"github.com/kaifa/game-platform/internal/storage"
+	"github.com/kaifa/game-platform/pkg/services"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +33,11 @@ func main() {
 		panic(fmt.Sprintf("加载配置失败: %v", err))
 	}
 
+	// 校验配置（聚合所有问题，启动时尽早暴露）
+	if err := config.Validate(cfg); err != nil {
+		panic(fmt.Sprintf("配置校验失败: %v", err))
+	}
+
 	// 初始化日志
 	if err := logger.InitLogger(cfg.Log); err != nil {
 		panic(fmt.Sprintf("初始化日志失败: %v", err))
@@ -33,12 +45,15 @@ func main() {
 	defer logger.Sync()
 
 	// 初始化数据库
-	_, err = database.InitMySQL(cfg)
+	db, err := database.InitMySQL(cfg)
 	if err != nil {
 		logger.Logger.Fatal("初始化数据库失败", zap.Error(err))
 	}
 	defer database.Close()
 
+	// 注入系统配置仓储
+	handlers.SetSystemConfigRepository(mysqlrepo.NewSystemConfigRepository(db))
+
 	// 初始化Redis（可选）
 	if _, err := cache.InitRedis(cfg); err != nil {
 		logger.Logger.Warn("Redis连接失败，将使用降级方案", zap.Error(err))
@@ -47,11 +62,54 @@ func main() {
 	}
 	defer cache.Close()
 
-	// 初始化Elasticsearch（必需）
+	// 游戏管理器（强制结束游戏用，依赖Redis；Redis不可用时该功能不可用）
+	if cache.RDB != nil {
+		roomRepo := mysqlrepo.NewRoomRepository(db)
+		userRepo := mysqlrepo.NewUserRepository(db)
+		gameRecordRepo := mysqlrepo.NewGameRecordRepository(db)
+		transactionRepo := mysqlrepo.NewTransactionRepository(db)
+		handlers.SetGameManager(gamesvc.NewManager(
+			storage.NewRedisGameStateStorage(cache.RDB),
+			roomRepo,
+			userRepo,
+			gameRecordRepo,
+			transactionRepo,
+			leaderboardsrv.New(cache.RDB, userRepo),
+			lock.NewRedisLock(cache.RDB),
+			lock.NewLocalRWLock(),
+		))
+	}
+
+	// 系统配置缓存（Redis不可用时退化为本地TTL缓存）
+	services.InitSystemConfigCache(cache.RDB)
+
+	// 登录失败限制（Redis不可用时退化为本地计数）
+	handlers.SetLoginLimiter(services.NewLoginLimiter(
+		cache.RDB,
+		cfg.AdminSecurity.MaxLoginFailures,
+		time.Duration(cfg.AdminSecurity.LockoutMinutes)*time.Minute,
+	))
+
+	// 初始化Elasticsearch（可选：不可用时操作日志读写退化到 MySQL）
 	if err := elasticsearch.Init(cfg); err != nil {
-		logger.Logger.Fatal("Elasticsearch连接失败，服务无法启动", zap.Error(err))
+		logger.Logger.Warn("Elasticsearch连接失败，操作日志将只使用 MySQL", zap.Error(err))
+	} else {
+		logger.Logger.Info("Elasticsearch连接成功")
+		// 按配置定期清理过期日志索引（log_retention_days=0 表示不清理）
+		elasticsearch.StartLogIndexRetention(cfg.ES.LogRetentionDays)
 	}
-	logger.Logger.Info("Elasticsearch连接成功")
+
+	// 公告实时推送（经游戏服务器下发给在线用户）
+	handlers.SetAnnouncementNotifyURL(cfg.GameServerBaseURL() + "/internal/announcement/notify")
+
+	// 强制结束游戏后经游戏服务器广播 game_end
+	handlers.SetRoomNotifyURL(cfg.GameServerBaseURL() + "/internal/room/notify")
+
+	// 公告定时发布/过期下架
+	handlers.StartAnnouncementScheduler()
+
+	// 续发重启前未完成的批量消息任务
+	handlers.ResumeBroadcastJobs()
 
 	// 设置Gin模式
 	if cfg.Server.Mode == "release" {
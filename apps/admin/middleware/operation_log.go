@@ -3,6 +3,8 @@ package middleware
 import (
 	"bytes"
 	"io"
+	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -14,6 +16,18 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxLoggedBodySize 请求/响应体最多记录的字节数（超出部分丢弃，避免大payload占用内存）
+const maxLoggedBodySize = 2000
+
+// sensitiveFieldPattern 匹配JSON中的敏感字段（密码/助记词/密钥/令牌），值替换为***。
+// 基于正则而非反序列化，对截断后的不完整JSON同样有效。
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)("[^"]*(?:password|mnemonic|secret|token)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// redactSensitiveFields 脱敏请求/响应体中的敏感字段
+func redactSensitiveFields(body string) string {
+	return sensitiveFieldPattern.ReplaceAllString(body, `${1}"***"`)
+}
+
 // OperationLogMiddleware 操作日志中间件
 func OperationLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -23,6 +37,13 @@ func OperationLogMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 只记录产生变更的请求（只读请求不入审计日志）
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
 		startTime := time.Now()
 
 		// 获取管理员信息
@@ -48,17 +69,16 @@ func OperationLogMiddleware() gin.HandlerFunc {
 			}
 		}
 
-		// 读取请求体（用于记录请求参数）
-		var requestBody []byte
+		// 流式捕获请求体（只保留前 maxLoggedBodySize 字节，不影响handler读取）
+		requestBody := &cappedBuffer{max: maxLoggedBodySize}
 		if c.Request.Body != nil {
-			requestBody, _ = io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+			c.Request.Body = io.NopCloser(io.TeeReader(c.Request.Body, requestBody))
 		}
 
-		// 创建响应写入器包装器
+		// 创建响应写入器包装器（同样限制捕获大小）
 		responseWriter := &responseWriter{
 			ResponseWriter: c.Writer,
-			body:           &bytes.Buffer{},
+			body:           &cappedBuffer{max: maxLoggedBodySize},
 		}
 		c.Writer = responseWriter
 
@@ -81,8 +101,8 @@ func OperationLogMiddleware() gin.HandlerFunc {
 			Path:      c.Request.URL.Path,
 			IP:        c.ClientIP(),
 			UserAgent: c.Request.UserAgent(),
-			Request:   string(requestBody),
-			Response:  responseWriter.body.String(),
+			Request:   redactSensitiveFields(requestBody.String()),
+			Response:  redactSensitiveFields(responseWriter.body.String()),
 			Status:    1,
 			Duration:  duration,
 			CreatedAt: time.Now().Unix(),
@@ -91,21 +111,13 @@ func OperationLogMiddleware() gin.HandlerFunc {
 		// 如果状态码不是2xx，记录为失败
 		if c.Writer.Status() >= 400 {
 			log.Status = 2
-			log.ErrorMsg = responseWriter.body.String()
+			log.ErrorMsg = log.Response
 			// 限制错误信息长度
 			if len(log.ErrorMsg) > 500 {
 				log.ErrorMsg = log.ErrorMsg[:500]
 			}
 		}
 
-		// 限制请求和响应长度
-		if len(log.Request) > 2000 {
-			log.Request = log.Request[:2000] + "..."
-		}
-		if len(log.Response) > 2000 {
-			log.Response = log.Response[:2000] + "..."
-		}
-
 		// 异步保存日志到 MySQL 和 Elasticsearch
 		go func() {
 			// 先保存到 MySQL，获取 ID
@@ -144,7 +156,7 @@ func OperationLogMiddleware() gin.HandlerFunc {
 // responseWriter 响应写入器包装器
 type responseWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body *cappedBuffer
 }
 
 func (w *responseWriter) Write(b []byte) (int, error) {
@@ -152,6 +164,34 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// cappedBuffer 只保留前 max 字节的缓冲区，超出部分直接丢弃
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remain := b.max - b.buf.Len(); remain > 0 {
+		if len(p) > remain {
+			b.buf.Write(p[:remain])
+			b.truncated = true
+		} else {
+			b.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "..."
+	}
+	return b.buf.String()
+}
+
 // parseModuleAndAction 解析模块和动作
 func parseModuleAndAction(path, method string) (module, action string) {
 	// 根据路径解析模块
@@ -192,6 +232,7 @@ func parseModuleAndAction(path, method string) (module, action string) {
 		"dashboard":         "仪表盘",
 		"permissions":       "权限管理",
 		"operation-logs":    "操作日志",
+		"game-rooms":        "游戏房间管理",
 		"system-configs":    "系统设置",
 		"profile":           "个人中心",
 	}
@@ -219,7 +260,7 @@ func parseModuleAndAction(path, method string) (module, action string) {
 		// 特殊处理：某些POST请求可能是其他操作
 		if len(parts) > 1 {
 			lastPart := parts[len(parts)-1]
-			if lastPart == "audit" || lastPart == "batch-delete" || lastPart == "clean" || lastPart == "collect" || lastPart == "batch-collect" {
+			if lastPart == "audit" || lastPart == "batch-delete" || lastPart == "clean" || lastPart == "collect" || lastPart == "batch-collect" || lastPart == "force-end" {
 				action = lastPart
 			} else {
 				action = "create"
@@ -4,53 +4,74 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/pkg/services"
+	"go.uber.org/zap"
 )
 
-// RequirePermission 权限检查中间件
+// dbPermissionsKey 当前请求已加载的权限集合的上下文键（同一请求内多次检查只查一次库）
+const dbPermissionsKey = "db_permissions"
+
+// RequirePermission 权限检查中间件。
+// 每次请求从数据库加载管理员的角色→权限集合（同一请求内缓存），
+// 角色或权限变更后下一个请求立即生效，不依赖Token中快照的权限列表；
+// 数据库查询失败时退回Token中的权限，避免DB抖动导致管理后台全面403。
 func RequirePermission(permissionCode string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取权限列表
-		permissions, exists := c.Get("permissions")
-		if !exists {
+		permSet := loadPermissionSet(c)
+
+		if _, ok := permSet[permissionCode]; !ok {
 			c.JSON(http.StatusForbidden, gin.H{
 				"code":    403,
-				"message": "权限不足",
+				"message": "权限不足：缺少 " + permissionCode + " 权限",
 			})
 			c.Abort()
 			return
 		}
 
-		// 转换权限列表
-		permList, ok := permissions.([]string)
-		if !ok {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "权限不足",
-			})
-			c.Abort()
-			return
+		c.Next()
+	}
+}
+
+// loadPermissionSet 加载当前管理员的权限集合（请求级缓存）
+func loadPermissionSet(c *gin.Context) map[string]struct{} {
+	// 同一请求内已加载过，直接复用
+	if cached, exists := c.Get(dbPermissionsKey); exists {
+		if permSet, ok := cached.(map[string]struct{}); ok {
+			return permSet
 		}
+	}
+
+	permSet := make(map[string]struct{})
 
-		// 检查是否有指定权限
-		hasPermission := false
-		for _, perm := range permList {
-			if perm == permissionCode {
-				hasPermission = true
-				break
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		return permSet
+	}
+	adminIDUint, ok := adminID.(uint)
+	if !ok {
+		return permSet
+	}
+
+	codes, err := services.NewAdminService().GetAdminWithPermissions(adminIDUint)
+	if err != nil {
+		// 数据库不可用时退回Token中的权限快照
+		logger.Logger.Warn("加载权限失败，退回Token权限", zap.Uint("admin_id", adminIDUint), zap.Error(err))
+		if tokenPerms, exists := c.Get("permissions"); exists {
+			if permList, ok := tokenPerms.([]string); ok {
+				for _, perm := range permList {
+					permSet[perm] = struct{}{}
+				}
 			}
 		}
-
-		if !hasPermission {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "权限不足：缺少 " + permissionCode + " 权限",
-			})
-			c.Abort()
-			return
+	} else {
+		for _, code := range codes {
+			permSet[code] = struct{}{}
 		}
-
-		c.Next()
 	}
+
+	c.Set(dbPermissionsKey, permSet)
+	return permSet
 }
 
 // RequireRole 角色检查中间件（通过权限实现）
@@ -30,6 +30,9 @@ func Setup(cfg *config.Config) *gin.Engine {
 		})
 	})
 
+	// 就绪检查（探测依赖，供K8s readiness probe使用）
+	r.GET("/ready", handlers.Ready)
+
 	// API路由组
 	v1 := r.Group("/api/v1")
 
@@ -71,6 +74,7 @@ func Setup(cfg *config.Config) *gin.Engine {
 		rechargeOrders.Use(middleware.RequirePermission(utils.PermissionRechargeOrdersList))
 		{
 			rechargeOrders.GET("", handlers.GetRechargeOrders)
+			rechargeOrders.GET("/mismatched", handlers.GetMismatchedRecharges)
 		}
 
 		// 提现订单
@@ -93,6 +97,16 @@ func Setup(cfg *config.Config) *gin.Engine {
 		{
 			payments.POST("/collect", middleware.RequirePermission(utils.PermissionPaymentsCollect), handlers.CollectUSDT)
 			payments.POST("/batch-collect", middleware.RequirePermission(utils.PermissionPaymentsBatchCollect), handlers.BatchCollectUSDT)
+
+			// 对账报告
+			payments.GET("/reconciliation", middleware.RequirePermission(utils.PermissionPaymentsCollect), handlers.GetReconciliationReport)
+
+			// 归集记录
+			payments.GET("/collection-records", middleware.RequirePermission(utils.PermissionPaymentsCollect), handlers.GetCollectionRecords)
+
+			// 回调推送记录（复用归集权限，后续可以添加专门权限）
+			payments.GET("/webhook-deliveries", middleware.RequirePermission(utils.PermissionPaymentsCollect), handlers.GetWebhookDeliveries)
+			payments.POST("/webhook-deliveries/:id/resend", middleware.RequirePermission(utils.PermissionPaymentsCollect), handlers.ResendWebhookDelivery)
 		}
 
 		// 系统管理 - 角色管理
@@ -104,6 +118,9 @@ func Setup(cfg *config.Config) *gin.Engine {
 			roles.POST("", middleware.RequirePermission(utils.PermissionRolesCreate), handlers.CreateRole)
 			roles.PUT("/:id", middleware.RequirePermission(utils.PermissionRolesUpdate), handlers.UpdateRole)
 			roles.DELETE("/:id", middleware.RequirePermission(utils.PermissionRolesDelete), handlers.DeleteRole)
+			roles.GET("/:id/admins", handlers.GetRoleAdmins)
+			roles.POST("/:id/assign", middleware.RequirePermission(utils.PermissionRolesUpdate), handlers.BatchAssignRole)
+			roles.POST("/:id/unassign", middleware.RequirePermission(utils.PermissionRolesUpdate), handlers.BatchUnassignRole)
 		}
 
 		// 系统管理 - 权限管理
@@ -121,6 +138,15 @@ func Setup(cfg *config.Config) *gin.Engine {
 			admins.POST("", middleware.RequirePermission(utils.PermissionAdminsCreate), handlers.CreateAdmin)
 			admins.PUT("/:id", middleware.RequirePermission(utils.PermissionAdminsUpdate), handlers.UpdateAdmin)
 			admins.DELETE("/:id", middleware.RequirePermission(utils.PermissionAdminsDelete), handlers.DeleteAdmin)
+			admins.POST("/:id/unlock", middleware.RequirePermission(utils.PermissionAdminsUpdate), handlers.UnlockAdmin)
+		}
+
+		// 回收站：查看与恢复软删除的数据（复用角色管理权限）
+		recycle := admin.Group("/recycle")
+		recycle.Use(middleware.RequirePermission(utils.PermissionRolesList))
+		{
+			recycle.GET("/:type", handlers.ListDeletedEntities)
+			recycle.POST("/:type/:id/restore", handlers.RestoreDeletedEntity)
 		}
 
 		// 操作日志
@@ -128,23 +154,38 @@ func Setup(cfg *config.Config) *gin.Engine {
 		logs.Use(middleware.RequirePermission(utils.PermissionRolesList)) // 使用已有权限，后续可以添加专门权限
 		{
 			logs.GET("", handlers.GetOperationLogs)
+			logs.GET("/aggregations", handlers.GetOperationLogAggregations)
 			logs.GET("/:id", handlers.GetOperationLog)
 			logs.DELETE("/:id", handlers.DeleteOperationLog)
 			logs.POST("/batch-delete", handlers.BatchDeleteOperationLogs)
 			logs.POST("/clean", handlers.CleanOldLogs)
+			logs.POST("/clean-indices", handlers.CleanLogIndices)
 		}
 
 		// 系统设置
 		configs := admin.Group("/system-configs")
 		{
-			configs.GET("", handlers.GetSystemConfigs)
+			configs.GET("", handlers.ListSystemConfigs)
 			configs.GET("/groups", handlers.GetSystemConfigGroups)
 			configs.GET("/:key", handlers.GetSystemConfig)
-			configs.PUT("/:key", handlers.UpdateSystemConfig)
-			configs.POST("", handlers.CreateSystemConfig)
+			configs.PUT("/:key", handlers.UpsertSystemConfig) // 不存在时创建
 			configs.DELETE("/:key", handlers.DeleteSystemConfig)
 		}
 
+		// 游戏房间管理
+		gameRooms := admin.Group("/game-rooms")
+		{
+			gameRooms.POST("/:roomId/force-end", middleware.RequirePermission(utils.PermissionGameRoomsForceEnd), handlers.ForceEndGame)
+		}
+
+		// 游戏记录分析（基于ES，复用仪表盘权限）
+		gameRecords := admin.Group("/game-records")
+		gameRecords.Use(middleware.RequirePermission(utils.PermissionDashboardView))
+		{
+			gameRecords.GET("", handlers.SearchGameRecords)
+			gameRecords.GET("/aggregations", handlers.GetGameRecordAggregations)
+		}
+
 		// 消息管理
 		messages := admin.Group("/messages")
 		{
@@ -166,6 +207,12 @@ func Setup(cfg *config.Config) *gin.Engine {
 				userMessages.DELETE("/:id", handlers.DeleteUserMessage)
 				userMessages.POST("/batch-delete", handlers.BatchDeleteUserMessages)
 			}
+
+			// 批量发送任务进度
+			broadcastJobs := messages.Group("/broadcast-jobs")
+			{
+				broadcastJobs.GET("/:id", handlers.GetBroadcastJob)
+			}
 		}
 	}
 
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/discovery"
+)
+
+var registryInstance discovery.Registry
+
+// SetRegistry 设置服务发现注册器（用于查询游戏服务器实例）
+func SetRegistry(r discovery.Registry) {
+	registryInstance = r
+}
+
+// GetGameServer 返回当前负载最低的游戏服务器实例，供客户端选择WebSocket接入点
+func GetGameServer(c *gin.Context) {
+	if registryInstance == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "服务发现未启用",
+		})
+		return
+	}
+
+	instances, err := registryInstance.ListInstances(c.Request.Context(), "game-server")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询游戏服务器失败",
+		})
+		return
+	}
+
+	if len(instances) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "暂无可用的游戏服务器",
+		})
+		return
+	}
+
+	// 选择连接数最少的实例（未上报连接数视为0）
+	best := instances[0]
+	bestLoad := instanceConnections(best)
+	for _, inst := range instances[1:] {
+		if load := instanceConnections(inst); load < bestLoad {
+			best = inst
+			bestLoad = load
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"instance_id": best.InstanceID,
+			"address":     best.Address,
+			"port":        best.Port,
+			"connections": bestLoad,
+			"ws_url":      fmt.Sprintf("ws://%s:%d/ws", best.Address, best.Port),
+		},
+	})
+}
+
+// instanceConnections 解析实例元数据中的连接数
+func instanceConnections(inst discovery.ServiceInstance) int {
+	if inst.Meta == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(inst.Meta["connections"])
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -12,10 +12,12 @@ import (
 	gamerecordsvc "github.com/kaifa/game-platform/internal/service/gamerecord"
 	roomsvc "github.com/kaifa/game-platform/internal/service/room"
 	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/kaifa/game-platform/pkg/services"
 )
 
 var (
 	roomService       roomsvc.Service
+	roomRepository    roomrepo.Repository
 	gameManager       *gamesvc.Manager // ✅ 使用新的 GameManager
 	gameRecordService gamerecordsvc.Service
 )
@@ -25,6 +27,11 @@ func SetRoomService(service roomsvc.Service) {
 	roomService = service
 }
 
+// SetRoomRepository 注入房间Repository（用于操作游戏状态前的房间成员校验）
+func SetRoomRepository(repo roomrepo.Repository) {
+	roomRepository = repo
+}
+
 // SetGameManager 注入游戏管理器实现（使用新的重构版本）
 func SetGameManager(manager *gamesvc.Manager) {
 	gameManager = manager
@@ -46,6 +53,39 @@ func ensureGameManager(c *gin.Context) bool {
 	return true
 }
 
+// isRoomPlayer 判断用户是否在房间的玩家列表中
+func isRoomPlayer(room *models.GameRoom, userID uint) bool {
+	var players []services.PlayerInfo
+	if err := json.Unmarshal(room.Players, &players); err != nil {
+		return false
+	}
+	for _, p := range players {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureRoomMembership 校验用户确实坐在该房间中，未入座返回403。
+// 防止知道roomID的用户绕过座位直接操作游戏状态（Manager内部的校验是第二道防线）。
+func ensureRoomMembership(c *gin.Context, roomID string, userID uint) bool {
+	if roomRepository == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "房间数据未初始化"})
+		return false
+	}
+	room, err := roomRepository.GetByRoomID(c.Request.Context(), roomID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "房间不存在"})
+		return false
+	}
+	if !isRoomPlayer(room, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "您不在该房间中"})
+		return false
+	}
+	return true
+}
+
 // SetGameRecordService 注入游戏记录服务实现。
 func SetGameRecordService(service gamerecordsvc.Service) {
 	gameRecordService = service
@@ -83,7 +123,7 @@ func CreateRoom(c *gin.Context) {
 	}
 	room, err := roomService.CreateRoom(c.Request.Context(), userID.(uint), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "创建房间成功", "data": room})
@@ -107,7 +147,7 @@ func JoinRoom(c *gin.Context) {
 
 	room, err := roomService.JoinRoom(c.Request.Context(), userID.(uint), roomID, req.Password)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "加入房间成功", "data": room})
@@ -121,12 +161,58 @@ func LeaveRoom(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	roomID := c.Param("roomId")
 	if err := roomService.LeaveRoom(c.Request.Context(), userID.(uint), roomID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "离开房间成功"})
 }
 
+// KickPlayer 房主踢出玩家
+func KickPlayer(c *gin.Context) {
+	if !ensureRoomService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	roomID := c.Param("roomId")
+
+	var req struct {
+		TargetUserID uint `json:"target_user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if err := roomService.KickPlayer(c.Request.Context(), userID.(uint), roomID, req.TargetUserID); err != nil {
+		RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "踢出成功"})
+}
+
+// TransferOwner 房主转让
+func TransferOwner(c *gin.Context) {
+	if !ensureRoomService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	roomID := c.Param("roomId")
+
+	var req struct {
+		NewOwnerID uint `json:"new_owner_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	if err := roomService.TransferOwner(c.Request.Context(), userID.(uint), roomID, req.NewOwnerID); err != nil {
+		RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "转让成功"})
+}
+
 // GetRoom 房间信息
 func GetRoom(c *gin.Context) {
 	if !ensureRoomService(c) {
@@ -135,7 +221,7 @@ func GetRoom(c *gin.Context) {
 	roomID := c.Param("roomId")
 	room, err := roomService.GetRoom(c.Request.Context(), roomID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": room})
@@ -149,20 +235,65 @@ func RoomList(c *gin.Context) {
 	gameType := c.Query("game_type")
 	statusStr := c.DefaultQuery("status", "1")
 	limitStr := c.DefaultQuery("limit", "20")
+	offsetStr := c.DefaultQuery("offset", "0")
 	var status int8 = 1
 	var limit = 20
+	var offset = 0
 	fmt.Sscanf(statusStr, "%d", &status)
 	fmt.Sscanf(limitStr, "%d", &limit)
-	rooms, err := roomService.ListRooms(c.Request.Context(), roomrepo.ListFilter{
+	fmt.Sscanf(offsetStr, "%d", &offset)
+	rooms, total, err := roomService.ListRooms(c.Request.Context(), roomrepo.ListFilter{
 		GameType: gameType,
 		Status:   status,
 		Limit:    limit,
+		Offset:   offset,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": rooms})
+	// 统一列表响应结构：{list, total, page, page_size}
+	if limit <= 0 {
+		limit = 20
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": gin.H{
+		"list":      rooms,
+		"total":     total,
+		"page":      offset/limit + 1,
+		"page_size": limit,
+	}})
+}
+
+// QuickMatch 快速匹配（自动加入或创建房间）
+func QuickMatch(c *gin.Context) {
+	if !ensureRoomService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	var req struct {
+		GameType string `json:"game_type" binding:"required"`
+		RoomType string `json:"room_type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误"})
+		return
+	}
+
+	room, created, err := roomService.QuickMatch(c.Request.Context(), userID.(uint), req.GameType, req.RoomType)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	message := "加入房间成功"
+	if created {
+		message = "创建房间成功"
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message, "data": gin.H{
+		"room":    room,
+		"created": created,
+	}})
 }
 
 // Ready 玩家准备
@@ -174,7 +305,7 @@ func Ready(c *gin.Context) {
 	roomID := c.Param("roomId")
 	room, err := roomService.Ready(c.Request.Context(), userID.(uint), roomID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "准备成功", "data": room})
@@ -189,7 +320,7 @@ func CancelReady(c *gin.Context) {
 	roomID := c.Param("roomId")
 	room, err := roomService.CancelReady(c.Request.Context(), userID.(uint), roomID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "取消准备成功", "data": room})
@@ -209,7 +340,7 @@ func StartGame(c *gin.Context) {
 	// ✅ 使用 RoomService 启动游戏流程
 	room, err := roomService.StartGame(c.Request.Context(), userID.(uint), roomID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -247,6 +378,11 @@ func PlayCards(c *gin.Context) {
 		return
 	}
 
+	// 防作弊：必须是房间内的玩家才能操作游戏状态
+	if !ensureRoomMembership(c, roomID, userID.(uint)) {
+		return
+	}
+
 	// ✅ 使用新的 GameManager 先获取游戏状态，判断游戏类型
 	currentState, err := gameManager.GetGameState(c.Request.Context(), roomID)
 	if err != nil {
@@ -271,7 +407,7 @@ func PlayCards(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -311,6 +447,38 @@ func PlayCards(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "出牌成功", "data": filteredState})
 }
 
+// SetBullBetMultiplier 牛牛闲家选择押注倍数（亮牌前）
+func SetBullBetMultiplier(c *gin.Context) {
+	if !ensureGameManager(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	roomID := c.Param("roomId")
+
+	var req struct {
+		Multiplier int `json:"multiplier" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误", "error": err.Error()})
+		return
+	}
+
+	// 防作弊：必须是房间内的玩家才能操作游戏状态
+	if !ensureRoomMembership(c, roomID, userID.(uint)) {
+		return
+	}
+
+	gameState, err := gameManager.SetBullBetMultiplier(c.Request.Context(), roomID, userID.(uint), req.Multiplier)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// 过滤手牌后返回
+	filteredState := gameState.FilterForUser(userID.(uint))
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "押注成功", "data": filteredState})
+}
+
 // Pass 过牌
 func Pass(c *gin.Context) {
 	if !ensureGameManager(c) {
@@ -319,10 +487,15 @@ func Pass(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	roomID := c.Param("roomId")
 
+	// 防作弊：必须是房间内的玩家才能操作游戏状态
+	if !ensureRoomMembership(c, roomID, userID.(uint)) {
+		return
+	}
+
 	// ✅ 使用新的 GameManager
 	gameState, err := gameManager.Pass(c.Request.Context(), roomID, userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -331,6 +504,52 @@ func Pass(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "过牌成功", "data": filteredState})
 }
 
+// Surrender 认输（仅跑得快）：提前放弃本局并按最差可用名次完成
+func Surrender(c *gin.Context) {
+	if !ensureGameManager(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	roomID := c.Param("roomId")
+
+	// 防作弊：必须是房间内的玩家才能操作游戏状态
+	if !ensureRoomMembership(c, roomID, userID.(uint)) {
+		return
+	}
+
+	gameState, err := gameManager.Surrender(c.Request.Context(), roomID, userID.(uint))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// 过滤手牌后返回
+	filteredState := gameState.FilterForUser(userID.(uint))
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "认输成功", "data": filteredState})
+}
+
+// GetPlayHints 获取当前玩家的合法出牌提示（轮到自己时）
+func GetPlayHints(c *gin.Context) {
+	if !ensureGameManager(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	roomID := c.Param("roomId")
+
+	// 防作弊：必须是房间内的玩家才能查看提示
+	if !ensureRoomMembership(c, roomID, userID.(uint)) {
+		return
+	}
+
+	hints, err := gameManager.GetLegalPlays(c.Request.Context(), roomID, userID.(uint))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "获取成功", "data": hints})
+}
+
 // GetGameState 获取游戏状态
 func GetGameState(c *gin.Context) {
 	if !ensureGameManager(c) {
@@ -362,7 +581,7 @@ func GetGameState(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -406,7 +625,7 @@ func GetUserRecords(c *gin.Context) {
 		"code":    200,
 		"message": "success",
 		"data": gin.H{
-			"records":   records,
+			"list":      records,
 			"total":     total,
 			"page":      page,
 			"page_size": pageSize,
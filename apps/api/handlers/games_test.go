@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	roomrepo "github.com/kaifa/game-platform/internal/repository/room"
+	gamesvc "github.com/kaifa/game-platform/internal/service/game"
+	"github.com/kaifa/game-platform/pkg/models"
+)
+
+// fakeRoomRepo 仅实现成员校验所需的GetByRoomID，其余方法为空实现
+type fakeRoomRepo struct {
+	room *models.GameRoom
+}
+
+func (f *fakeRoomRepo) Create(ctx context.Context, room *models.GameRoom) error { return nil }
+func (f *fakeRoomRepo) Update(ctx context.Context, room *models.GameRoom) error { return nil }
+func (f *fakeRoomRepo) DeleteByRoomID(ctx context.Context, roomID string) error { return nil }
+func (f *fakeRoomRepo) GetByRoomID(ctx context.Context, roomID string) (*models.GameRoom, error) {
+	return f.room, nil
+}
+func (f *fakeRoomRepo) List(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, int64, error) {
+	return nil, 0, nil
+}
+func (f *fakeRoomRepo) Count(ctx context.Context, filter roomrepo.ListFilter) (int64, error) {
+	return 0, nil
+}
+func (f *fakeRoomRepo) ListIdleWaiting(ctx context.Context, cutoff int64) ([]*models.GameRoom, error) {
+	return nil, nil
+}
+func (f *fakeRoomRepo) CountActiveByCreator(ctx context.Context, creatorID uint) (int64, error) {
+	return 0, nil
+}
+func (f *fakeRoomRepo) FindInProgressByPlayer(ctx context.Context, userID uint) (*models.GameRoom, error) {
+	return nil, nil
+}
+
+// TestPlayCardsRejectsNonMember 不在房间玩家列表中的用户出牌应返回403，不触碰游戏状态
+func TestPlayCardsRejectsNonMember(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 房间中只有玩家1
+	room := &models.GameRoom{
+		RoomID:  "R1",
+		Status:  2,
+		Players: models.JSON(`[{"user_id":1,"nickname":"p1"}]`),
+	}
+	SetRoomRepository(&fakeRoomRepo{room: room})
+	defer SetRoomRepository(nil)
+	// 游戏管理器只需非nil即可通过前置检查，403应在访问游戏状态之前返回
+	SetGameManager(gamesvc.NewManager(nil, nil, nil, nil, nil, nil, nil, nil))
+	defer SetGameManager(nil)
+
+	r := gin.New()
+	r.POST("/games/rooms/:roomId/play", func(c *gin.Context) {
+		c.Set("user_id", uint(2)) // 模拟已认证但未入座的用户
+		PlayCards(c)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/games/rooms/R1/play",
+		strings.NewReader(`{"cards":[101,102,103,104,105]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("非房间成员出牌应返回403，实际: %d, body: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestIsRoomPlayer 玩家列表解析与成员判断
+func TestIsRoomPlayer(t *testing.T) {
+	room := &models.GameRoom{
+		Players: models.JSON(`[{"user_id":1},{"user_id":3}]`),
+	}
+
+	if !isRoomPlayer(room, 1) || !isRoomPlayer(room, 3) {
+		t.Error("列表中的玩家应被识别为房间成员")
+	}
+	if isRoomPlayer(room, 2) {
+		t.Error("不在列表中的用户不应被识别为房间成员")
+	}
+
+	// 玩家列表损坏时按非成员处理
+	if isRoomPlayer(&models.GameRoom{Players: models.JSON(`not-json`)}, 1) {
+		t.Error("玩家列表无法解析时应视为非成员")
+	}
+}
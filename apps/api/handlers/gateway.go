@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/discovery"
+)
+
+// gatewayGamePort 游戏服务器端口（单实例/无服务发现时的兜底）
+var gatewayGamePort int
+
+// statsClient 查询游戏服务器 /stats 的HTTP客户端（短超时，避免拖慢路由）
+var statsClient = &http.Client{Timeout: 2 * time.Second}
+
+// SetGatewayGamePort 设置游戏服务器端口（用于无服务发现时的兜底路由）
+func SetGatewayGamePort(port int) {
+	gatewayGamePort = port
+}
+
+// GetWSEndpoint 返回客户端应连接的WebSocket地址。
+// 启用服务发现时按各实例 /stats 上报的实时连接数选择负载最低的实例；
+// 未启用或无可用实例时，退回请求Host + 配置的游戏服务端口。
+func GetWSEndpoint(c *gin.Context) {
+	if registryInstance != nil {
+		instances, err := registryInstance.ListInstances(c.Request.Context(), "game-server")
+		if err == nil && len(instances) > 0 {
+			best, load := pickLeastLoadedInstance(c.Request.Context(), instances)
+			c.JSON(http.StatusOK, gin.H{
+				"code":    200,
+				"message": "获取成功",
+				"data": gin.H{
+					"ws_url":      fmt.Sprintf("ws://%s:%d/ws", best.Address, best.Port),
+					"instance_id": best.InstanceID,
+					"connections": load,
+				},
+			})
+			return
+		}
+	}
+
+	// 单实例/未启用服务发现：使用请求Host + 配置的游戏服务端口
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"ws_url":      fmt.Sprintf("ws://%s:%d/ws", host, gatewayGamePort),
+			"instance_id": "",
+			"connections": 0,
+		},
+	})
+}
+
+// pickLeastLoadedInstance 按实时连接数选择负载最低的实例。
+// 优先查询各实例的 /stats 接口；查询失败时退回注册元数据中的连接数。
+func pickLeastLoadedInstance(ctx context.Context, instances []discovery.ServiceInstance) (discovery.ServiceInstance, int) {
+	best := instances[0]
+	bestLoad := queryInstanceConnections(ctx, best)
+
+	for _, inst := range instances[1:] {
+		if load := queryInstanceConnections(ctx, inst); load < bestLoad {
+			best = inst
+			bestLoad = load
+		}
+	}
+
+	return best, bestLoad
+}
+
+// queryInstanceConnections 查询单个实例的当前连接数
+func queryInstanceConnections(ctx context.Context, inst discovery.ServiceInstance) int {
+	statsURL := fmt.Sprintf("http://%s:%d/stats", inst.Address, inst.Port)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statsURL, nil)
+	if err != nil {
+		return instanceConnections(inst)
+	}
+
+	resp, err := statsClient.Do(req)
+	if err != nil {
+		return instanceConnections(inst)
+	}
+	defer resp.Body.Close()
+
+	var stats struct {
+		Connections int `json:"connections"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return instanceConnections(inst)
+	}
+
+	return stats.Connections
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness 就绪检查：探测 MySQL/Redis 等依赖，必需依赖不可用时返回 503。
+// 与 /health 的存活检查不同，/ready 用于 Kubernetes 判断实例能否接收流量。
+func Readiness(c *gin.Context) {
+	if infrastructure == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not ready",
+			"error":  "基础设施未初始化",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	deps, ready := infrastructure.Readiness(ctx)
+
+	status := "ready"
+	code := http.StatusOK
+	if !ready {
+		status = "not ready"
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":       status,
+		"dependencies": deps,
+		"time":         time.Now().Format(time.RFC3339),
+	})
+}
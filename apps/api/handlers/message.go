@@ -58,8 +58,10 @@ func GetUserMessages(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"data": gin.H{
-			"list":  messages,
-			"total": total,
+			"list":      messages,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
 		},
 	})
 }
@@ -162,6 +164,28 @@ func BatchReadMessages(c *gin.Context) {
 	})
 }
 
+// ReadAllMessages 标记当前用户所有消息为已读
+func ReadAllMessages(c *gin.Context) {
+	if !ensureMessageService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	// ✅ 使用 MessageService（空ID列表 = 标记所有）
+	if err := messageService.BatchReadMessages(c.Request.Context(), userID.(uint), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "标记失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已全部标记为已读",
+	})
+}
+
 // DeleteUserMessage 删除用户消息
 func DeleteUserMessage(c *gin.Context) {
 	if !ensureMessageService(c) {
@@ -198,8 +222,16 @@ func GetAnnouncements(c *gin.Context) {
 		return
 	}
 
+	// 公开接口：未登录时 user_id 不存在，只返回全员公告
+	var userID uint
+	if v, exists := c.Get("user_id"); exists {
+		if id, ok := v.(uint); ok {
+			userID = id
+		}
+	}
+
 	// ✅ 使用 MessageService
-	announcements, err := messageService.GetAnnouncements(c.Request.Context())
+	announcements, err := messageService.GetAnnouncements(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
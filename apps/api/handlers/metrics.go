@@ -21,9 +21,10 @@ func GetMetrics(c *gin.Context) {
 	m := metrics.GetGlobalMetrics()
 
 	data := map[string]interface{}{
-		"lock":      m.GetLockSummary(),
-		"goroutine": m.GetGoroutineStats(),
-		"runtime":   m.GetRuntimeStats(),
+		"lock":       m.GetLockSummary(),
+		"rate_limit": m.GetRateLimitStats(),
+		"goroutine":  m.GetGoroutineStats(),
+		"runtime":    m.GetRuntimeStats(),
 	}
 
 	// 添加 Worker Pool 统计
@@ -81,6 +82,16 @@ func GetWorkerPoolMetrics(c *gin.Context) {
 	})
 }
 
+// GetRateLimitMetrics 获取限流监控
+func GetRateLimitMetrics(c *gin.Context) {
+	m := metrics.GetGlobalMetrics()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": m.GetRateLimitStats(),
+	})
+}
+
 // GetGoroutineMetrics 获取 goroutine 监控
 func GetGoroutineMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
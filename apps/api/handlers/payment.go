@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -78,6 +79,46 @@ func GetPaymentConfig(c *gin.Context) {
 	})
 }
 
+// GetDepositAddress 获取充值地址（含派生路径和二维码）
+func GetDepositAddress(c *gin.Context) {
+	if !ensurePaymentService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	chainType := c.Query("chain_type")
+
+	if chainType != "trc20" && chainType != "erc20" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "链类型必须是trc20或erc20"})
+		return
+	}
+
+	info, err := paymentService.GetDepositAddress(c.Request.Context(), userID.(uint), chainType)
+	if err != nil {
+		// HD钱包未配置时返回503，提示运维配置助记词
+		if errors.Is(err, paymentsvc.ErrHDWalletNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "message": "支付功能暂不可用，请联系管理员"})
+			return
+		}
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "查询成功",
+		"data":    info,
+	})
+}
+
+// idempotencyKey 读取请求的幂等键（可选，超长时截断到数据库字段长度）
+func idempotencyKey(c *gin.Context) string {
+	key := c.GetHeader("Idempotency-Key")
+	if len(key) > 64 {
+		key = key[:64]
+	}
+	return key
+}
+
 // CreateRechargeOrder 创建充值订单
 func CreateRechargeOrder(c *gin.Context) {
 	if !ensurePaymentService(c) {
@@ -95,10 +136,10 @@ func CreateRechargeOrder(c *gin.Context) {
 		return
 	}
 
-	// ✅ 使用新的 PaymentService
-	order, err := paymentService.CreateRechargeOrder(c.Request.Context(), userID.(uint), req.Amount, req.ChainType)
+	// ✅ 使用新的 PaymentService（携带幂等键的重试请求返回原订单）
+	order, err := paymentService.CreateRechargeOrder(c.Request.Context(), userID.(uint), req.Amount, req.ChainType, idempotencyKey(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -120,7 +161,7 @@ func GetRechargeOrder(c *gin.Context) {
 	// ✅ 使用新的 PaymentService
 	order, err := paymentService.GetRechargeOrder(c.Request.Context(), orderID, userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -151,7 +192,7 @@ func GetUserRechargeOrders(c *gin.Context) {
 	// ✅ 使用新的 PaymentService
 	orders, total, err := paymentService.GetUserRechargeOrders(c.Request.Context(), userID.(uint), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -159,7 +200,7 @@ func GetUserRechargeOrders(c *gin.Context) {
 		"code":    200,
 		"message": "查询成功",
 		"data": gin.H{
-			"orders":    orders,
+			"list":      orders,
 			"total":     total,
 			"page":      page,
 			"page_size": pageSize,
@@ -179,14 +220,14 @@ func CheckRechargeTransaction(c *gin.Context) {
 	// ✅ 使用新的 PaymentService
 	order, err := paymentService.GetRechargeOrder(c.Request.Context(), orderID, userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	// 检查交易
 	// ✅ 使用新的 PaymentService
 	if err := paymentService.CheckTransaction(c.Request.Context(), order.OrderID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -218,10 +259,10 @@ func CreateWithdrawOrder(c *gin.Context) {
 		return
 	}
 
-	// ✅ 使用新的 PaymentService
-	order, err := paymentService.CreateWithdrawOrder(c.Request.Context(), userID.(uint), req.Amount, req.ChainType, req.ToAddress)
+	// ✅ 使用新的 PaymentService（携带幂等键的重试请求返回原订单）
+	order, err := paymentService.CreateWithdrawOrder(c.Request.Context(), userID.(uint), req.Amount, req.ChainType, req.ToAddress, idempotencyKey(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -232,6 +273,35 @@ func CreateWithdrawOrder(c *gin.Context) {
 	})
 }
 
+// GetUserTransactions 获取当前用户的交易流水（充值/提现/游戏结算）
+func GetUserTransactions(c *gin.Context) {
+	if !ensurePaymentService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	txType := c.Query("type")
+
+	// ✅ 使用新的 PaymentService
+	transactions, total, err := paymentService.GetUserTransactions(c.Request.Context(), userID.(uint), txType, page, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"list":      transactions,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
 // GetWithdrawOrder 获取提现订单
 func GetWithdrawOrder(c *gin.Context) {
 	if !ensurePaymentService(c) {
@@ -243,7 +313,7 @@ func GetWithdrawOrder(c *gin.Context) {
 	// ✅ 使用新的 PaymentService
 	order, err := paymentService.GetWithdrawOrder(c.Request.Context(), orderID, userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -274,7 +344,7 @@ func GetUserWithdrawOrders(c *gin.Context) {
 	// ✅ 使用新的 PaymentService
 	orders, total, err := paymentService.GetUserWithdrawOrders(c.Request.Context(), userID.(uint), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -282,7 +352,7 @@ func GetUserWithdrawOrders(c *gin.Context) {
 		"code":    200,
 		"message": "查询成功",
 		"data": gin.H{
-			"orders":    orders,
+			"list":      orders,
 			"total":     total,
 			"page":      page,
 			"page_size": pageSize,
@@ -311,7 +381,7 @@ func AuditWithdrawOrder(c *gin.Context) {
 	// ✅ 使用新的 PaymentService
 	err := paymentService.AuditWithdrawOrder(c.Request.Context(), auditorID.(uint), orderID, req.Approve, req.Remark)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/pkg/errs"
+)
+
+// RespondError 统一输出业务错误。
+// errs.Error按其错误码映射HTTP状态并下发稳定的业务错误码；
+// 未分类的错误保持既有行为（HTTP 400 + 错误文本），避免老调用方受影响
+func RespondError(c *gin.Context, err error) {
+	var bizErr *errs.Error
+	if errors.As(err, &bizErr) {
+		c.JSON(bizErr.HTTPStatus(), gin.H{
+			"code":    bizErr.Code,
+			"message": bizErr.Message,
+		})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"code":    400,
+		"message": err.Error(),
+	})
+}
@@ -52,7 +52,7 @@ func Register(c *gin.Context) {
 
 	user, token, err := userService.Register(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -80,7 +80,7 @@ func Login(c *gin.Context) {
 	ip := c.ClientIP()
 	user, token, err := userService.Login(c.Request.Context(), &req, ip)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -110,6 +110,32 @@ func Profile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": user})
 }
 
+// UpdateProfile 更新用户昵称/头像。
+// 注意：已在房间内的玩家昵称/头像快照在 Players JSON 中，不会随之更新。
+func UpdateProfile(c *gin.Context) {
+	if !ensureUserService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	var req struct {
+		Nickname string `json:"nickname"`
+		Avatar   string `json:"avatar"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误", "error": err.Error()})
+		return
+	}
+
+	user, err := userService.UpdateProfile(c.Request.Context(), userID.(uint), req.Nickname, req.Avatar)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "更新成功", "data": user})
+}
+
 // GetUserStats 获取用户游戏统计
 func GetUserStats(c *gin.Context) {
 	if !ensureUserStatsService(c) {
@@ -134,3 +160,123 @@ func GetUserStats(c *gin.Context) {
 		"data":    stats,
 	})
 }
+
+// GetUserWallet 获取当前用户钱包（可用余额、冻结金额、累计充提）
+func GetUserWallet(c *gin.Context) {
+	if !ensureUserService(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	wallet, err := userService.GetWallet(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": wallet})
+}
+
+// RequestEmailVerification 请求邮箱验证（需登录）
+func RequestEmailVerification(c *gin.Context) {
+	if !ensureUserService(c) {
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未授权"})
+		return
+	}
+
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误", "error": err.Error()})
+		return
+	}
+
+	if err := userService.RequestEmailVerification(c.Request.Context(), userID.(uint), req.Email); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "验证邮件已发送",
+	})
+}
+
+// ConfirmEmailVerification 用邮件中的令牌确认邮箱
+func ConfirmEmailVerification(c *gin.Context) {
+	if !ensureUserService(c) {
+		return
+	}
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误", "error": err.Error()})
+		return
+	}
+
+	if err := userService.ConfirmEmailVerification(c.Request.Context(), req.Token); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "邮箱验证成功",
+	})
+}
+
+// RequestPasswordReset 请求重置密码（向已验证邮箱发送重置令牌）
+func RequestPasswordReset(c *gin.Context) {
+	if !ensureUserService(c) {
+		return
+	}
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误", "error": err.Error()})
+		return
+	}
+
+	if err := userService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// 邮箱不存在时同样返回成功，避免探测注册邮箱
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "如果该邮箱已验证，重置邮件将会发出",
+	})
+}
+
+// ConfirmPasswordReset 用重置令牌设置新密码
+func ConfirmPasswordReset(c *gin.Context) {
+	if !ensureUserService(c) {
+		return
+	}
+	var req struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误", "error": err.Error()})
+		return
+	}
+
+	if err := userService.ConfirmPasswordReset(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码重置成功",
+	})
+}
@@ -14,7 +14,12 @@ import (
 	"github.com/kaifa/game-platform/apps/api/router"
 	"github.com/kaifa/game-platform/internal/bootstrap"
 	"github.com/kaifa/game-platform/internal/config"
+	"github.com/kaifa/game-platform/internal/discovery"
+	"github.com/kaifa/game-platform/internal/elasticsearch"
+	"github.com/kaifa/game-platform/internal/idgen"
 	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/messaging"
+	"github.com/kaifa/game-platform/internal/middleware"
 	mysqlrepo "github.com/kaifa/game-platform/internal/repository/mysql"
 	gamesvc "github.com/kaifa/game-platform/internal/service/game"
 	gamerecordsrv "github.com/kaifa/game-platform/internal/service/gamerecord"
@@ -36,12 +41,22 @@ func main() {
 		panic(fmt.Sprintf("加载配置失败: %v", err))
 	}
 
+	// 校验配置（聚合所有问题，启动时尽早暴露）
+	if err := config.Validate(cfg); err != nil {
+		panic(fmt.Sprintf("配置校验失败: %v", err))
+	}
+
 	// 初始化日志
 	if err := logger.InitLogger(cfg.Log); err != nil {
 		panic(fmt.Sprintf("初始化日志失败: %v", err))
 	}
 	defer logger.Sync()
 
+	// 初始化业务ID生成器（订单号/房间号）
+	if err := idgen.Init(cfg.Server.MachineID, cfg.Server.UseSnowflakeID); err != nil {
+		logger.Logger.Fatal("初始化ID生成器失败", zap.Error(err))
+	}
+
 	infra, err := bootstrap.InitInfrastructure(cfg)
 	if err != nil {
 		logger.Logger.Fatal("初始化基础设施失败", zap.Error(err))
@@ -54,6 +69,19 @@ func main() {
 		logger.Logger.Info("Redis连接成功")
 	}
 
+	// 系统配置缓存（Redis不可用时退化为本地TTL缓存）
+	services.InitSystemConfigCache(infra.Redis)
+
+	// 初始化限流器（Redis不可用时自动降级为本地内存桶）
+	middleware.InitRateLimiter(infra.Redis)
+
+	// 初始化Elasticsearch（可选，异步连接，失败时游戏记录分析索引不可用）
+	go func() {
+		if err := elasticsearch.Init(cfg); err != nil {
+			logger.Logger.Warn("Elasticsearch连接失败，游戏记录将不写入分析索引", zap.Error(err))
+		}
+	}()
+
 	// ============================================
 	// 初始化 Repository 层（9个）
 	// ============================================
@@ -68,6 +96,7 @@ func main() {
 	withdrawOrderRepo := mysqlrepo.NewWithdrawOrderRepository(infra.DB)
 	transactionRepo := mysqlrepo.NewTransactionRepository(infra.DB)
 	depositAddrRepo := mysqlrepo.NewDepositAddressRepository(infra.DB)
+	idempotencyKeyRepo := mysqlrepo.NewIdempotencyKeyRepository(infra.DB)
 
 	// ============================================
 	// 初始化 Service 层并注入到 handlers
@@ -93,6 +122,7 @@ func main() {
 		roomRepo,           // 房间Repository
 		userRepo,           // 用户Repository
 		gameRecordRepo,     // 游戏记录Repository
+		transactionRepo,    // 交易流水Repository（结算记账）
 		leaderboardService, // 排行榜服务
 		infra.DistLock,     // ✅ 分布式锁
 		infra.LocalLock,    // ✅ 本地读写锁
@@ -101,27 +131,57 @@ func main() {
 	logger.Logger.Info("✓ 游戏管理器初始化成功（已启用并发控制）")
 
 	// 5. 房间服务（依赖 GameManager + 并发控制组件）
-	notifyURL := fmt.Sprintf("http://localhost:%d/internal/room/notify", cfg.Server.GamePort)
+	notifyURL := cfg.GameServerBaseURL() + "/internal/room/notify"
 	roomService := roomsrv.New(
-		roomRepo,         // Repository
-		userRepo,         // Repository
-		gameManager,      // Service（依赖前面创建的）
-		infra.Redis,      // 基础设施
-		notifyURL,        // 配置
-		infra.DistLock,   // ✅ 分布式锁
-		infra.LocalLock,  // ✅ 本地锁
-		infra.NotifyPool, // ✅ 通知池
+		roomRepo,                      // Repository
+		userRepo,                      // Repository
+		gameManager,                   // Service（依赖前面创建的）
+		infra.Redis,                   // 基础设施
+		notifyURL,                     // 配置
+		infra.DistLock,                // ✅ 分布式锁
+		infra.LocalLock,               // ✅ 本地锁
+		infra.NotifyPool,              // ✅ 通知池
+		cfg.Room.DissolveOnOwnerLeave, // 房主离开时的处理策略
+		cfg.Room.MaxRoomsPerUser,      // 单用户最大活跃房间数
+		cfg.Room.AllowMultiSession,    // 允许同时参与多局（仅测试用）
 	)
 	handlers.SetRoomService(roomService)
+	handlers.SetRoomRepository(roomRepo) // 游戏操作前的房间成员校验
 	logger.Logger.Info("✓ 房间服务初始化成功（已启用并发控制）")
 
-	// 6. 用户服务（无外部依赖）
-	userService := usersvc.New(userRepo)
+	// 回合计时广播：CurrentPlayer变更时经房间服务的通知管线发timer_start/timer_stop
+	if cfg.Room.TurnTimeout > 0 {
+		gameManager.SetTurnNotifier(roomService.NotifyRoomAction, time.Duration(cfg.Room.TurnTimeout)*time.Second)
+		logger.Logger.Info("✓ 回合计时广播已启用", zap.Int("turn_timeout", cfg.Room.TurnTimeout))
+	}
+
+	// 启动闲置房间清理（idle_timeout为0时不启动）
+	if cfg.Room.IdleTimeout > 0 {
+		roomService.StartIdleReaper(time.Duration(cfg.Room.IdleTimeout) * time.Second)
+		logger.Logger.Info("✓ 闲置房间清理已启动",
+			zap.Int("idle_timeout", cfg.Room.IdleTimeout),
+		)
+	}
+
+	// 启动准备超时检查（任一阈值为0时不启动）
+	if cfg.Room.ReadyWarnTimeout > 0 && cfg.Room.ReadyKickTimeout > 0 {
+		roomService.StartReadyTimeoutChecker(
+			time.Duration(cfg.Room.ReadyWarnTimeout)*time.Second,
+			time.Duration(cfg.Room.ReadyKickTimeout)*time.Second,
+		)
+		logger.Logger.Info("✓ 准备超时检查已启动",
+			zap.Int("ready_warn_timeout", cfg.Room.ReadyWarnTimeout),
+			zap.Int("ready_kick_timeout", cfg.Room.ReadyKickTimeout),
+		)
+	}
+
+	// 6. 用户服务（Redis用于邮箱验证/密码重置令牌；邮件器默认日志模式）
+	userService := usersvc.New(userRepo, infra.Redis, nil)
 	handlers.SetUserService(userService)
 	logger.Logger.Info("✓ 用户服务初始化成功")
 
 	// 7. 用户统计服务（依赖 GamePlayerRepo）
-	userStatsService := userstatssvc.New(gamePlayerRepo)
+	userStatsService := userstatssvc.New(gamePlayerRepo, infra.Redis)
 	handlers.SetUserStatsService(userStatsService)
 	logger.Logger.Info("✓ 用户统计服务初始化成功")
 
@@ -157,6 +217,7 @@ func main() {
 		withdrawOrderRepo,
 		transactionRepo,
 		depositAddrRepo,
+		idempotencyKeyRepo,
 		userRepo,
 		hdWallet,
 		transferService,
@@ -164,9 +225,47 @@ func main() {
 	)
 	handlers.SetPaymentService(paymentService)
 
-	// 启动交易监控
-	paymentService.StartTransactionMonitor()
-	logger.Logger.Info("✓ 支付服务初始化成功，交易监控已启动")
+	// 启动交易监控（停机时通过monitorCancel停止）
+	monitorCtx, monitorCancel := context.WithCancel(context.Background())
+	defer monitorCancel()
+	if cfg.Payment.ProviderRateLimit > 0 {
+		services.SetChainAPIRate(cfg.Payment.ProviderRateLimit)
+	}
+	paymentService.StartTransactionMonitor(monitorCtx,
+		time.Duration(cfg.Payment.MonitorInterval)*time.Second,
+		cfg.Payment.MonitorConcurrency)
+
+	// 过期订单清扫（独立于交易监控的兜底任务）
+	paymentService.StartExpirySweeper(monitorCtx,
+		time.Duration(cfg.Payment.ExpireSweepInterval)*time.Second)
+	logger.Logger.Info("✓ 支付服务初始化成功，交易监控与过期订单清扫已启动")
+
+	// 订单通知渠道（站内信/WebSocket推送/Webhook，可在配置中组合）
+	var notifyBus messaging.MessageBus
+	for _, channel := range cfg.Notification.Channels {
+		if channel == "websocket" && cfg.Kafka.Enabled {
+			notifyBus, err = messaging.NewMessageBus(messaging.BusDeps{
+				Type:            "kafka",
+				Brokers:         cfg.Kafka.Brokers,
+				TopicPrefix:     cfg.Kafka.TopicPrefix,
+				ProducerAcks:    cfg.Kafka.ProducerAcks,
+				ProducerRetries: cfg.Kafka.ProducerRetries,
+				BatchSize:       cfg.Kafka.BatchSize,
+				LingerMs:        cfg.Kafka.LingerMs,
+				CompressionType: cfg.Kafka.CompressionType,
+			})
+			if err != nil {
+				logger.Logger.Warn("创建通知消息总线失败，websocket通知渠道不可用", zap.Error(err))
+				notifyBus = nil
+			}
+			break
+		}
+	}
+	services.InitOrderNotifiers(cfg.Notification.Channels,
+		cfg.Notification.WebhookURL, cfg.Notification.WebhookSecret, notifyBus)
+	logger.Logger.Info("✓ 订单通知渠道初始化成功",
+		zap.Strings("channels", cfg.Notification.Channels),
+	)
 
 	logger.Logger.Info("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	logger.Logger.Info("✅ 所有服务初始化完成")
@@ -181,6 +280,26 @@ func main() {
 	// ✅ 设置基础设施引用（用于监控端点）
 	handlers.SetInfrastructure(infra)
 
+	// 网关兜底端口（无服务发现时 /gateway/ws-endpoint 使用）
+	handlers.SetGatewayGamePort(cfg.Server.GamePort)
+
+	// 服务发现（只读：用于查询游戏服务器实例供客户端路由）
+	if cfg.ServiceDiscovery.Enabled {
+		registry, err := discovery.NewRegistry(discovery.RegistryDeps{
+			Type:       cfg.ServiceDiscovery.Type,
+			ConsulAddr: cfg.ServiceDiscovery.ConsulAddr,
+			Redis:      infra.Redis,
+		})
+		if err != nil {
+			logger.Logger.Warn("初始化服务发现失败，/games/server 接口不可用", zap.Error(err))
+		} else if registry != nil {
+			handlers.SetRegistry(registry)
+			logger.Logger.Info("✓ 服务发现初始化成功",
+				zap.String("type", cfg.ServiceDiscovery.Type),
+			)
+		}
+	}
+
 	// 设置Gin模式
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -215,6 +334,9 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// 停止后台交易监控
+	monitorCancel()
+
 	logger.Logger.Info("正在关闭API服务器...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/kaifa/game-platform/apps/api/handlers"
 	"github.com/kaifa/game-platform/internal/config"
+	"github.com/kaifa/game-platform/internal/logger"
 	"github.com/kaifa/game-platform/internal/middleware"
+	"go.uber.org/zap"
 )
 
 // Setup 装配所有路由
@@ -15,6 +17,8 @@ func Setup(cfg *config.Config) *gin.Engine {
 	r := gin.New()
 
 	// 通用中间件（保留与 main.go 一致的行为）
+	r.Use(middleware.RequestID())
+	r.Use(middleware.CORS(cfg))
 	r.Use(ginLogger())
 	r.Use(ginRecovery())
 
@@ -42,6 +46,9 @@ func Setup(cfg *config.Config) *gin.Engine {
 		})
 	})
 
+	// 就绪检查（探测依赖，供K8s readiness probe使用）
+	r.GET("/ready", handlers.Readiness)
+
 	// ✅ 监控端点（调试用，生产环境可以添加认证）
 	debug := r.Group("/debug")
 	{
@@ -50,14 +57,23 @@ func Setup(cfg *config.Config) *gin.Engine {
 		debug.GET("/metrics/worker-pool", handlers.GetWorkerPoolMetrics) // Worker Pool 监控
 		debug.GET("/metrics/goroutine", handlers.GetGoroutineMetrics)    // goroutine 监控
 		debug.GET("/metrics/runtime", handlers.GetRuntimeMetrics)        // 运行时监控
+		debug.GET("/metrics/rate-limit", handlers.GetRateLimitMetrics)   // 限流监控
 	}
 
+	// 限流规则：资金类接口最严格，创建类次之，认证类防撞库
+	authLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "auth", Capacity: 5, Rate: 10.0 / 60})
+	createRoomLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "create_room", Capacity: 3, Rate: 6.0 / 60})
+	paymentLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "payment", Capacity: 2, Rate: 3.0 / 60})
+
 	v1 := r.Group("/api/v1")
 	{
 		users := v1.Group("/users")
 		{
-			users.POST("/register", handlers.Register)
-			users.POST("/login", handlers.Login)
+			users.POST("/register", authLimit, handlers.Register)
+			users.POST("/login", authLimit, handlers.Login)
+			users.POST("/email/confirm", authLimit, handlers.ConfirmEmailVerification)
+			users.POST("/password-reset/request", authLimit, handlers.RequestPasswordReset)
+			users.POST("/password-reset/confirm", authLimit, handlers.ConfirmPasswordReset)
 			users.GET("/profile", middleware.AuthMiddleware(), handlers.Profile)
 			users.GET("/stats", middleware.AuthMiddleware(), handlers.GetUserStats)
 
@@ -69,21 +85,53 @@ func Setup(cfg *config.Config) *gin.Engine {
 			users.DELETE("/messages/:id", middleware.AuthMiddleware(), handlers.DeleteUserMessage)
 		}
 
+		// 当前用户（单数路径）
+		user := v1.Group("/user", middleware.AuthMiddleware())
+		{
+			user.PUT("/profile", handlers.UpdateProfile)
+			user.POST("/email/verify-request", handlers.RequestEmailVerification)
+			user.GET("/stats", handlers.GetUserStats)
+			user.GET("/wallet", handlers.GetUserWallet)
+			user.GET("/transactions", handlers.GetUserTransactions)
+		}
+
+		// 消息中心（/users/messages 的标准路径，处理逻辑相同）
+		messages := v1.Group("/messages", middleware.AuthMiddleware())
+		{
+			messages.GET("", handlers.GetUserMessages)
+			messages.GET("/unread-count", handlers.GetUnreadMessageCount)
+			messages.POST("/:id/read", handlers.ReadMessage)
+			messages.POST("/read-all", handlers.ReadAllMessages)
+		}
+
 		// 公告相关（公开接口）
 		v1.GET("/announcements", handlers.GetAnnouncements)
 
+		// 网关相关（公开接口：客户端获取WebSocket接入点）
+		gateway := v1.Group("/gateway")
+		{
+			gateway.GET("/ws-endpoint", handlers.GetWSEndpoint)
+		}
+
 		games := v1.Group("/games")
 		{
 			games.GET("/list", handlers.GameList)
-			games.POST("/rooms", middleware.AuthMiddleware(), handlers.CreateRoom)
+			games.GET("/server", handlers.GetGameServer)
+			games.POST("/rooms", middleware.AuthMiddleware(), createRoomLimit, handlers.CreateRoom)
 			games.GET("/rooms", handlers.RoomList)
+			games.POST("/rooms/quick-match", middleware.AuthMiddleware(), handlers.QuickMatch)
 			games.POST("/rooms/:roomId/join", middleware.AuthMiddleware(), handlers.JoinRoom)
 			games.POST("/rooms/:roomId/leave", middleware.AuthMiddleware(), handlers.LeaveRoom)
+			games.POST("/rooms/:roomId/kick", middleware.AuthMiddleware(), handlers.KickPlayer)
+			games.POST("/rooms/:roomId/transfer-owner", middleware.AuthMiddleware(), handlers.TransferOwner)
 			games.POST("/rooms/:roomId/ready", middleware.AuthMiddleware(), handlers.Ready)
 			games.POST("/rooms/:roomId/cancel-ready", middleware.AuthMiddleware(), handlers.CancelReady)
 			games.POST("/rooms/:roomId/start", middleware.AuthMiddleware(), handlers.StartGame)
 			games.POST("/rooms/:roomId/play", middleware.AuthMiddleware(), handlers.PlayCards)
+			games.POST("/rooms/:roomId/bet-multiplier", middleware.AuthMiddleware(), handlers.SetBullBetMultiplier)
 			games.POST("/rooms/:roomId/pass", middleware.AuthMiddleware(), handlers.Pass)
+			games.POST("/rooms/:roomId/surrender", middleware.AuthMiddleware(), handlers.Surrender)
+			games.GET("/rooms/:roomId/hints", middleware.AuthMiddleware(), handlers.GetPlayHints)
 			games.GET("/rooms/:roomId/game-state", handlers.GetGameState)
 			games.GET("/rooms/:roomId/records", middleware.AuthMiddleware(), handlers.GetRoomRecords)
 			games.GET("/rooms/:roomId", handlers.GetRoom)
@@ -107,14 +155,17 @@ func Setup(cfg *config.Config) *gin.Engine {
 			paymentsAuth := payments.Group("")
 			paymentsAuth.Use(middleware.AuthMiddleware())
 			{
+				// 充值地址（含派生路径和二维码）
+				paymentsAuth.GET("/deposit-address", handlers.GetDepositAddress)
+
 				// 充值相关
-				paymentsAuth.POST("/recharge", handlers.CreateRechargeOrder)
+				paymentsAuth.POST("/recharge", paymentLimit, handlers.CreateRechargeOrder)
 				paymentsAuth.GET("/recharge/:orderId", handlers.GetRechargeOrder)
 				paymentsAuth.GET("/recharge", handlers.GetUserRechargeOrders)
 				paymentsAuth.POST("/recharge/:orderId/check", handlers.CheckRechargeTransaction)
 
 				// 提现相关
-				paymentsAuth.POST("/withdraw", handlers.CreateWithdrawOrder)
+				paymentsAuth.POST("/withdraw", paymentLimit, handlers.CreateWithdrawOrder)
 				paymentsAuth.GET("/withdraw/:orderId", handlers.GetWithdrawOrder)
 				paymentsAuth.GET("/withdraw", handlers.GetUserWithdrawOrders)
 				paymentsAuth.POST("/withdraw/:orderId/audit", handlers.AuditWithdrawOrder)
@@ -132,8 +183,14 @@ func ginLogger() gin.HandlerFunc {
 		start := time.Now()
 		path := c.Request.URL.Path
 		c.Next()
-		_ = time.Since(start)
-		_ = path
+		// 带request_id记录，便于与下游通知/广播日志串联
+		logger.Logger.Debug("HTTP请求",
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("request_id", c.GetString("request_id")),
+		)
 	}
 }
 
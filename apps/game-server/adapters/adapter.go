@@ -68,6 +68,11 @@ func (a *HubAdapter) BroadcastMessage(msg handlers.MessageInterface) {
 	a.broadcaster.BroadcastMessage(ma.msg)
 }
 
+// IsShuttingDown 是否已进入关闭流程
+func (a *HubAdapter) IsShuttingDown() bool {
+	return a.hub.IsShuttingDown()
+}
+
 // PublishSystemMessage 发布系统消息
 func (a *HubAdapter) PublishSystemMessage(msgType, roomID string, data map[string]interface{}) error {
 	if a.kafkaHandler != nil {
@@ -140,3 +145,8 @@ func (a *MessageAdapter) GetUserID() uint {
 func (a *MessageAdapter) GetRawData() interface{} {
 	return a.msg.RawData
 }
+
+// SetRequestID 写入请求追踪ID（用于日志串联）
+func (a *MessageAdapter) SetRequestID(requestID string) {
+	a.msg.RequestID = requestID
+}
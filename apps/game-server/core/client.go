@@ -2,7 +2,9 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -15,6 +17,34 @@ type MessageHandlerInterface interface {
 	HandleMessage(msg *Message)
 }
 
+// maxMessageSize WebSocket单条消息大小上限（字节），可通过配置覆盖
+var maxMessageSize int64 = 4096
+
+// SetMaxMessageSize 设置WebSocket单条消息大小上限（<=0时保持默认值）
+func SetMaxMessageSize(size int64) {
+	if size > 0 {
+		maxMessageSize = size
+	}
+}
+
+// allowedMessageTypes 客户端可发送的消息类型白名单，
+// 与 services.MessageHandler 的处理分支保持同步
+var allowedMessageTypes = map[string]bool{
+	"join_room":      true,
+	"leave_room":     true,
+	"ping":           true,
+	"reconnect":      true,
+	"play_cards":     true,
+	"pass":           true,
+	"surrender":      true,
+	"get_game_state": true,
+	"test_message":   true,
+	"room_message":   true,
+	"chat":           true,
+	"chat_history":   true,
+	"emote":          true,
+}
+
 // Client WebSocket客户端
 type Client struct {
 	conn      *websocket.Conn
@@ -23,19 +53,31 @@ type Client struct {
 	userID    uint
 	hub       *Hub
 	closeOnce sync.Once // 确保 send channel 只被关闭一次
+	lastPong  int64     // 最近一次收到pong/消息的时间（UnixNano，atomic访问）
 }
 
 // NewClient 创建新的客户端
 func NewClient(conn *websocket.Conn, ip string, userID uint, hub *Hub) *Client {
 	return &Client{
-		conn:   conn,
-		send:   make(chan []byte, 256),
-		ip:     ip,
-		userID: userID,
-		hub:    hub,
+		conn:     conn,
+		send:     make(chan []byte, 256),
+		ip:       ip,
+		userID:   userID,
+		hub:      hub,
+		lastPong: time.Now().UnixNano(),
 	}
 }
 
+// touchPong 刷新最近活跃时间
+func (c *Client) touchPong() {
+	atomic.StoreInt64(&c.lastPong, time.Now().UnixNano())
+}
+
+// LastPong 最近一次收到pong（或任意消息）的时间，供Hub清理僵尸连接用
+func (c *Client) LastPong() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.lastPong))
+}
+
 // CloseSend 安全地关闭 send channel（确保只关闭一次）
 func (c *Client) CloseSend() {
 	c.closeOnce.Do(func() {
@@ -117,8 +159,10 @@ func (c *Client) ReadPump(messageHandler MessageHandlerInterface) {
 		c.conn.Close()
 	}()
 
+	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.conn.SetPongHandler(func(string) error {
+		c.touchPong()
 		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
@@ -126,7 +170,16 @@ func (c *Client) ReadPump(messageHandler MessageHandlerInterface) {
 	for {
 		_, rawMessage, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				// 超大消息：以策略违规码断开，防止内存滥用
+				logger.Logger.Warn("WebSocket消息超过大小上限",
+					zap.Uint("user_id", c.userID),
+					zap.Int64("max_message_size", maxMessageSize),
+				)
+				c.conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "消息过大"),
+					time.Now().Add(time.Second))
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				logger.Logger.Error("WebSocket读取错误",
 					zap.Uint("user_id", c.userID),
 					zap.Error(err),
@@ -135,6 +188,9 @@ func (c *Client) ReadPump(messageHandler MessageHandlerInterface) {
 			break
 		}
 
+		// 正常收到消息也算活跃
+		c.touchPong()
+
 		// 解析消息
 		var msg Message
 		if err := json.Unmarshal(rawMessage, &msg); err != nil {
@@ -152,6 +208,21 @@ func (c *Client) ReadPump(messageHandler MessageHandlerInterface) {
 			continue
 		}
 
+		// 未知消息类型在入口处拒绝，不进入处理流程
+		if !allowedMessageTypes[msg.Type] {
+			logger.Logger.Warn("拒绝未知消息类型",
+				zap.Uint("user_id", c.userID),
+				zap.String("type", msg.Type),
+			)
+			c.SendMessage(&Message{
+				Type: "error",
+				RawData: map[string]interface{}{
+					"message": "不支持的消息类型: " + msg.Type,
+				},
+			})
+			continue
+		}
+
 		// 设置用户ID
 		msg.UserID = c.userID
 
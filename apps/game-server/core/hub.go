@@ -1,9 +1,13 @@
 package core
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	"github.com/kaifa/game-platform/internal/logger"
 	"github.com/kaifa/game-platform/internal/messaging"
+	"go.uber.org/zap"
 )
 
 // Hub 管理所有WebSocket连接和房间
@@ -40,6 +44,13 @@ type Hub struct {
 
 	// 实例ID（用于消息去重）
 	instanceID string
+
+	// 是否已进入关闭流程（关闭后拒绝新连接）
+	shuttingDown bool
+
+	// 远端实例的在线用户快照：instanceID -> 快照（presence.go）
+	presenceMu     sync.Mutex
+	remotePresence map[string]*presenceSnapshot
 }
 
 // NewHub 创建新的Hub
@@ -55,6 +66,7 @@ func NewHub(messageBus messaging.MessageBus, instanceID string) *Hub {
 		broadcastWorkerCount: 2,
 		messageBus:           messageBus,
 		instanceID:           instanceID,
+		remotePresence:       make(map[string]*presenceSnapshot),
 	}
 }
 
@@ -188,6 +200,14 @@ func (h *Hub) GetRoomClients(roomID string) []*Client {
 	return clients
 }
 
+// GetClientRoom 获取客户端当前所在的房间ID
+func (h *Hub) GetClientRoom(client *Client) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	roomID, ok := h.clientRooms[client]
+	return roomID, ok
+}
+
 // GetUserClient 根据用户ID获取客户端
 func (h *Hub) GetUserClient(userID uint) *Client {
 	h.mu.RLock()
@@ -227,6 +247,133 @@ func (h *Hub) GetUserClients() map[uint]*Client {
 	return h.userClients
 }
 
+// GetRoomClientCounts 获取每个房间的客户端数量快照（诊断用）
+func (h *Hub) GetRoomClientCounts() map[string]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	counts := make(map[string]int, len(h.rooms))
+	for roomID, room := range h.rooms {
+		counts[roomID] = len(room)
+	}
+	return counts
+}
+
+// GetConnectedUserIDs 获取当前在线用户ID快照（诊断用）
+func (h *Hub) GetConnectedUserIDs() []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]uint, 0, len(h.userClients))
+	for id := range h.userClients {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// IsShuttingDown 是否已进入关闭流程（用于拒绝新的 /ws 升级）
+func (h *Hub) IsShuttingDown() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.shuttingDown
+}
+
+// Shutdown 优雅关闭所有WebSocket连接：
+// 1. 标记关闭状态，拒绝新的连接升级
+// 2. 向所有在线客户端发送 server_shutdown 消息
+// 3. 短暂等待 WritePump 刷出发送缓冲区
+// 4. 关闭 send channel（触发 CloseMessage）并断开底层连接
+// 整体耗时受 ctx 限制。
+func (h *Hub) Shutdown(ctx context.Context) {
+	h.mu.Lock()
+	h.shuttingDown = true
+	clients := make([]*Client, 0, len(h.userClients))
+	for _, client := range h.userClients {
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	// 通知所有客户端服务器即将关闭
+	for _, client := range clients {
+		client.SendMessage(&Message{
+			Type: "server_shutdown",
+			RawData: map[string]interface{}{
+				"message": "服务器即将关闭，请稍后重连",
+			},
+		})
+	}
+
+	// 留出时间让 WritePump 刷出发送缓冲区
+	select {
+	case <-time.After(500 * time.Millisecond):
+	case <-ctx.Done():
+	}
+
+	// 关闭 send channel，WritePump 会发送 CloseMessage 后退出
+	for _, client := range clients {
+		client.CloseSend()
+	}
+
+	// 等待客户端收到关闭帧后主动断开，超时则强制关闭连接
+	select {
+	case <-time.After(time.Second):
+	case <-ctx.Done():
+	}
+	for _, client := range clients {
+		client.conn.Close()
+	}
+}
+
+// OldestIdleAge 最久未活跃连接的空闲时长（无连接时为0），用于监控半开连接
+func (h *Hub) OldestIdleAge() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var oldest time.Duration
+	now := time.Now()
+	for _, client := range h.userClients {
+		if age := now.Sub(client.LastPong()); age > oldest {
+			oldest = age
+		}
+	}
+	return oldest
+}
+
+// StartReaper 启动僵尸连接清理：超过threshold未收到pong（或任意消息）的连接
+// 会被主动断开，不必等到读超时。断开后ReadPump退出并走正常注销流程。
+func (h *Hub) StartReaper(threshold, interval time.Duration) {
+	if threshold <= 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			h.reapStaleClients(threshold)
+		}
+	}()
+}
+
+// reapStaleClients 断开超过threshold未活跃的连接
+func (h *Hub) reapStaleClients(threshold time.Duration) {
+	h.mu.RLock()
+	stale := make([]*Client, 0)
+	now := time.Now()
+	for _, client := range h.userClients {
+		if now.Sub(client.LastPong()) > threshold {
+			stale = append(stale, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range stale {
+		logger.Logger.Warn("清理僵尸WebSocket连接",
+			zap.Uint("user_id", client.userID),
+			zap.Duration("idle", now.Sub(client.LastPong())),
+		)
+		// 关闭底层连接，ReadPump随之退出并触发注销
+		client.conn.Close()
+	}
+}
+
 // StartWorkers 启动 worker goroutines（处理注册/注销）
 func (h *Hub) StartWorkers() {
 	// 启动多个 worker goroutine 并行处理注册/注销
@@ -4,11 +4,12 @@ import "encoding/json"
 
 // Message WebSocket消息
 type Message struct {
-	Type    string          `json:"type"`    // 消息类型
-	RoomID  string          `json:"room_id"` // 房间ID（可选）
-	UserID  uint            `json:"user_id"` // 用户ID
-	Data    json.RawMessage `json:"data"`    // 消息数据
-	RawData interface{}     `json:"-"`       // 原始数据（用于内部处理）
+	Type      string          `json:"type"`    // 消息类型
+	RoomID    string          `json:"room_id"` // 房间ID（可选）
+	UserID    uint            `json:"user_id"` // 用户ID
+	Data      json.RawMessage `json:"data"`    // 消息数据
+	RawData   interface{}     `json:"-"`       // 原始数据（用于内部处理）
+	RequestID string          `json:"-"`       // 请求追踪ID（仅用于日志串联，不下发客户端）
 }
 
 // GetString 从 map 中安全获取字符串
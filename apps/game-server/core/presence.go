@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/logger"
+	"go.uber.org/zap"
+)
+
+// PresenceTopic 在线状态快照使用的消息总线主题
+const PresenceTopic = "game-presence"
+
+// presenceStaleAfter 超过该时长未更新的远端快照视为实例下线，不再计入
+const presenceStaleAfter = 90 * time.Second
+
+// presenceSnapshot 单个远端实例的在线用户快照
+type presenceSnapshot struct {
+	userIDs   []uint
+	updatedAt time.Time
+}
+
+// StartPresenceBroadcast 定期把本实例的在线用户快照发布到消息总线。
+// 各实例互相收集快照后合并出全局在线状态（近似值，最终一致），
+// interval非正值时使用30秒；未启用消息总线时不发布
+func (h *Hub) StartPresenceBroadcast(ctx context.Context, interval time.Duration) {
+	if h.messageBus == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := h.publishPresence(ctx); err != nil {
+					logger.Logger.Debug("发布在线状态快照失败", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// publishPresence 发布一次本实例的在线用户快照
+func (h *Hub) publishPresence(ctx context.Context) error {
+	return h.messageBus.Publish(ctx, PresenceTopic, map[string]interface{}{
+		"type":        "presence_snapshot",
+		"instance_id": h.instanceID,
+		"user_ids":    h.GetConnectedUserIDs(),
+		"timestamp":   time.Now().Unix(),
+	})
+}
+
+// HandlePresenceMessage 接收其他实例的在线状态快照（消息总线回调）
+func (h *Hub) HandlePresenceMessage(topic string, message []byte) error {
+	var wrapper map[string]interface{}
+	if err := json.Unmarshal(message, &wrapper); err != nil {
+		return err
+	}
+
+	// Kafka 消息被包装在 data 字段中（与广播消息格式一致）
+	msg := wrapper
+	if data, ok := wrapper["data"].(map[string]interface{}); ok {
+		msg = data
+	}
+
+	instanceID, _ := msg["instance_id"].(string)
+	if instanceID == "" || instanceID == h.instanceID {
+		return nil
+	}
+
+	var userIDs []uint
+	if rawIDs, ok := msg["user_ids"].([]interface{}); ok {
+		userIDs = make([]uint, 0, len(rawIDs))
+		for _, raw := range rawIDs {
+			if id, ok := raw.(float64); ok && id > 0 {
+				userIDs = append(userIDs, uint(id))
+			}
+		}
+	}
+
+	h.presenceMu.Lock()
+	h.remotePresence[instanceID] = &presenceSnapshot{
+		userIDs:   userIDs,
+		updatedAt: time.Now(),
+	}
+	h.presenceMu.Unlock()
+	return nil
+}
+
+// OnlineUsers 获取全局在线用户ID（本实例实时 + 远端快照合并去重）。
+// 远端数据按广播周期更新，结果是近似的最终一致视图
+func (h *Hub) OnlineUsers() []uint {
+	seen := make(map[uint]bool)
+	for _, id := range h.GetConnectedUserIDs() {
+		seen[id] = true
+	}
+
+	now := time.Now()
+	h.presenceMu.Lock()
+	for instanceID, snapshot := range h.remotePresence {
+		// 过期快照视为实例下线，顺手清理
+		if now.Sub(snapshot.updatedAt) > presenceStaleAfter {
+			delete(h.remotePresence, instanceID)
+			continue
+		}
+		for _, id := range snapshot.userIDs {
+			seen[id] = true
+		}
+	}
+	h.presenceMu.Unlock()
+
+	ids := make([]uint, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// OnlineCount 获取全局在线用户数（跨实例去重后的近似值）
+func (h *Hub) OnlineCount() int {
+	return len(h.OnlineUsers())
+}
+
+// IsUserOnline 用户是否在任一实例在线（供好友在线等功能使用）
+func (h *Hub) IsUserOnline(userID uint) bool {
+	for _, id := range h.OnlineUsers() {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
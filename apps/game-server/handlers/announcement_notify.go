@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/logger"
+	"go.uber.org/zap"
+)
+
+// AnnouncementNotifyRequest 公告推送请求
+type AnnouncementNotifyRequest struct {
+	ID          uint   `json:"id" binding:"required"`
+	Title       string `json:"title" binding:"required"`
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Priority    int    `json:"priority"`
+	TargetUsers string `json:"target_users"` // "all" 或逗号分隔的用户ID列表
+}
+
+// HandleAnnouncementNotify 处理公告推送通知（供管理后台调用）。
+// 通过 Broadcaster 投递给本实例在线用户，并经消息总线同步到其他实例。
+func HandleAnnouncementNotify(c *gin.Context) {
+	var req AnnouncementNotifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误",
+			"error":   err.Error(),
+		})
+		logger.Logger.Error("handleAnnouncementNotify: 参数绑定失败", zap.Error(err))
+		return
+	}
+
+	rawData := map[string]interface{}{
+		"id":       req.ID,
+		"title":    req.Title,
+		"content":  req.Content,
+		"type":     req.Type,
+		"priority": req.Priority,
+	}
+
+	if req.TargetUsers == "" || req.TargetUsers == "all" {
+		// 全员公告：大厅广播（RoomID 为空且 UserID 为 0）
+		hubInstance.BroadcastMessage(newMessageFunc("announcement", "", 0, rawData))
+	} else {
+		// 定向公告：对每个目标用户单播，不在线的用户由其他实例或落库消息兜底
+		for _, idStr := range strings.Split(req.TargetUsers, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 64)
+			if err != nil || id == 0 {
+				continue
+			}
+			hubInstance.BroadcastMessage(newMessageFunc("announcement", "", uint(id), rawData))
+		}
+	}
+
+	logger.Logger.Info("公告通知已广播",
+		zap.Uint("announcement_id", req.ID),
+		zap.String("target_users", req.TargetUsers),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "通知已发送",
+	})
+}
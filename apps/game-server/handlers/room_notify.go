@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/apps/game-server/services/history"
 	"github.com/kaifa/game-platform/internal/logger"
 	"go.uber.org/zap"
 )
@@ -43,6 +44,13 @@ func HandleRoomNotify(c *gin.Context) {
 		}
 	}
 
+	// 带request_id记录，与API服务和Kafka广播日志串联
+	logger.Logger.Debug("收到房间通知",
+		zap.String("action", req.Action),
+		zap.String("room_id", req.RoomID),
+		zap.String("request_id", c.GetString("request_id")),
+	)
+
 	// 根据 action 路由到不同的处理函数
 	switch req.Action {
 	case "room_created":
@@ -64,8 +72,12 @@ func HandleRoomNotify(c *gin.Context) {
 		handleGameStarted(&req)
 		handleGenericBroadcast(c, &req)
 	case "game_end":
+		history.Record(req.RoomID, req.Action, req.UserID)
 		handleGameEnd(&req)
 		handleGenericBroadcast(c, &req)
+	case "kicked":
+		handleKicked(&req)
+		handleGenericBroadcast(c, &req)
 	default:
 		// 其他通用 action（join, leave, ready等）
 		handleGenericAction(c, &req)
@@ -75,6 +87,8 @@ func HandleRoomNotify(c *gin.Context) {
 
 // handleGenericAction 处理通用 action（join, leave, ready等）
 func handleGenericAction(c *gin.Context, req *RoomNotifyRequest) {
+	// 记入房间事件时间线（中途加入/重连的客户端通过room_history查看）
+	history.Record(req.RoomID, req.Action, req.UserID)
 	handleGenericBroadcast(c, req)
 }
 
@@ -86,6 +100,7 @@ func handleGenericBroadcast(c *gin.Context, req *RoomNotifyRequest) {
 		"user_id":   req.UserID,
 		"room_data": req.RoomData,
 	})
+	setMessageRequestID(msg, c.GetString("request_id"))
 
 	// 如果提供了房间数据，从房间数据中获取所有用户ID并广播给这些用户
 	broadcastToRoomPlayers(req, msg)
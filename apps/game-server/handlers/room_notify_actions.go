@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/apps/game-server/services/history"
 	"github.com/kaifa/game-platform/apps/game-server/utils"
 	"github.com/kaifa/game-platform/internal/logger"
 	"go.uber.org/zap"
@@ -90,6 +91,9 @@ func handleRoomDeleted(c *gin.Context, req *RoomNotifyRequest) {
 		"room_id": req.RoomID,
 	}))
 
+	// 房间已解散，事件时间线一并清理
+	history.Clear(req.RoomID)
+
 	logger.Logger.Info("房间删除通知已广播",
 		zap.String("room_id", req.RoomID),
 	)
@@ -221,6 +225,22 @@ func handleGameStarted(req *RoomNotifyRequest) {
 	}
 }
 
+// handleKicked 处理玩家被踢出
+// 被踢玩家已从房间玩家列表中移除，通用广播覆盖不到，需要单独通知
+func handleKicked(req *RoomNotifyRequest) {
+	if client := hubInstance.GetUserClient(req.UserID); client != nil {
+		client.SendMessage(newMessageFunc("kicked", req.RoomID, req.UserID, map[string]interface{}{
+			"message": "你已被房主踢出房间",
+			"room_id": req.RoomID,
+		}))
+	} else {
+		logger.Logger.Warn("被踢玩家未连接WebSocket",
+			zap.Uint("user_id", req.UserID),
+			zap.String("room_id", req.RoomID),
+		)
+	}
+}
+
 // handleGameEnd 处理游戏结束
 func handleGameEnd(req *RoomNotifyRequest) {
 	if req.RoomData == nil {
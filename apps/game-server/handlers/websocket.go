@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/pkg/services"
 	"github.com/kaifa/game-platform/pkg/utils"
 	"go.uber.org/zap"
 )
@@ -23,6 +24,7 @@ type HubInterface interface {
 	GetRoomClients(roomID string) []ClientInterface
 	BroadcastMessage(msg MessageInterface)
 	PublishSystemMessage(msgType, roomID string, data map[string]interface{}) error
+	IsShuttingDown() bool
 }
 
 // ClientInterface Client 接口
@@ -46,6 +48,21 @@ type NewClientFunc func(conn *websocket.Conn, ip string, userID uint) ClientInte
 // NewMessageFunc 创建消息的函数类型
 type NewMessageFunc func(msgType, roomID string, userID uint, rawData interface{}) MessageInterface
 
+// requestIDSetter 可选能力：消息可携带请求追踪ID（用于日志串联）
+type requestIDSetter interface {
+	SetRequestID(requestID string)
+}
+
+// setMessageRequestID 若消息支持则写入请求追踪ID
+func setMessageRequestID(msg MessageInterface, requestID string) {
+	if requestID == "" {
+		return
+	}
+	if setter, ok := msg.(requestIDSetter); ok {
+		setter.SetRequestID(requestID)
+	}
+}
+
 var newMessageFunc NewMessageFunc
 
 // SetUpgrader 设置 WebSocket Upgrader
@@ -70,6 +87,12 @@ func SetNewMessageFunc(f NewMessageFunc) {
 
 // HandleWebSocket 处理WebSocket连接
 func HandleWebSocket(c *gin.Context) {
+	// 服务器关闭流程中不再接受新连接
+	if hubInstance != nil && hubInstance.IsShuttingDown() {
+		c.JSON(503, gin.H{"code": 503, "message": "服务器正在关闭"})
+		return
+	}
+
 	// 获取Token（从query参数或header）
 	token := c.Query("token")
 	if token == "" {
@@ -92,6 +115,12 @@ func HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	// 维护模式下不接受新连接（豁免名单除外），已建立的连接继续完成当前对局
+	if services.MaintenanceBlocked(claims.UserID) {
+		c.JSON(503, gin.H{"code": 503, "message": "系统维护中，请稍后再试"})
+		return
+	}
+
 	// 升级到WebSocket连接
 	conn, err := upgraderInstance.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
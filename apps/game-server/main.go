@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,31 +20,47 @@ import (
 	"github.com/kaifa/game-platform/apps/game-server/handlers"
 	gameMessaging "github.com/kaifa/game-platform/apps/game-server/messaging"
 	"github.com/kaifa/game-platform/apps/game-server/services"
+	wschat "github.com/kaifa/game-platform/apps/game-server/services/chat"
+	wsgame "github.com/kaifa/game-platform/apps/game-server/services/game"
+	"github.com/kaifa/game-platform/apps/game-server/services/history"
 	"github.com/kaifa/game-platform/internal/bootstrap"
 	"github.com/kaifa/game-platform/internal/config"
 	"github.com/kaifa/game-platform/internal/discovery"
 	"github.com/kaifa/game-platform/internal/logger"
 	"github.com/kaifa/game-platform/internal/messaging"
+	"github.com/kaifa/game-platform/internal/middleware"
+	mysqlrepo "github.com/kaifa/game-platform/internal/repository/mysql"
+	gamesvc "github.com/kaifa/game-platform/internal/service/game"
+	leaderboardsrv "github.com/kaifa/game-platform/internal/service/leaderboard"
+	"github.com/kaifa/game-platform/internal/storage"
+	pkgservices "github.com/kaifa/game-platform/pkg/services"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 var (
 	upgrader = websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
-			// 生产环境需要验证来源
-			return true
+			// 与API的CORS共用同一来源白名单（cors.allowed_origins）
+			cfg := config.Get()
+			if cfg == nil {
+				return true
+			}
+			return cfg.IsOriginAllowed(r.Header.Get("Origin"))
 		},
 		// 增加读写缓冲区大小，提高性能
 		ReadBufferSize:  4096,
 		WriteBufferSize: 4096,
-		// 允许所有来源（开发环境）
-		EnableCompression: false, // 禁用压缩，减少CPU开销
+		// 默认禁用压缩减少CPU开销；可通过 server.ws_enable_compression 开启，
+		// 开启后仅对升级时协商了permessage-deflate的连接生效
+		EnableCompression: false,
 	}
 
 	// 全局实例
 	hubInstance          *core.Hub
 	broadcasterInstance  *gameMessaging.Broadcaster
 	kafkaHandlerInstance *gameMessaging.KafkaHandler
+	infraInstance        *bootstrap.Infrastructure
 )
 
 func main() {
@@ -51,6 +70,11 @@ func main() {
 		panic(fmt.Sprintf("加载配置失败: %v", err))
 	}
 
+	// 校验配置（聚合所有问题，启动时尽早暴露）
+	if err := config.Validate(cfg); err != nil {
+		panic(fmt.Sprintf("配置校验失败: %v", err))
+	}
+
 	// 初始化日志
 	if err := logger.InitLogger(cfg.Log); err != nil {
 		panic(fmt.Sprintf("初始化日志失败: %v", err))
@@ -62,6 +86,7 @@ func main() {
 		logger.Logger.Fatal("初始化基础设施失败", zap.Error(err))
 	}
 	defer infra.Close()
+	infraInstance = infra
 
 	if infra.RedisErr != nil {
 		logger.Logger.Warn("Redis连接失败，将使用降级方案", zap.Error(infra.RedisErr))
@@ -72,6 +97,12 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// 按配置开启WebSocket压缩协商（带宽/CPU权衡由部署方决定）
+	if cfg.Server.WSEnableCompression {
+		upgrader.EnableCompression = true
+		logger.Logger.Info("WebSocket permessage-deflate压缩协商已开启")
+	}
+
 	// 获取本机IP
 	localIP := getLocalIP()
 	instanceID := fmt.Sprintf("gs-%d-%d", cfg.Server.MachineID, os.Getpid())
@@ -102,6 +133,13 @@ func main() {
 			DeregisterAfter:     time.Duration(cfg.ServiceDiscovery.DeregisterAfter) * time.Second,
 			InstanceTTL:         time.Duration(cfg.ServiceDiscovery.InstanceTTL) * time.Second,
 			HeartbeatInterval:   time.Duration(cfg.ServiceDiscovery.HeartbeatInterval) * time.Second,
+			// 心跳时上报当前连接数（Hub 在注册流程之后创建，需判空）
+			LoadFunc: func() int {
+				if hubInstance == nil {
+					return 0
+				}
+				return hubInstance.GetConnectionCount()
+			},
 		}
 
 		var err error
@@ -181,7 +219,38 @@ func main() {
 		logger.Logger.Warn("消息总线未启用，跨实例消息功能不可用")
 	}
 
+	// WebSocket游戏操作直连游戏管理器（依赖Redis；不可用时退回HTTP重定向模式）
+	wsgame.SetRedirectGameActions(cfg.Server.WSRedirectGameActions)
+	if infra.Redis != nil && infra.RedisErr == nil {
+		userRepo := mysqlrepo.NewUserRepository(infra.DB)
+		wsgame.SetGameManager(gamesvc.NewManager(
+			storage.NewRedisGameStateStorage(infra.Redis),
+			mysqlrepo.NewRoomRepository(infra.DB),
+			userRepo,
+			mysqlrepo.NewGameRecordRepository(infra.DB),
+			mysqlrepo.NewTransactionRepository(infra.DB),
+			leaderboardsrv.New(infra.Redis, userRepo),
+			infra.DistLock,
+			infra.LocalLock,
+		))
+		logger.Logger.Info("✓ WebSocket游戏操作已启用进程内直连",
+			zap.Bool("redirect_mode", cfg.Server.WSRedirectGameActions))
+	} else {
+		logger.Logger.Warn("Redis不可用，WebSocket游戏操作退回HTTP重定向模式")
+	}
+
+	// 房间聊天/事件时间线：注入Redis以启用跨实例共享（不可用时退回进程内方案）
+	if infra.Redis != nil && infra.RedisErr == nil {
+		wschat.SetRedis(infra.Redis)
+		history.SetRedis(infra.Redis)
+	}
+
+	// 系统配置缓存（维护模式开关等），订阅配置变更实现跨实例失效
+	pkgservices.InitSystemConfigCache(infra.Redis)
+	wschat.SetEmoteCatalog(cfg.Server.WSEmoteIDs)
+
 	// 初始化 Hub
+	core.SetMaxMessageSize(cfg.Server.WSMaxMessageSize)
 	hubInstance = core.NewHub(messageBus, instanceID)
 
 	// 初始化 Broadcaster
@@ -193,9 +262,17 @@ func main() {
 	// 启动 Hub workers
 	hubInstance.StartWorkers()
 
+	// 启动僵尸连接清理（两个ping周期无响应即清理）
+	hubInstance.StartReaper(2*time.Minute, 30*time.Second)
+
 	// 启动广播 worker
 	go runBroadcastWorker(hubInstance, broadcasterInstance)
 
+	// 维护开关切换时向所有在线客户端广播公告
+	if infra.Redis != nil && infra.RedisErr == nil {
+		go watchMaintenanceToggle(infra.Redis)
+	}
+
 	// 如果启用了消息总线，订阅全局广播频道
 	if messageBus != nil {
 		broadcastTopic := "broadcast-all"
@@ -207,6 +284,12 @@ func main() {
 				zap.String("instance_id", instanceID),
 			)
 		}
+
+		// 在线状态：订阅其他实例的快照并定期发布本实例快照
+		if err := messageBus.Subscribe(context.Background(), core.PresenceTopic, hubInstance.HandlePresenceMessage); err != nil {
+			logger.Logger.Error("订阅在线状态频道失败", zap.Error(err))
+		}
+		hubInstance.StartPresenceBroadcast(context.Background(), 30*time.Second)
 	}
 
 	// 初始化 handlers 依赖
@@ -271,6 +354,11 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// 先优雅断开WebSocket客户端（通知 + 排空发送缓冲区），再关闭HTTP服务器
+	if hubInstance != nil {
+		hubInstance.Shutdown(ctx)
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Logger.Fatal("游戏服务器强制关闭", zap.Error(err))
 	}
@@ -321,6 +409,96 @@ func runBroadcastWorker(hub *core.Hub, broadcaster *gameMessaging.Broadcaster) {
 	}
 }
 
+// watchMaintenanceToggle 订阅配置变更频道，维护开关切换时向所有在线客户端广播公告
+func watchMaintenanceToggle(rdb *redis.Client) {
+	sub := rdb.Subscribe(context.Background(), pkgservices.ConfigChangedChannel)
+	for msg := range sub.Channel() {
+		if msg.Payload != pkgservices.MaintenanceModeKey && msg.Payload != "*" {
+			continue
+		}
+
+		// 先失效本地缓存，确保读到的是切换后的最新值
+		pkgservices.GetSystemConfigService().Invalidate(msg.Payload)
+
+		announcement := "系统维护已结束，欢迎回来"
+		if pkgservices.IsMaintenanceMode() {
+			announcement = "系统即将进入维护，当前对局结束后请退出游戏"
+		}
+
+		broadcasterInstance.BroadcastMessage(&core.Message{
+			Type: "announcement",
+			RawData: map[string]interface{}{
+				"message": announcement,
+			},
+		})
+		logger.Logger.Info("维护模式公告已广播", zap.String("announcement", announcement))
+	}
+}
+
+// handleDiagnostics 返回连接/房间诊断信息（排查线上问题用）。
+// 包含每个房间的客户端数、在线用户ID列表；指定room_id时额外返回该房间
+// 的成员及其WebSocket是否存活。会暴露用户ID，必须配置令牌后才可访问。
+func handleDiagnostics(c *gin.Context) {
+	token := config.Get().Server.DiagnosticsToken
+	if token == "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "诊断接口未启用（未配置diagnostics_token）",
+		})
+		return
+	}
+
+	provided := c.GetHeader("X-Diagnostics-Token")
+	if provided == "" {
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			provided = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "诊断令牌无效",
+		})
+		return
+	}
+
+	userIDs := hubInstance.GetConnectedUserIDs()
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	data := gin.H{
+		"instance_id":     hubInstance.GetInstanceID(),
+		"connections":     hubInstance.GetConnectionCount(),
+		"room_count":      hubInstance.GetRoomCount(),
+		"rooms":           hubInstance.GetRoomClientCounts(),
+		"connected_users": userIDs,
+		"time":            time.Now().Format(time.RFC3339),
+	}
+
+	// 指定房间时返回成员详情：房间内每个客户端及其连接是否仍在用户映射中
+	// （不在说明该连接已被新连接顶替或正在注销）
+	if roomID := c.Query("room_id"); roomID != "" {
+		clients := hubInstance.GetRoomClients(roomID)
+		members := make([]gin.H, 0, len(clients))
+		for _, client := range clients {
+			userID := client.GetUserID()
+			members = append(members, gin.H{
+				"user_id": userID,
+				"live":    hubInstance.GetUserClient(userID) == client,
+			})
+		}
+		data["room"] = gin.H{
+			"room_id":      roomID,
+			"client_count": len(members),
+			"members":      members,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": data,
+	})
+}
+
 // getLocalIP 获取本机IP地址
 func getLocalIP() string {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
@@ -336,6 +514,9 @@ func getLocalIP() string {
 func setupRouter() *gin.Engine {
 	r := gin.New()
 
+	// 请求追踪ID（接受API服务透传的X-Request-ID，日志可端到端串联）
+	r.Use(middleware.RequestID())
+
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -346,20 +527,69 @@ func setupRouter() *gin.Engine {
 		})
 	})
 
+	// 就绪检查（探测依赖，供K8s readiness probe使用）
+	r.GET("/ready", func(c *gin.Context) {
+		if infraInstance == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "not ready",
+				"error":  "基础设施未初始化",
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+		defer cancel()
+
+		deps, ready := infraInstance.Readiness(ctx)
+
+		status := "ready"
+		code := http.StatusOK
+		if !ready {
+			status = "not ready"
+			code = http.StatusServiceUnavailable
+		}
+
+		c.JSON(code, gin.H{
+			"status":       status,
+			"dependencies": deps,
+			"time":         time.Now().Format(time.RFC3339),
+		})
+	})
+
+	// 大厅在线状态（跨实例合并的近似在线人数）
+	// 好友在线列表待好友关系上线后在此扩展
+	r.GET("/presence", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "success",
+			"data": gin.H{
+				"online":            hubInstance.OnlineCount(),
+				"local_connections": hubInstance.GetConnectionCount(),
+			},
+		})
+	})
+
 	// 连接统计（用于测试和监控）
 	r.GET("/stats", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"connections": hubInstance.GetConnectionCount(),
-			"rooms":       hubInstance.GetRoomCount(),
-			"time":        time.Now().Format(time.RFC3339),
+			"connections":         hubInstance.GetConnectionCount(),
+			"rooms":               hubInstance.GetRoomCount(),
+			"oldest_idle_seconds": hubInstance.OldestIdleAge().Seconds(),
+			"time":                time.Now().Format(time.RFC3339),
 		})
 	})
 
 	// WebSocket连接
 	r.GET("/ws", handlers.HandleWebSocket)
 
+	// 内部API：连接/房间诊断（需配置diagnostics_token，携带令牌访问）
+	r.GET("/internal/diagnostics", handleDiagnostics)
+
 	// 内部API：房间状态更新通知（供API服务调用）
 	r.POST("/internal/room/notify", handlers.HandleRoomNotify)
 
+	// 内部API：公告实时推送（供管理后台调用）
+	r.POST("/internal/announcement/notify", handlers.HandleAnnouncementNotify)
+
 	return r
 }
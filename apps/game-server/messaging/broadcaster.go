@@ -32,12 +32,16 @@ func (b *Broadcaster) BroadcastMessage(message *core.Message) {
 	// 第一步：获取目标客户端列表
 	clientList := b.getTargetClients(message)
 
-	// 如果没有目标客户端，直接返回
+	// 如果没有目标客户端，本地不发送；跨实例类型仍需发布到消息总线
+	// （目标用户可能连接在其他实例上）
 	if len(clientList) == 0 {
-		logger.Logger.Debug("没有目标客户端，跳过广播",
+		logger.Logger.Debug("没有本地目标客户端，跳过本地广播",
 			zap.String("type", message.Type),
 			zap.String("room_id", message.RoomID),
 		)
+		if b.messageBus != nil && isCrossInstanceType(message.Type) {
+			b.publishToKafka(message)
+		}
 		return
 	}
 
@@ -58,12 +62,21 @@ func (b *Broadcaster) BroadcastMessage(message *core.Message) {
 	b.sendToClients(clientList, data, message.Type)
 
 	// 第四步：如果启用了消息总线，发布到 Kafka（跨实例通信）
-	// 只对特定类型的消息进行跨实例广播（如 room_message, test_message）
-	if b.messageBus != nil && (message.Type == "room_message" || message.Type == "test_message") {
+	// 只对特定类型的消息进行跨实例广播
+	if b.messageBus != nil && isCrossInstanceType(message.Type) {
 		b.publishToKafka(message)
 	}
 }
 
+// isCrossInstanceType 判断消息类型是否需要跨实例广播
+func isCrossInstanceType(msgType string) bool {
+	switch msgType {
+	case "room_message", "test_message", "announcement":
+		return true
+	}
+	return false
+}
+
 // BroadcastMessageLocal 仅本地广播（不发布到 Kafka）
 func (b *Broadcaster) BroadcastMessageLocal(message *core.Message) {
 	// 获取目标客户端列表
@@ -210,6 +223,10 @@ func (b *Broadcaster) publishToKafka(message *core.Message) {
 		"room_id": message.RoomID,
 		"user_id": message.UserID,
 	}
+	if message.RequestID != "" {
+		// 透传请求追踪ID，消费实例用同一ID记录日志
+		crossInstanceMsg["request_id"] = message.RequestID
+	}
 	if message.RawData != nil {
 		crossInstanceMsg["raw_data"] = message.RawData
 	} else if len(message.Data) > 0 {
@@ -61,9 +61,10 @@ func (h *KafkaHandler) HandleCrossInstanceBroadcast(topic string, message []byte
 	// 构建内部 Message 格式
 	userID := core.GetUint(msg, "user_id")
 	internalMsg := &core.Message{
-		Type:   core.GetString(msg, "type"),
-		RoomID: roomID,
-		UserID: userID,
+		Type:      core.GetString(msg, "type"),
+		RoomID:    roomID,
+		UserID:    userID,
+		RequestID: core.GetString(msg, "request_id"),
 	}
 
 	if rawData, ok := msg["raw_data"].(map[string]interface{}); ok {
@@ -76,6 +77,7 @@ func (h *KafkaHandler) HandleCrossInstanceBroadcast(topic string, message []byte
 		zap.Uint("user_id", userID),
 		zap.String("source_instance", sourceInstance),
 		zap.String("instance_id", h.instanceID),
+		zap.String("request_id", internalMsg.RequestID),
 		zap.Any("raw_data", internalMsg.RawData),
 	)
 
@@ -94,10 +96,13 @@ func (h *KafkaHandler) HandleCrossInstanceBroadcast(topic string, message []byte
 				zap.String("room_id", roomID),
 			)
 		}
+	} else if msgType == "announcement" && userID != 0 {
+		// 定向公告：目标用户可能连接在本实例，按单播投递（没有则静默忽略）
+		h.broadcaster.BroadcastMessageLocal(internalMsg)
 	} else {
 		// room_id 为空
-		// test_message 和 room_message 类型应该广播给所有客户端（用于跨实例消息传播测试）
-		if msgType == "test_message" || msgType == "room_message" {
+		// test_message、room_message 和全员公告应该广播给所有客户端
+		if msgType == "test_message" || msgType == "room_message" || msgType == "announcement" {
 			// 广播给所有客户端（大厅广播）
 			totalClients := h.hub.GetConnectionCount()
 			if totalClients > 0 {
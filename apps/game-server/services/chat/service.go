@@ -0,0 +1,339 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/kaifa/game-platform/apps/game-server/core"
+	"github.com/kaifa/game-platform/apps/game-server/messaging"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/pkg/services"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxChatLength 单条聊天内容的最大字符数
+	maxChatLength = 200
+	// rateWindow / rateLimit 限流窗口：每个连接rateWindow内最多rateLimit条
+	rateWindow = 10 * time.Second
+	rateLimit  = 5
+	// historySize / historyTTL 每个房间保留的最近聊天条数及过期时间（断线重连用）
+	historySize = 50
+	historyTTL  = time.Hour
+)
+
+// redisClient 聊天历史存储（为nil时不持久化，聊天仍可用）
+var redisClient *redis.Client
+
+// SetRedis 注入Redis客户端，启用聊天历史持久化
+func SetRedis(client *redis.Client) {
+	redisClient = client
+}
+
+// emoteCatalog 服务端表情目录：客户端只能发送目录内的表情ID（防止注入任意内容）
+var emoteCatalog = map[string]bool{}
+
+// SetEmoteCatalog 设置表情目录（从配置注入）
+func SetEmoteCatalog(ids []string) {
+	catalog := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		catalog[id] = true
+	}
+	emoteCatalog = catalog
+}
+
+// sensitiveWords 基础敏感词表，命中的词会被替换为等长的*
+var sensitiveWords = []string{
+	"傻逼", "妈的", "操你", "去死",
+}
+
+// maskSensitiveWords 屏蔽敏感词
+func maskSensitiveWords(content string) string {
+	for _, word := range sensitiveWords {
+		if strings.Contains(content, word) {
+			content = strings.ReplaceAll(content, word, strings.Repeat("*", len([]rune(word))))
+		}
+	}
+	return content
+}
+
+// Service 聊天服务（每个连接一个实例）
+type Service struct {
+	client      *core.Client
+	hub         *core.Hub
+	broadcaster *messaging.Broadcaster
+
+	// sendTimes / emoteTimes 限流窗口内的发送时间（单连接内串行处理，无需加锁）
+	sendTimes  []time.Time
+	emoteTimes []time.Time
+}
+
+// NewService 创建聊天服务
+func NewService(client *core.Client, hub *core.Hub, broadcaster *messaging.Broadcaster) *Service {
+	return &Service{
+		client:      client,
+		hub:         hub,
+		broadcaster: broadcaster,
+	}
+}
+
+// HandleChat 处理聊天消息：校验房间成员身份、长度、频率，
+// 过滤敏感词后广播给房间并记录历史
+func (s *Service) HandleChat(msg *core.Message) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		s.sendError("解析聊天数据失败")
+		return
+	}
+
+	roomID, _ := data["room_id"].(string)
+	if roomID == "" {
+		roomID = msg.RoomID
+	}
+
+	// 发言者必须已通过join_room进入该房间
+	currentRoom, ok := s.hub.GetClientRoom(s.client)
+	if !ok || currentRoom != roomID {
+		s.sendError("请先加入房间再发言")
+		return
+	}
+
+	content, _ := data["content"].(string)
+	content = strings.TrimSpace(content)
+	if content == "" {
+		s.sendError("聊天内容不能为空")
+		return
+	}
+	if len([]rune(content)) > maxChatLength {
+		s.sendError("聊天内容过长")
+		return
+	}
+
+	if !allowSend(&s.sendTimes) {
+		s.sendError("发言太频繁，请稍后再试")
+		return
+	}
+
+	content = maskSensitiveWords(content)
+	userID := s.client.GetUserID()
+	nickname := s.lookupNickname(roomID, userID)
+	timestamp := time.Now().Unix()
+
+	chatData := map[string]interface{}{
+		"user_id":   userID,
+		"nickname":  nickname,
+		"content":   content,
+		"timestamp": timestamp,
+	}
+
+	// 广播给房间（含跨实例）
+	s.broadcaster.BroadcastMessage(&core.Message{
+		Type:    "chat",
+		RoomID:  roomID,
+		UserID:  userID,
+		RawData: chatData,
+	})
+
+	s.appendHistory(roomID, chatData)
+}
+
+// HandleEmote 处理表情消息：校验房间成员身份、表情ID在目录内、频率，
+// 然后广播给房间。表情不写入聊天历史
+func (s *Service) HandleEmote(msg *core.Message) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		s.sendError("解析表情数据失败")
+		return
+	}
+
+	roomID, _ := data["room_id"].(string)
+	if roomID == "" {
+		roomID = msg.RoomID
+	}
+
+	// 与聊天一致：发送者必须已在该房间内
+	currentRoom, ok := s.hub.GetClientRoom(s.client)
+	if !ok || currentRoom != roomID {
+		s.sendError("请先加入房间再发送表情")
+		return
+	}
+
+	emoteID, _ := data["emote_id"].(string)
+	if !emoteCatalog[emoteID] {
+		s.sendError("不支持的表情")
+		return
+	}
+
+	if !allowSend(&s.emoteTimes) {
+		s.sendError("表情发送太频繁，请稍后再试")
+		return
+	}
+
+	userID := s.client.GetUserID()
+	s.broadcaster.BroadcastMessage(&core.Message{
+		Type:   "emote",
+		RoomID: roomID,
+		UserID: userID,
+		RawData: map[string]interface{}{
+			"user_id":   userID,
+			"nickname":  s.lookupNickname(roomID, userID),
+			"emote_id":  emoteID,
+			"timestamp": time.Now().Unix(),
+		},
+	})
+}
+
+// SendHistory 向当前客户端下发最近的聊天记录（加入/重连时调用）
+func (s *Service) SendHistory(msg *core.Message) {
+	if redisClient == nil {
+		return
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return
+	}
+	roomID, _ := data["room_id"].(string)
+	if roomID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	entries, err := redisClient.LRange(ctx, historyKey(roomID), 0, historySize-1).Result()
+	if err != nil || len(entries) == 0 {
+		return
+	}
+
+	// 存储是新消息在前，下发时还原时间顺序
+	history := make([]map[string]interface{}, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(entries[i]), &entry); err == nil {
+			history = append(history, entry)
+		}
+	}
+
+	s.sendMessage(&core.Message{
+		Type:   "chat_history",
+		RoomID: roomID,
+		RawData: map[string]interface{}{
+			"messages": history,
+		},
+	})
+}
+
+// allowSend 滑动窗口限流（聊天和表情各自维护一个窗口）
+func allowSend(times *[]time.Time) bool {
+	now := time.Now()
+	kept := (*times)[:0]
+	for _, t := range *times {
+		if now.Sub(t) < rateWindow {
+			kept = append(kept, t)
+		}
+	}
+	*times = kept
+
+	if len(*times) >= rateLimit {
+		return false
+	}
+	*times = append(*times, now)
+	return true
+}
+
+// lookupNickname 从Redis同步的房间信息中查昵称，查不到时返回空串
+func (s *Service) lookupNickname(roomID string, userID uint) string {
+	if redisClient == nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	playersJSON, err := redisClient.HGet(ctx, "room:"+roomID, "players").Result()
+	if err != nil {
+		return ""
+	}
+
+	var players []services.PlayerInfo
+	if err := json.Unmarshal([]byte(playersJSON), &players); err != nil {
+		return ""
+	}
+	for _, p := range players {
+		if p.UserID == userID {
+			return p.Nickname
+		}
+	}
+	return ""
+}
+
+// appendHistory 记录聊天历史（最近historySize条，historyTTL后过期）
+func (s *Service) appendHistory(roomID string, chatData map[string]interface{}) {
+	if redisClient == nil {
+		return
+	}
+
+	entry, err := json.Marshal(chatData)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := historyKey(roomID)
+	pipe := redisClient.Pipeline()
+	pipe.LPush(ctx, key, entry)
+	pipe.LTrim(ctx, key, 0, historySize-1)
+	pipe.Expire(ctx, key, historyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Logger.Warn("记录聊天历史失败",
+			zap.String("room_id", roomID),
+			zap.Error(err),
+		)
+	}
+}
+
+func historyKey(roomID string) string {
+	return "chat:history:" + roomID
+}
+
+// sendError 发送错误消息给客户端
+func (s *Service) sendError(message string) {
+	s.sendMessage(&core.Message{
+		Type: "error",
+		RawData: map[string]interface{}{
+			"message": message,
+		},
+	})
+}
+
+// sendMessage 发送消息给客户端
+func (s *Service) sendMessage(msg *core.Message) {
+	// 构建要发送的消息对象
+	sendMsg := map[string]interface{}{
+		"type":    msg.Type,
+		"room_id": msg.RoomID,
+		"user_id": msg.UserID,
+	}
+
+	if msg.RawData != nil {
+		sendMsg["raw_data"] = msg.RawData
+	}
+
+	data, err := json.Marshal(sendMsg)
+	if err != nil {
+		logger.Logger.Error("序列化消息失败", zap.Error(err))
+		return
+	}
+
+	select {
+	case s.client.GetSendChannel() <- data:
+	default:
+		logger.Logger.Warn("发送缓冲区满", zap.Uint("user_id", s.client.GetUserID()))
+	}
+}
@@ -1,16 +1,42 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/kaifa/game-platform/apps/game-server/core"
 	"github.com/kaifa/game-platform/apps/game-server/messaging"
+	"github.com/kaifa/game-platform/apps/game-server/services/history"
 	"github.com/kaifa/game-platform/internal/config"
 	"github.com/kaifa/game-platform/internal/logger"
+	gamesvc "github.com/kaifa/game-platform/internal/service/game"
+	"github.com/kaifa/game-platform/pkg/models"
 	"go.uber.org/zap"
 )
 
+var (
+	// gameManager 游戏管理器（为nil时退回HTTP重定向模式）
+	gameManager *gamesvc.Manager
+	// redirectGameActions 强制使用HTTP重定向（迁移期的兼容开关）
+	redirectGameActions bool
+)
+
+// SetGameManager 注入游戏管理器，启用WebSocket直连游戏操作
+func SetGameManager(manager *gamesvc.Manager) {
+	gameManager = manager
+}
+
+// SetRedirectGameActions 设置是否保留HTTP重定向模式（迁移期兼容用）
+func SetRedirectGameActions(redirect bool) {
+	redirectGameActions = redirect
+}
+
+// useDirectActions 是否走进程内直连路径
+func useDirectActions() bool {
+	return gameManager != nil && !redirectGameActions
+}
+
 // Service 游戏服务
 type Service struct {
 	client      *core.Client
@@ -31,12 +57,7 @@ func NewService(client *core.Client, hub *core.Hub, broadcaster *messaging.Broad
 func (s *Service) HandlePlayCards(msg *core.Message) {
 	var data map[string]interface{}
 	if err := json.Unmarshal(msg.Data, &data); err != nil {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "解析出牌数据失败",
-			},
-		})
+		s.sendError("解析出牌数据失败")
 		return
 	}
 
@@ -47,12 +68,7 @@ func (s *Service) HandlePlayCards(msg *core.Message) {
 
 	cardsData, ok := data["cards"].([]interface{})
 	if !ok {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "无效的牌数据",
-			},
-		})
+		s.sendError("无效的牌数据")
 		return
 	}
 
@@ -64,31 +80,159 @@ func (s *Service) HandlePlayCards(msg *core.Message) {
 		}
 	}
 
-	// 通过HTTP调用API服务的出牌接口
-	cfg := config.Get()
-	if cfg == nil {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "配置加载失败",
-			},
-		})
+	if !useDirectActions() {
+		s.redirectPlayCards(roomID, cards)
+		return
+	}
+
+	// 进程内直连：直接调用游戏管理器，省去HTTP往返
+	ctx := context.Background()
+	userID := s.client.GetUserID()
+
+	currentState, err := gameManager.GetGameState(ctx, roomID)
+	if err != nil {
+		s.sendError("获取游戏状态失败: " + err.Error())
 		return
 	}
 
-	// 构建请求数据
-	reqData := map[string]interface{}{
-		"cards": cards,
+	var gameState *models.GameState
+	if currentState.GameType == "bull" {
+		if len(cards) != 5 {
+			s.sendError("牛牛游戏必须选择5张牌")
+			return
+		}
+		gameState, err = gameManager.PlayBullGame(ctx, roomID, userID, cards)
+	} else {
+		gameState, err = gameManager.PlayCards(ctx, roomID, userID, cards)
+	}
+	if err != nil {
+		s.sendError(err.Error())
+		return
+	}
+
+	s.pushDelta(roomID, gameState.BuildDelta("play_cards", userID, cards))
+}
+
+// HandlePass 处理过牌
+func (s *Service) HandlePass(msg *core.Message) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		s.sendError("解析数据失败")
+		return
+	}
+
+	roomID, _ := data["room_id"].(string)
+	if roomID == "" {
+		roomID = msg.RoomID
+	}
+
+	if !useDirectActions() {
+		s.redirectPass(roomID)
+		return
 	}
 
-	// 通知客户端通过API调用
-	apiURL := fmt.Sprintf("http://localhost:%d/api/v1/games/rooms/%s/play", cfg.Server.Port, roomID)
+	// 进程内直连
+	gameState, err := gameManager.Pass(context.Background(), roomID, s.client.GetUserID())
+	if err != nil {
+		s.sendError(err.Error())
+		return
+	}
+
+	s.pushDelta(roomID, gameState.BuildDelta("pass", s.client.GetUserID(), nil))
+}
+
+// HandleSurrender 处理认输（仅跑得快）
+func (s *Service) HandleSurrender(msg *core.Message) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		s.sendError("解析数据失败")
+		return
+	}
+
+	roomID, _ := data["room_id"].(string)
+	if roomID == "" {
+		roomID = msg.RoomID
+	}
+
+	if !useDirectActions() {
+		s.redirectSurrender(roomID)
+		return
+	}
+
+	// 进程内直连
+	gameState, err := gameManager.Surrender(context.Background(), roomID, s.client.GetUserID())
+	if err != nil {
+		s.sendError(err.Error())
+		return
+	}
+
+	s.pushDelta(roomID, gameState.BuildDelta("surrender", s.client.GetUserID(), nil))
+}
+
+// HandleGetGameState 处理获取游戏状态
+func (s *Service) HandleGetGameState(msg *core.Message) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		s.sendError("解析数据失败")
+		return
+	}
+
+	roomID, _ := data["room_id"].(string)
+	if roomID == "" {
+		roomID = msg.RoomID
+	}
+
+	if !useDirectActions() {
+		s.redirectGetGameState(roomID)
+		return
+	}
+
+	// 进程内直连：返回按当前用户过滤的游戏状态
+	gameState, err := gameManager.GetGameStateForUser(context.Background(), roomID, s.client.GetUserID())
+	if err != nil {
+		s.sendError(err.Error())
+		return
+	}
+
+	s.sendMessage(&core.Message{
+		Type:   "game_state",
+		RoomID: roomID,
+		RawData: map[string]interface{}{
+			"game_state": gameState,
+		},
+	})
+}
+
+// pushDelta 把状态增量广播给房间（本地连接+跨实例）。增量不含任何手牌，
+// 可以整体广播；客户端按Seq递增应用，发现序列号跳跃时应通过
+// get_game_state拉取完整状态重新同步（加入/重连时始终下发完整状态）。
+func (s *Service) pushDelta(roomID string, delta *models.GameStateDelta) {
+	history.Record(roomID, delta.Action, delta.ActorID)
+	s.broadcaster.BroadcastMessage(&core.Message{
+		Type:   "game_state_delta",
+		RoomID: roomID,
+		UserID: s.client.GetUserID(),
+		RawData: map[string]interface{}{
+			"delta":    delta,
+			"game_end": delta.Status == 3,
+		},
+	})
+}
+
+// redirectPlayCards 旧的HTTP重定向模式：提示客户端自行调用API出牌接口
+func (s *Service) redirectPlayCards(roomID string, cards []int) {
+	cfg := config.Get()
+	if cfg == nil {
+		s.sendError("配置加载失败")
+		return
+	}
+
+	apiURL := fmt.Sprintf("%s/api/v1/games/rooms/%s/play", cfg.APIBaseURL(), roomID)
 	logger.Logger.Debug("提示客户端通过API调用",
 		zap.String("url", apiURL),
 		zap.Uint("user_id", s.client.GetUserID()),
 	)
 
-	// 发送消息通知客户端通过API调用
 	s.sendMessage(&core.Message{
 		Type:   "play_cards_redirect",
 		RoomID: roomID,
@@ -96,7 +240,9 @@ func (s *Service) HandlePlayCards(msg *core.Message) {
 			"message": "请通过HTTP API调用出牌接口",
 			"url":     apiURL,
 			"method":  "POST",
-			"data":    reqData,
+			"data": map[string]interface{}{
+				"cards": cards,
+			},
 		},
 	})
 
@@ -112,37 +258,15 @@ func (s *Service) HandlePlayCards(msg *core.Message) {
 	})
 }
 
-// HandlePass 处理过牌
-func (s *Service) HandlePass(msg *core.Message) {
-	var data map[string]interface{}
-	if err := json.Unmarshal(msg.Data, &data); err != nil {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "解析数据失败",
-			},
-		})
-		return
-	}
-
-	roomID, _ := data["room_id"].(string)
-	if roomID == "" {
-		roomID = msg.RoomID
-	}
-
-	// 通知客户端通过API调用
+// redirectPass 旧的HTTP重定向模式：提示客户端自行调用API过牌接口
+func (s *Service) redirectPass(roomID string) {
 	cfg := config.Get()
 	if cfg == nil {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "配置加载失败",
-			},
-		})
+		s.sendError("配置加载失败")
 		return
 	}
 
-	apiURL := fmt.Sprintf("http://localhost:%d/api/v1/games/rooms/%s/pass", cfg.Server.Port, roomID)
+	apiURL := fmt.Sprintf("%s/api/v1/games/rooms/%s/pass", cfg.APIBaseURL(), roomID)
 	s.sendMessage(&core.Message{
 		Type:   "pass_redirect",
 		RoomID: roomID,
@@ -165,37 +289,46 @@ func (s *Service) HandlePass(msg *core.Message) {
 	})
 }
 
-// HandleGetGameState 处理获取游戏状态
-func (s *Service) HandleGetGameState(msg *core.Message) {
-	var data map[string]interface{}
-	if err := json.Unmarshal(msg.Data, &data); err != nil {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "解析数据失败",
-			},
-		})
+// redirectSurrender 旧的HTTP重定向模式：提示客户端自行调用API认输接口
+func (s *Service) redirectSurrender(roomID string) {
+	cfg := config.Get()
+	if cfg == nil {
+		s.sendError("配置加载失败")
 		return
 	}
 
-	roomID, _ := data["room_id"].(string)
-	if roomID == "" {
-		roomID = msg.RoomID
-	}
+	apiURL := fmt.Sprintf("%s/api/v1/games/rooms/%s/surrender", cfg.APIBaseURL(), roomID)
+	s.sendMessage(&core.Message{
+		Type:   "surrender_redirect",
+		RoomID: roomID,
+		RawData: map[string]interface{}{
+			"message": "请通过HTTP API调用认输接口",
+			"url":     apiURL,
+			"method":  "POST",
+		},
+	})
 
-	// 通知客户端通过API调用
+	// 广播给房间内其他客户端
+	s.broadcaster.BroadcastMessage(&core.Message{
+		Type:   "player_surrendered",
+		RoomID: roomID,
+		UserID: s.client.GetUserID(),
+		RawData: map[string]interface{}{
+			"user_id": s.client.GetUserID(),
+			"action":  "surrendered",
+		},
+	})
+}
+
+// redirectGetGameState 旧的HTTP重定向模式：提示客户端自行调用API获取状态
+func (s *Service) redirectGetGameState(roomID string) {
 	cfg := config.Get()
 	if cfg == nil {
-		s.sendMessage(&core.Message{
-			Type: "error",
-			RawData: map[string]interface{}{
-				"message": "配置加载失败",
-			},
-		})
+		s.sendError("配置加载失败")
 		return
 	}
 
-	apiURL := fmt.Sprintf("http://localhost:%d/api/v1/games/rooms/%s/game-state", cfg.Server.Port, roomID)
+	apiURL := fmt.Sprintf("%s/api/v1/games/rooms/%s/game-state", cfg.APIBaseURL(), roomID)
 	s.sendMessage(&core.Message{
 		Type:   "get_game_state_redirect",
 		RoomID: roomID,
@@ -207,6 +340,16 @@ func (s *Service) HandleGetGameState(msg *core.Message) {
 	})
 }
 
+// sendError 发送错误消息给客户端
+func (s *Service) sendError(message string) {
+	s.sendMessage(&core.Message{
+		Type: "error",
+		RawData: map[string]interface{}{
+			"message": message,
+		},
+	})
+}
+
 // sendMessage 发送消息给客户端
 func (s *Service) sendMessage(msg *core.Message) {
 	// 构建要发送的消息对象
@@ -0,0 +1,128 @@
+// Package history 维护每个房间最近的事件时间线（加入、出牌、过牌、超时等），
+// 供中途加入或断线重连的客户端了解房间里刚发生了什么。完整游戏状态恢复
+// 仍走 game_state_recovery，时间线只是人类可读的补充。
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxEvents 每个房间保留的最近事件条数
+	maxEvents = 50
+	// eventTTL Redis中时间线的过期时间（兜底，正常随room_deleted清理）
+	eventTTL = 2 * time.Hour
+)
+
+// redisClient 事件存储：有Redis时跨实例共享，否则退回进程内环形缓冲
+var redisClient *redis.Client
+
+// SetRedis 注入Redis客户端，启用跨实例共享的事件时间线
+func SetRedis(client *redis.Client) {
+	redisClient = client
+}
+
+// memEvents 进程内退化方案（Redis不可用时），roomID -> 最近事件（新在前）
+var (
+	memMu     sync.Mutex
+	memEvents = make(map[string][][]byte)
+)
+
+// Record 记录一条房间事件
+func Record(roomID, action string, userID uint) {
+	if roomID == "" || action == "" {
+		return
+	}
+
+	entry, err := json.Marshal(map[string]interface{}{
+		"action":    action,
+		"user_id":   userID,
+		"timestamp": time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+
+	if redisClient == nil {
+		memMu.Lock()
+		defer memMu.Unlock()
+		events := append([][]byte{entry}, memEvents[roomID]...)
+		if len(events) > maxEvents {
+			events = events[:maxEvents]
+		}
+		memEvents[roomID] = events
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := eventsKey(roomID)
+	pipe := redisClient.Pipeline()
+	pipe.LPush(ctx, key, entry)
+	pipe.LTrim(ctx, key, 0, maxEvents-1)
+	pipe.Expire(ctx, key, eventTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Logger.Warn("记录房间事件失败",
+			zap.String("room_id", roomID),
+			zap.Error(err),
+		)
+	}
+}
+
+// Recent 获取房间最近的事件（时间正序），没有事件时返回空切片
+func Recent(roomID string) []map[string]interface{} {
+	var raw [][]byte
+
+	if redisClient == nil {
+		memMu.Lock()
+		raw = append(raw, memEvents[roomID]...)
+		memMu.Unlock()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		entries, err := redisClient.LRange(ctx, eventsKey(roomID), 0, maxEvents-1).Result()
+		if err != nil {
+			return nil
+		}
+		for _, entry := range entries {
+			raw = append(raw, []byte(entry))
+		}
+	}
+
+	// 存储是新事件在前，下发时还原时间顺序
+	events := make([]map[string]interface{}, 0, len(raw))
+	for i := len(raw) - 1; i >= 0; i-- {
+		var event map[string]interface{}
+		if err := json.Unmarshal(raw[i], &event); err == nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Clear 清除房间的事件时间线（房间解散时调用）
+func Clear(roomID string) {
+	if redisClient == nil {
+		memMu.Lock()
+		delete(memEvents, roomID)
+		memMu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	redisClient.Del(ctx, eventsKey(roomID))
+}
+
+func eventsKey(roomID string) string {
+	return "room:events:" + roomID
+}
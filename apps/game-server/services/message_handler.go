@@ -6,6 +6,7 @@ import (
 
 	"github.com/kaifa/game-platform/apps/game-server/core"
 	"github.com/kaifa/game-platform/apps/game-server/messaging"
+	"github.com/kaifa/game-platform/apps/game-server/services/chat"
 	"github.com/kaifa/game-platform/apps/game-server/services/game"
 	"github.com/kaifa/game-platform/apps/game-server/services/room"
 	"github.com/kaifa/game-platform/internal/logger"
@@ -19,6 +20,7 @@ type MessageHandler struct {
 	broadcaster *messaging.Broadcaster
 	roomService *room.Service
 	gameService *game.Service
+	chatService *chat.Service
 }
 
 // NewMessageHandler 创建消息处理器
@@ -29,6 +31,7 @@ func NewMessageHandler(client *core.Client, hub *core.Hub, broadcaster *messagin
 		broadcaster: broadcaster,
 		roomService: room.NewService(client, hub, broadcaster),
 		gameService: game.NewService(client, hub, broadcaster),
+		chatService: chat.NewService(client, hub, broadcaster),
 	}
 }
 
@@ -74,10 +77,26 @@ func (h *MessageHandler) HandleMessage(msg *core.Message) {
 		// 过牌
 		h.gameService.HandlePass(msg)
 
+	case "surrender":
+		// 认输（仅跑得快）
+		h.gameService.HandleSurrender(msg)
+
 	case "get_game_state":
 		// 获取游戏状态
 		h.gameService.HandleGetGameState(msg)
 
+	case "chat":
+		// 房间聊天
+		h.chatService.HandleChat(msg)
+
+	case "emote":
+		// 房间表情（仅允许目录内的表情ID）
+		h.chatService.HandleEmote(msg)
+
+	case "chat_history":
+		// 拉取最近聊天记录（重连恢复用）
+		h.chatService.SendHistory(msg)
+
 	case "test_message", "room_message":
 		// 测试消息/房间消息（用于跨实例消息传播测试）
 		var data map[string]interface{}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kaifa/game-platform/apps/game-server/core"
 	"github.com/kaifa/game-platform/apps/game-server/messaging"
+	"github.com/kaifa/game-platform/apps/game-server/services/history"
 	"github.com/kaifa/game-platform/internal/logger"
 	"go.uber.org/zap"
 )
@@ -43,6 +44,9 @@ func (s *Service) HandleJoinRoom(msg *core.Message) {
 			// 如果房间有游戏状态，发送恢复消息（断线重连）
 			s.SendGameStateRecovery(roomID)
 
+			// 下发最近的房间事件时间线（中途加入/重连时了解刚发生了什么）
+			s.sendRoomHistory(roomID)
+
 			// 广播房间状态更新
 			s.broadcaster.BroadcastMessage(&core.Message{
 				Type:   "room_updated",
@@ -89,6 +93,22 @@ func (s *Service) HandleLeaveRoom(msg *core.Message) {
 	}
 }
 
+// sendRoomHistory 下发房间最近的事件时间线
+func (s *Service) sendRoomHistory(roomID string) {
+	events := history.Recent(roomID)
+	if len(events) == 0 {
+		return
+	}
+
+	s.sendMessage(&core.Message{
+		Type:   "room_history",
+		RoomID: roomID,
+		RawData: map[string]interface{}{
+			"events": events,
+		},
+	})
+}
+
 // SendGameStateRecovery 发送游戏状态恢复（断线重连）
 func (s *Service) SendGameStateRecovery(roomID string) {
 	// TODO: 从 API Server 或 Redis 获取游戏状态
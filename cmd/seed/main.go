@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/kaifa/game-platform/internal/config"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/kaifa/game-platform/pkg/utils"
+)
+
+// resourceNames 权限资源的中文名称
+var resourceNames = map[string]string{
+	"dashboard":         "仪表盘",
+	"users":             "用户管理",
+	"recharge_orders":   "充值订单",
+	"withdraw_orders":   "提现订单",
+	"deposit_addresses": "充值地址",
+	"payments":          "支付管理",
+	"roles":             "角色管理",
+	"admins":            "管理员管理",
+}
+
+// actionNames 权限动作的中文名称
+var actionNames = map[string]string{
+	"view":              "查看",
+	"list":              "列表",
+	"detail":            "详情",
+	"update":            "更新",
+	"ban":               "封禁",
+	"audit":             "审核",
+	"collect":           "归集",
+	"batch_collect":     "批量归集",
+	"create":            "创建",
+	"delete":            "删除",
+	"assign_permission": "分配权限",
+	"assign_role":       "分配角色",
+}
+
+// 初始化数据工具：写入默认权限目录、超级管理员角色和初始管理员账号。
+// 所有写入都是幂等的，重复执行不会产生重复数据，也不会覆盖已有管理员的密码。
+func main() {
+	configPath := flag.String("config", "", "配置文件路径（默认按 configs/ 目录查找）")
+	username := flag.String("username", "admin", "初始管理员用户名")
+	password := flag.String("password", "", "初始管理员密码（也可通过 SEED_ADMIN_PASSWORD 环境变量传入）")
+	flag.Parse()
+
+	if *password == "" {
+		*password = os.Getenv("SEED_ADMIN_PASSWORD")
+	}
+	if *password == "" {
+		fmt.Println("错误: 必须通过 -password 或 SEED_ADMIN_PASSWORD 指定初始管理员密码")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		panic(fmt.Sprintf("加载配置失败: %v", err))
+	}
+
+	db, err := database.InitMySQL(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("初始化数据库失败: %v", err))
+	}
+	defer database.Close()
+
+	permissions, err := seedPermissions(db)
+	if err != nil {
+		panic(fmt.Sprintf("写入权限目录失败: %v", err))
+	}
+	fmt.Printf("✅ 权限目录就绪（%d 个权限）\n", len(permissions))
+
+	role, err := seedSuperAdminRole(db, permissions)
+	if err != nil {
+		panic(fmt.Sprintf("写入超级管理员角色失败: %v", err))
+	}
+	fmt.Printf("✅ 超级管理员角色就绪（role_code=%s）\n", role.RoleCode)
+
+	if err := seedAdmin(db, *username, *password, role); err != nil {
+		panic(fmt.Sprintf("写入初始管理员失败: %v", err))
+	}
+
+	fmt.Println("✅ 初始化完成！")
+	fmt.Printf("   用户名: %s\n", *username)
+	fmt.Println("   角色: 超级管理员（拥有所有权限）")
+	fmt.Println("   ⚠️  请首次登录后立即修改密码！")
+}
+
+// seedPermissions 按权限代码目录写入 admin_permissions（已存在的不动）
+func seedPermissions(db *gorm.DB) ([]models.AdminPermission, error) {
+	codes := utils.GetAllPermissions()
+	permissions := make([]models.AdminPermission, 0, len(codes))
+
+	for i, code := range codes {
+		// 权限代码形如 admin:users:list
+		parts := strings.Split(code, ":")
+		resource, action := "", ""
+		if len(parts) == 3 {
+			resource, action = parts[1], parts[2]
+		}
+
+		name := code
+		if rn, ok := resourceNames[resource]; ok {
+			name = rn
+			if an, ok := actionNames[action]; ok {
+				name = rn + "-" + an
+			}
+		}
+
+		var perm models.AdminPermission
+		err := db.Where("permission_code = ?", code).
+			Attrs(models.AdminPermission{
+				PermissionName: name,
+				Resource:       resource,
+				Action:         action,
+				SortOrder:      i,
+			}).
+			FirstOrCreate(&perm, models.AdminPermission{PermissionCode: code}).Error
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, perm)
+	}
+
+	return permissions, nil
+}
+
+// seedSuperAdminRole 写入超级管理员角色并关联全部权限
+func seedSuperAdminRole(db *gorm.DB, permissions []models.AdminPermission) (*models.AdminRole, error) {
+	var role models.AdminRole
+	err := db.Where("role_code = ?", utils.RoleSuperAdmin).
+		Attrs(models.AdminRole{
+			RoleName:    "超级管理员",
+			Description: "拥有所有权限",
+			Status:      1,
+		}).
+		FirstOrCreate(&role, models.AdminRole{RoleCode: utils.RoleSuperAdmin}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, perm := range permissions {
+		var relation models.RolePermissionRelation
+		err := db.FirstOrCreate(&relation, models.RolePermissionRelation{
+			RoleID:       role.ID,
+			PermissionID: perm.ID,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &role, nil
+}
+
+// seedAdmin 写入初始管理员并关联超级管理员角色。
+// 管理员已存在时不覆盖密码，只确保角色关联存在。
+func seedAdmin(db *gorm.DB, username, password string, role *models.AdminRole) error {
+	// 与 handlers.hashPassword 一致的加密方式
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("加密密码失败: %w", err)
+	}
+
+	var admin models.Admin
+	result := db.Where("username = ?", username).
+		Attrs(models.Admin{
+			Password: string(hashedPassword),
+			Nickname: "超级管理员",
+			Status:   1,
+		}).
+		FirstOrCreate(&admin, models.Admin{Username: username})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		fmt.Printf("ℹ️  管理员 %s 已存在，保留原密码\n", username)
+	}
+
+	var relation models.AdminRoleRelation
+	return db.FirstOrCreate(&relation, models.AdminRoleRelation{
+		AdminID: admin.ID,
+		RoleID:  role.ID,
+	}).Error
+}
@@ -1,6 +1,7 @@
 package bootstrap
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -93,6 +94,54 @@ func InitInfrastructure(cfg *config.Config) (*Infrastructure, error) {
 	return infra, nil
 }
 
+// DependencyStatus 描述单个依赖的就绪状态
+type DependencyStatus struct {
+	Status   string `json:"status"`          // ok / error / disabled
+	Required bool   `json:"required"`        // 是否为必需依赖
+	Error    string `json:"error,omitempty"` // 失败原因
+}
+
+// Readiness 探测各基础依赖是否就绪（供 /ready 就绪检查使用）。
+// 返回各依赖的状态以及整体是否就绪：
+// - MySQL 为必需依赖，不可用时整体不就绪
+// - Redis 为可降级依赖，状态会一并上报但不影响就绪结果
+func (infra *Infrastructure) Readiness(ctx context.Context) (map[string]DependencyStatus, bool) {
+	statuses := make(map[string]DependencyStatus)
+	ready := true
+
+	// MySQL（必需）
+	mysqlStatus := DependencyStatus{Status: "ok", Required: true}
+	if infra.DB == nil {
+		mysqlStatus.Status = "error"
+		mysqlStatus.Error = "未初始化"
+	} else if sqlDB, err := infra.DB.DB(); err != nil {
+		mysqlStatus.Status = "error"
+		mysqlStatus.Error = err.Error()
+	} else if err := sqlDB.PingContext(ctx); err != nil {
+		mysqlStatus.Status = "error"
+		mysqlStatus.Error = err.Error()
+	}
+	if mysqlStatus.Status != "ok" {
+		ready = false
+	}
+	statuses["mysql"] = mysqlStatus
+
+	// Redis（可降级）
+	redisStatus := DependencyStatus{Status: "ok", Required: false}
+	if infra.Redis == nil {
+		redisStatus.Status = "disabled"
+		if infra.RedisErr != nil {
+			redisStatus.Error = infra.RedisErr.Error()
+		}
+	} else if err := infra.Redis.Ping(ctx).Err(); err != nil {
+		redisStatus.Status = "error"
+		redisStatus.Error = err.Error()
+	}
+	statuses["redis"] = redisStatus
+
+	return statuses, ready
+}
+
 // Close 依照逆序调用已注册的释放函数，确保资源按初始化顺序倒序释放。
 func (infra *Infrastructure) Close() {
 	for i := len(infra.closers) - 1; i >= 0; i-- {
@@ -18,8 +18,13 @@ type Config struct {
 	JWT              JWTConfig              `mapstructure:"jwt"`
 	Log              LogConfig              `mapstructure:"log"`
 	Payment          PaymentConfig          `mapstructure:"payment"`
+	Room             RoomConfig             `mapstructure:"room"`
 	ServiceDiscovery ServiceDiscoveryConfig `mapstructure:"service_discovery"`
 	Kafka            KafkaConfig            `mapstructure:"kafka"`
+	Internal         InternalConfig         `mapstructure:"internal"`
+	AdminSecurity    AdminSecurityConfig    `mapstructure:"admin_security"`
+	Notification     NotificationConfig     `mapstructure:"notification"`
+	CORS             CORSConfig             `mapstructure:"cors"`
 }
 
 // ServerConfig 服务器配置
@@ -31,6 +36,20 @@ type ServerConfig struct {
 	MachineID    int    `mapstructure:"machine_id"`   // 机器ID（0-1023，用于雪花算法）
 	ReadTimeout  int    `mapstructure:"read_timeout"` // 秒
 	WriteTimeout int    `mapstructure:"write_timeout"`
+
+	// 订单号/房间号是否使用雪花算法（时间有序、固定宽度），false回退UUID截断方案
+	UseSnowflakeID bool `mapstructure:"use_snowflake_id"`
+	// DiagnosticsToken 游戏服务器诊断接口令牌（为空时诊断接口禁用）
+	DiagnosticsToken string `mapstructure:"diagnostics_token"`
+	// WSMaxMessageSize WebSocket单条消息大小上限（字节），超出时以策略违规码断开
+	WSMaxMessageSize int64 `mapstructure:"ws_max_message_size"`
+	// WSRedirectGameActions WebSocket出牌/过牌走HTTP重定向（迁移期兼容开关，默认进程内直连）
+	WSRedirectGameActions bool `mapstructure:"ws_redirect_game_actions"`
+	// WSEnableCompression 允许WebSocket协商permessage-deflate压缩（按连接协商，
+	// 客户端不支持时自动退回明文）。省带宽、费CPU，默认关闭保持现状
+	WSEnableCompression bool `mapstructure:"ws_enable_compression"`
+	// WSEmoteIDs 房间表情目录：客户端只能发送目录内的表情ID，防止注入任意内容
+	WSEmoteIDs []string `mapstructure:"ws_emote_ids"`
 }
 
 // DatabaseConfig 数据库配置
@@ -56,9 +75,10 @@ type RedisConfig struct {
 
 // ESConfig Elasticsearch配置
 type ESConfig struct {
-	Addresses []string `mapstructure:"addresses"`
-	Username  string   `mapstructure:"username"`
-	Password  string   `mapstructure:"password"`
+	Addresses        []string `mapstructure:"addresses"`
+	Username         string   `mapstructure:"username"`
+	Password         string   `mapstructure:"password"`
+	LogRetentionDays int      `mapstructure:"log_retention_days"` // 日志索引保留天数（0表示不清理）
 }
 
 // JWTConfig JWT配置
@@ -78,8 +98,34 @@ type LogConfig struct {
 
 // PaymentConfig 支付配置
 type PaymentConfig struct {
-	EtherscanAPIKey string `mapstructure:"etherscan_api_key"` // Etherscan API Key（用于ERC20查询）
-	MasterMnemonic  string `mapstructure:"master_mnemonic"`   // 主钱包助记词（必须配置，用于HD钱包派生地址）
+	EtherscanAPIKey   string `mapstructure:"etherscan_api_key"`   // Etherscan API Key（用于ERC20查询）
+	MasterMnemonic    string `mapstructure:"master_mnemonic"`     // 主钱包助记词（必须配置，用于HD钱包派生地址）
+	WebhookURL        string `mapstructure:"webhook_url"`         // 订单状态回调地址（为空则不推送）
+	WebhookSecret     string `mapstructure:"webhook_secret"`      // 回调签名密钥（HMAC-SHA256）
+	WebhookMaxRetries int    `mapstructure:"webhook_max_retries"` // 回调最大重试次数
+
+	GasCacheTTL int `mapstructure:"gas_cache_ttl"` // Gas价格缓存TTL（秒）
+
+	MonitorInterval    int     `mapstructure:"monitor_interval"`    // 交易监控检查间隔（秒，默认30）
+	MonitorConcurrency int     `mapstructure:"monitor_concurrency"` // 单轮监控的并发检查数上限（默认5）
+	ProviderRateLimit  float64 `mapstructure:"provider_rate_limit"` // 链上API客户端限流（每提供商每秒请求数，默认5）
+
+	ExpireSweepInterval int `mapstructure:"expire_sweep_interval"` // 过期订单清扫间隔（秒，默认300）
+
+	CollectInterval    int     `mapstructure:"collect_interval"`    // 自动归集间隔（秒，0表示关闭）
+	CollectThreshold   float64 `mapstructure:"collect_threshold"`   // 自动归集最小余额（USDT）
+	CollectConcurrency int     `mapstructure:"collect_concurrency"` // 自动归集并发数
+}
+
+// RoomConfig 房间配置
+type RoomConfig struct {
+	DissolveOnOwnerLeave bool `mapstructure:"dissolve_on_owner_leave"` // 房主离开时解散房间（false则自动转让房主）
+	IdleTimeout          int  `mapstructure:"idle_timeout"`            // 等待中房间闲置超时（秒，0表示不清理）
+	MaxRoomsPerUser      int  `mapstructure:"max_rooms_per_user"`      // 单用户最大活跃房间数（0表示不限制）
+	AllowMultiSession    bool `mapstructure:"allow_multi_session"`     // 允许同一用户同时参与多局游戏（仅测试用）
+	ReadyWarnTimeout     int  `mapstructure:"ready_warn_timeout"`      // 他人准备后提醒未准备玩家的等待秒数（0表示关闭）
+	ReadyKickTimeout     int  `mapstructure:"ready_kick_timeout"`      // 提醒后仍未准备被移出房间的等待秒数（0表示关闭）
+	TurnTimeout          int  `mapstructure:"turn_timeout"`            // 回合倒计时时长（秒，0表示不发计时广播）
 }
 
 // ServiceDiscoveryConfig 服务发现配置
@@ -115,6 +161,77 @@ type KafkaConfig struct {
 	FetchMaxWaitMs         int  `mapstructure:"fetch_max_wait_ms"`         // 最大等待时间（毫秒）
 }
 
+// NotificationConfig 订单通知渠道配置
+type NotificationConfig struct {
+	Channels      []string `mapstructure:"channels"`       // 启用的渠道: user_message/websocket/webhook（可组合）
+	WebhookURL    string   `mapstructure:"webhook_url"`    // webhook渠道的接收地址
+	WebhookSecret string   `mapstructure:"webhook_secret"` // webhook请求头中的来源校验令牌
+}
+
+// InternalConfig 服务间内部调用配置
+// 为空时退回 localhost + 对应端口，兼容单机部署
+type InternalConfig struct {
+	GameServerBaseURL string `mapstructure:"game_server_base_url"` // API → 游戏服务器的回调基地址
+	APIBaseURL        string `mapstructure:"api_base_url"`         // 游戏服务器 → API 的基地址
+}
+
+// AdminSecurityConfig 管理后台安全配置
+type AdminSecurityConfig struct {
+	MaxLoginFailures int `mapstructure:"max_login_failures"` // 连续失败多少次后锁定
+	LockoutMinutes   int `mapstructure:"lockout_minutes"`    // 锁定时长（分钟）
+}
+
+// CORSConfig 跨域配置。API的CORS中间件和游戏服务器的WebSocket来源校验
+// 共用同一份来源白名单，避免两边配置不一致
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`   // 允许的来源；["*"]为全放行
+	AllowedMethods   []string `mapstructure:"allowed_methods"`   // 允许的HTTP方法
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`   // 允许的请求头
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带Cookie等凭据
+}
+
+// CORSAllowedOrigins 生效的来源白名单。
+// 未配置时按环境取默认值：debug全放行（本地开发方便），release拒绝所有跨域来源
+// （上线必须显式配置allowed_origins）。
+func (c *Config) CORSAllowedOrigins() []string {
+	if len(c.CORS.AllowedOrigins) > 0 {
+		return c.CORS.AllowedOrigins
+	}
+	if c.Server.Mode == "release" {
+		return nil
+	}
+	return []string{"*"}
+}
+
+// IsOriginAllowed 来源是否在白名单内。空来源（同源请求或非浏览器客户端）放行
+func (c *Config) IsOriginAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range c.CORSAllowedOrigins() {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// GameServerBaseURL 返回游戏服务器的内部基地址（末尾不含斜杠）
+func (c *Config) GameServerBaseURL() string {
+	if c.Internal.GameServerBaseURL != "" {
+		return strings.TrimRight(c.Internal.GameServerBaseURL, "/")
+	}
+	return fmt.Sprintf("http://localhost:%d", c.Server.GamePort)
+}
+
+// APIBaseURL 返回API服务的内部基地址（末尾不含斜杠）
+func (c *Config) APIBaseURL() string {
+	if c.Internal.APIBaseURL != "" {
+		return strings.TrimRight(c.Internal.APIBaseURL, "/")
+	}
+	return fmt.Sprintf("http://localhost:%d", c.Server.Port)
+}
+
 var globalConfig *Config
 
 type loadOptions struct {
@@ -197,6 +314,17 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.game_port", 8081)
 	v.SetDefault("server.read_timeout", 30)
 	v.SetDefault("server.write_timeout", 30)
+	v.SetDefault("server.ws_max_message_size", 4096)
+	v.SetDefault("server.ws_redirect_game_actions", false)
+	v.SetDefault("server.ws_enable_compression", false)
+	v.SetDefault("server.ws_emote_ids", []string{
+		"smile", "laugh", "cry", "angry", "clap", "thumbs_up", "hurry_up", "good_game",
+	})
+
+	// CORS默认配置（allowed_origins未配置时debug全放行、release全拒绝）
+	v.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	v.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "X-Request-ID"})
+	v.SetDefault("cors.allow_credentials", false)
 
 	// 数据库默认配置
 	v.SetDefault("database.host", "localhost")
@@ -217,6 +345,7 @@ func setDefaults(v *viper.Viper) {
 
 	// ES默认配置
 	v.SetDefault("elasticsearch.addresses", []string{"http://localhost:9200"})
+	v.SetDefault("elasticsearch.log_retention_days", 0)
 
 	// JWT默认配置
 	v.SetDefault("jwt.secret", "your-secret-key-change-in-production")
@@ -229,6 +358,31 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("log.max_backups", 7)
 	v.SetDefault("log.max_age", 30)
 
+	// 支付回调默认配置
+	v.SetDefault("payment.webhook_url", "")
+	v.SetDefault("payment.webhook_secret", "")
+	v.SetDefault("payment.webhook_max_retries", 3)
+
+	// Gas价格缓存默认配置
+	v.SetDefault("payment.gas_cache_ttl", 60)
+	v.SetDefault("payment.monitor_interval", 30)
+	v.SetDefault("payment.monitor_concurrency", 5)
+	v.SetDefault("payment.provider_rate_limit", 5.0)
+	v.SetDefault("payment.expire_sweep_interval", 300)
+
+	// 自动归集默认配置
+	v.SetDefault("payment.collect_interval", 0)
+	v.SetDefault("payment.collect_threshold", 10.0)
+	v.SetDefault("payment.collect_concurrency", 3)
+
+	// 房间默认配置
+	v.SetDefault("room.dissolve_on_owner_leave", false)
+	v.SetDefault("room.idle_timeout", 0)
+	v.SetDefault("room.max_rooms_per_user", 3)
+	v.SetDefault("room.ready_warn_timeout", 0)
+	v.SetDefault("room.ready_kick_timeout", 0)
+	v.SetDefault("room.turn_timeout", 30)
+
 	// 服务发现默认配置
 	v.SetDefault("service_discovery.enabled", false)
 	v.SetDefault("service_discovery.type", "consul")
@@ -253,4 +407,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("kafka.consumer_max_poll_records", 100)
 	v.SetDefault("kafka.fetch_min_bytes", 1024)
 	v.SetDefault("kafka.fetch_max_wait_ms", 100)
+
+	// 内部服务调用默认配置（为空则使用 localhost + 对应端口）
+	v.SetDefault("notification.channels", []string{"user_message"})
+
+	v.SetDefault("internal.game_server_base_url", "")
+	v.SetDefault("internal.api_base_url", "")
+	v.SetDefault("admin_security.max_login_failures", 5)
+	v.SetDefault("admin_security.lockout_minutes", 15)
 }
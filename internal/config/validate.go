@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate 校验配置的语义约束，聚合返回所有问题。
+// 建议在 Load 之后立即调用，让配置错误在启动时暴露而非运行期。
+func Validate(cfg *Config) error {
+	var problems []string
+
+	// Server
+	if cfg.Server.MachineID < 0 || cfg.Server.MachineID > 1023 {
+		problems = append(problems, fmt.Sprintf("server.machine_id 必须在 0-1023 之间（雪花算法限制），当前: %d", cfg.Server.MachineID))
+	}
+	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port 必须在 1-65535 之间，当前: %d", cfg.Server.Port))
+	}
+	if cfg.Server.GamePort <= 0 || cfg.Server.GamePort > 65535 {
+		problems = append(problems, fmt.Sprintf("server.game_port 必须在 1-65535 之间，当前: %d", cfg.Server.GamePort))
+	}
+	if cfg.Server.AdminPort < 0 || cfg.Server.AdminPort > 65535 {
+		problems = append(problems, fmt.Sprintf("server.admin_port 必须在 0-65535 之间（0表示使用默认端口），当前: %d", cfg.Server.AdminPort))
+	}
+	if cfg.Server.ReadTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("server.read_timeout 不能为负数，当前: %d", cfg.Server.ReadTimeout))
+	}
+	if cfg.Server.WriteTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("server.write_timeout 不能为负数，当前: %d", cfg.Server.WriteTimeout))
+	}
+
+	// Database
+	if cfg.Database.Host == "" {
+		problems = append(problems, "database.host 不能为空")
+	}
+	if cfg.Database.MaxOpenConns < 0 || cfg.Database.MaxIdleConns < 0 {
+		problems = append(problems, "database.max_open_conns/max_idle_conns 不能为负数")
+	}
+
+	// JWT
+	if cfg.JWT.Expiration <= 0 {
+		problems = append(problems, fmt.Sprintf("jwt.expiration 必须大于0（小时），当前: %d", cfg.JWT.Expiration))
+	}
+
+	// Elasticsearch（管理后台的操作日志查询依赖 ES）
+	if len(cfg.ES.Addresses) == 0 {
+		problems = append(problems, "elasticsearch.addresses 不能为空（管理后台依赖 ES）")
+	}
+	if cfg.ES.LogRetentionDays < 0 {
+		problems = append(problems, fmt.Sprintf("elasticsearch.log_retention_days 不能为负数，当前: %d", cfg.ES.LogRetentionDays))
+	}
+
+	// 管理后台安全
+	if cfg.AdminSecurity.MaxLoginFailures < 0 {
+		problems = append(problems, fmt.Sprintf("admin_security.max_login_failures 不能为负数，当前: %d", cfg.AdminSecurity.MaxLoginFailures))
+	}
+	if cfg.AdminSecurity.LockoutMinutes < 0 {
+		problems = append(problems, fmt.Sprintf("admin_security.lockout_minutes 不能为负数，当前: %d", cfg.AdminSecurity.LockoutMinutes))
+	}
+
+	// Payment：助记词为 BIP39 标准，单词数必须是 12/15/18/21/24
+	if cfg.Payment.MasterMnemonic != "" {
+		switch len(strings.Fields(cfg.Payment.MasterMnemonic)) {
+		case 12, 15, 18, 21, 24:
+		default:
+			problems = append(problems, "payment.master_mnemonic 单词数不符合 BIP39 标准（应为 12/15/18/21/24 个）")
+		}
+	}
+	if cfg.Payment.WebhookMaxRetries < 0 {
+		problems = append(problems, fmt.Sprintf("payment.webhook_max_retries 不能为负数，当前: %d", cfg.Payment.WebhookMaxRetries))
+	}
+
+	// Room
+	if cfg.Room.IdleTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("room.idle_timeout 不能为负数，当前: %d", cfg.Room.IdleTimeout))
+	}
+	if cfg.Room.MaxRoomsPerUser < 0 {
+		problems = append(problems, fmt.Sprintf("room.max_rooms_per_user 不能为负数（0表示不限制），当前: %d", cfg.Room.MaxRoomsPerUser))
+	}
+	if cfg.Room.ReadyWarnTimeout < 0 || cfg.Room.ReadyKickTimeout < 0 {
+		problems = append(problems, "room.ready_warn_timeout/ready_kick_timeout 不能为负数")
+	}
+	if cfg.Room.TurnTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("room.turn_timeout 不能为负数（0表示不发计时广播），当前: %d", cfg.Room.TurnTimeout))
+	}
+	if cfg.Room.ReadyWarnTimeout > 0 && cfg.Room.ReadyKickTimeout > 0 && cfg.Room.ReadyKickTimeout <= cfg.Room.ReadyWarnTimeout {
+		problems = append(problems, fmt.Sprintf("room.ready_kick_timeout 必须大于 ready_warn_timeout，当前: %d <= %d", cfg.Room.ReadyKickTimeout, cfg.Room.ReadyWarnTimeout))
+	}
+
+	// 服务发现
+	if cfg.ServiceDiscovery.Enabled {
+		switch cfg.ServiceDiscovery.Type {
+		case "consul":
+			if cfg.ServiceDiscovery.ConsulAddr == "" {
+				problems = append(problems, "service_discovery.consul_addr 不能为空（type 为 consul 时必填）")
+			}
+		case "redis":
+			// Redis 客户端由基础设施层提供，此处无需额外校验
+		default:
+			problems = append(problems, fmt.Sprintf("service_discovery.type 仅支持 consul/redis，当前: %q", cfg.ServiceDiscovery.Type))
+		}
+	}
+
+	// Kafka
+	if cfg.Kafka.Enabled && len(cfg.Kafka.Brokers) == 0 {
+		problems = append(problems, "kafka.brokers 不能为空（kafka.enabled 为 true 时必填）")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("配置校验失败（%d 个问题）:\n  - %s", len(problems), strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
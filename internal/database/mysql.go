@@ -1,7 +1,9 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kaifa/game-platform/internal/config"
@@ -12,6 +14,18 @@ import (
 
 var DB *gorm.DB
 
+// IsDuplicateKeyError 判断是否为唯一键冲突错误
+// 兼容gorm错误翻译（ErrDuplicatedKey）和原始MySQL错误信息（Error 1062: Duplicate entry）
+func IsDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	return strings.Contains(err.Error(), "Duplicate")
+}
+
 // InitMySQL 初始化MySQL连接
 func InitMySQL(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -1,7 +1,265 @@
 package discovery
 
-// NewRedisRegistry 创建 Redis 注册器（占位实现，后续实现）
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// redisInstanceKeyPrefix 实例键前缀：discovery:instances:<服务名>:<实例ID>
+const redisInstanceKeyPrefix = "discovery:instances"
+
+// 默认值（RegistryDeps 未配置时使用）
+const (
+	defaultInstanceTTL       = 30 * time.Second
+	defaultHeartbeatInterval = 10 * time.Second
+)
+
+// RedisRegistry 基于 Redis 的服务注册实现。
+// Register 写入带 TTL 的实例键，KeepAlive 周期性刷新 TTL，
+// 实例异常退出后键过期即自动视为下线（无需显式注销）。
+type RedisRegistry struct {
+	rdb         *redis.Client
+	serviceName string
+	instanceTTL time.Duration
+	heartbeat   time.Duration
+	loadFunc    func() int // 可选：心跳时上报当前负载（如连接数）
+
+	mu        sync.Mutex
+	instances map[string]ServiceInstance // 本进程注册的实例（用于心跳刷新）
+}
+
+// NewRedisRegistry 创建 Redis 注册器
 func NewRedisRegistry(deps RegistryDeps) (Registry, error) {
-	// TODO: 实现 Redis 服务发现
-	return nil, nil
+	rdb, ok := deps.Redis.(*redis.Client)
+	if !ok || rdb == nil {
+		return nil, fmt.Errorf("Redis 服务发现需要有效的 Redis 客户端")
+	}
+
+	instanceTTL := deps.InstanceTTL
+	if instanceTTL <= 0 {
+		instanceTTL = defaultInstanceTTL
+	}
+
+	heartbeat := deps.HeartbeatInterval
+	if heartbeat <= 0 {
+		heartbeat = instanceTTL / 3
+		if heartbeat <= 0 {
+			heartbeat = defaultHeartbeatInterval
+		}
+	}
+
+	return &RedisRegistry{
+		rdb:         rdb,
+		serviceName: deps.ServiceName,
+		instanceTTL: instanceTTL,
+		heartbeat:   heartbeat,
+		loadFunc:    deps.LoadFunc,
+		instances:   make(map[string]ServiceInstance),
+	}, nil
+}
+
+// instanceKey 构建实例键
+func instanceKey(serviceName, instanceID string) string {
+	return fmt.Sprintf("%s:%s:%s", redisInstanceKeyPrefix, serviceName, instanceID)
+}
+
+// Register 注册服务实例（写入带 TTL 的键）
+func (r *RedisRegistry) Register(ctx context.Context, instance ServiceInstance) error {
+	now := time.Now().Unix()
+	instance.RegisteredAt = now
+	instance.LastHeartbeat = now
+	r.reportLoad(&instance)
+
+	if err := r.writeInstance(ctx, instance); err != nil {
+		return fmt.Errorf("注册服务失败: %w", err)
+	}
+
+	r.mu.Lock()
+	r.instances[instance.InstanceID] = instance
+	r.mu.Unlock()
+
+	logger.Logger.Info("服务已注册到 Redis",
+		zap.String("service", instance.ServiceName),
+		zap.String("instance_id", instance.InstanceID),
+		zap.String("address", instance.Address),
+		zap.Int("port", instance.Port),
+		zap.Duration("ttl", r.instanceTTL),
+	)
+
+	return nil
+}
+
+// Deregister 注销服务实例（删除实例键）
+func (r *RedisRegistry) Deregister(ctx context.Context, instanceID string) error {
+	r.mu.Lock()
+	instance, exists := r.instances[instanceID]
+	delete(r.instances, instanceID)
+	r.mu.Unlock()
+
+	serviceName := r.serviceName
+	if exists {
+		serviceName = instance.ServiceName
+	}
+
+	if err := r.rdb.Del(ctx, instanceKey(serviceName, instanceID)).Err(); err != nil {
+		return fmt.Errorf("注销服务失败: %w", err)
+	}
+
+	logger.Logger.Info("服务已从 Redis 注销",
+		zap.String("service", serviceName),
+		zap.String("instance_id", instanceID),
+	)
+
+	return nil
+}
+
+// KeepAlive 启动心跳保活：周期性刷新实例键的 TTL 与心跳时间
+func (r *RedisRegistry) KeepAlive(ctx context.Context, instanceID string) (stop func(), err error) {
+	r.mu.Lock()
+	_, exists := r.instances[instanceID]
+	r.mu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("实例未注册，无法启动心跳: %s", instanceID)
+	}
+
+	done := make(chan struct{})
+	var stopOnce sync.Once
+
+	go func() {
+		ticker := time.NewTicker(r.heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.mu.Lock()
+				instance, ok := r.instances[instanceID]
+				if ok {
+					instance.LastHeartbeat = time.Now().Unix()
+					r.reportLoad(&instance)
+					r.instances[instanceID] = instance
+				}
+				r.mu.Unlock()
+
+				if !ok {
+					return
+				}
+
+				hbCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+				if err := r.writeInstance(hbCtx, instance); err != nil {
+					logger.Logger.Warn("刷新服务心跳失败",
+						zap.String("instance_id", instanceID),
+						zap.Error(err),
+					)
+				}
+				cancel()
+
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		stopOnce.Do(func() { close(done) })
+	}, nil
+}
+
+// ListInstances 列出指定服务的所有存活实例（键未过期即视为存活）
+func (r *RedisRegistry) ListInstances(ctx context.Context, serviceName string) ([]ServiceInstance, error) {
+	pattern := fmt.Sprintf("%s:%s:*", redisInstanceKeyPrefix, serviceName)
+
+	var instances []ServiceInstance
+	var cursor uint64
+
+	for {
+		keys, nextCursor, err := r.rdb.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("查询服务实例失败: %w", err)
+		}
+
+		for _, key := range keys {
+			data, err := r.rdb.Get(ctx, key).Result()
+			if err != nil {
+				// 键可能在 SCAN 和 GET 之间过期，跳过
+				continue
+			}
+
+			var instance ServiceInstance
+			if err := json.Unmarshal([]byte(data), &instance); err != nil {
+				logger.Logger.Warn("解析服务实例数据失败",
+					zap.String("key", key),
+					zap.Error(err),
+				)
+				continue
+			}
+			instances = append(instances, instance)
+		}
+
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return instances, nil
+}
+
+// GetInstance 获取指定实例
+func (r *RedisRegistry) GetInstance(ctx context.Context, instanceID string) (ServiceInstance, error) {
+	data, err := r.rdb.Get(ctx, instanceKey(r.serviceName, instanceID)).Result()
+	if err == redis.Nil {
+		return ServiceInstance{}, fmt.Errorf("实例不存在: %s", instanceID)
+	}
+	if err != nil {
+		return ServiceInstance{}, fmt.Errorf("查询实例失败: %w", err)
+	}
+
+	var instance ServiceInstance
+	if err := json.Unmarshal([]byte(data), &instance); err != nil {
+		return ServiceInstance{}, fmt.Errorf("解析实例数据失败: %w", err)
+	}
+
+	return instance, nil
+}
+
+// IsInstanceAlive 检查实例是否存活（键存在即存活）
+func (r *RedisRegistry) IsInstanceAlive(ctx context.Context, instanceID string) (bool, error) {
+	count, err := r.rdb.Exists(ctx, instanceKey(r.serviceName, instanceID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// writeInstance 序列化实例并写入带 TTL 的键
+func (r *RedisRegistry) writeInstance(ctx context.Context, instance ServiceInstance) error {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return fmt.Errorf("序列化实例数据失败: %w", err)
+	}
+
+	key := instanceKey(instance.ServiceName, instance.InstanceID)
+	return r.rdb.Set(ctx, key, data, r.instanceTTL).Err()
+}
+
+// reportLoad 将当前负载写入实例元数据（loadFunc 未设置时不处理）
+func (r *RedisRegistry) reportLoad(instance *ServiceInstance) {
+	if r.loadFunc == nil {
+		return
+	}
+	if instance.Meta == nil {
+		instance.Meta = make(map[string]string)
+	}
+	instance.Meta["connections"] = strconv.Itoa(r.loadFunc())
 }
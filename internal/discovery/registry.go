@@ -54,6 +54,7 @@ type RegistryDeps struct {
 	// Redis 相关
 	InstanceTTL       time.Duration
 	HeartbeatInterval time.Duration
+	LoadFunc          func() int // 可选：心跳时上报当前负载（如连接数），用于客户端路由
 }
 
 // NewRegistry 创建注册器（工厂方法）
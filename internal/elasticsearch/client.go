@@ -151,6 +151,24 @@ func GetClient() *elasticsearch.Client {
 	return client
 }
 
+// Ping 检查 Elasticsearch 连通性（用于就绪检查）
+func Ping(ctx context.Context) error {
+	if client == nil {
+		return fmt.Errorf("Elasticsearch 客户端未初始化")
+	}
+
+	res, err := client.Ping(client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("Elasticsearch 响应异常: %s", res.Status())
+	}
+	return nil
+}
+
 // IndexOperationLog 索引操作日志到 Elasticsearch
 func IndexOperationLog(logData map[string]interface{}) error {
 	if client == nil {
@@ -327,6 +345,159 @@ func SearchOperationLogs(query map[string]interface{}, from, size int) ([]map[st
 	return logs, total, nil
 }
 
+// AggBucket 聚合桶结果
+type AggBucket struct {
+	Key        string  `json:"key"`
+	Count      int64   `json:"count"`
+	ErrorCount int64   `json:"error_count"`
+	ErrorRate  float64 `json:"error_rate"`
+}
+
+// AggregateOperationLogs 对操作日志执行聚合统计。
+// aggType 支持 by_admin（按管理员）、by_module（按模块）、daily（按天时间序列），
+// 每个桶内附带失败次数（status=2）用于计算错误率。
+// query 使用 BuildQuery 的结果，与列表查询共享过滤条件。
+func AggregateOperationLogs(query map[string]interface{}, aggType string) ([]AggBucket, error) {
+	if client == nil {
+		return nil, fmt.Errorf("Elasticsearch 未初始化")
+	}
+
+	// 错误子聚合（status=2 表示失败）
+	errorsAgg := map[string]interface{}{
+		"filter": map[string]interface{}{
+			"term": map[string]interface{}{
+				"status": 2,
+			},
+		},
+	}
+
+	var bucketsAgg map[string]interface{}
+	switch aggType {
+	case "by_admin":
+		bucketsAgg = map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "admin_name.keyword",
+				"size":  50,
+			},
+			"aggs": map[string]interface{}{"errors": errorsAgg},
+		}
+	case "by_module":
+		bucketsAgg = map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "module.keyword",
+				"size":  50,
+			},
+			"aggs": map[string]interface{}{"errors": errorsAgg},
+		}
+	case "daily":
+		bucketsAgg = map[string]interface{}{
+			"date_histogram": map[string]interface{}{
+				"field":             "@timestamp",
+				"calendar_interval": "day",
+				"format":            "yyyy-MM-dd",
+			},
+			"aggs": map[string]interface{}{"errors": errorsAgg},
+		}
+	default:
+		return nil, fmt.Errorf("不支持的聚合类型: %s（支持 by_admin/by_module/daily）", aggType)
+	}
+
+	searchQuery := map[string]interface{}{
+		"size":  0,
+		"query": query,
+		"aggs": map[string]interface{}{
+			"buckets": bucketsAgg,
+		},
+	}
+
+	queryBody, err := json.Marshal(searchQuery)
+	if err != nil {
+		return nil, fmt.Errorf("序列化聚合查询失败: %w", err)
+	}
+
+	res, err := client.Search(
+		client.Search.WithIndex("admin-operation-logs-*"),
+		client.Search.WithBody(strings.NewReader(string(queryBody))),
+		client.Search.WithContext(context.Background()),
+		client.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		logger.Logger.Error("Elasticsearch 聚合请求失败", zap.Error(err), zap.String("agg_type", aggType))
+		return nil, fmt.Errorf("聚合查询失败: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		var errorResp map[string]interface{}
+		if err := json.NewDecoder(res.Body).Decode(&errorResp); err == nil {
+			logger.Logger.Error("Elasticsearch 聚合错误", zap.String("status", res.Status()), zap.Any("error", errorResp))
+			return nil, fmt.Errorf("Elasticsearch 聚合错误: %s, 详情: %v", res.Status(), errorResp)
+		}
+		return nil, fmt.Errorf("Elasticsearch 聚合错误: %s", res.Status())
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析聚合响应失败: %w", err)
+	}
+
+	return parseAggBuckets(result), nil
+}
+
+// parseAggBuckets 从聚合响应中提取桶列表
+func parseAggBuckets(result map[string]interface{}) []AggBucket {
+	buckets := []AggBucket{}
+
+	aggs, ok := result["aggregations"].(map[string]interface{})
+	if !ok {
+		return buckets
+	}
+	bucketsAgg, ok := aggs["buckets"].(map[string]interface{})
+	if !ok {
+		return buckets
+	}
+	bucketList, ok := bucketsAgg["buckets"].([]interface{})
+	if !ok {
+		return buckets
+	}
+
+	for _, b := range bucketList {
+		bucketMap, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		bucket := AggBucket{}
+
+		// date_histogram 的桶用 key_as_string，terms 的桶直接用 key
+		if keyStr, ok := bucketMap["key_as_string"].(string); ok {
+			bucket.Key = keyStr
+		} else if keyStr, ok := bucketMap["key"].(string); ok {
+			bucket.Key = keyStr
+		} else if keyNum, ok := bucketMap["key"].(float64); ok {
+			bucket.Key = fmt.Sprintf("%v", keyNum)
+		}
+
+		if count, ok := bucketMap["doc_count"].(float64); ok {
+			bucket.Count = int64(count)
+		}
+
+		if errors, ok := bucketMap["errors"].(map[string]interface{}); ok {
+			if errorCount, ok := errors["doc_count"].(float64); ok {
+				bucket.ErrorCount = int64(errorCount)
+			}
+		}
+
+		if bucket.Count > 0 {
+			bucket.ErrorRate = float64(bucket.ErrorCount) / float64(bucket.Count)
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
+
 // BuildQuery 构建查询条件
 func BuildQuery(filters map[string]interface{}) map[string]interface{} {
 	mustQueries := []map[string]interface{}{}
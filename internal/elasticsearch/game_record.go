@@ -0,0 +1,295 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/kaifa/game-platform/internal/logger"
+	"go.uber.org/zap"
+)
+
+// gameRecordIndexPrefix 游戏记录索引前缀（按天分索引）
+const gameRecordIndexPrefix = "game-records"
+
+// IndexGameRecord 索引游戏对局记录到 Elasticsearch（分析用）。
+// docID 使用游戏记录的数据库 ID，重复写入会覆盖同一文档（幂等）。
+// 客户端未初始化时静默跳过，调用方无需关心 ES 是否可用。
+func IndexGameRecord(docID string, doc map[string]interface{}) error {
+	if client == nil {
+		return nil // 如果未初始化，静默失败
+	}
+
+	indexName := fmt.Sprintf("%s-%s", gameRecordIndexPrefix, time.Now().Format("2006.01.02"))
+
+	if doc["@timestamp"] == nil {
+		doc["@timestamp"] = time.Now().Format(time.RFC3339)
+	}
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("序列化游戏记录失败: %w", err)
+	}
+
+	req := esapi.IndexRequest{
+		Index:      indexName,
+		DocumentID: docID, // 用记录ID作为文档ID，保证幂等
+		Body:       strings.NewReader(string(body)),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		return fmt.Errorf("索引游戏记录失败: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("Elasticsearch 错误: %s", res.Status())
+	}
+
+	logger.Logger.Debug("游戏记录已写入 Elasticsearch",
+		zap.String("index", indexName),
+		zap.String("doc_id", docID))
+	return nil
+}
+
+// SearchGameRecords 搜索游戏记录
+func SearchGameRecords(query map[string]interface{}, from, size int) ([]map[string]interface{}, int64, error) {
+	if client == nil {
+		return nil, 0, fmt.Errorf("Elasticsearch 未初始化")
+	}
+
+	searchQuery := map[string]interface{}{
+		"from": from,
+		"size": size,
+		"sort": []map[string]interface{}{
+			{
+				"@timestamp": map[string]interface{}{
+					"order": "desc",
+				},
+			},
+		},
+		"query": query,
+	}
+
+	queryBody, err := json.Marshal(searchQuery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("序列化查询失败: %w", err)
+	}
+
+	res, err := client.Search(
+		client.Search.WithIndex(gameRecordIndexPrefix+"-*"),
+		client.Search.WithBody(strings.NewReader(string(queryBody))),
+		client.Search.WithContext(context.Background()),
+		client.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("搜索游戏记录失败: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, 0, fmt.Errorf("Elasticsearch 搜索错误: %s", res.Status())
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	hits, ok := result["hits"].(map[string]interface{})
+	if !ok {
+		return []map[string]interface{}{}, 0, nil
+	}
+
+	var total int64
+	if totalObj, ok := hits["total"].(map[string]interface{}); ok {
+		if value, ok := totalObj["value"].(float64); ok {
+			total = int64(value)
+		}
+	}
+
+	hitsArray, ok := hits["hits"].([]interface{})
+	if !ok {
+		return []map[string]interface{}{}, total, nil
+	}
+
+	records := make([]map[string]interface{}, 0, len(hitsArray))
+	for _, hit := range hitsArray {
+		hitMap, ok := hit.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		source, ok := hitMap["_source"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		records = append(records, source)
+	}
+
+	return records, total, nil
+}
+
+// AggregateGameRecords 对游戏记录执行聚合统计。
+// aggType 支持 by_game_type（按游戏类型）、daily（按天时间序列），
+// 每个桶内附带平均时长和抽水合计。
+func AggregateGameRecords(query map[string]interface{}, aggType string) ([]map[string]interface{}, error) {
+	if client == nil {
+		return nil, fmt.Errorf("Elasticsearch 未初始化")
+	}
+
+	subAggs := map[string]interface{}{
+		"avg_duration": map[string]interface{}{
+			"avg": map[string]interface{}{"field": "duration"},
+		},
+		"total_rake": map[string]interface{}{
+			"sum": map[string]interface{}{"field": "rake"},
+		},
+	}
+
+	var bucketsAgg map[string]interface{}
+	switch aggType {
+	case "by_game_type":
+		bucketsAgg = map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "game_type.keyword",
+				"size":  20,
+			},
+			"aggs": subAggs,
+		}
+	case "daily":
+		bucketsAgg = map[string]interface{}{
+			"date_histogram": map[string]interface{}{
+				"field":             "@timestamp",
+				"calendar_interval": "day",
+				"format":            "yyyy-MM-dd",
+			},
+			"aggs": subAggs,
+		}
+	default:
+		return nil, fmt.Errorf("不支持的聚合类型: %s（支持 by_game_type/daily）", aggType)
+	}
+
+	searchQuery := map[string]interface{}{
+		"size":  0,
+		"query": query,
+		"aggs": map[string]interface{}{
+			"buckets": bucketsAgg,
+		},
+	}
+
+	queryBody, err := json.Marshal(searchQuery)
+	if err != nil {
+		return nil, fmt.Errorf("序列化聚合查询失败: %w", err)
+	}
+
+	res, err := client.Search(
+		client.Search.WithIndex(gameRecordIndexPrefix+"-*"),
+		client.Search.WithBody(strings.NewReader(string(queryBody))),
+		client.Search.WithContext(context.Background()),
+		client.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("聚合查询失败: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("Elasticsearch 聚合错误: %s", res.Status())
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析聚合响应失败: %w", err)
+	}
+
+	buckets := []map[string]interface{}{}
+	aggsResp, ok := result["aggregations"].(map[string]interface{})
+	if !ok {
+		return buckets, nil
+	}
+	bucketsResp, ok := aggsResp["buckets"].(map[string]interface{})
+	if !ok {
+		return buckets, nil
+	}
+	bucketList, ok := bucketsResp["buckets"].([]interface{})
+	if !ok {
+		return buckets, nil
+	}
+
+	for _, b := range bucketList {
+		bucketMap, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		bucket := map[string]interface{}{
+			"key":   bucketMap["key"],
+			"count": bucketMap["doc_count"],
+		}
+		if keyStr, ok := bucketMap["key_as_string"].(string); ok {
+			bucket["key"] = keyStr
+		}
+		if avg, ok := bucketMap["avg_duration"].(map[string]interface{}); ok {
+			bucket["avg_duration"] = avg["value"]
+		}
+		if rake, ok := bucketMap["total_rake"].(map[string]interface{}); ok {
+			bucket["total_rake"] = rake["value"]
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, nil
+}
+
+// BuildGameRecordQuery 构建游戏记录查询条件
+func BuildGameRecordQuery(filters map[string]interface{}) map[string]interface{} {
+	mustQueries := []map[string]interface{}{}
+
+	if roomID, ok := filters["room_id"]; ok && roomID != nil && roomID != "" {
+		mustQueries = append(mustQueries, map[string]interface{}{
+			"term": map[string]interface{}{
+				"room_id.keyword": roomID,
+			},
+		})
+	}
+
+	if gameType, ok := filters["game_type"]; ok && gameType != nil && gameType != "" {
+		mustQueries = append(mustQueries, map[string]interface{}{
+			"term": map[string]interface{}{
+				"game_type.keyword": gameType,
+			},
+		})
+	}
+
+	if startTime, ok := filters["start_time"]; ok && startTime != nil {
+		timestampRange := map[string]interface{}{"gte": startTime}
+		if endTime, ok := filters["end_time"]; ok && endTime != nil {
+			timestampRange["lte"] = endTime
+		}
+		mustQueries = append(mustQueries, map[string]interface{}{
+			"range": map[string]interface{}{
+				"@timestamp": timestampRange,
+			},
+		})
+	}
+
+	if len(mustQueries) == 0 {
+		return map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		}
+	}
+
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": mustQueries,
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/kaifa/game-platform/internal/logger"
+	"go.uber.org/zap"
+)
+
+// operationLogIndexPrefix 操作日志索引前缀（与 IndexOperationLog 保持一致）
+const operationLogIndexPrefix = "admin-operation-logs"
+
+// CleanupOldLogIndices 删除超过保留期的操作日志索引。
+// 索引按 admin-operation-logs-YYYY.MM.DD 命名，根据名称中的日期判断是否过期；
+// dryRun 为 true 时只返回待删除的索引列表，不实际删除。
+// 返回（已删除或待删除的）索引名列表。
+func CleanupOldLogIndices(retentionDays int, dryRun bool) ([]string, error) {
+	if client == nil {
+		return nil, fmt.Errorf("Elasticsearch 未初始化")
+	}
+	if retentionDays < 1 {
+		return nil, fmt.Errorf("保留天数必须大于0，当前: %d", retentionDays)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// 列出所有操作日志索引
+	req := esapi.IndicesGetRequest{
+		Index: []string{operationLogIndexPrefix + "-*"},
+	}
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("列出索引失败: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("Elasticsearch 错误: %s", res.Status())
+	}
+
+	var indices map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, fmt.Errorf("解析索引列表失败: %w", err)
+	}
+
+	// 根据索引名中的日期筛选过期索引
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	expired := []string{}
+	for name := range indices {
+		dateStr := strings.TrimPrefix(name, operationLogIndexPrefix+"-")
+		indexDate, err := time.Parse("2006.01.02", dateStr)
+		if err != nil {
+			// 名称不符合日期格式的索引不动
+			continue
+		}
+		if indexDate.Before(cutoff) {
+			expired = append(expired, name)
+		}
+	}
+	sort.Strings(expired)
+
+	if len(expired) == 0 || dryRun {
+		return expired, nil
+	}
+
+	// 实际删除
+	delRes, err := client.Indices.Delete(expired, client.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("删除索引失败: %w", err)
+	}
+	defer delRes.Body.Close()
+
+	if delRes.IsError() {
+		return nil, fmt.Errorf("删除索引错误: %s", delRes.Status())
+	}
+
+	logger.Logger.Info("已清理过期操作日志索引",
+		zap.Int("retention_days", retentionDays),
+		zap.Strings("indices", expired))
+	return expired, nil
+}
+
+// StartLogIndexRetention 启动定时清理任务，每天执行一次。
+// retentionDays 小于1时不启动（视为关闭清理）。
+func StartLogIndexRetention(retentionDays int) {
+	if retentionDays < 1 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		// 启动时先执行一次
+		if _, err := CleanupOldLogIndices(retentionDays, false); err != nil {
+			logger.Logger.Warn("清理过期日志索引失败", zap.Error(err))
+		}
+
+		for range ticker.C {
+			if _, err := CleanupOldLogIndices(retentionDays, false); err != nil {
+				logger.Logger.Warn("清理过期日志索引失败", zap.Error(err))
+			}
+		}
+	}()
+}
@@ -0,0 +1,64 @@
+package idgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kaifa/game-platform/pkg/utils"
+)
+
+// MaxCreateAttempts 插入时唯一键冲突（ID碰撞）换新ID重试的最大尝试次数
+const MaxCreateAttempts = 3
+
+// useSnowflake 是否启用雪花算法生成业务ID
+// false时保持旧的UUID截断方案（随机、不可排序）
+var useSnowflake bool
+
+// Init 按配置初始化ID生成器
+// machineID: 机器ID（0-1023），多实例部署时每个实例应不同；
+// snowflake: false时回退为UUID截断方案（兼容旧部署）
+func Init(machineID int, snowflake bool) error {
+	useSnowflake = snowflake
+	if !snowflake {
+		return nil
+	}
+	return utils.InitSnowflake(int64(machineID))
+}
+
+// next 生成一个雪花ID
+// 未初始化或时钟回退时返回false，调用方回退为UUID方案
+func next() (int64, bool) {
+	gen := utils.GetSnowflakeGenerator()
+	if gen == nil {
+		return 0, false
+	}
+	id, err := gen.GenerateID()
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// OrderID 生成订单号：前缀 + 19位十进制雪花ID
+// 时间有序、固定宽度、无截断碰撞风险；
+// 未启用雪花时回退为 前缀+15位UUID截断（旧格式）
+func OrderID(prefix string) string {
+	if useSnowflake {
+		if id, ok := next(); ok {
+			return fmt.Sprintf("%s%019d", prefix, id)
+		}
+	}
+	return fmt.Sprintf("%s%s", prefix, strings.ToUpper(uuid.New().String()[:15]))
+}
+
+// RoomID 生成房间号：R + 19位十进制雪花ID
+// 未启用雪花时回退为 R+8位UUID截断（旧格式）
+func RoomID() string {
+	if useSnowflake {
+		if id, ok := next(); ok {
+			return fmt.Sprintf("R%019d", id)
+		}
+	}
+	return fmt.Sprintf("R%s", uuid.New().String()[:8])
+}
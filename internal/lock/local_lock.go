@@ -7,6 +7,10 @@ import (
 // LocalRWLock 本地读写锁实现
 // 适用于单实例部署，基于 sync.RWMutex 实现
 // 性能优于分布式锁，但不支持多实例
+//
+// ⚠️ 不可重入：同一goroutine在持有某key的写锁期间再次对该key加锁会自我死锁
+// （例如锁内的业务函数调用了另一个也会对同一roomID加锁的方法）。
+// 存在嵌套加锁可能的调用链请使用 ReentrantRWLock。
 type LocalRWLock struct {
 	locks sync.Map // key: string, value: *sync.RWMutex
 }
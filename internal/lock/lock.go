@@ -2,9 +2,58 @@ package lock
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrTimeout 锁竞争超时（重试耗尽仍未抢到锁）
+// 与Redis故障等系统错误区分，调用方可据此返回不同的提示
+var ErrTimeout = errors.New("获取锁超时")
+
+// IsTimeout 判断错误是否为锁竞争超时（而非Redis故障等系统错误）
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout)
+}
+
+// FriendlyError 将锁获取失败转换为面向用户的错误提示
+// 竞争超时返回"操作繁忙"，其他（如Redis故障）返回"系统错误"
+func FriendlyError(err error) error {
+	if IsTimeout(err) {
+		return errors.New("操作繁忙，请重试")
+	}
+	return errors.New("系统错误，请稍后重试")
+}
+
+// 默认锁TTL（集中维护，避免调用方散落魔法数字）
+var (
+	defaultTTL = 5 * time.Second  // 短操作（出牌等单次状态变更）
+	longTTL    = 10 * time.Second // 长操作（开始游戏、快速匹配等含多次DB往返）
+)
+
+// DefaultTTL 返回短操作的默认锁TTL
+func DefaultTTL() time.Duration {
+	return defaultTTL
+}
+
+// LongTTL 返回长操作的默认锁TTL
+func LongTTL() time.Duration {
+	return longTTL
+}
+
+// SetDefaultTTL 设置短操作的默认锁TTL（启动时调用，非并发安全）
+func SetDefaultTTL(ttl time.Duration) {
+	if ttl > 0 {
+		defaultTTL = ttl
+	}
+}
+
+// SetLongTTL 设置长操作的默认锁TTL（启动时调用，非并发安全）
+func SetLongTTL(ttl time.Duration) {
+	if ttl > 0 {
+		longTTL = ttl
+	}
+}
+
 // Lock 定义分布式锁接口
 // 支持 Redis 分布式锁、本地内存锁等多种实现
 type Lock interface {
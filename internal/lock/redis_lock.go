@@ -73,7 +73,7 @@ func (l *RedisLock) Lock(ctx context.Context, key string, ttl time.Duration, max
 		}
 	}
 
-	return errors.New("获取锁超时，请稍后重试")
+	return ErrTimeout
 }
 
 // Unlock 释放锁
@@ -111,6 +111,10 @@ func (l *RedisLock) WithLock(ctx context.Context, key string, ttl time.Duration,
 	metrics.GetGlobalMetrics().RecordLockAcquire(key, success, waitTime)
 
 	if err != nil {
+		// ✅ 区分竞争超时与系统错误（分别计数，调用方可用 IsTimeout 判断）
+		if errors.Is(err, ErrTimeout) {
+			metrics.GetGlobalMetrics().RecordLockTimeout(key)
+		}
 		return fmt.Errorf("获取锁失败: %w", err)
 	}
 
@@ -0,0 +1,116 @@
+package lock
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ReentrantRWLock 可重入的本地读写锁实现
+// 与 LocalRWLock 的区别：同一goroutine对同一key重复加写锁不会自我死锁，
+// 而是按计数重入；持有写锁期间对同一key加读锁也视为重入（空操作）。
+// 用于存在嵌套加锁可能的调用链（如锁内的业务函数调用了另一个同key加锁的方法）。
+type ReentrantRWLock struct {
+	locks  sync.Map // key: string, value: *sync.RWMutex
+	owners sync.Map // key: string, value: *lockOwner（仅写锁持有期间存在）
+}
+
+// lockOwner 写锁持有者信息
+// gid 在 Store 前写入后只读；count 仅由持有者goroutine读写
+type lockOwner struct {
+	gid   int64 // 持有者goroutine ID
+	count int   // 重入计数
+}
+
+// NewReentrantRWLock 创建可重入本地读写锁实例
+func NewReentrantRWLock() RWLock {
+	return &ReentrantRWLock{}
+}
+
+// getLock 获取或创建指定 key 的锁
+func (l *ReentrantRWLock) getLock(key string) *sync.RWMutex {
+	lock, _ := l.locks.LoadOrStore(key, &sync.RWMutex{})
+	return lock.(*sync.RWMutex)
+}
+
+// holdsWriteLock 判断当前goroutine是否持有key的写锁
+func (l *ReentrantRWLock) holdsWriteLock(key string, gid int64) (*lockOwner, bool) {
+	if v, ok := l.owners.Load(key); ok {
+		owner := v.(*lockOwner)
+		if owner.gid == gid {
+			return owner, true
+		}
+	}
+	return nil, false
+}
+
+// RLock 获取读锁（当前goroutine已持有同key写锁时为空操作）
+func (l *ReentrantRWLock) RLock(key string) {
+	if _, held := l.holdsWriteLock(key, goroutineID()); held {
+		return
+	}
+	l.getLock(key).RLock()
+}
+
+// RUnlock 释放读锁（与 RLock 对称，写锁重入时为空操作）
+func (l *ReentrantRWLock) RUnlock(key string) {
+	if _, held := l.holdsWriteLock(key, goroutineID()); held {
+		return
+	}
+	l.getLock(key).RUnlock()
+}
+
+// Lock 获取写锁（同一goroutine重复加锁按计数重入）
+func (l *ReentrantRWLock) Lock(key string) {
+	gid := goroutineID()
+	if owner, held := l.holdsWriteLock(key, gid); held {
+		owner.count++
+		return
+	}
+
+	l.getLock(key).Lock()
+	l.owners.Store(key, &lockOwner{gid: gid, count: 1})
+}
+
+// Unlock 释放写锁（重入计数归零时才真正释放）
+func (l *ReentrantRWLock) Unlock(key string) {
+	owner, held := l.holdsWriteLock(key, goroutineID())
+	if !held {
+		panic("lock: 当前goroutine未持有该key的写锁: " + key)
+	}
+
+	owner.count--
+	if owner.count == 0 {
+		l.owners.Delete(key)
+		l.getLock(key).Unlock()
+	}
+}
+
+// WithRLock 在读锁保护下执行函数
+func (l *ReentrantRWLock) WithRLock(key string, fn func() error) error {
+	l.RLock(key)
+	defer l.RUnlock(key)
+	return fn()
+}
+
+// WithLock 在写锁保护下执行函数
+func (l *ReentrantRWLock) WithLock(key string, fn func() error) error {
+	l.Lock(key)
+	defer l.Unlock(key)
+	return fn()
+}
+
+// goroutineID 获取当前goroutine ID
+// 解析 runtime.Stack 首行 "goroutine N [running]:"，仅用于重入判断
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	stack := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if idx := bytes.IndexByte(stack, ' '); idx > 0 {
+		if id, err := strconv.ParseInt(string(stack[:idx]), 10, 64); err == nil {
+			return id
+		}
+	}
+	return -1
+}
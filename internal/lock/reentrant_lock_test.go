@@ -0,0 +1,162 @@
+package lock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// deadlockTimeout 死锁检测超时：正常路径远小于该值，超时即判定为自我死锁
+const deadlockTimeout = 500 * time.Millisecond
+
+// runWithDeadlockDetector 在独立goroutine中执行fn，超时未返回则判定死锁并使测试失败
+func runWithDeadlockDetector(t *testing.T, name string, fn func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(deadlockTimeout):
+		t.Fatalf("%s: 锁内重复获取同一key导致死锁", name)
+	}
+}
+
+// TestReentrantRWLockReentry 同一goroutine嵌套加写锁不应死锁
+func TestReentrantRWLockReentry(t *testing.T) {
+	l := NewReentrantRWLock()
+
+	runWithDeadlockDetector(t, "嵌套写锁", func() {
+		_ = l.WithLock("room-1", func() error {
+			// 模拟锁内业务函数再次对同一key加锁（如StartGame调用JoinRoom逻辑）
+			return l.WithLock("room-1", func() error {
+				return nil
+			})
+		})
+	})
+}
+
+// TestReentrantRWLockRLockUnderWriteLock 持有写锁期间加读锁不应死锁
+func TestReentrantRWLockRLockUnderWriteLock(t *testing.T) {
+	l := NewReentrantRWLock()
+
+	runWithDeadlockDetector(t, "写锁内加读锁", func() {
+		_ = l.WithLock("room-1", func() error {
+			return l.WithRLock("room-1", func() error {
+				return nil
+			})
+		})
+	})
+}
+
+// TestReentrantRWLockMutualExclusion 不同goroutine之间仍需互斥
+func TestReentrantRWLockMutualExclusion(t *testing.T) {
+	l := NewReentrantRWLock()
+
+	const goroutines = 10
+	const increments = 100
+	counter := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				_ = l.WithLock("counter", func() error {
+					counter++
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter != goroutines*increments {
+		t.Fatalf("互斥失效: counter = %d, 期望 %d", counter, goroutines*increments)
+	}
+}
+
+// TestReentrantRWLockUnlockWithoutLock 未持锁时释放应panic（使用错误可及早暴露）
+func TestReentrantRWLockUnlockWithoutLock(t *testing.T) {
+	l := NewReentrantRWLock()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("未持锁时Unlock应panic")
+		}
+	}()
+	l.Unlock("room-1")
+}
+
+// TestLocalRWLockNestedLockBlocks 验证LocalRWLock不可重入：
+// 嵌套加同一key的写锁会阻塞，直到外层释放
+func TestLocalRWLockNestedLockBlocks(t *testing.T) {
+	l := NewLocalRWLock()
+
+	l.Lock("room-1")
+
+	entered := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		close(entered)
+		l.Lock("room-1") // 阻塞直到外层释放
+		l.Unlock("room-1")
+		close(done)
+	}()
+
+	<-entered
+	select {
+	case <-done:
+		t.Fatal("LocalRWLock嵌套加锁不应直接成功")
+	case <-time.After(50 * time.Millisecond):
+		// 符合预期：被阻塞
+	}
+
+	l.Unlock("room-1")
+	select {
+	case <-done:
+	case <-time.After(deadlockTimeout):
+		t.Fatal("外层释放后内层加锁仍未完成")
+	}
+}
+
+// BenchmarkLocalRWLockUncontended 无竞争路径：LocalRWLock
+func BenchmarkLocalRWLockUncontended(b *testing.B) {
+	l := NewLocalRWLock()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = l.WithLock("room-1", func() error {
+			return nil
+		})
+	}
+}
+
+// BenchmarkReentrantRWLockUncontended 无竞争路径：ReentrantRWLock
+func BenchmarkReentrantRWLockUncontended(b *testing.B) {
+	l := NewReentrantRWLock()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = l.WithLock("room-1", func() error {
+			return nil
+		})
+	}
+}
+
+// BenchmarkReentrantRWLockReentry 重入路径：外层已持锁时的内层加锁
+func BenchmarkReentrantRWLockReentry(b *testing.B) {
+	l := NewReentrantRWLock()
+	l.Lock("room-1")
+	defer l.Unlock("room-1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = l.WithLock("room-1", func() error {
+			return nil
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/kaifa/game-platform/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Mailer 邮件发送接口。
+// 部署方可接入SMTP或第三方邮件服务商，默认实现只记日志不真正发信。
+type Mailer interface {
+	// Send 发送邮件
+	// to: 收件地址; subject: 主题; body: 正文（纯文本）
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// LogMailer 默认实现：把邮件内容写入日志，不对外发送。
+// 用于未配置邮件服务的环境（开发/测试），避免发信失败阻断业务流程。
+type LogMailer struct{}
+
+// NewLogMailer 创建日志邮件器
+func NewLogMailer() Mailer {
+	return &LogMailer{}
+}
+
+// Send 记录邮件内容到日志
+func (m *LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	if logger.Logger != nil {
+		logger.Logger.Info("邮件发送（日志模式，未真正发出）",
+			zap.String("to", to),
+			zap.String("subject", subject),
+			zap.String("body", body),
+		)
+	}
+	return nil
+}
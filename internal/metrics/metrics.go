@@ -13,25 +13,61 @@ type Metrics struct {
 	lockAcquireCount   int64 // 锁获取总次数
 	lockAcquireSuccess int64 // 锁获取成功次数
 	lockAcquireFailed  int64 // 锁获取失败次数
+	lockTimeoutCount   int64 // 锁竞争超时次数（failed中的超时部分，其余为系统错误）
 	lockWaitTimeTotal  int64 // 锁等待总时间（纳秒）
 	lockHoldTimeTotal  int64 // 锁持有总时间（纳秒）
 
+	// 锁持有时长直方图（桶上界见 lockHoldBucketBounds，最后一桶为+Inf）
+	lockHoldHistogram [len(lockHoldBucketBounds) + 1]int64
+
 	// 按key统计的锁信息
 	lockStatsByKey sync.Map // key: lockKey, value: *LockStats
 
+	// 按规则统计的限流信息
+	rateLimitStats sync.Map // key: ruleName, value: *RateLimitStats
+
 	// Worker Pool 指标（已在 worker.Pool 中实现）
 
+	// 交易监控指标
+	txMonitorRunCount   int64        // 监控执行次数
+	txMonitorErrorCount int64        // 监控执行失败次数
+	txMonitorSkipCount  int64        // 因上次仍在执行而跳过的次数
+	txMonitorLastRunAt  int64        // 最近一次执行时间（Unix时间戳）
+	txMonitorLastError  atomic.Value // 最近一次错误信息（string）
+
+	// 房间Redis同步指标
+	roomSyncSuccessCount int64        // 同步成功次数
+	roomSyncErrorCount   int64        // 同步失败次数
+	roomSyncSkipCount    int64        // 熔断期间快速跳过的次数
+	roomSyncLastError    atomic.Value // 最近一次错误信息（string）
+
 	// 系统指标
 	mu        sync.RWMutex
 	startTime time.Time
 }
 
+// lockHoldBucketBounds 锁持有时长直方图的桶上界
+var lockHoldBucketBounds = [...]time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// lockHoldBucketLabels 直方图各桶的展示标签（与桶上界一一对应，末尾为溢出桶）
+var lockHoldBucketLabels = [...]string{
+	"le_10ms", "le_50ms", "le_100ms", "le_500ms", "le_1s", "le_5s", "gt_5s",
+}
+
 // LockStats 单个锁的统计信息
 type LockStats struct {
 	Key           string
 	AcquireCount  int64
 	SuccessCount  int64
 	FailedCount   int64
+	TimeoutCount  int64 // 竞争超时次数
 	TotalWaitTime int64 // 纳秒
 	TotalHoldTime int64 // 纳秒
 	LastAcquireAt int64 // Unix 时间戳
@@ -71,16 +107,66 @@ func (m *Metrics) RecordLockAcquire(key string, success bool, waitTime time.Dura
 	atomic.AddInt64(&stats.TotalWaitTime, int64(waitTime))
 }
 
+// RecordLockTimeout 记录锁竞争超时（重试耗尽仍未抢到锁）
+func (m *Metrics) RecordLockTimeout(key string) {
+	atomic.AddInt64(&m.lockTimeoutCount, 1)
+
+	stats := m.getOrCreateLockStats(key)
+	atomic.AddInt64(&stats.TimeoutCount, 1)
+}
+
 // RecordLockRelease 记录锁释放
 func (m *Metrics) RecordLockRelease(key string, holdTime time.Duration) {
 	atomic.AddInt64(&m.lockHoldTimeTotal, int64(holdTime))
 
+	// 更新持有时长直方图
+	bucket := len(lockHoldBucketBounds)
+	for i, bound := range lockHoldBucketBounds {
+		if holdTime <= bound {
+			bucket = i
+			break
+		}
+	}
+	atomic.AddInt64(&m.lockHoldHistogram[bucket], 1)
+
 	// 更新按key的统计
 	stats := m.getOrCreateLockStats(key)
 	atomic.AddInt64(&stats.TotalHoldTime, int64(holdTime))
 	atomic.StoreInt64(&stats.LastReleaseAt, time.Now().Unix())
 }
 
+// RateLimitStats 单条限流规则的统计信息
+type RateLimitStats struct {
+	Name     string
+	Allowed  int64 // 放行次数
+	Rejected int64 // 拒绝次数
+}
+
+// RecordRateLimit 记录一次限流判定
+func (m *Metrics) RecordRateLimit(name string, allowed bool) {
+	v, ok := m.rateLimitStats.Load(name)
+	if !ok {
+		v, _ = m.rateLimitStats.LoadOrStore(name, &RateLimitStats{Name: name})
+	}
+	stats := v.(*RateLimitStats)
+
+	if allowed {
+		atomic.AddInt64(&stats.Allowed, 1)
+	} else {
+		atomic.AddInt64(&stats.Rejected, 1)
+	}
+}
+
+// GetRateLimitStats 获取所有限流规则的统计信息
+func (m *Metrics) GetRateLimitStats() []*RateLimitStats {
+	stats := make([]*RateLimitStats, 0)
+	m.rateLimitStats.Range(func(key, value interface{}) bool {
+		stats = append(stats, value.(*RateLimitStats))
+		return true
+	})
+	return stats
+}
+
 // getOrCreateLockStats 获取或创建锁统计
 func (m *Metrics) getOrCreateLockStats(key string) *LockStats {
 	if stats, ok := m.lockStatsByKey.Load(key); ok {
@@ -109,9 +195,15 @@ func (m *Metrics) GetLockSummary() map[string]interface{} {
 	totalCount := atomic.LoadInt64(&m.lockAcquireCount)
 	successCount := atomic.LoadInt64(&m.lockAcquireSuccess)
 	failedCount := atomic.LoadInt64(&m.lockAcquireFailed)
+	timeoutCount := atomic.LoadInt64(&m.lockTimeoutCount)
 	totalWaitTime := atomic.LoadInt64(&m.lockWaitTimeTotal)
 	totalHoldTime := atomic.LoadInt64(&m.lockHoldTimeTotal)
 
+	holdHistogram := make(map[string]int64, len(lockHoldBucketLabels))
+	for i, label := range lockHoldBucketLabels {
+		holdHistogram[label] = atomic.LoadInt64(&m.lockHoldHistogram[i])
+	}
+
 	avgWaitTime := int64(0)
 	avgHoldTime := int64(0)
 	successRate := float64(0)
@@ -126,7 +218,9 @@ func (m *Metrics) GetLockSummary() map[string]interface{} {
 		"total_acquire_count": totalCount,
 		"success_count":       successCount,
 		"failed_count":        failedCount,
+		"timeout_count":       timeoutCount,
 		"success_rate":        successRate,
+		"hold_time_histogram": holdHistogram,
 		"avg_wait_time_ms":    float64(avgWaitTime) / 1e6,
 		"avg_hold_time_ms":    float64(avgHoldTime) / 1e6,
 		"total_wait_time_ms":  float64(totalWaitTime) / 1e6,
@@ -160,10 +254,66 @@ func (m *Metrics) GetRuntimeStats() map[string]interface{} {
 }
 
 // GetAllMetrics 获取所有监控指标
+// RecordTxMonitorRun 记录一次交易监控执行
+func (m *Metrics) RecordTxMonitorRun(err error) {
+	atomic.AddInt64(&m.txMonitorRunCount, 1)
+	atomic.StoreInt64(&m.txMonitorLastRunAt, time.Now().Unix())
+	if err != nil {
+		atomic.AddInt64(&m.txMonitorErrorCount, 1)
+		m.txMonitorLastError.Store(err.Error())
+	}
+}
+
+// RecordTxMonitorSkip 记录一次因重叠而跳过的监控执行
+func (m *Metrics) RecordTxMonitorSkip() {
+	atomic.AddInt64(&m.txMonitorSkipCount, 1)
+}
+
+// GetTxMonitorStats 获取交易监控统计
+func (m *Metrics) GetTxMonitorStats() map[string]interface{} {
+	lastError, _ := m.txMonitorLastError.Load().(string)
+	return map[string]interface{}{
+		"run_count":   atomic.LoadInt64(&m.txMonitorRunCount),
+		"error_count": atomic.LoadInt64(&m.txMonitorErrorCount),
+		"skip_count":  atomic.LoadInt64(&m.txMonitorSkipCount),
+		"last_run_at": atomic.LoadInt64(&m.txMonitorLastRunAt),
+		"last_error":  lastError,
+	}
+}
+
+// RecordRoomSync 记录一次房间Redis同步结果
+func (m *Metrics) RecordRoomSync(err error) {
+	if err != nil {
+		atomic.AddInt64(&m.roomSyncErrorCount, 1)
+		m.roomSyncLastError.Store(err.Error())
+		return
+	}
+	atomic.AddInt64(&m.roomSyncSuccessCount, 1)
+}
+
+// RecordRoomSyncSkip 记录一次因Redis不可用而快速跳过的同步
+func (m *Metrics) RecordRoomSyncSkip() {
+	atomic.AddInt64(&m.roomSyncSkipCount, 1)
+}
+
+// GetRoomSyncStats 获取房间Redis同步统计
+func (m *Metrics) GetRoomSyncStats() map[string]interface{} {
+	lastError, _ := m.roomSyncLastError.Load().(string)
+	return map[string]interface{}{
+		"success_count": atomic.LoadInt64(&m.roomSyncSuccessCount),
+		"error_count":   atomic.LoadInt64(&m.roomSyncErrorCount),
+		"skip_count":    atomic.LoadInt64(&m.roomSyncSkipCount),
+		"last_error":    lastError,
+	}
+}
+
 func (m *Metrics) GetAllMetrics() map[string]interface{} {
 	return map[string]interface{}{
 		"lock_summary": m.GetLockSummary(),
 		"lock_details": m.GetLockStats(),
+		"rate_limit":   m.GetRateLimitStats(),
+		"tx_monitor":   m.GetTxMonitorStats(),
+		"room_sync":    m.GetRoomSyncStats(),
 		"goroutine":    m.GetGoroutineStats(),
 		"runtime":      m.GetRuntimeStats(),
 	}
@@ -174,7 +324,21 @@ func (m *Metrics) Reset() {
 	atomic.StoreInt64(&m.lockAcquireCount, 0)
 	atomic.StoreInt64(&m.lockAcquireSuccess, 0)
 	atomic.StoreInt64(&m.lockAcquireFailed, 0)
+	atomic.StoreInt64(&m.lockTimeoutCount, 0)
 	atomic.StoreInt64(&m.lockWaitTimeTotal, 0)
 	atomic.StoreInt64(&m.lockHoldTimeTotal, 0)
+	for i := range m.lockHoldHistogram {
+		atomic.StoreInt64(&m.lockHoldHistogram[i], 0)
+	}
+	atomic.StoreInt64(&m.txMonitorRunCount, 0)
+	atomic.StoreInt64(&m.txMonitorErrorCount, 0)
+	atomic.StoreInt64(&m.txMonitorSkipCount, 0)
+	atomic.StoreInt64(&m.txMonitorLastRunAt, 0)
+	m.txMonitorLastError.Store("")
+	atomic.StoreInt64(&m.roomSyncSuccessCount, 0)
+	atomic.StoreInt64(&m.roomSyncErrorCount, 0)
+	atomic.StoreInt64(&m.roomSyncSkipCount, 0)
+	m.roomSyncLastError.Store("")
 	m.lockStatsByKey = sync.Map{}
+	m.rateLimitStats = sync.Map{}
 }
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/config"
+)
+
+// CORS 跨域中间件。来源白名单由配置驱动（cors.allowed_origins），
+// 与游戏服务器的WebSocket来源校验共用同一配置。白名单外的来源
+// 不下发CORS响应头，由浏览器拦截。
+func CORS(cfg *config.Config) gin.HandlerFunc {
+	allowMethods := strings.Join(cfg.CORS.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.CORS.AllowedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+
+		if origin != "" && cfg.IsOriginAllowed(origin) {
+			// 允许凭据时不能使用通配符，回显具体来源
+			if cfg.CORS.AllowCredentials {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Header("Access-Control-Allow-Credentials", "true")
+			} else {
+				allowOrigin := origin
+				for _, allowed := range cfg.CORSAllowedOrigins() {
+					if allowed == "*" {
+						allowOrigin = "*"
+						break
+					}
+				}
+				c.Header("Access-Control-Allow-Origin", allowOrigin)
+			}
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			c.Header("Vary", "Origin")
+		}
+
+		// 预检请求直接返回，不进入业务处理
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
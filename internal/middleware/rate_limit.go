@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaifa/game-platform/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitConfig 单条限流规则（令牌桶）
+// 按用户（已认证）或客户端IP（未认证）分别计数
+type RateLimitConfig struct {
+	Name     string  // 规则名（用于Redis键前缀和监控计数）
+	Capacity int     // 桶容量（允许的突发请求数）
+	Rate     float64 // 每秒补充的令牌数
+}
+
+// rateLimitRedis 限流使用的Redis客户端（nil时退化为本地内存桶）
+var rateLimitRedis *redis.Client
+
+// InitRateLimiter 绑定Redis客户端（启动时调用）
+// 不调用或传nil时限流仅在单实例内存中生效
+func InitRateLimiter(rdb *redis.Client) {
+	rateLimitRedis = rdb
+}
+
+// tokenBucketScript 令牌桶Lua脚本（原子执行补充+扣减）
+// KEYS[1]: 桶键；ARGV: rate, capacity, now(毫秒), ttl(毫秒)
+// 返回: {是否放行(0/1), 剩余令牌数(向下取整)}
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local tokens = capacity
+local ts = now
+local data = redis.call('HMGET', key, 'tokens', 'ts')
+if data[1] then
+  tokens = tonumber(data[1])
+  ts = tonumber(data[2])
+end
+
+tokens = math.min(capacity, tokens + (now - ts) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', key, 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', key, ttl)
+return {allowed, math.floor(tokens)}
+`)
+
+// memBucket 本地内存令牌桶（Redis不可用时的降级方案，仅单实例有效）
+type memBucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+var (
+	memBuckets   = make(map[string]*memBucket)
+	memBucketsMu sync.Mutex
+)
+
+// allowMemory 本地令牌桶判定
+func allowMemory(key string, cfg RateLimitConfig) bool {
+	memBucketsMu.Lock()
+	defer memBucketsMu.Unlock()
+
+	now := time.Now()
+	b, ok := memBuckets[key]
+	if !ok {
+		b = &memBucket{tokens: float64(cfg.Capacity), ts: now}
+		memBuckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(cfg.Capacity), b.tokens+now.Sub(b.ts).Seconds()*cfg.Rate)
+	b.ts = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
+
+// allowRedis 通过Redis令牌桶判定；Redis出错时返回错误，调用方降级
+func allowRedis(ctx context.Context, key string, cfg RateLimitConfig) (bool, error) {
+	// TTL取桶填满所需时间的两倍，空闲桶自动过期
+	ttl := time.Duration(float64(cfg.Capacity)/cfg.Rate*2) * time.Second
+	if ttl < time.Minute {
+		ttl = time.Minute
+	}
+
+	result, err := tokenBucketScript.Run(ctx, rateLimitRedis, []string{key},
+		cfg.Rate, cfg.Capacity, time.Now().UnixMilli(), ttl.Milliseconds()).Slice()
+	if err != nil {
+		return false, err
+	}
+	if len(result) < 1 {
+		return false, fmt.Errorf("限流脚本返回异常: %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	return allowed == 1, nil
+}
+
+// rateLimitSubject 限流主体：已认证用户按user_id，否则按客户端IP
+func rateLimitSubject(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprintf("u%v", userID)
+	}
+	return c.ClientIP()
+}
+
+// RateLimit 令牌桶限流中间件
+// Redis可用时跨实例生效，不可用时降级为本地内存桶；超限返回429和Retry-After
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := fmt.Sprintf("ratelimit:%s:%s", cfg.Name, rateLimitSubject(c))
+
+		var allowed bool
+		if rateLimitRedis != nil {
+			var err error
+			allowed, err = allowRedis(c.Request.Context(), key, cfg)
+			if err != nil {
+				// Redis故障降级为本地桶，不因限流组件阻断业务
+				allowed = allowMemory(key, cfg)
+			}
+		} else {
+			allowed = allowMemory(key, cfg)
+		}
+
+		metrics.GetGlobalMetrics().RecordRateLimit(cfg.Name, allowed)
+
+		if !allowed {
+			// 下一个令牌的预计等待时间
+			retryAfter := int(math.Ceil(1 / cfg.Rate))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", fmt.Sprintf("%d", retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"code":    429,
+				"message": "请求过于频繁，请稍后重试",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kaifa/game-platform/internal/tracing"
+)
+
+// RequestID 请求追踪ID中间件
+// 优先使用客户端/上游传入的X-Request-ID，否则生成新ID；
+// 写入gin上下文、请求context和响应头，便于跨服务串联日志
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(tracing.HeaderRequestID)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.New().String()
+		}
+
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(tracing.WithRequestID(c.Request.Context(), requestID))
+		c.Header(tracing.HeaderRequestID, requestID)
+
+		c.Next()
+	}
+}
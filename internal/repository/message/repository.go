@@ -32,6 +32,8 @@ type Repository interface {
 	// Create 创建消息
 	Create(ctx context.Context, message *models.UserMessage) error
 
-	// GetAnnouncements 获取有效的公告列表
-	GetAnnouncements(ctx context.Context, limit int) ([]models.Announcement, error)
+	// GetAnnouncements 获取当前生效的公告列表。
+	// userID 为 0 表示未登录用户，只返回面向全部用户的公告；
+	// 指定用户的公告只对目标用户可见。
+	GetAnnouncements(ctx context.Context, userID uint, limit int) ([]models.Announcement, error)
 }
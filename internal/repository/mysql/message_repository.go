@@ -111,7 +111,7 @@ func (r *MessageRepository) Create(ctx context.Context, message *models.UserMess
 }
 
 // GetAnnouncements 获取有效的公告列表
-func (r *MessageRepository) GetAnnouncements(ctx context.Context, limit int) ([]models.Announcement, error) {
+func (r *MessageRepository) GetAnnouncements(ctx context.Context, userID uint, limit int) ([]models.Announcement, error) {
 	var announcements []models.Announcement
 	now := time.Now().Unix()
 
@@ -121,6 +121,13 @@ func (r *MessageRepository) GetAnnouncements(ctx context.Context, limit int) ([]
 	// 时间范围筛选
 	query = query.Where("(start_time IS NULL OR start_time <= ?) AND (end_time IS NULL OR end_time >= ?)", now, now)
 
+	// 目标用户筛选：未登录只看全员公告，登录用户还能看到指定给自己的公告
+	if userID > 0 {
+		query = query.Where("target_users = ? OR FIND_IN_SET(?, target_users)", "all", userID)
+	} else {
+		query = query.Where("target_users = ?", "all")
+	}
+
 	// 获取列表
 	if err := query.Order("priority DESC, created_at DESC").Limit(limit).Find(&announcements).Error; err != nil {
 		return nil, err
@@ -67,6 +67,15 @@ func (r *RechargeOrderRepository) ListPending(ctx context.Context, minExpireAt i
 	return orders, nil
 }
 
+// ListExpiredPending 获取已过有效期但仍处于待支付状态的订单
+func (r *RechargeOrderRepository) ListExpiredPending(ctx context.Context, now int64) ([]models.RechargeOrder, error) {
+	var orders []models.RechargeOrder
+	if err := r.db.WithContext(ctx).Where("status = ? AND expire_at <= ?", 1, now).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
 var _ paymentrepo.RechargeOrderRepository = (*RechargeOrderRepository)(nil)
 
 // ==================== WithdrawOrderRepository ====================
@@ -120,6 +129,14 @@ func (r *WithdrawOrderRepository) ListByUser(ctx context.Context, userID uint, o
 	return orders, total, nil
 }
 
+func (r *WithdrawOrderRepository) ListByStatus(ctx context.Context, status int8) ([]models.WithdrawOrder, error) {
+	var orders []models.WithdrawOrder
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
 var _ paymentrepo.WithdrawOrderRepository = (*WithdrawOrderRepository)(nil)
 
 // ==================== TransactionRepository ====================
@@ -144,11 +161,14 @@ func (r *TransactionRepository) GetByOrderID(ctx context.Context, orderID string
 	return &transaction, nil
 }
 
-func (r *TransactionRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.Transaction, int64, error) {
+func (r *TransactionRepository) ListByUser(ctx context.Context, userID uint, txType string, offset, limit int) ([]models.Transaction, int64, error) {
 	var transactions []models.Transaction
 	var total int64
 
 	query := r.db.WithContext(ctx).Model(&models.Transaction{}).Where("user_id = ?", userID)
+	if txType != "" {
+		query = query.Where("type = ?", txType)
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -190,3 +210,31 @@ func (r *DepositAddressRepository) Update(ctx context.Context, address *models.U
 }
 
 var _ paymentrepo.DepositAddressRepository = (*DepositAddressRepository)(nil)
+
+// ==================== IdempotencyKeyRepository ====================
+
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) paymentrepo.IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{db: db}
+}
+
+func (r *IdempotencyKeyRepository) Create(ctx context.Context, key *models.PaymentIdempotencyKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *IdempotencyKeyRepository) GetByUserAndKey(ctx context.Context, userID uint, key string) (*models.PaymentIdempotencyKey, error) {
+	var record models.PaymentIdempotencyKey
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND `key` = ?", userID, key).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *IdempotencyKeyRepository) DeleteExpired(ctx context.Context, now int64) error {
+	return r.db.WithContext(ctx).Where("expire_at < ?", now).Delete(&models.PaymentIdempotencyKey{}).Error
+}
+
+var _ paymentrepo.IdempotencyKeyRepository = (*IdempotencyKeyRepository)(nil)
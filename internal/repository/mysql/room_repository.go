@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"errors"
 
 	roomrepo "github.com/kaifa/game-platform/internal/repository/room"
 	"github.com/kaifa/game-platform/pkg/models"
@@ -23,7 +24,23 @@ func (r *RoomRepository) Create(ctx context.Context, room *models.GameRoom) erro
 }
 
 func (r *RoomRepository) Update(ctx context.Context, room *models.GameRoom) error {
-	return r.db.WithContext(ctx).Save(room).Error
+	// 乐观锁：只在版本号未变时更新，防止多实例并发写丢失
+	currentVersion := room.Version
+	room.Version = currentVersion + 1
+
+	result := r.db.WithContext(ctx).Model(room).
+		Where("version = ?", currentVersion).
+		Select("*").Omit("id", "created_at").
+		Updates(room)
+	if result.Error != nil {
+		room.Version = currentVersion
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		room.Version = currentVersion
+		return roomrepo.ErrVersionConflict
+	}
+	return nil
 }
 
 func (r *RoomRepository) DeleteByRoomID(ctx context.Context, roomID string) error {
@@ -38,7 +55,8 @@ func (r *RoomRepository) GetByRoomID(ctx context.Context, roomID string) (*model
 	return &room, nil
 }
 
-func (r *RoomRepository) List(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, error) {
+// applyListFilter 按筛选条件装配查询（List和Count共用）
+func (r *RoomRepository) applyListFilter(ctx context.Context, filter roomrepo.ListFilter) *gorm.DB {
 	query := r.db.WithContext(ctx).Model(&models.GameRoom{})
 
 	if filter.GameType != "" {
@@ -50,6 +68,24 @@ func (r *RoomRepository) List(ctx context.Context, filter roomrepo.ListFilter) (
 	if filter.OwnerID > 0 {
 		query = query.Where("creator_id = ?", filter.OwnerID)
 	}
+	return query
+}
+
+func (r *RoomRepository) Count(ctx context.Context, filter roomrepo.ListFilter) (int64, error) {
+	var total int64
+	if err := r.applyListFilter(ctx, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *RoomRepository) List(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, int64, error) {
+	query := r.applyListFilter(ctx, filter)
+
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -61,9 +97,44 @@ func (r *RoomRepository) List(ctx context.Context, filter roomrepo.ListFilter) (
 
 	var rooms []*models.GameRoom
 	if err := query.Order("created_at DESC").Limit(limit).Offset(filter.Offset).Find(&rooms).Error; err != nil {
+		return nil, 0, err
+	}
+	return rooms, total, nil
+}
+
+func (r *RoomRepository) ListIdleWaiting(ctx context.Context, cutoff int64) ([]*models.GameRoom, error) {
+	var rooms []*models.GameRoom
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND last_activity_at < ?", 1, cutoff).
+		Find(&rooms).Error; err != nil {
 		return nil, err
 	}
 	return rooms, nil
 }
 
+func (r *RoomRepository) CountActiveByCreator(ctx context.Context, creatorID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.GameRoom{}).
+		Where("creator_id = ? AND status IN (?)", creatorID, []int8{1, 2}).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *RoomRepository) FindInProgressByPlayer(ctx context.Context, userID uint) (*models.GameRoom, error) {
+	var room models.GameRoom
+	// players 为 PlayerInfo 数组的 JSON，按 user_id 匹配数组元素
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND JSON_CONTAINS(players, JSON_OBJECT('user_id', ?))", 2, userID).
+		First(&room).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
 var _ roomrepo.Repository = (*RoomRepository)(nil)
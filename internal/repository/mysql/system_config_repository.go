@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	systemconfigrepo "github.com/kaifa/game-platform/internal/repository/systemconfig"
+	"github.com/kaifa/game-platform/pkg/models"
+	"gorm.io/gorm"
+)
+
+// SystemConfigRepository MySQL 实现。
+type SystemConfigRepository struct {
+	db *gorm.DB
+}
+
+// NewSystemConfigRepository 创建系统配置仓储实例。
+func NewSystemConfigRepository(db *gorm.DB) *SystemConfigRepository {
+	return &SystemConfigRepository{db: db}
+}
+
+func (r *SystemConfigRepository) List(ctx context.Context, groupName string, publicOnly bool) ([]*models.SystemConfig, error) {
+	query := r.db.WithContext(ctx).Model(&models.SystemConfig{})
+
+	if groupName != "" {
+		query = query.Where("group_name = ?", groupName)
+	}
+	if publicOnly {
+		query = query.Where("is_public = ?", true)
+	}
+
+	var configs []*models.SystemConfig
+	err := query.Order("group_name ASC, config_key ASC").Find(&configs).Error
+	return configs, err
+}
+
+func (r *SystemConfigRepository) GetByKey(ctx context.Context, key string) (*models.SystemConfig, error) {
+	var config models.SystemConfig
+	if err := r.db.WithContext(ctx).Where("config_key = ?", key).First(&config).Error; err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *SystemConfigRepository) Upsert(ctx context.Context, config *models.SystemConfig) error {
+	var existing models.SystemConfig
+	err := r.db.WithContext(ctx).Where("config_key = ?", config.ConfigKey).First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		return r.db.WithContext(ctx).Create(config).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	config.ID = existing.ID
+	config.CreatedAt = existing.CreatedAt
+	config.UpdatedAt = time.Now().Unix()
+	return r.db.WithContext(ctx).Save(config).Error
+}
+
+func (r *SystemConfigRepository) DeleteByKey(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("config_key = ?", key).Delete(&models.SystemConfig{}).Error
+}
+
+func (r *SystemConfigRepository) ListGroups(ctx context.Context) ([]string, error) {
+	var groups []string
+	err := r.db.WithContext(ctx).Model(&models.SystemConfig{}).
+		Select("DISTINCT group_name").
+		Order("group_name ASC").
+		Pluck("group_name", &groups).Error
+	return groups, err
+}
+
+// 编译期校验接口实现
+var _ systemconfigrepo.Repository = (*SystemConfigRepository)(nil)
@@ -25,6 +25,9 @@ type RechargeOrderRepository interface {
 
 	// ListPending 获取待支付的订单
 	ListPending(ctx context.Context, minExpireAt int64) ([]models.RechargeOrder, error)
+
+	// ListExpiredPending 获取已过有效期但仍处于待支付状态的订单
+	ListExpiredPending(ctx context.Context, now int64) ([]models.RechargeOrder, error)
 }
 
 // WithdrawOrderRepository 提现订单数据访问接口
@@ -43,6 +46,9 @@ type WithdrawOrderRepository interface {
 
 	// ListByUser 获取用户的提现订单列表
 	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.WithdrawOrder, int64, error)
+
+	// ListByStatus 按状态获取提现订单列表
+	ListByStatus(ctx context.Context, status int8) ([]models.WithdrawOrder, error)
 }
 
 // TransactionRepository 交易记录数据访问接口
@@ -53,8 +59,20 @@ type TransactionRepository interface {
 	// GetByOrderID 根据订单号获取交易记录
 	GetByOrderID(ctx context.Context, orderID string) (*models.Transaction, error)
 
-	// ListByUser 获取用户的交易记录
-	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.Transaction, int64, error)
+	// ListByUser 获取用户的交易记录（txType为空时不过滤类型）
+	ListByUser(ctx context.Context, userID uint, txType string, offset, limit int) ([]models.Transaction, int64, error)
+}
+
+// IdempotencyKeyRepository 支付幂等键数据访问接口
+type IdempotencyKeyRepository interface {
+	// Create 创建幂等键记录
+	Create(ctx context.Context, key *models.PaymentIdempotencyKey) error
+
+	// GetByUserAndKey 根据用户ID和幂等键获取记录
+	GetByUserAndKey(ctx context.Context, userID uint, key string) (*models.PaymentIdempotencyKey, error)
+
+	// DeleteExpired 删除过期的幂等键记录
+	DeleteExpired(ctx context.Context, now int64) error
 }
 
 // DepositAddressRepository 用户充值地址数据访问接口
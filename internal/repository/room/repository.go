@@ -2,19 +2,35 @@ package room
 
 import (
 	"context"
+	"errors"
 
 	"github.com/kaifa/game-platform/pkg/models"
 )
 
+// ErrVersionConflict 表示房间在读取后被其他请求修改（乐观锁冲突），
+// 调用方应重新读取房间后重试
+var ErrVersionConflict = errors.New("房间已被其他操作修改")
+
 // Repository 定义房间相关的数据访问接口。
 // 后续将把 pkg/services/room_service.go 中直接依赖数据库/Redis 的逻辑迁移到具体实现中。
 // 当前仅作为解耦骨架，不参与实际业务调用。
 type Repository interface {
 	Create(ctx context.Context, room *models.GameRoom) error
+	// Update 以乐观锁方式更新房间，版本不匹配时返回 ErrVersionConflict
 	Update(ctx context.Context, room *models.GameRoom) error
 	DeleteByRoomID(ctx context.Context, roomID string) error
 	GetByRoomID(ctx context.Context, roomID string) (*models.GameRoom, error)
-	List(ctx context.Context, filter ListFilter) ([]*models.GameRoom, error)
+	// List 按条件分页查询房间，total为符合条件的总数（不受Limit/Offset影响）
+	List(ctx context.Context, filter ListFilter) ([]*models.GameRoom, int64, error)
+	// Count 统计符合条件的房间总数（忽略Limit/Offset）
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+	// ListIdleWaiting 查询最后活动时间早于cutoff的等待中房间（用于闲置清理）
+	ListIdleWaiting(ctx context.Context, cutoff int64) ([]*models.GameRoom, error)
+	// CountActiveByCreator 统计用户作为创建者的活跃房间数（等待中或游戏中）
+	CountActiveByCreator(ctx context.Context, creatorID uint) (int64, error)
+	// FindInProgressByPlayer 查找用户作为玩家参与的游戏中房间（Status==2），
+	// 不存在时返回 (nil, nil)
+	FindInProgressByPlayer(ctx context.Context, userID uint) (*models.GameRoom, error)
 }
 
 // ListFilter 描述房间列表查询的筛选条件。
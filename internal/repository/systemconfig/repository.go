@@ -0,0 +1,19 @@
+package systemconfig
+
+import (
+	"context"
+
+	"github.com/kaifa/game-platform/pkg/models"
+)
+
+// Repository 定义系统配置的数据访问接口。
+type Repository interface {
+	// List 按条件查询配置（groupName为空表示全部；publicOnly为true时只返回公开配置）
+	List(ctx context.Context, groupName string, publicOnly bool) ([]*models.SystemConfig, error)
+	GetByKey(ctx context.Context, key string) (*models.SystemConfig, error)
+	// Upsert 按配置键新增或更新配置
+	Upsert(ctx context.Context, config *models.SystemConfig) error
+	DeleteByKey(ctx context.Context, key string) error
+	// ListGroups 查询所有配置分组名
+	ListGroups(ctx context.Context) ([]string, error)
+}
@@ -14,6 +14,9 @@ type Repository interface {
 	// GetByPhone 根据手机号获取用户
 	GetByPhone(ctx context.Context, phone string) (*models.User, error)
 
+	// GetByEmail 根据邮箱获取用户
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+
 	// Create 创建用户
 	Create(ctx context.Context, user *models.User) error
 
@@ -0,0 +1,106 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// 默认重试参数
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 100 * time.Millisecond
+	defaultMaxDelay    = 5 * time.Second
+)
+
+// Options 重试选项
+// 零值可用：未设置的字段取默认值
+type Options struct {
+	MaxAttempts int           // 最大尝试次数（含首次），<=0时默认3次
+	BaseDelay   time.Duration // 首次重试的退避间隔，<=0时默认100ms
+	MaxDelay    time.Duration // 单次退避间隔上限，<=0时默认5s
+
+	// Retryable 判断错误是否可重试
+	// nil表示所有错误均可重试；返回false时立即放弃并原样返回该错误
+	Retryable func(error) bool
+
+	// Sleep 等待函数，nil时使用真实时钟
+	// 测试时可注入假时钟，避免用例真实等待
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Do 执行fn，失败时按指数退避（带抖动）重试
+// 返回nil表示某次尝试成功；ctx取消时立即终止并返回ctx错误
+func Do(ctx context.Context, opts Options, fn func() error) error {
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	baseDelay := opts.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultBaseDelay
+	}
+	maxDelay := opts.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	sleep := opts.Sleep
+	if sleep == nil {
+		sleep = sleepCtx
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		// 不可重试的错误立即放弃
+		if opts.Retryable != nil && !opts.Retryable(err) {
+			return err
+		}
+
+		if attempt >= maxAttempts {
+			return fmt.Errorf("重试%d次后仍失败: %w", maxAttempts, err)
+		}
+
+		if serr := sleep(ctx, backoff(baseDelay, maxDelay, attempt)); serr != nil {
+			return serr
+		}
+	}
+}
+
+// backoff 计算第attempt次失败后的退避间隔
+// 指数退避（base * 2^(attempt-1)，封顶maxDelay）+ 抖动（取[delay/2, delay]内随机值），
+// 避免多个调用方同时失败后同步重试造成尖峰
+func backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			delay = maxDelay
+			break
+		}
+	}
+
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// sleepCtx 真实时钟等待，ctx取消时提前返回
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -8,25 +8,31 @@ import (
 	"sort"
 	"time"
 
+	esClient "github.com/kaifa/game-platform/internal/elasticsearch"
 	"github.com/kaifa/game-platform/internal/lock"
+	"github.com/kaifa/game-platform/internal/logger"
 	gamerecordrepo "github.com/kaifa/game-platform/internal/repository/gamerecord"
+	paymentrepo "github.com/kaifa/game-platform/internal/repository/payment"
 	roomrepo "github.com/kaifa/game-platform/internal/repository/room"
 	userrepo "github.com/kaifa/game-platform/internal/repository/user"
 	leaderboardsvc "github.com/kaifa/game-platform/internal/service/leaderboard"
 	"github.com/kaifa/game-platform/internal/storage"
+	"github.com/kaifa/game-platform/pkg/errs"
 	"github.com/kaifa/game-platform/pkg/models"
 	"github.com/kaifa/game-platform/pkg/services"
+	"go.uber.org/zap"
 )
 
 // Manager 游戏管理器（重构版本 - 使用依赖注入）
 // 职责：管理游戏流程逻辑，不直接操作数据库和缓存
 type Manager struct {
 	// Repository 和 Service 依赖
-	stateStorage   storage.GameStateStorage  // 游戏状态存储
-	roomRepo       roomrepo.Repository       // 房间数据访问
-	userRepo       userrepo.Repository       // 用户数据访问
-	gameRecordRepo gamerecordrepo.Repository // 游戏记录数据访问
-	leaderboardSvc leaderboardsvc.Service    // 排行榜服务
+	stateStorage    storage.GameStateStorage          // 游戏状态存储
+	roomRepo        roomrepo.Repository               // 房间数据访问
+	userRepo        userrepo.Repository               // 用户数据访问
+	gameRecordRepo  gamerecordrepo.Repository         // 游戏记录数据访问
+	transactionRepo paymentrepo.TransactionRepository // 交易流水（结算记账）
+	leaderboardSvc  leaderboardsvc.Service            // 排行榜服务
 
 	// 并发控制组件
 	distLock  lock.Lock   // ✅ 分布式锁（用于关键游戏操作）
@@ -34,33 +40,72 @@ type Manager struct {
 
 	// 游戏引擎
 	engines map[string]services.GameEngine // 游戏引擎映射
+
+	// 回合计时通知（可选）：CurrentPlayer变更时广播timer_start，
+	// 玩家操作后广播timer_stop，客户端据此渲染倒计时
+	turnNotifier TurnNotifyFunc
+	turnTimeout  time.Duration
 }
 
+// TurnNotifyFunc 回合计时通知回调，由上层（房间服务）注入，
+// 负责把timer_start/timer_stop动作送达游戏服务器广播
+type TurnNotifyFunc func(ctx context.Context, roomID, action string, userID uint, data map[string]interface{})
+
 // NewManager 创建游戏管理器实例
 func NewManager(
 	stateStorage storage.GameStateStorage,
 	roomRepo roomrepo.Repository,
 	userRepo userrepo.Repository,
 	gameRecordRepo gamerecordrepo.Repository,
+	transactionRepo paymentrepo.TransactionRepository,
 	leaderboardSvc leaderboardsvc.Service,
 	distLock lock.Lock, // ✅ 注入分布式锁
 	localLock lock.RWLock, // ✅ 注入本地锁
 ) *Manager {
-	engines := make(map[string]services.GameEngine)
-	// 注册游戏引擎
-	engines["running"] = services.NewRunningFastGame()
-	engines["bull"] = services.NewBullGame()
+	// 从引擎注册表构建引擎映射：各引擎在pkg/services里自注册，
+	// 新增游戏无需改动Manager
+	engines := services.NewRegisteredEngines()
 
 	return &Manager{
-		stateStorage:   stateStorage,
-		roomRepo:       roomRepo,
-		userRepo:       userRepo,
-		gameRecordRepo: gameRecordRepo,
-		leaderboardSvc: leaderboardSvc,
-		distLock:       distLock,
-		localLock:      localLock,
-		engines:        engines,
+		stateStorage:    stateStorage,
+		roomRepo:        roomRepo,
+		userRepo:        userRepo,
+		gameRecordRepo:  gameRecordRepo,
+		transactionRepo: transactionRepo,
+		leaderboardSvc:  leaderboardSvc,
+		distLock:        distLock,
+		localLock:       localLock,
+		engines:         engines,
+	}
+}
+
+// SetTurnNotifier 注入回合计时通知回调与每回合时长。
+// fn为nil或timeout<=0时不发计时通知
+func (m *Manager) SetTurnNotifier(fn TurnNotifyFunc, timeout time.Duration) {
+	m.turnNotifier = fn
+	m.turnTimeout = timeout
+}
+
+// notifyTimerStop 玩家完成操作后广播停止该玩家的倒计时
+func (m *Manager) notifyTimerStop(ctx context.Context, roomID string, userID uint) {
+	if m.turnNotifier == nil || m.turnTimeout <= 0 {
+		return
 	}
+	m.turnNotifier(ctx, roomID, "timer_stop", userID, nil)
+}
+
+// notifyTimerStart 为当前出牌玩家广播开始倒计时（游戏已结束时不发）
+func (m *Manager) notifyTimerStart(ctx context.Context, roomID string, gameState *models.GameState) {
+	if m.turnNotifier == nil || m.turnTimeout <= 0 {
+		return
+	}
+	if gameState == nil || gameState.Status == 3 || gameState.CurrentPlayer == 0 {
+		return
+	}
+	m.turnNotifier(ctx, roomID, "timer_start", gameState.CurrentPlayer, map[string]interface{}{
+		"timeout":    int(m.turnTimeout / time.Second),
+		"start_time": time.Now().Unix(),
+	})
 }
 
 // StartGame 开始游戏（重构版本）
@@ -68,12 +113,12 @@ func (m *Manager) StartGame(ctx context.Context, roomID string) (*models.GameSta
 	// ✅ 通过 Repository 获取房间信息
 	room, err := m.roomRepo.GetByRoomID(ctx, roomID)
 	if err != nil {
-		return nil, errors.New("房间不存在")
+		return nil, errs.NotFound("房间不存在")
 	}
 
 	// 检查房间状态
 	if room.Status != 1 {
-		return nil, errors.New("房间状态不正确")
+		return nil, errs.BadRequest("房间状态不正确")
 	}
 
 	// 解析玩家列表
@@ -83,13 +128,13 @@ func (m *Manager) StartGame(ctx context.Context, roomID string) (*models.GameSta
 	}
 
 	if len(players) < 2 {
-		return nil, errors.New("至少需要2人才能开始游戏")
+		return nil, errs.BadRequest("至少需要2人才能开始游戏")
 	}
 
 	// 检查所有人是否都准备好了
 	for _, p := range players {
 		if !p.Ready {
-			return nil, errors.New("还有玩家未准备")
+			return nil, errs.BadRequest("还有玩家未准备")
 		}
 	}
 
@@ -99,21 +144,16 @@ func (m *Manager) StartGame(ctx context.Context, roomID string) (*models.GameSta
 		return nil, err
 	}
 
-	// ✅ 业务逻辑：创建游戏状态
-	var gameState *models.GameState
-	switch room.GameType {
-	case "running":
-		gameState, err = m.startRunningFastGame(roomID, players)
-	case "bull":
-		gameState, err = m.startBullGame(roomID, players, engine.(*services.BullGame))
-	default:
-		return nil, fmt.Errorf("未知的游戏类型: %s", room.GameType)
-	}
-
+	// ✅ 业务逻辑：创建游戏状态（发牌+校验+通用初始化，
+	// 首出玩家/庄家等游戏特有规则由引擎的Setup钩子决定）
+	gameState, err := m.createGameState(ctx, room, players, engine)
 	if err != nil {
 		return nil, err
 	}
 
+	// 初始序列号，后续每次状态变更递增
+	gameState.Seq = 1
+
 	// ✅ 通过 Storage 保存游戏状态
 	if err := m.stateStorage.Save(ctx, gameState, 2*time.Hour); err != nil {
 		return nil, fmt.Errorf("保存游戏状态失败: %w", err)
@@ -125,6 +165,9 @@ func (m *Manager) StartGame(ctx context.Context, roomID string) (*models.GameSta
 		return nil, fmt.Errorf("更新房间状态失败: %w", err)
 	}
 
+	// 为首位出牌玩家广播倒计时
+	m.notifyTimerStart(ctx, roomID, gameState)
+
 	return gameState, nil
 }
 
@@ -144,6 +187,97 @@ func (m *Manager) GetGameStateForUser(ctx context.Context, roomID string, userID
 	return gameState.FilterForUser(userID), nil
 }
 
+// maxHintPlays 单次提示返回的合法出牌组合上限
+const maxHintPlays = 20
+
+// GetLegalPlays 计算当前玩家的合法出牌提示（轮到自己时）。
+// 枚举手牌子集并逐组复用引擎的ValidateCards校验，保证提示与出牌
+// 强校验永不脱节；需要压牌而无牌可压时标记被迫过牌。
+func (m *Manager) GetLegalPlays(ctx context.Context, roomID string, userID uint) (*LegalPlaysResult, error) {
+	// ✅ 通过 Storage 获取游戏状态（只读，不需要加锁）
+	gameState, err := m.stateStorage.Get(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	playerInfo, ok := gameState.Players[userID]
+	if !ok {
+		return nil, errs.BadRequest("玩家不在游戏中")
+	}
+
+	if playerInfo.IsFinished {
+		return nil, errs.BadRequest("你已经出完牌了")
+	}
+
+	if gameState.CurrentPlayer != userID {
+		return nil, errs.BadRequest("还没轮到你出牌")
+	}
+
+	// 与PlayCards相同的压牌判定：有人过牌后可以自由出牌
+	var lastCards []int
+	if gameState.PassCount == 0 {
+		lastCards = gameState.LastCards
+	}
+
+	// 获取游戏引擎
+	engine, err := m.getEngine(gameState.GameType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LegalPlaysResult{
+		RoomID: roomID,
+		UserID: userID,
+		Plays:  make([][]int, 0, maxHintPlays),
+	}
+
+	hand := playerInfo.Cards
+	seen := make(map[string]bool)
+	combo := make([]int, 0, len(hand))
+
+	var walk func(start int)
+	walk = func(start int) {
+		// 已确认有牌可出且提示够多时提前结束枚举
+		if result.CanPlay && len(result.Plays) >= maxHintPlays {
+			return
+		}
+
+		if len(combo) > 0 {
+			if valid, _ := engine.ValidateCards(combo, lastCards); valid {
+				result.CanPlay = true
+				key := playKey(combo)
+				if !seen[key] && len(result.Plays) < maxHintPlays {
+					seen[key] = true
+					play := make([]int, len(combo))
+					copy(play, combo)
+					result.Plays = append(result.Plays, play)
+				}
+			}
+		}
+
+		for i := start; i < len(hand); i++ {
+			combo = append(combo, hand[i])
+			walk(i + 1)
+			combo = combo[:len(combo)-1]
+		}
+	}
+	walk(0)
+
+	// 只有在需要压别人的牌而又无牌可压时才是被迫过牌
+	result.MustPass = !result.CanPlay && len(lastCards) > 0
+	return result, nil
+}
+
+// playKey 以排序后的点数序列作为提示去重键（同点数不同花色视为同一种打法）
+func playKey(cards []int) string {
+	values := make([]int, len(cards))
+	for i, card := range cards {
+		values[i] = models.GetCardValue(card)
+	}
+	sort.Ints(values)
+	return fmt.Sprint(values)
+}
+
 // PlayCards 出牌（重构版本）
 func (m *Manager) PlayCards(ctx context.Context, roomID string, userID uint, cards []int) (*models.GameState, error) {
 	// ✅ 使用分布式锁保护出牌操作（防止并发出牌导致状态错乱）
@@ -152,7 +286,7 @@ func (m *Manager) PlayCards(ctx context.Context, roomID string, userID uint, car
 	var finalState *models.GameState
 	var finalErr error
 
-	err := m.distLock.WithLock(ctx, lockKey, 5*time.Second, func() error {
+	err := m.distLock.WithLock(ctx, lockKey, lock.DefaultTTL(), func() error {
 		// ✅ 在锁保护下获取游戏状态
 		gameState, err := m.stateStorage.Get(ctx, roomID)
 		if err != nil {
@@ -162,25 +296,25 @@ func (m *Manager) PlayCards(ctx context.Context, roomID string, userID uint, car
 
 		// 检查是否轮到自己
 		if gameState.CurrentPlayer != userID {
-			finalErr = errors.New("还没轮到你出牌")
+			finalErr = errs.BadRequest("还没轮到你出牌")
 			return finalErr
 		}
 
 		// 检查玩家是否已经完成
 		playerInfo, ok := gameState.Players[userID]
 		if !ok {
-			finalErr = errors.New("玩家不在游戏中")
+			finalErr = errs.BadRequest("玩家不在游戏中")
 			return finalErr
 		}
 
 		if playerInfo.IsFinished {
-			finalErr = errors.New("你已经出完牌了")
+			finalErr = errs.BadRequest("你已经出完牌了")
 			return finalErr
 		}
 
 		// 验证出的牌是否在手牌中
 		if !m.hasCards(playerInfo.Cards, cards) {
-			finalErr = errors.New("你手中没有这些牌")
+			finalErr = errs.BadRequest("你手中没有这些牌")
 			return finalErr
 		}
 
@@ -228,6 +362,9 @@ func (m *Manager) PlayCards(ctx context.Context, roomID string, userID uint, car
 		// 设置下一个出牌玩家
 		gameState.CurrentPlayer = m.getNextPlayer(gameState, userID)
 
+		// 状态变更，序列号递增（客户端据此应用增量）
+		gameState.Seq++
+
 		// ✅ 通过 Storage 保存游戏状态
 		if err := m.stateStorage.Save(ctx, gameState, 2*time.Hour); err != nil {
 			finalErr = fmt.Errorf("保存游戏状态失败: %w", err)
@@ -253,9 +390,17 @@ func (m *Manager) PlayCards(ctx context.Context, roomID string, userID uint, car
 	})
 
 	if err != nil {
-		return nil, finalErr
+		if finalErr != nil {
+			return nil, finalErr
+		}
+		// 锁获取失败：区分竞争超时与系统错误
+		return nil, lock.FriendlyError(err)
 	}
 
+	// 操作成功：停止该玩家的倒计时，并为下一位出牌玩家重新计时
+	m.notifyTimerStop(ctx, roomID, userID)
+	m.notifyTimerStart(ctx, roomID, finalState)
+
 	return finalState, nil
 }
 
@@ -267,7 +412,7 @@ func (m *Manager) PlayBullGame(ctx context.Context, roomID string, userID uint,
 	var finalState *models.GameState
 	var finalErr error
 
-	err := m.distLock.WithLock(ctx, lockKey, 5*time.Second, func() error {
+	err := m.distLock.WithLock(ctx, lockKey, lock.DefaultTTL(), func() error {
 		// ✅ 在锁保护下获取游戏状态
 		gameState, err := m.stateStorage.Get(ctx, roomID)
 		if err != nil {
@@ -336,6 +481,7 @@ func (m *Manager) PlayBullGame(ctx context.Context, roomID string, userID uint,
 		// 更新游戏状态
 		gameState.Round++
 		gameState.CurrentPlayer = m.getNextPlayer(gameState, userID)
+		gameState.Seq++
 
 		// ✅ 通过 Storage 保存游戏状态
 		if err := m.stateStorage.Save(ctx, gameState, 2*time.Hour); err != nil {
@@ -346,13 +492,12 @@ func (m *Manager) PlayBullGame(ctx context.Context, roomID string, userID uint,
 		// 检查游戏是否结束（所有人都出完牌）
 		isEnded, endedGameState := m.checkGameEnd(ctx, roomID, gameState)
 		if isEnded {
-			// 游戏结束，进行牛牛结算
-			settlement, err := m.settleBullGame(ctx, roomID, endedGameState, bullGame)
+			// 游戏结束，进行结算
+			_, err := m.SettleGame(ctx, roomID, endedGameState)
 			if err != nil {
 				finalState = endedGameState
 				return nil
 			}
-			_ = settlement
 			finalState = endedGameState
 			return nil
 		}
@@ -362,9 +507,79 @@ func (m *Manager) PlayBullGame(ctx context.Context, roomID string, userID uint,
 	})
 
 	if err != nil {
-		return nil, finalErr
+		if finalErr != nil {
+			return nil, finalErr
+		}
+		// 锁获取失败：区分竞争超时与系统错误
+		return nil, lock.FriendlyError(err)
 	}
 
+	// 操作成功：停止该玩家的倒计时，并为下一位出牌玩家重新计时
+	m.notifyTimerStop(ctx, roomID, userID)
+	m.notifyTimerStart(ctx, roomID, finalState)
+
+	return finalState, nil
+}
+
+// SetBullBetMultiplier 闲家在亮牌前选择押注倍数（1-3倍）。
+// 庄家不押注；已亮牌后倍数锁定，不能再修改。
+func (m *Manager) SetBullBetMultiplier(ctx context.Context, roomID string, userID uint, multiplier int) (*models.GameState, error) {
+	if multiplier < 1 || multiplier > 3 {
+		return nil, errs.BadRequest("押注倍数必须在1-3之间")
+	}
+
+	// ✅ 与出牌共用同一把锁，避免亮牌与改倍数并发
+	lockKey := fmt.Sprintf("game:%s:play", roomID)
+
+	var finalState *models.GameState
+	var finalErr error
+
+	err := m.distLock.WithLock(ctx, lockKey, lock.DefaultTTL(), func() error {
+		gameState, err := m.stateStorage.Get(ctx, roomID)
+		if err != nil {
+			finalErr = err
+			return finalErr
+		}
+
+		if gameState.GameType != "bull" {
+			finalErr = errs.BadRequest("当前房间不是牛牛游戏")
+			return finalErr
+		}
+
+		playerInfo, ok := gameState.Players[userID]
+		if !ok {
+			finalErr = errs.BadRequest("玩家不在游戏中")
+			return finalErr
+		}
+
+		if playerInfo.IsBanker {
+			finalErr = errs.BadRequest("庄家不能选择押注倍数")
+			return finalErr
+		}
+
+		if playerInfo.IsFinished {
+			finalErr = errs.BadRequest("已亮牌，押注倍数已锁定")
+			return finalErr
+		}
+
+		playerInfo.BetMultiplier = multiplier
+		gameState.Seq++
+
+		if err := m.stateStorage.Save(ctx, gameState, 2*time.Hour); err != nil {
+			finalErr = fmt.Errorf("保存游戏状态失败: %w", err)
+			return finalErr
+		}
+
+		finalState = gameState
+		return nil
+	})
+
+	if finalErr != nil {
+		return nil, finalErr
+	}
+	if err != nil {
+		return nil, lock.FriendlyError(err)
+	}
 	return finalState, nil
 }
 
@@ -378,22 +593,22 @@ func (m *Manager) Pass(ctx context.Context, roomID string, userID uint) (*models
 
 	// 检查是否轮到自己
 	if gameState.CurrentPlayer != userID {
-		return nil, errors.New("还没轮到你出牌")
+		return nil, errs.BadRequest("还没轮到你出牌")
 	}
 
 	// 检查玩家信息
 	playerInfo, ok := gameState.Players[userID]
 	if !ok {
-		return nil, errors.New("玩家不在游戏中")
+		return nil, errs.BadRequest("玩家不在游戏中")
 	}
 
 	if playerInfo.IsFinished {
-		return nil, errors.New("你已经出完牌了")
+		return nil, errs.BadRequest("你已经出完牌了")
 	}
 
 	// 检查是否可以过（必须有人出过牌）
 	if len(gameState.LastCards) == 0 {
-		return nil, errors.New("第一手牌不能过")
+		return nil, errs.BadRequest("第一手牌不能过")
 	}
 
 	// 标记已过
@@ -403,21 +618,131 @@ func (m *Manager) Pass(ctx context.Context, roomID string, userID uint) (*models
 	// 设置下一个出牌玩家
 	gameState.CurrentPlayer = m.getNextPlayer(gameState, userID)
 
-	// 检查是否所有人都过了（新一轮）
-	if gameState.PassCount >= m.getActivePlayerCount(gameState) {
+	// 检查是否新一轮：除持有牌权的玩家外，其余活跃玩家都已过牌。
+	// 牌权玩家刚打出最后一手牌（已完成）时，则需要所有活跃玩家都过
+	requiredPasses := m.getActivePlayerCount(gameState)
+	if lastPlayerInfo, ok := gameState.Players[gameState.LastPlayer]; ok && !lastPlayerInfo.IsFinished {
+		requiredPasses-- // 牌权玩家自己不需要过
+	}
+	if gameState.PassCount >= requiredPasses {
 		gameState.LastCards = nil
 		gameState.LastPlayer = 0
 		gameState.PassCount = 0
 	}
 
+	gameState.Seq++
+
 	// ✅ 通过 Storage 保存游戏状态
 	if err := m.stateStorage.Save(ctx, gameState, 2*time.Hour); err != nil {
 		return nil, fmt.Errorf("保存游戏状态失败: %w", err)
 	}
 
+	// 过牌成功：停止该玩家的倒计时，并为下一位出牌玩家重新计时
+	m.notifyTimerStop(ctx, roomID, userID)
+	m.notifyTimerStart(ctx, roomID, gameState)
+
 	return gameState, nil
 }
 
+// Surrender 认输（仅跑得快）：玩家提前放弃本局，按当前最差的可用名次
+// 完成；轮到自己时把出牌权交给下一位玩家，只剩一名活跃玩家时触发
+// 结束检查并结算。占着牌权必须出牌时（首出或一圈无人压过）不允许认输。
+func (m *Manager) Surrender(ctx context.Context, roomID string, userID uint) (*models.GameState, error) {
+	// ✅ 与出牌共用同一把锁：认输可能触发结算
+	lockKey := fmt.Sprintf("game:%s:play", roomID)
+
+	var finalState *models.GameState
+	var finalErr error
+
+	err := m.distLock.WithLock(ctx, lockKey, lock.DefaultTTL(), func() error {
+		// ✅ 在锁保护下获取游戏状态
+		gameState, err := m.stateStorage.Get(ctx, roomID)
+		if err != nil {
+			finalErr = err
+			return finalErr
+		}
+
+		if gameState.GameType != "running" {
+			finalErr = errs.BadRequest("当前游戏不支持认输")
+			return finalErr
+		}
+
+		playerInfo, ok := gameState.Players[userID]
+		if !ok {
+			finalErr = errs.BadRequest("玩家不在游戏中")
+			return finalErr
+		}
+
+		if playerInfo.IsFinished {
+			finalErr = errs.BadRequest("你已经出完牌了")
+			return finalErr
+		}
+
+		// 占着牌权必须出牌时不能认输（首出、或一圈无人压过轮回自己）
+		if gameState.LastPlayer == userID ||
+			(gameState.CurrentPlayer == userID && len(gameState.LastCards) == 0) {
+			finalErr = errs.BadRequest("现在必须由你出牌，不能认输")
+			return finalErr
+		}
+
+		// 认输按当前最差的可用名次完成
+		playerInfo.Rank = m.worstAvailableRank(gameState)
+		playerInfo.IsFinished = true
+		playerInfo.IsPassed = false
+		playerInfo.Cards = nil
+		playerInfo.CardCount = 0
+
+		// 轮到自己时把出牌权交给下一位玩家
+		if gameState.CurrentPlayer == userID {
+			gameState.CurrentPlayer = m.getNextPlayer(gameState, userID)
+		}
+
+		// 活跃人数减少后本圈可能已经全过，清空桌面开新一圈
+		if gameState.PassCount >= m.getActivePlayerCount(gameState) {
+			gameState.LastCards = nil
+			gameState.LastPlayer = 0
+			gameState.PassCount = 0
+		}
+
+		gameState.Seq++
+
+		// ✅ 通过 Storage 保存游戏状态
+		if err := m.stateStorage.Save(ctx, gameState, 2*time.Hour); err != nil {
+			finalErr = fmt.Errorf("保存游戏状态失败: %w", err)
+			return finalErr
+		}
+
+		// 只剩一名活跃玩家时游戏结束，进行结算
+		isEnded, endedGameState := m.checkGameEnd(ctx, roomID, gameState)
+		if isEnded {
+			if _, err := m.SettleGame(ctx, roomID, endedGameState); err != nil {
+				// 结算失败，记录日志但返回游戏状态
+				finalState = endedGameState
+				return nil
+			}
+			finalState = endedGameState
+			return nil
+		}
+
+		finalState = gameState
+		return nil
+	})
+
+	if err != nil {
+		if finalErr != nil {
+			return nil, finalErr
+		}
+		// 锁获取失败：区分竞争超时与系统错误
+		return nil, lock.FriendlyError(err)
+	}
+
+	// 操作成功：停止该玩家的倒计时，并为下一位出牌玩家重新计时
+	m.notifyTimerStop(ctx, roomID, userID)
+	m.notifyTimerStart(ctx, roomID, finalState)
+
+	return finalState, nil
+}
+
 // CheckGameEnd 检查游戏是否结束（重构版本）
 func (m *Manager) CheckGameEnd(ctx context.Context, roomID string) (bool, *models.GameState) {
 	gameState, err := m.stateStorage.Get(ctx, roomID)
@@ -433,68 +758,153 @@ func (m *Manager) SettleGame(ctx context.Context, roomID string, gameState *mode
 	// ✅ 通过 Repository 获取房间信息
 	room, err := m.gameRecordRepo.GetRoomByRoomID(ctx, roomID)
 	if err != nil {
-		return nil, errors.New("房间不存在")
+		return nil, errs.NotFound("房间不存在")
 	}
 
-	// ✅ 业务逻辑：计算结算结果
-	settlement := m.calculateSettlement(gameState, room.BaseBet)
+	// 获取游戏引擎
+	engine, err := m.getEngine(gameState.GameType)
+	if err != nil {
+		return nil, err
+	}
 
-	// 准备批量更新余额的数据
-	balanceUpdates := make(map[uint]float64)
-	for userID, playerSettlement := range settlement.Players {
-		// ✅ 通过 Repository 获取当前余额
-		user, err := m.userRepo.GetByID(ctx, userID)
+	// ✅ 业务逻辑：由引擎计算结算结果（跑得快按名次、牛牛庄闲对赌），
+	// 赔付规则归引擎所有，Manager只负责通用流程
+	balances := engine.Settle(gameState, room.BaseBet)
+	settlement := &GameSettlement{
+		RoomID:  roomID,
+		Players: make(map[uint]*PlayerSettlement),
+	}
+	for userID, balance := range balances {
+		rank := 0
+		if p, ok := gameState.Players[userID]; ok {
+			rank = p.Rank
+		}
+		settlement.Players[userID] = &PlayerSettlement{
+			UserID:  userID,
+			Rank:    rank,
+			Balance: balance,
+		}
+	}
+
+	// ✅ 执行通用结算流程（余额落库、对局记录、排行榜、交易流水）
+	return m.executeSettlement(ctx, roomID, room, gameState, settlement)
+}
+
+// ForceEndGame 强制结束游戏（运营工具：游戏卡死/玩家全部掉线时使用）。
+// void 为 false 时按当前牌面正常结算；为 true 时作废本局（不产生输赢、
+// 不写对局记录，余额在结算前未扣减，作废即视为退还）。
+// 返回结算结果（作废时为 nil）。
+func (m *Manager) ForceEndGame(ctx context.Context, roomID string, void bool) (*GameSettlement, error) {
+	// ✅ 与出牌共用同一把锁，避免和进行中的操作并发结算
+	lockKey := fmt.Sprintf("game:%s:play", roomID)
+
+	var settlement *GameSettlement
+	var finalErr error
+
+	err := m.distLock.WithLock(ctx, lockKey, lock.DefaultTTL(), func() error {
+		// ✅ 通过 Repository 获取房间信息
+		room, err := m.roomRepo.GetByRoomID(ctx, roomID)
 		if err != nil {
-			return nil, fmt.Errorf("用户不存在: %d", userID)
+			finalErr = errs.NotFound("房间不存在")
+			return finalErr
 		}
 
-		// 计算新余额
-		newBalance := user.Balance + playerSettlement.Balance
-		if newBalance < 0 {
-			newBalance = 0
+		// 防止重复结算：房间已结束说明本局已经结算或作废过
+		if room.Status == 3 {
+			finalErr = errs.BadRequest("游戏已结束，无法重复强制结束")
+			return finalErr
+		}
+		if room.Status != 2 {
+			finalErr = errs.BadRequest("房间不在游戏中")
+			return finalErr
 		}
 
-		balanceUpdates[userID] = newBalance
-		playerSettlement.FinalBalance = newBalance
-	}
+		if void {
+			// 作废：只更新房间状态并清理游戏状态，不动余额
+			room.Status = 3
+			if err := m.roomRepo.Update(ctx, room); err != nil {
+				finalErr = fmt.Errorf("更新房间状态失败: %w", err)
+				return finalErr
+			}
+			if err := m.stateStorage.Delete(ctx, roomID); err != nil {
+				logger.Logger.Warn("清理游戏状态失败",
+					zap.String("room_id", roomID),
+					zap.Error(err),
+				)
+			}
+			return nil
+		}
 
-	// ✅ 通过 Repository 批量更新余额（使用事务）
-	if err := m.userRepo.BatchUpdateBalances(ctx, balanceUpdates); err != nil {
-		return nil, fmt.Errorf("更新用户余额失败: %w", err)
-	}
+		// 正常结算需要游戏状态
+		gameState, err := m.stateStorage.Get(ctx, roomID)
+		if err != nil {
+			finalErr = errs.NotFound("游戏状态不存在，只能作废本局")
+			return finalErr
+		}
 
-	// ✅ 保存游戏记录
-	now := time.Now().Unix()
-	startTime := gameState.StartTime
-	if startTime == 0 {
-		startTime = now - 300
-	}
+		gameState.Status = 3
+		settlement, err = m.SettleGame(ctx, roomID, gameState)
+		if err != nil {
+			finalErr = err
+			return finalErr
+		}
 
-	gameRecord, err := m.saveGameRecord(ctx, roomID, room.GameType, gameState, settlement, startTime, now)
+		// 结算完成后清理游戏状态，避免残留状态被继续操作
+		if err := m.stateStorage.Delete(ctx, roomID); err != nil {
+			logger.Logger.Warn("清理游戏状态失败",
+				zap.String("room_id", roomID),
+				zap.Error(err),
+			)
+		}
+		return nil
+	})
+
+	if finalErr != nil {
+		return nil, finalErr
+	}
 	if err != nil {
-		return nil, fmt.Errorf("保存游戏记录失败: %w", err)
+		return nil, lock.FriendlyError(err)
 	}
+	return settlement, nil
+}
 
-	// ✅ 保存玩家对局记录
-	if err := m.saveGamePlayers(ctx, roomID, gameState, settlement); err != nil {
-		return nil, fmt.Errorf("保存玩家记录失败: %w", err)
+// recordSettlementTransactions 将结算的余额变动写入交易流水（game_win/game_loss）。
+// 记账失败不影响结算结果，只记录日志。
+func (m *Manager) recordSettlementTransactions(ctx context.Context, roomID string, recordID uint, settlement *GameSettlement) {
+	if m.transactionRepo == nil {
+		return
 	}
 
-	// ✅ 通过 Repository 更新房间状态为已结束
-	room.Status = 3
-	if err := m.roomRepo.Update(ctx, room); err != nil {
-		return nil, fmt.Errorf("更新房间状态失败: %w", err)
-	}
+	for userID, playerSettlement := range settlement.Players {
+		if playerSettlement.Balance == 0 {
+			continue
+		}
 
-	// ✅ 通过 Service 更新排行榜
-	scores := make(map[uint]float64, len(settlement.Players))
-	for userID, info := range settlement.Players {
-		scores[userID] = info.Balance
-	}
-	_ = m.leaderboardSvc.UpdateLeaderboard(ctx, room.GameType, scores)
+		txType := "game_win"
+		amount := playerSettlement.Balance
+		if amount < 0 {
+			txType = "game_loss"
+			amount = -amount
+		}
 
-	settlement.RecordID = gameRecord.ID
-	return settlement, nil
+		transaction := &models.Transaction{
+			// 对局记录ID+用户ID组成订单号，重复结算时唯一键冲突自然去重
+			OrderID: fmt.Sprintf("G%d-%d", recordID, userID),
+			UserID:  userID,
+			Type:    txType,
+			Amount:  amount,
+			Status:  2,
+			Channel: "game",
+			Remark:  fmt.Sprintf("游戏结算 - 房间%s", roomID),
+		}
+		if err := m.transactionRepo.Create(ctx, transaction); err != nil {
+			logger.Logger.Warn("写入游戏结算流水失败",
+				zap.Uint("user_id", userID),
+				zap.String("order_id", transaction.OrderID),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 // ==================== 私有辅助方法 ====================
@@ -507,157 +917,159 @@ func (m *Manager) getEngine(gameType string) (services.GameEngine, error) {
 	return engine, nil
 }
 
-func (m *Manager) startRunningFastGame(roomID string, players []services.PlayerInfo) (*models.GameState, error) {
-	playerCount := len(players)
-
-	engine, err := m.getEngine("running")
-	if err != nil {
-		return nil, err
-	}
-
-	// 发牌
-	hands, err := engine.DealCards(playerCount)
-	if err != nil {
-		return nil, err
+// PlayerRange 获取指定游戏类型支持的玩家数量范围
+// 未注册引擎的游戏类型返回ok=false，由调用方使用通用限制
+func (m *Manager) PlayerRange(gameType string) (min, max int, ok bool) {
+	engine, exists := m.engines[gameType]
+	if !exists {
+		return 0, 0, false
 	}
+	min, max = engine.PlayerRange()
+	return min, max, true
+}
 
-	// 创建游戏状态
-	now := time.Now().Unix()
-	gameState := &models.GameState{
-		RoomID:        roomID,
-		GameType:      "running",
-		Status:        1,
-		Round:         1,
-		CurrentPlayer: 0,
-		Players:       make(map[uint]*models.PlayerGameInfo),
-		StartTime:     now,
+// expectedDeck 返回指定游戏类型的整副牌集合（用于发牌校验）
+func expectedDeck(gameType string) map[int]bool {
+	deck := make(map[int]bool, 52)
+	switch gameType {
+	case "running":
+		// 与 RunningFastGame.DealCards 的构牌逻辑一致：花色*100+牌值（3到2），
+		// 带王变体额外允许大小王
+		for suit := 0; suit < 4; suit++ {
+			for value := 3; value <= 15; value++ {
+				deck[suit*100+value] = true
+			}
+		}
+		deck[models.CardJoker] = true
+		deck[models.CardKing] = true
+	case "bull":
+		// 与 BullGame.DealCards 一致：花色*100+点数（A到K）
+		for suit := 0; suit < 4; suit++ {
+			for rank := 1; rank <= 13; rank++ {
+				deck[suit*100+rank] = true
+			}
+		}
 	}
+	return deck
+}
 
-	// 初始化玩家游戏信息
-	firstPlayer := uint(0)
-	minCard := 999
-
-	for i, player := range players {
-		playerID := player.UserID
-		cards := hands[uint(i+1)]
+// validateDealtHands 校验发牌结果：所有玩家的手牌互不重叠，且每张牌都属于
+// 该游戏类型的整副牌。suit*100+value编码或洗牌实现出bug时可能静默发出重复牌，
+// 带着坏牌面开局会导致结算争议，因此校验失败直接报错终止。
+func validateDealtHands(gameType string, hands map[uint][]int) error {
+	deck := expectedDeck(gameType)
+	seen := make(map[int]uint, len(deck))
 
-		// 查找手牌中最小的牌（确定首出玩家）
+	for playerID, cards := range hands {
 		for _, card := range cards {
-			val := models.GetCardValue(card)
-			if val < minCard && val != models.CardJoker && val != models.CardKing {
-				minCard = val
-				firstPlayer = playerID
+			if !deck[card] {
+				return fmt.Errorf("发牌校验失败: 玩家%d持有非法牌%d", playerID, card)
 			}
-		}
-
-		gameState.Players[playerID] = &models.PlayerGameInfo{
-			UserID:     playerID,
-			Position:   player.Position,
-			Cards:      cards,
-			CardCount:  len(cards),
-			IsPassed:   false,
-			IsFinished: false,
-			Rank:       0,
+			if holder, dup := seen[card]; dup {
+				return fmt.Errorf("发牌校验失败: 牌%d同时发给了玩家%d和玩家%d", card, holder, playerID)
+			}
+			seen[card] = playerID
 		}
 	}
 
-	if firstPlayer == 0 && len(players) > 0 {
-		firstPlayer = players[0].UserID
-	}
-	gameState.CurrentPlayer = firstPlayer
-
-	return gameState, nil
+	return nil
 }
 
-func (m *Manager) startBullGame(roomID string, players []services.PlayerInfo, bullGame *services.BullGame) (*models.GameState, error) {
+// createGameState 发牌并构建基础游戏状态（各游戏通用）。
+// 发牌结果先经过整副牌校验；首出玩家、庄家等游戏特有初始化由引擎的
+// Setup钩子完成，未实现钩子时默认位置最靠前的玩家先出。
+func (m *Manager) createGameState(ctx context.Context, room *models.GameRoom, players []services.PlayerInfo, engine services.GameEngine) (*models.GameState, error) {
+	roomID := room.RoomID
+	gameType := room.GameType
 	playerCount := len(players)
 
-	// 发牌（每人5张）
-	hands, err := bullGame.DealCards(playerCount)
+	// 发牌
+	hands, err := engine.DealCards(playerCount)
 	if err != nil {
 		return nil, err
 	}
 
+	// 发牌校验：手牌互不重叠且全部来自整副牌，异常直接终止开局
+	if err := validateDealtHands(gameType, hands); err != nil {
+		return nil, err
+	}
+
 	// 创建游戏状态
 	now := time.Now().Unix()
 	gameState := &models.GameState{
-		RoomID:        roomID,
-		GameType:      "bull",
-		Status:        1,
-		Round:         1,
-		CurrentPlayer: 0,
-		Players:       make(map[uint]*models.PlayerGameInfo),
-		StartTime:     now,
+		RoomID:          roomID,
+		GameType:        gameType,
+		FirstPlayerRule: room.FirstPlayerRule,
+		Status:          1,
+		Round:           1,
+		CurrentPlayer:   0,
+		Players:         make(map[uint]*models.PlayerGameInfo),
+		StartTime:       now,
 	}
 
 	// 初始化玩家游戏信息
 	for i, player := range players {
-		playerID := player.UserID
 		cards := hands[uint(i+1)]
-
-		playerInfo := &models.PlayerGameInfo{
-			UserID:     playerID,
+		gameState.Players[player.UserID] = &models.PlayerGameInfo{
+			UserID:     player.UserID,
 			Position:   player.Position,
 			Cards:      cards,
 			CardCount:  len(cards),
-			IsFinished: false,
 			IsPassed:   false,
+			IsFinished: false,
 			Rank:       0,
 		}
+	}
 
-		gameState.Players[playerID] = playerInfo
+	// 游戏特有初始化（跑得快定首出玩家、牛牛定庄家等）
+	if setup, ok := engine.(services.GameSetup); ok {
+		if err := setup.Setup(gameState); err != nil {
+			return nil, err
+		}
+	}
 
-		// 找出牛最大的玩家作为庄家
-		if gameState.CurrentPlayer == 0 {
-			gameState.CurrentPlayer = playerID
-		} else {
-			currentCards := gameState.Players[gameState.CurrentPlayer].Cards
-			if bullGame.CompareBull(cards, currentCards) > 0 {
-				gameState.CurrentPlayer = playerID
+	// previous_winner：上一局赢家先出（有记录且赢家还在本局时覆盖）
+	if gameType == "running" && room.FirstPlayerRule == services.FirstPlayerRulePreviousWinner {
+		if winner := m.previousWinner(ctx, roomID); winner != 0 {
+			if _, ok := gameState.Players[winner]; ok {
+				gameState.CurrentPlayer = winner
 			}
 		}
 	}
 
+	// 兜底：钩子未指定首出玩家时，位置最靠前的玩家先出
+	if gameState.CurrentPlayer == 0 && len(players) > 0 {
+		gameState.CurrentPlayer = players[0].UserID
+	}
+
 	return gameState, nil
 }
 
-func (m *Manager) calculateSettlement(gameState *models.GameState, baseBet float64) *GameSettlement {
-	settlement := &GameSettlement{
-		RoomID:  gameState.RoomID,
-		Players: make(map[uint]*PlayerSettlement),
+// previousWinner 读取房间最近一局记录的第一名（没有记录或解析失败时返回0）
+func (m *Manager) previousWinner(ctx context.Context, roomID string) uint {
+	records, err := m.gameRecordRepo.ListRecordsByRoom(ctx, roomID)
+	if err != nil || len(records) == 0 {
+		return 0
 	}
+	// ListRecordsByRoom按创建时间倒序，第一条即最近一局
+	return winnerFromRecord(&records[0])
+}
 
-	// 获取所有玩家的名次
-	rankedPlayers := make([]*models.PlayerGameInfo, 0, len(gameState.Players))
-	for _, playerInfo := range gameState.Players {
-		rankedPlayers = append(rankedPlayers, playerInfo)
+// winnerFromRecord 从对局记录的结算结果JSON中解析第一名的用户ID
+func winnerFromRecord(record *models.GameRecord) uint {
+	var result map[string]struct {
+		UserID uint `json:"user_id"`
+		Rank   int  `json:"rank"`
 	}
-
-	// 按名次排序
-	sort.Slice(rankedPlayers, func(i, j int) bool {
-		return rankedPlayers[i].Rank < rankedPlayers[j].Rank
-	})
-
-	// 计算每个玩家的输赢
-	playerCount := len(rankedPlayers)
-	for i, playerInfo := range rankedPlayers {
-		rank := i + 1
-		var balance float64
-
-		if rank == 1 {
-			balance = float64(playerCount-1) * baseBet
-		} else {
-			balance = -float64(rank-1) * baseBet
-		}
-
-		settlement.Players[playerInfo.UserID] = &PlayerSettlement{
-			UserID:  playerInfo.UserID,
-			Rank:    rank,
-			Balance: balance,
+	if err := json.Unmarshal(record.Result, &result); err != nil {
+		return 0
+	}
+	for _, info := range result {
+		if info.Rank == 1 {
+			return info.UserID
 		}
 	}
-
-	return settlement
+	return 0
 }
 
 func (m *Manager) saveGameRecord(ctx context.Context, roomID, gameType string, gameState *models.GameState, settlement *GameSettlement, startTime, endTime int64) (*models.GameRecord, error) {
@@ -701,9 +1113,43 @@ func (m *Manager) saveGameRecord(ctx context.Context, roomID, gameType string, g
 		return nil, err
 	}
 
+	// 异步写入 Elasticsearch 供分析查询（尽力而为，失败不影响结算）
+	go indexGameRecord(gameRecord, playersData)
+
 	return gameRecord, nil
 }
 
+// indexGameRecord 将对局记录写入 Elasticsearch（game-records-YYYY.MM.DD 索引）。
+// 以记录ID作为文档ID，重试或重复调用不会产生重复文档。
+// ES 未初始化或写入失败只记日志，不影响结算主流程。
+func indexGameRecord(record *models.GameRecord, playersData []map[string]interface{}) {
+	var resultData map[string]interface{}
+	if len(record.Result) > 0 {
+		_ = json.Unmarshal(record.Result, &resultData)
+	}
+
+	doc := map[string]interface{}{
+		"id":           record.ID,
+		"room_id":      record.RoomID,
+		"game_type":    record.GameType,
+		"players":      playersData,
+		"player_count": len(playersData),
+		"result":       resultData,
+		"start_time":   record.StartTime,
+		"end_time":     record.EndTime,
+		"duration":     record.Duration,
+		"rake":         0.0, // 当前玩法不抽水，字段预留给分析口径
+		"created_at":   record.CreatedAt,
+		"@timestamp":   time.Unix(record.EndTime, 0).Format(time.RFC3339),
+	}
+
+	if err := esClient.IndexGameRecord(fmt.Sprintf("%d", record.ID), doc); err != nil {
+		logger.Logger.Warn("游戏记录写入 Elasticsearch 失败",
+			zap.Uint("record_id", record.ID),
+			zap.Error(err))
+	}
+}
+
 func (m *Manager) saveGamePlayers(ctx context.Context, roomID string, gameState *models.GameState, settlement *GameSettlement) error {
 	players := make([]*models.GamePlayer, 0, len(gameState.Players))
 
@@ -750,6 +1196,7 @@ func (m *Manager) checkGameEnd(ctx context.Context, roomID string, gameState *mo
 		}
 
 		gameState.Status = 3 // 已结束
+		gameState.Seq++
 		_ = m.stateStorage.Save(ctx, gameState, 2*time.Hour)
 		return true, gameState
 	}
@@ -757,108 +1204,6 @@ func (m *Manager) checkGameEnd(ctx context.Context, roomID string, gameState *mo
 	return false, gameState
 }
 
-// settleBullGame 结算牛牛游戏
-func (m *Manager) settleBullGame(ctx context.Context, roomID string, gameState *models.GameState, bullGame *services.BullGame) (*GameSettlement, error) {
-	// ✅ 通过 Repository 获取房间信息
-	room, err := m.gameRecordRepo.GetRoomByRoomID(ctx, roomID)
-	if err != nil {
-		return nil, fmt.Errorf("房间不存在: %w", err)
-	}
-
-	// 收集所有玩家的牛牛结果
-	type PlayerBull struct {
-		UserID      uint
-		PlayerInfo  *models.PlayerGameInfo
-		PlayedCards []int
-		BullType    int
-		BullNum     int
-		MaxCard     int
-	}
-
-	playerBulls := make([]PlayerBull, 0)
-	for userID, playerInfo := range gameState.Players {
-		// 如果玩家还没有出牌，使用原始手牌计算
-		var cards []int
-		if len(playerInfo.PlayedCards) > 0 {
-			cards = playerInfo.PlayedCards
-		} else if len(playerInfo.Cards) > 0 {
-			cards = playerInfo.Cards
-		} else {
-			continue
-		}
-
-		// 计算或使用已存储的牛牛结果
-		bullType := playerInfo.BullType
-		bullNum := playerInfo.BullNum
-		maxCard := playerInfo.MaxCard
-
-		// 如果还没有计算，重新计算
-		if bullType == 0 && bullNum == 0 && maxCard == 0 {
-			bullType, bullNum, maxCard = bullGame.CalculateBull(cards)
-			playerInfo.BullType = bullType
-			playerInfo.BullNum = bullNum
-			playerInfo.MaxCard = maxCard
-			playerInfo.PlayedCards = cards
-		}
-
-		playerBulls = append(playerBulls, PlayerBull{
-			UserID:      userID,
-			PlayerInfo:  playerInfo,
-			PlayedCards: cards,
-			BullType:    bullType,
-			BullNum:     bullNum,
-			MaxCard:     maxCard,
-		})
-	}
-
-	// 按照牛牛类型和牛数排序（从大到小）
-	sort.Slice(playerBulls, func(i, j int) bool {
-		if playerBulls[i].BullType != playerBulls[j].BullType {
-			return playerBulls[i].BullType > playerBulls[j].BullType
-		}
-		if playerBulls[i].BullNum != playerBulls[j].BullNum {
-			return playerBulls[i].BullNum > playerBulls[j].BullNum
-		}
-		return playerBulls[i].MaxCard > playerBulls[j].MaxCard
-	})
-
-	// 分配名次
-	for i, pb := range playerBulls {
-		pb.PlayerInfo.Rank = i + 1
-		gameState.Players[pb.UserID] = pb.PlayerInfo
-	}
-
-	// 计算结算结果
-	settlement := &GameSettlement{
-		RoomID:  roomID,
-		Players: make(map[uint]*PlayerSettlement),
-	}
-
-	// 牛牛规则：第一名获得所有玩家的底注，其他人扣除底注
-	playerCount := len(playerBulls)
-	baseBet := room.BaseBet
-
-	for _, pb := range playerBulls {
-		rank := pb.PlayerInfo.Rank
-		var balance float64
-
-		if rank == 1 {
-			balance = float64(playerCount-1) * baseBet
-		} else {
-			balance = -baseBet
-		}
-
-		settlement.Players[pb.UserID] = &PlayerSettlement{
-			UserID:  pb.UserID,
-			Rank:    rank,
-			Balance: balance,
-		}
-	}
-
-	// 执行通用结算流程
-	return m.executeSettlement(ctx, roomID, room, gameState, settlement)
-}
-
 // executeSettlement 执行结算流程（通用方法）
 func (m *Manager) executeSettlement(ctx context.Context, roomID string, room *models.GameRoom, gameState *models.GameState, settlement *GameSettlement) (*GameSettlement, error) {
 	// 准备批量更新余额的数据
@@ -913,6 +1258,9 @@ func (m *Manager) executeSettlement(ctx context.Context, roomID string, room *mo
 	}
 	_ = m.leaderboardSvc.UpdateLeaderboard(ctx, room.GameType, scores)
 
+	// ✅ 结算余额变动写入交易流水（供用户账单查询）
+	m.recordSettlementTransactions(ctx, roomID, gameRecord.ID, settlement)
+
 	settlement.RecordID = gameRecord.ID
 	return settlement, nil
 }
@@ -999,15 +1347,34 @@ func (m *Manager) getActivePlayerCount(gameState *models.GameState) int {
 	return count
 }
 
-// calculateRank 计算玩家名次
+// calculateRank 计算玩家名次（当前最好的未被占用名次。
+// 认输玩家直接占用末位名次，所以不能简单按已完成人数+1）
 func (m *Manager) calculateRank(gameState *models.GameState) int {
+	taken := rankTaken(gameState)
 	rank := 1
+	for taken[rank] {
+		rank++
+	}
+	return rank
+}
+
+// worstAvailableRank 计算当前最差的未被占用名次（认输玩家用）
+func (m *Manager) worstAvailableRank(gameState *models.GameState) int {
+	taken := rankTaken(gameState)
+	rank := len(gameState.Players)
+	for rank > 1 && taken[rank] {
+		rank--
+	}
+	return rank
+}
+
+// rankTaken 已被完成玩家占用的名次集合
+func rankTaken(gameState *models.GameState) map[int]bool {
+	taken := make(map[int]bool, len(gameState.Players))
 	for _, playerInfo := range gameState.Players {
 		if playerInfo.IsFinished && playerInfo.Rank > 0 {
-			if playerInfo.Rank >= rank {
-				rank = playerInfo.Rank + 1
-			}
+			taken[playerInfo.Rank] = true
 		}
 	}
-	return rank
+	return taken
 }
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// TestDeterministicGameEndToEnd 用内存依赖打完一整局跑得快：
+// 开局→脚本化出牌→结算，全程不依赖外部服务
+func TestDeterministicGameEndToEnd(t *testing.T) {
+	const (
+		roomID  = "RE2E0001"
+		baseBet = 10.0
+		initial = 1000.0
+	)
+	ctx := context.Background()
+
+	kit := newTestManagerKit(42)
+	kit.seedGameRoom(t, roomID, "running", baseBet, initial, []uint{1, 2})
+
+	gameState, err := kit.manager.StartGame(ctx, roomID)
+	if err != nil {
+		t.Fatalf("开始游戏失败: %v", err)
+	}
+	if gameState.Status != 1 || gameState.CurrentPlayer == 0 {
+		t.Fatalf("初始游戏状态不符: status=%d current=%d", gameState.Status, gameState.CurrentPlayer)
+	}
+	if room, _ := kit.roomRepo.GetByRoomID(ctx, roomID); room.Status != 2 {
+		t.Fatalf("开局后房间状态 = %d, 期望 2", room.Status)
+	}
+
+	// 脚本化打完整局
+	finalState := kit.playOut(t, roomID, 500)
+
+	// 所有玩家都有名次
+	ranks := make(map[int]uint)
+	for userID, playerInfo := range finalState.Players {
+		if playerInfo.Rank < 1 || playerInfo.Rank > 2 {
+			t.Fatalf("玩家%d名次非法: %d", userID, playerInfo.Rank)
+		}
+		ranks[playerInfo.Rank] = userID
+	}
+	winner, loser := ranks[1], ranks[2]
+	if winner == 0 || loser == 0 {
+		t.Fatalf("名次不完整: %v", ranks)
+	}
+
+	// 结算：2人局赢家+baseBet、输家-baseBet，余额总和不变
+	winnerUser, _ := kit.userRepo.GetByID(ctx, winner)
+	loserUser, _ := kit.userRepo.GetByID(ctx, loser)
+	if winnerUser.Balance != initial+baseBet {
+		t.Errorf("赢家余额 = %.2f, 期望 %.2f", winnerUser.Balance, initial+baseBet)
+	}
+	if loserUser.Balance != initial-baseBet {
+		t.Errorf("输家余额 = %.2f, 期望 %.2f", loserUser.Balance, initial-baseBet)
+	}
+
+	// 房间收尾 + 对局记录落库
+	if room, _ := kit.roomRepo.GetByRoomID(ctx, roomID); room.Status != 3 {
+		t.Errorf("结算后房间状态 = %d, 期望 3", room.Status)
+	}
+	if len(kit.recordRepo.records) != 1 {
+		t.Fatalf("对局记录数 = %d, 期望 1", len(kit.recordRepo.records))
+	}
+	if len(kit.recordRepo.players) != 2 {
+		t.Errorf("玩家对局记录数 = %d, 期望 2", len(kit.recordRepo.players))
+	}
+
+	// 交易流水：赢家game_win、输家game_loss各一条
+	txTypes := make(map[uint]string)
+	for _, tx := range kit.txRepo.transactions {
+		txTypes[tx.UserID] = tx.Type
+	}
+	if txTypes[winner] != "game_win" || txTypes[loser] != "game_loss" {
+		t.Errorf("结算流水类型不符: %v", txTypes)
+	}
+
+	// 排行榜上报的分数与输赢一致
+	if kit.leaderboard.scores[winner] != baseBet || kit.leaderboard.scores[loser] != -baseBet {
+		t.Errorf("排行榜分数不符: %v", kit.leaderboard.scores)
+	}
+}
+
+// TestDeterministicDealRepeatable 相同seed下两次开局的发牌完全一致
+func TestDeterministicDealRepeatable(t *testing.T) {
+	ctx := context.Background()
+
+	deal := func(seed int64) string {
+		kit := newTestManagerKit(seed)
+		kit.seedGameRoom(t, "RSEED001", "running", 10, 1000, []uint{1, 2, 3})
+		gameState, err := kit.manager.StartGame(ctx, "RSEED001")
+		if err != nil {
+			t.Fatalf("开始游戏失败: %v", err)
+		}
+		hands, _ := json.Marshal(gameState.Players)
+		return string(hands)
+	}
+
+	if deal(7) != deal(7) {
+		t.Error("相同seed的发牌结果应完全一致")
+	}
+	if deal(7) == deal(8) {
+		t.Error("不同seed的发牌结果不应一致")
+	}
+}
+
+// TestForceEndGameWithKit 内存环境下强制结束：作废不动余额，游戏状态被清理
+func TestForceEndGameWithKit(t *testing.T) {
+	const roomID = "RFORCE01"
+	ctx := context.Background()
+
+	kit := newTestManagerKit(42)
+	kit.seedGameRoom(t, roomID, "running", 10, 1000, []uint{1, 2})
+	if _, err := kit.manager.StartGame(ctx, roomID); err != nil {
+		t.Fatalf("开始游戏失败: %v", err)
+	}
+
+	settlement, err := kit.manager.ForceEndGame(ctx, roomID, true)
+	if err != nil {
+		t.Fatalf("作废本局失败: %v", err)
+	}
+	if settlement != nil {
+		t.Error("作废时不应产生结算结果")
+	}
+
+	for _, userID := range []uint{1, 2} {
+		user, _ := kit.userRepo.GetByID(ctx, userID)
+		if user.Balance != 1000 {
+			t.Errorf("作废后玩家%d余额 = %.2f, 期望不变", userID, user.Balance)
+		}
+	}
+	if exists, _ := kit.storage.Exists(ctx, roomID); exists {
+		t.Error("作废后游戏状态应被清理")
+	}
+	if room, _ := kit.roomRepo.GetByRoomID(ctx, roomID); room.Status != 3 {
+		t.Errorf("作废后房间状态 = %d, 期望 3", room.Status)
+	}
+}
@@ -0,0 +1,492 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/kaifa/game-platform/pkg/services"
+)
+
+// TestValidateDealtHandsRunning 跑得快引擎在2/3人默认17张、4人13张时的发牌都应通过校验
+// （4人×17张超出52张牌组，需要显式配置每人牌数）
+func TestValidateDealtHandsRunning(t *testing.T) {
+	for playerCount := 2; playerCount <= 4; playerCount++ {
+		engine := services.NewRunningFastGameWithShuffler(services.NewSeededShuffler(1))
+		if playerCount == 4 {
+			engine = services.NewRunningFastGameWithConfig(services.NewSeededShuffler(1), services.RunningFastConfig{CardsPerPlayer: 13})
+		}
+
+		hands, err := engine.DealCards(playerCount)
+		if err != nil {
+			t.Fatalf("%d人发牌失败: %v", playerCount, err)
+		}
+		if err := validateDealtHands("running", hands); err != nil {
+			t.Errorf("%d人发牌未通过校验: %v", playerCount, err)
+		}
+	}
+}
+
+// TestValidateDealtHandsBull 牛牛引擎在2/3/4人时的发牌都应通过校验，且每人5张
+func TestValidateDealtHandsBull(t *testing.T) {
+	engine := services.NewBullGameWithShuffler(services.NewSeededShuffler(1))
+
+	for playerCount := 2; playerCount <= 4; playerCount++ {
+		hands, err := engine.DealCards(playerCount)
+		if err != nil {
+			t.Fatalf("%d人发牌失败: %v", playerCount, err)
+		}
+		if err := validateDealtHands("bull", hands); err != nil {
+			t.Errorf("%d人发牌未通过校验: %v", playerCount, err)
+		}
+		for playerID, cards := range hands {
+			if len(cards) != 5 {
+				t.Errorf("%d人局中玩家%d的手牌为%d张，期望5张", playerCount, playerID, len(cards))
+			}
+		}
+	}
+}
+
+// TestValidateDealtHandsDuplicate 跨玩家的重复牌必须被检出
+func TestValidateDealtHandsDuplicate(t *testing.T) {
+	hands := map[uint][]int{
+		1: {103, 104, 105},
+		2: {205, 103, 207}, // 103与玩家1重复
+	}
+
+	err := validateDealtHands("running", hands)
+	if err == nil {
+		t.Fatal("重复牌未被检出")
+	}
+	if !strings.Contains(err.Error(), "103") {
+		t.Errorf("错误信息未指明重复的牌: %v", err)
+	}
+}
+
+// TestValidateDealtHandsForeignCard 不属于整副牌的编码必须被检出
+func TestValidateDealtHandsForeignCard(t *testing.T) {
+	hands := map[uint][]int{
+		1: {103, 999}, // 999不是合法编码
+	}
+
+	if err := validateDealtHands("running", hands); err == nil {
+		t.Fatal("非法牌编码未被检出")
+	}
+
+	// 牛牛的整副牌是1-13点：跑得快的15点（2）在牛牛中同样非法
+	if err := validateDealtHands("bull", map[uint][]int{1: {115}}); err == nil {
+		t.Fatal("超出牛牛点数范围的牌未被检出")
+	}
+}
+
+// bullSettlementState 构造庄家为玩家1的牛牛结算状态
+func bullSettlementState(players map[uint]*models.PlayerGameInfo) *models.GameState {
+	players[1].IsBanker = true
+	return &models.GameState{
+		RoomID:   "R1",
+		GameType: "bull",
+		Banker:   1,
+		Players:  players,
+	}
+}
+
+// TestCalculateBullSettlementBankerWins 庄家牛牛赢没牛闲家：闲家赔 底注*押注倍数*庄家牌型倍数（牛牛4倍）
+func TestCalculateBullSettlementBankerWins(t *testing.T) {
+	gameState := bullSettlementState(map[uint]*models.PlayerGameInfo{
+		1: {UserID: 1, BullType: 10, MaxCard: 13},                  // 庄家牛牛
+		2: {UserID: 2, BullType: 0, MaxCard: 12, BetMultiplier: 2}, // 闲家没牛，押2倍
+		3: {UserID: 3, BullType: 0, MaxCard: 9},                    // 闲家没牛，未押注按1倍
+	})
+
+	balances := services.NewBullGame().Settle(gameState, 10)
+
+	if balances[2] != -80 { // 10 * 2 * 4
+		t.Errorf("押2倍的没牛闲家应赔80，实际: %v", balances[2])
+	}
+	if balances[3] != -40 { // 10 * 1 * 4
+		t.Errorf("未押注的没牛闲家应赔40，实际: %v", balances[3])
+	}
+	if balances[1] != 120 {
+		t.Errorf("庄家应赢120，实际: %v", balances[1])
+	}
+}
+
+// TestCalculateBullSettlementPlayerWins 闲家牛牛赢没牛庄家：闲家赢 底注*押注倍数*闲家牌型倍数
+func TestCalculateBullSettlementPlayerWins(t *testing.T) {
+	gameState := bullSettlementState(map[uint]*models.PlayerGameInfo{
+		1: {UserID: 1, BullType: 0, MaxCard: 14},                    // 庄家没牛
+		2: {UserID: 2, BullType: 10, MaxCard: 13, BetMultiplier: 3}, // 闲家牛牛，押3倍
+	})
+
+	balances := services.NewBullGame().Settle(gameState, 10)
+
+	if balances[2] != 120 { // 10 * 3 * 4
+		t.Errorf("押3倍的牛牛闲家应赢120，实际: %v", balances[2])
+	}
+	if balances[1] != -120 {
+		t.Errorf("庄家应赔120，实际: %v", balances[1])
+	}
+}
+
+// TestCalculateBullSettlementTieFavorsBanker 牌型完全相同时算庄家赢（庄家通吃）
+func TestCalculateBullSettlementTieFavorsBanker(t *testing.T) {
+	gameState := bullSettlementState(map[uint]*models.PlayerGameInfo{
+		1: {UserID: 1, BullType: 8, BullNum: 8, MaxCard: 13}, // 庄家牛八
+		2: {UserID: 2, BullType: 8, BullNum: 8, MaxCard: 13}, // 闲家同为牛八同最大牌
+	})
+
+	balances := services.NewBullGame().Settle(gameState, 10)
+
+	if balances[2] != -20 { // 10 * 1 * 2（牛八2倍）
+		t.Errorf("平局闲家应赔20，实际: %v", balances[2])
+	}
+	if balances[1] != 20 {
+		t.Errorf("平局庄家应赢20，实际: %v", balances[1])
+	}
+}
+
+// TestCalculateBullSettlementZeroSum 多闲家混合胜负时整体零和
+func TestCalculateBullSettlementZeroSum(t *testing.T) {
+	gameState := bullSettlementState(map[uint]*models.PlayerGameInfo{
+		1: {UserID: 1, BullType: 5, BullNum: 5, MaxCard: 10},       // 庄家牛五
+		2: {UserID: 2, BullType: 14, MaxCard: 4, BetMultiplier: 2}, // 闲家五小牛赢
+		3: {UserID: 3, BullType: 0, MaxCard: 14, BetMultiplier: 3}, // 闲家没牛输
+		4: {UserID: 4, BullType: 9, BullNum: 9, MaxCard: 12},       // 闲家牛九赢
+	})
+
+	balances := services.NewBullGame().Settle(gameState, 10)
+
+	if balances[2] != 160 { // 10 * 2 * 8（五小牛8倍）
+		t.Errorf("五小牛闲家应赢160，实际: %v", balances[2])
+	}
+	if balances[3] != -30 { // 10 * 3 * 1（庄家牛五1倍）
+		t.Errorf("没牛闲家应赔30，实际: %v", balances[3])
+	}
+	if balances[4] != 30 { // 10 * 1 * 3（牛九3倍）
+		t.Errorf("牛九闲家应赢30，实际: %v", balances[4])
+	}
+
+	var total float64
+	for _, amount := range balances {
+		total += amount
+	}
+	if total != 0 {
+		t.Errorf("结算结果不是零和: %v", total)
+	}
+}
+
+// TestRunningFastSettleByRank 跑得快按名次结算：第一名赢 (人数-1)*底注，第k名赔 (k-1)*底注
+func TestRunningFastSettleByRank(t *testing.T) {
+	gameState := &models.GameState{
+		RoomID:   "R2",
+		GameType: "running",
+		Players: map[uint]*models.PlayerGameInfo{
+			1: {UserID: 1, Rank: 2},
+			2: {UserID: 2, Rank: 1},
+			3: {UserID: 3, Rank: 3},
+		},
+	}
+
+	balances := services.NewRunningFastGame().Settle(gameState, 10)
+
+	if balances[2] != 20 { // (3-1) * 10
+		t.Errorf("第一名应赢20，实际: %v", balances[2])
+	}
+	if balances[1] != -10 { // (2-1) * 10
+		t.Errorf("第二名应赔10，实际: %v", balances[1])
+	}
+	if balances[3] != -20 { // (3-1) * 10
+		t.Errorf("第三名应赔20，实际: %v", balances[3])
+	}
+}
+
+// TestSetupFirstPlayerLowestCard 默认规则：手牌最小牌（大小王除外）的玩家先出
+func TestSetupFirstPlayerLowestCard(t *testing.T) {
+	gameState := &models.GameState{
+		GameType: "running",
+		Players: map[uint]*models.PlayerGameInfo{
+			1: {UserID: 1, Position: 1, Cards: []int{108, 110}},
+			2: {UserID: 2, Position: 2, Cards: []int{103, 112}}, // 持有最小牌3
+			3: {UserID: 3, Position: 3, Cards: []int{205, 307}},
+		},
+	}
+
+	if err := services.NewRunningFastGame().Setup(gameState); err != nil {
+		t.Fatalf("Setup失败: %v", err)
+	}
+	if gameState.CurrentPlayer != 2 {
+		t.Errorf("最小牌规则应由玩家2先出，实际: %d", gameState.CurrentPlayer)
+	}
+}
+
+// TestSetupFirstPlayerSeatOne seat_one规则：固定1号位先出
+func TestSetupFirstPlayerSeatOne(t *testing.T) {
+	gameState := &models.GameState{
+		GameType:        "running",
+		FirstPlayerRule: services.FirstPlayerRuleSeatOne,
+		Players: map[uint]*models.PlayerGameInfo{
+			5: {UserID: 5, Position: 2, Cards: []int{103}}, // 持有最小牌但不在1号位
+			7: {UserID: 7, Position: 1, Cards: []int{215}},
+			9: {UserID: 9, Position: 3, Cards: []int{310}},
+		},
+	}
+
+	if err := services.NewRunningFastGame().Setup(gameState); err != nil {
+		t.Fatalf("Setup失败: %v", err)
+	}
+	if gameState.CurrentPlayer != 7 {
+		t.Errorf("seat_one规则应由1号位玩家7先出，实际: %d", gameState.CurrentPlayer)
+	}
+}
+
+// TestWinnerFromRecord previous_winner规则：从上一局结算结果中解析第一名
+func TestWinnerFromRecord(t *testing.T) {
+	record := &models.GameRecord{
+		Result: models.JSON(`{"3":{"user_id":3,"rank":2,"balance":-10},"8":{"user_id":8,"rank":1,"balance":20}}`),
+	}
+	if winner := winnerFromRecord(record); winner != 8 {
+		t.Errorf("应解析出第一名玩家8，实际: %d", winner)
+	}
+
+	// 结算结果损坏时返回0，由开局兜底规则接管
+	broken := &models.GameRecord{Result: models.JSON(`not-json`)}
+	if winner := winnerFromRecord(broken); winner != 0 {
+		t.Errorf("损坏的结算结果应返回0，实际: %d", winner)
+	}
+}
+
+// fakeStateStorage 内存版游戏状态存储（Manager单元测试用）
+type fakeStateStorage struct {
+	states map[string]*models.GameState
+}
+
+func newFakeStateStorage() *fakeStateStorage {
+	return &fakeStateStorage{states: make(map[string]*models.GameState)}
+}
+
+func (f *fakeStateStorage) Get(ctx context.Context, roomID string) (*models.GameState, error) {
+	state, ok := f.states[roomID]
+	if !ok {
+		return nil, errors.New("游戏状态不存在")
+	}
+	return state, nil
+}
+
+func (f *fakeStateStorage) Save(ctx context.Context, state *models.GameState, expiration time.Duration) error {
+	f.states[state.RoomID] = state
+	return nil
+}
+
+func (f *fakeStateStorage) Delete(ctx context.Context, roomID string) error {
+	delete(f.states, roomID)
+	return nil
+}
+
+func (f *fakeStateStorage) Exists(ctx context.Context, roomID string) (bool, error) {
+	_, ok := f.states[roomID]
+	return ok, nil
+}
+
+// TestPassNewRoundAfterLeaderFinished 3人局中牌权玩家打出最后一手后，
+// 其余两人都过牌应清空桌面开新一轮
+func TestPassNewRoundAfterLeaderFinished(t *testing.T) {
+	storage := newFakeStateStorage()
+	m := &Manager{stateStorage: storage}
+
+	storage.states["R1"] = &models.GameState{
+		RoomID:   "R1",
+		GameType: "running",
+		Players: map[uint]*models.PlayerGameInfo{
+			1: {UserID: 1, Position: 1, IsFinished: true, Rank: 1}, // 牌权玩家已出完
+			2: {UserID: 2, Position: 2, Cards: []int{103, 104}, CardCount: 2},
+			3: {UserID: 3, Position: 3, Cards: []int{105, 106}, CardCount: 2},
+		},
+		CurrentPlayer: 2,
+		LastPlayer:    1,
+		LastCards:     []int{115},
+	}
+
+	// 第一个过牌：桌面不应清空
+	gameState, err := m.Pass(context.Background(), "R1", 2)
+	if err != nil {
+		t.Fatalf("玩家2过牌失败: %v", err)
+	}
+	if len(gameState.LastCards) == 0 {
+		t.Fatal("只有一人过牌，桌面不应被清空")
+	}
+
+	// 第二个过牌：所有活跃玩家都过了，应清空桌面开新一轮
+	gameState, err = m.Pass(context.Background(), "R1", gameState.CurrentPlayer)
+	if err != nil {
+		t.Fatalf("第二人过牌失败: %v", err)
+	}
+	if len(gameState.LastCards) != 0 || gameState.LastPlayer != 0 || gameState.PassCount != 0 {
+		t.Errorf("所有活跃玩家都过牌后桌面未清空: lastCards=%v lastPlayer=%d passCount=%d",
+			gameState.LastCards, gameState.LastPlayer, gameState.PassCount)
+	}
+	if p := gameState.Players[gameState.CurrentPlayer]; p == nil || p.IsFinished {
+		t.Errorf("新一轮的出牌权落在了已完成的玩家: %d", gameState.CurrentPlayer)
+	}
+}
+
+// TestPassNewRoundReturnsLeadToActiveLeader 牌权玩家仍活跃时，
+// 其余玩家都过牌后桌面清空、牌权回到该玩家自由出牌
+func TestPassNewRoundReturnsLeadToActiveLeader(t *testing.T) {
+	storage := newFakeStateStorage()
+	m := &Manager{stateStorage: storage}
+
+	storage.states["R2"] = &models.GameState{
+		RoomID:   "R2",
+		GameType: "running",
+		Players: map[uint]*models.PlayerGameInfo{
+			1: {UserID: 1, Position: 1, Cards: []int{107}, CardCount: 1}, // 牌权玩家
+			2: {UserID: 2, Position: 2, Cards: []int{103, 104}, CardCount: 2},
+			3: {UserID: 3, Position: 3, Cards: []int{105, 106}, CardCount: 2},
+		},
+		CurrentPlayer: 2,
+		LastPlayer:    1,
+		LastCards:     []int{115},
+	}
+
+	gameState, err := m.Pass(context.Background(), "R2", 2)
+	if err != nil {
+		t.Fatalf("玩家2过牌失败: %v", err)
+	}
+	if len(gameState.LastCards) == 0 {
+		t.Fatal("牌权玩家之外还有人未过牌，桌面不应被清空")
+	}
+
+	gameState, err = m.Pass(context.Background(), "R2", gameState.CurrentPlayer)
+	if err != nil {
+		t.Fatalf("第二人过牌失败: %v", err)
+	}
+	if len(gameState.LastCards) != 0 || gameState.PassCount != 0 {
+		t.Errorf("其余玩家都过牌后桌面未清空: lastCards=%v passCount=%d",
+			gameState.LastCards, gameState.PassCount)
+	}
+}
+
+// legalPlaysManager 仅注入提示计算所需依赖的Manager
+func legalPlaysManager(storage *fakeStateStorage) *Manager {
+	return &Manager{stateStorage: storage, engines: services.NewRegisteredEngines()}
+}
+
+// TestGetLegalPlaysCanBeat 手里有更大的对子时应给出压牌提示
+func TestGetLegalPlaysCanBeat(t *testing.T) {
+	storage := newFakeStateStorage()
+	m := legalPlaysManager(storage)
+
+	storage.states["R1"] = &models.GameState{
+		RoomID:   "R1",
+		GameType: "running",
+		Players: map[uint]*models.PlayerGameInfo{
+			1: {UserID: 1, Position: 1, Cards: []int{110, 210, 304}, CardCount: 3},
+			2: {UserID: 2, Position: 2, Cards: []int{105}, CardCount: 1},
+		},
+		CurrentPlayer: 1,
+		LastPlayer:    2,
+		LastCards:     []int{108, 208}, // 对8
+	}
+
+	hints, err := m.GetLegalPlays(context.Background(), "R1", 1)
+	if err != nil {
+		t.Fatalf("获取提示失败: %v", err)
+	}
+	if !hints.CanPlay || hints.MustPass {
+		t.Fatalf("持有对10应可压对8: canPlay=%v mustPass=%v", hints.CanPlay, hints.MustPass)
+	}
+	if len(hints.Plays) == 0 {
+		t.Error("提示列表不应为空")
+	}
+}
+
+// TestGetLegalPlaysMustPass 无牌可压时应标记被迫过牌
+func TestGetLegalPlaysMustPass(t *testing.T) {
+	storage := newFakeStateStorage()
+	m := legalPlaysManager(storage)
+
+	storage.states["R2"] = &models.GameState{
+		RoomID:   "R2",
+		GameType: "running",
+		Players: map[uint]*models.PlayerGameInfo{
+			1: {UserID: 1, Position: 1, Cards: []int{103, 204}, CardCount: 2},
+			2: {UserID: 2, Position: 2, Cards: []int{105}, CardCount: 1},
+		},
+		CurrentPlayer: 1,
+		LastPlayer:    2,
+		LastCards:     []int{115}, // 单张2，只有王炸能压
+	}
+
+	hints, err := m.GetLegalPlays(context.Background(), "R2", 1)
+	if err != nil {
+		t.Fatalf("获取提示失败: %v", err)
+	}
+	if hints.CanPlay || !hints.MustPass {
+		t.Errorf("小牌手牌应被迫过牌: canPlay=%v mustPass=%v", hints.CanPlay, hints.MustPass)
+	}
+}
+
+// recordedNotify 记录一次回合计时通知
+type recordedNotify struct {
+	action string
+	userID uint
+	data   map[string]interface{}
+}
+
+// TestTurnTimerNotify CurrentPlayer有效时发timer_start，操作后发timer_stop
+func TestTurnTimerNotify(t *testing.T) {
+	var notifies []recordedNotify
+	m := &Manager{}
+	m.SetTurnNotifier(func(ctx context.Context, roomID, action string, userID uint, data map[string]interface{}) {
+		notifies = append(notifies, recordedNotify{action, userID, data})
+	}, 30*time.Second)
+
+	gameState := &models.GameState{RoomID: "R1", Status: 2, CurrentPlayer: 7}
+	m.notifyTimerStart(context.Background(), "R1", gameState)
+	m.notifyTimerStop(context.Background(), "R1", 5)
+
+	if len(notifies) != 2 {
+		t.Fatalf("通知次数 = %d, 期望 2", len(notifies))
+	}
+	start := notifies[0]
+	if start.action != "timer_start" || start.userID != 7 {
+		t.Fatalf("timer_start通知不符: %+v", start)
+	}
+	if timeout, ok := start.data["timeout"].(int); !ok || timeout != 30 {
+		t.Fatalf("timeout = %v, 期望 30", start.data["timeout"])
+	}
+	if _, ok := start.data["start_time"].(int64); !ok {
+		t.Fatal("timer_start应携带start_time")
+	}
+	if notifies[1].action != "timer_stop" || notifies[1].userID != 5 {
+		t.Fatalf("timer_stop通知不符: %+v", notifies[1])
+	}
+}
+
+// TestTurnTimerNotifySkipped 游戏已结束或未配置时不发计时通知
+func TestTurnTimerNotifySkipped(t *testing.T) {
+	var count int
+	m := &Manager{}
+	m.SetTurnNotifier(func(ctx context.Context, roomID, action string, userID uint, data map[string]interface{}) {
+		count++
+	}, 30*time.Second)
+
+	// 游戏已结束或无当前玩家
+	m.notifyTimerStart(context.Background(), "R1", &models.GameState{Status: 3, CurrentPlayer: 7})
+	m.notifyTimerStart(context.Background(), "R1", &models.GameState{Status: 2, CurrentPlayer: 0})
+	m.notifyTimerStart(context.Background(), "R1", nil)
+
+	// 时长为0视为关闭
+	m.SetTurnNotifier(func(ctx context.Context, roomID, action string, userID uint, data map[string]interface{}) {
+		count++
+	}, 0)
+	m.notifyTimerStart(context.Background(), "R1", &models.GameState{Status: 2, CurrentPlayer: 7})
+	m.notifyTimerStop(context.Background(), "R1", 7)
+
+	if count != 0 {
+		t.Fatalf("不应发出计时通知, 实际 %d 次", count)
+	}
+}
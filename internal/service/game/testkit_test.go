@@ -0,0 +1,448 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/lock"
+	"github.com/kaifa/game-platform/internal/logger"
+	gamerecordrepo "github.com/kaifa/game-platform/internal/repository/gamerecord"
+	paymentrepo "github.com/kaifa/game-platform/internal/repository/payment"
+	roomrepo "github.com/kaifa/game-platform/internal/repository/room"
+	userrepo "github.com/kaifa/game-platform/internal/repository/user"
+	leaderboardsvc "github.com/kaifa/game-platform/internal/service/leaderboard"
+	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/kaifa/game-platform/pkg/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 确定性对局测试工具：内存版的状态存储、各仓储与锁，
+// 配合种子洗牌引擎让一整局游戏可以在测试里脚本化地打完，
+// 不依赖MySQL/Redis等外部服务
+
+func init() {
+	// 测试环境不初始化完整日志系统
+	logger.Logger = zap.NewNop()
+	logger.Sugar = logger.Logger.Sugar()
+}
+
+// memGameStateStorage 内存游戏状态存储。
+// 读写都经过JSON序列化，模拟Redis存储的值语义，避免测试共享指针掩盖bug
+type memGameStateStorage struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+func newMemGameStateStorage() *memGameStateStorage {
+	return &memGameStateStorage{states: make(map[string][]byte)}
+}
+
+func (s *memGameStateStorage) Get(ctx context.Context, roomID string) (*models.GameState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.states[roomID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var state models.GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (s *memGameStateStorage) Save(ctx context.Context, state *models.GameState, expiration time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.states[state.RoomID] = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memGameStateStorage) Delete(ctx context.Context, roomID string) error {
+	s.mu.Lock()
+	delete(s.states, roomID)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memGameStateStorage) Exists(ctx context.Context, roomID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.states[roomID]
+	return ok, nil
+}
+
+// memRoomRepo 内存房间仓储
+type memRoomRepo struct {
+	rooms map[string]*models.GameRoom
+}
+
+func newMemRoomRepo() *memRoomRepo {
+	return &memRoomRepo{rooms: make(map[string]*models.GameRoom)}
+}
+
+func (r *memRoomRepo) Create(ctx context.Context, room *models.GameRoom) error {
+	r.rooms[room.RoomID] = room
+	return nil
+}
+
+func (r *memRoomRepo) Update(ctx context.Context, room *models.GameRoom) error {
+	r.rooms[room.RoomID] = room
+	return nil
+}
+
+func (r *memRoomRepo) DeleteByRoomID(ctx context.Context, roomID string) error {
+	delete(r.rooms, roomID)
+	return nil
+}
+
+func (r *memRoomRepo) GetByRoomID(ctx context.Context, roomID string) (*models.GameRoom, error) {
+	room, ok := r.rooms[roomID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return room, nil
+}
+
+func (r *memRoomRepo) List(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *memRoomRepo) Count(ctx context.Context, filter roomrepo.ListFilter) (int64, error) {
+	return 0, nil
+}
+
+func (r *memRoomRepo) ListIdleWaiting(ctx context.Context, cutoff int64) ([]*models.GameRoom, error) {
+	return nil, nil
+}
+
+func (r *memRoomRepo) CountActiveByCreator(ctx context.Context, creatorID uint) (int64, error) {
+	return 0, nil
+}
+
+func (r *memRoomRepo) FindInProgressByPlayer(ctx context.Context, userID uint) (*models.GameRoom, error) {
+	return nil, nil
+}
+
+// memUserRepo 内存用户仓储，只维护结算用到的余额
+type memUserRepo struct {
+	users map[uint]*models.User
+}
+
+func newMemUserRepo() *memUserRepo {
+	return &memUserRepo{users: make(map[uint]*models.User)}
+}
+
+func (r *memUserRepo) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *memUserRepo) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memUserRepo) Create(ctx context.Context, user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memUserRepo) Update(ctx context.Context, user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memUserRepo) CreateWallet(ctx context.Context, wallet *models.UserWallet) error { return nil }
+
+func (r *memUserRepo) CreateLoginLog(ctx context.Context, log *models.UserLogin) error { return nil }
+
+func (r *memUserRepo) GetWallet(ctx context.Context, userID uint) (*models.UserWallet, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memUserRepo) UpdateBalance(ctx context.Context, userID uint, newBalance float64) error {
+	if user, ok := r.users[userID]; ok {
+		user.Balance = newBalance
+	}
+	return nil
+}
+
+func (r *memUserRepo) BatchUpdateBalances(ctx context.Context, balances map[uint]float64) error {
+	for userID, balance := range balances {
+		if user, ok := r.users[userID]; ok {
+			user.Balance = balance
+		}
+	}
+	return nil
+}
+
+// memGameRecordRepo 内存游戏记录仓储
+type memGameRecordRepo struct {
+	roomRepo *memRoomRepo
+	records  []*models.GameRecord
+	players  []*models.GamePlayer
+}
+
+func newMemGameRecordRepo(roomRepo *memRoomRepo) *memGameRecordRepo {
+	return &memGameRecordRepo{roomRepo: roomRepo}
+}
+
+func (r *memGameRecordRepo) ListRoomIDsByUser(ctx context.Context, userID uint) ([]string, error) {
+	return nil, nil
+}
+
+func (r *memGameRecordRepo) CountRecordsByRoomIDs(ctx context.Context, roomIDs []string, gameType string) (int64, error) {
+	return 0, nil
+}
+
+func (r *memGameRecordRepo) ListRecordsByRoomIDs(ctx context.Context, roomIDs []string, gameType string, offset, limit int) ([]models.GameRecord, error) {
+	return nil, nil
+}
+
+func (r *memGameRecordRepo) GetRecordByID(ctx context.Context, recordID uint) (*models.GameRecord, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memGameRecordRepo) ListRecordsByRoom(ctx context.Context, roomID string) ([]models.GameRecord, error) {
+	// 与MySQL实现一致：按创建时间倒序
+	var records []models.GameRecord
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].RoomID == roomID {
+			records = append(records, *r.records[i])
+		}
+	}
+	return records, nil
+}
+
+func (r *memGameRecordRepo) GetPlayerInRoom(ctx context.Context, roomID string, userID uint) (*models.GamePlayer, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memGameRecordRepo) ListPlayersByRoom(ctx context.Context, roomID string) ([]models.GamePlayer, error) {
+	var players []models.GamePlayer
+	for _, p := range r.players {
+		if p.RoomID == roomID {
+			players = append(players, *p)
+		}
+	}
+	return players, nil
+}
+
+func (r *memGameRecordRepo) GetRoomByRoomID(ctx context.Context, roomID string) (*models.GameRoom, error) {
+	return r.roomRepo.GetByRoomID(ctx, roomID)
+}
+
+func (r *memGameRecordRepo) CreateGameRecord(ctx context.Context, record *models.GameRecord) error {
+	record.ID = uint(len(r.records) + 1)
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *memGameRecordRepo) CreateGamePlayer(ctx context.Context, player *models.GamePlayer) error {
+	r.players = append(r.players, player)
+	return nil
+}
+
+func (r *memGameRecordRepo) BatchCreateGamePlayers(ctx context.Context, players []*models.GamePlayer) error {
+	r.players = append(r.players, players...)
+	return nil
+}
+
+// memTransactionRepo 内存交易流水仓储
+type memTransactionRepo struct {
+	transactions []*models.Transaction
+}
+
+func (r *memTransactionRepo) Create(ctx context.Context, transaction *models.Transaction) error {
+	r.transactions = append(r.transactions, transaction)
+	return nil
+}
+
+func (r *memTransactionRepo) GetByOrderID(ctx context.Context, orderID string) (*models.Transaction, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memTransactionRepo) ListByUser(ctx context.Context, userID uint, txType string, offset, limit int) ([]models.Transaction, int64, error) {
+	return nil, 0, nil
+}
+
+// memLeaderboard 内存排行榜服务，记录每次上报的分数
+type memLeaderboard struct {
+	scores map[uint]float64
+}
+
+func newMemLeaderboard() *memLeaderboard {
+	return &memLeaderboard{scores: make(map[uint]float64)}
+}
+
+func (l *memLeaderboard) UpdateLeaderboard(ctx context.Context, gameType string, scores map[uint]float64) error {
+	for userID, score := range scores {
+		l.scores[userID] += score
+	}
+	return nil
+}
+
+func (l *memLeaderboard) GetLeaderboard(ctx context.Context, gameType, period string, page, pageSize int) (*leaderboardsvc.LeaderboardResponse, error) {
+	return nil, nil
+}
+
+func (l *memLeaderboard) GetUserRank(ctx context.Context, gameType, period string, userID uint) (int, float64, error) {
+	return 0, 0, nil
+}
+
+// memLock 进程内lock.Lock实现（单测试进程内无跨实例竞争，直接串行执行）
+type memLock struct {
+	mu sync.Mutex
+}
+
+func (l *memLock) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (l *memLock) Lock(ctx context.Context, key string, ttl time.Duration, maxRetries int, retryInterval time.Duration) error {
+	return nil
+}
+
+func (l *memLock) Unlock(ctx context.Context, key string) error { return nil }
+
+func (l *memLock) WithLock(ctx context.Context, key string, ttl time.Duration, fn func() error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return fn()
+}
+
+func (l *memLock) Refresh(ctx context.Context, key string, ttl time.Duration) error { return nil }
+
+var _ lock.Lock = (*memLock)(nil)
+var _ roomrepo.Repository = (*memRoomRepo)(nil)
+var _ userrepo.Repository = (*memUserRepo)(nil)
+var _ gamerecordrepo.Repository = (*memGameRecordRepo)(nil)
+var _ paymentrepo.TransactionRepository = (*memTransactionRepo)(nil)
+var _ leaderboardsvc.Service = (*memLeaderboard)(nil)
+
+// testManagerKit 确定性对局测试环境：Manager及其全部内存依赖
+type testManagerKit struct {
+	manager     *Manager
+	storage     *memGameStateStorage
+	roomRepo    *memRoomRepo
+	userRepo    *memUserRepo
+	recordRepo  *memGameRecordRepo
+	txRepo      *memTransactionRepo
+	leaderboard *memLeaderboard
+}
+
+// newTestManagerKit 构建内存依赖的Manager，跑得快引擎替换为种子洗牌版本，
+// 同一个seed下发牌结果完全确定
+func newTestManagerKit(seed int64) *testManagerKit {
+	stateStorage := newMemGameStateStorage()
+	roomRepo := newMemRoomRepo()
+	userRepo := newMemUserRepo()
+	recordRepo := newMemGameRecordRepo(roomRepo)
+	txRepo := &memTransactionRepo{}
+	leaderboard := newMemLeaderboard()
+
+	manager := NewManager(
+		stateStorage,
+		roomRepo,
+		userRepo,
+		recordRepo,
+		txRepo,
+		leaderboard,
+		&memLock{},
+		lock.NewLocalRWLock(),
+	)
+	manager.engines["running"] = services.NewRunningFastGameWithShuffler(services.NewSeededShuffler(seed))
+
+	return &testManagerKit{
+		manager:     manager,
+		storage:     stateStorage,
+		roomRepo:    roomRepo,
+		userRepo:    userRepo,
+		recordRepo:  recordRepo,
+		txRepo:      txRepo,
+		leaderboard: leaderboard,
+	}
+}
+
+// seedGameRoom 写入一个全员已准备的等待中房间和对应的用户（初始余额balance）
+func (k *testManagerKit) seedGameRoom(t *testing.T, roomID, gameType string, baseBet, balance float64, userIDs []uint) {
+	t.Helper()
+
+	players := make([]services.PlayerInfo, 0, len(userIDs))
+	for i, userID := range userIDs {
+		players = append(players, services.PlayerInfo{
+			UserID:   userID,
+			UID:      int64(userID),
+			Position: i + 1,
+			Ready:    true,
+		})
+		k.userRepo.users[userID] = &models.User{ID: userID, UID: int64(userID), Balance: balance}
+	}
+	playersJSON, _ := json.Marshal(players)
+
+	k.roomRepo.rooms[roomID] = &models.GameRoom{
+		RoomID:         roomID,
+		GameType:       gameType,
+		RoomType:       "quick",
+		BaseBet:        baseBet,
+		MaxPlayers:     len(userIDs),
+		CurrentPlayers: len(userIDs),
+		Status:         1,
+		CreatorID:      userIDs[0],
+		Players:        models.JSON(playersJSON),
+	}
+}
+
+// playOut 按固定策略把一局跑得快打到结束：当前玩家有合法出牌就打提示的
+// 第一组，否则过牌。返回结束时的游戏状态
+func (k *testManagerKit) playOut(t *testing.T, roomID string, maxTurns int) *models.GameState {
+	t.Helper()
+	ctx := context.Background()
+
+	for turn := 0; turn < maxTurns; turn++ {
+		gameState, err := k.manager.GetGameState(ctx, roomID)
+		if err != nil {
+			t.Fatalf("第%d手获取游戏状态失败: %v", turn, err)
+		}
+		if gameState.Status == 3 {
+			return gameState
+		}
+
+		current := gameState.CurrentPlayer
+		hint, err := k.manager.GetLegalPlays(ctx, roomID, current)
+		if err != nil {
+			t.Fatalf("第%d手获取出牌提示失败(玩家%d): %v", turn, current, err)
+		}
+
+		if hint.CanPlay {
+			if gameState, err = k.manager.PlayCards(ctx, roomID, current, hint.Plays[0]); err != nil {
+				t.Fatalf("第%d手出牌失败(玩家%d, %v): %v", turn, current, hint.Plays[0], err)
+			}
+		} else {
+			if gameState, err = k.manager.Pass(ctx, roomID, current); err != nil {
+				t.Fatalf("第%d手过牌失败(玩家%d): %v", turn, current, err)
+			}
+		}
+		if gameState.Status == 3 {
+			return gameState
+		}
+	}
+
+	t.Fatalf("%d手后游戏仍未结束", maxTurns)
+	return nil
+}
@@ -14,3 +14,12 @@ type PlayerSettlement struct {
 	Balance      float64 `json:"balance"`       // 本局余额变化
 	FinalBalance float64 `json:"final_balance"` // 结算后余额
 }
+
+// LegalPlaysResult 当前玩家的合法出牌提示
+type LegalPlaysResult struct {
+	RoomID   string  `json:"room_id"`
+	UserID   uint    `json:"user_id"`
+	CanPlay  bool    `json:"can_play"`  // 是否存在合法出牌
+	MustPass bool    `json:"must_pass"` // 是否被迫过牌（需要压牌但无牌可压）
+	Plays    [][]int `json:"plays"`     // 合法出牌组合（按点数去重，最多maxHintPlays组）
+}
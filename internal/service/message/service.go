@@ -26,8 +26,8 @@ type Service interface {
 	// DeleteMessage 删除用户消息
 	DeleteMessage(ctx context.Context, id, userID uint) error
 
-	// GetAnnouncements 获取公告列表
-	GetAnnouncements(ctx context.Context) ([]models.Announcement, error)
+	// GetAnnouncements 获取当前生效的公告列表（userID 为 0 表示未登录）
+	GetAnnouncements(ctx context.Context, userID uint) ([]models.Announcement, error)
 }
 
 type service struct {
@@ -106,8 +106,8 @@ func (s *service) DeleteMessage(ctx context.Context, id, userID uint) error {
 	return s.repo.Delete(ctx, id, userID)
 }
 
-// GetAnnouncements 获取公告列表
-func (s *service) GetAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+// GetAnnouncements 获取当前生效的公告列表（userID 为 0 表示未登录）
+func (s *service) GetAnnouncements(ctx context.Context, userID uint) ([]models.Announcement, error) {
 	// ✅ 业务逻辑：最多返回20条
-	return s.repo.GetAnnouncements(ctx, 20)
+	return s.repo.GetAnnouncements(ctx, userID, 20)
 }
@@ -3,27 +3,49 @@ package payment
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/google/uuid"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/idgen"
 	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/metrics"
 	paymentrepo "github.com/kaifa/game-platform/internal/repository/payment"
 	userrepo "github.com/kaifa/game-platform/internal/repository/user"
+	"github.com/kaifa/game-platform/internal/retry"
+	"github.com/kaifa/game-platform/pkg/errs"
 	"github.com/kaifa/game-platform/pkg/models"
 	"github.com/kaifa/game-platform/pkg/services"
+	qrcode "github.com/skip2/go-qrcode"
 	"go.uber.org/zap"
 )
 
+// ErrHDWalletNotConfigured HD钱包未配置（handler 可据此返回503）
+var ErrHDWalletNotConfigured = errors.New("HD钱包未初始化")
+
+// newOrderID 订单号生成函数（测试时可替换为种子生成器制造碰撞）
+var newOrderID = idgen.OrderID
+
+// DepositAddressInfo 充值地址信息（含派生路径和二维码）
+type DepositAddressInfo struct {
+	Address   string `json:"address"`
+	ChainType string `json:"chain_type"`
+	Path      string `json:"path"`    // BIP44派生路径
+	QRCode    string `json:"qr_code"` // base64编码的PNG二维码
+}
+
 // Service 定义支付业务服务接口
 type Service interface {
-	// CreateRechargeOrder 创建充值订单
-	CreateRechargeOrder(ctx context.Context, userID uint, amount float64, chainType string) (*models.RechargeOrder, error)
+	// CreateRechargeOrder 创建充值订单（idempotencyKey非空时，重复请求返回已创建的订单）
+	CreateRechargeOrder(ctx context.Context, userID uint, amount float64, chainType string, idempotencyKey string) (*models.RechargeOrder, error)
 
 	// GetRechargeOrder 获取充值订单
 	GetRechargeOrder(ctx context.Context, orderID string, userID uint) (*models.RechargeOrder, error)
@@ -34,8 +56,8 @@ type Service interface {
 	// CheckTransaction 检查交易状态
 	CheckTransaction(ctx context.Context, orderID string) error
 
-	// CreateWithdrawOrder 创建提现订单
-	CreateWithdrawOrder(ctx context.Context, userID uint, amount float64, chainType string, toAddress string) (*models.WithdrawOrder, error)
+	// CreateWithdrawOrder 创建提现订单（idempotencyKey非空时，重复请求返回已创建的订单）
+	CreateWithdrawOrder(ctx context.Context, userID uint, amount float64, chainType string, toAddress string, idempotencyKey string) (*models.WithdrawOrder, error)
 
 	// GetWithdrawOrder 获取提现订单
 	GetWithdrawOrder(ctx context.Context, orderID string, userID uint) (*models.WithdrawOrder, error)
@@ -46,8 +68,21 @@ type Service interface {
 	// AuditWithdrawOrder 审核提现订单
 	AuditWithdrawOrder(ctx context.Context, auditorID uint, orderID string, approve bool, remark string) error
 
-	// StartTransactionMonitor 启动交易监控
-	StartTransactionMonitor()
+	// GetUserTransactions 获取用户的交易流水（txType为空时返回全部类型）
+	GetUserTransactions(ctx context.Context, userID uint, txType string, page, pageSize int) ([]models.Transaction, int64, error)
+
+	// GetDepositAddress 获取用户充值地址（含派生路径和二维码）
+	GetDepositAddress(ctx context.Context, userID uint, chainType string) (*DepositAddressInfo, error)
+
+	// StartTransactionMonitor 启动交易监控。
+	// interval为检查间隔（非正值时使用30秒），maxConcurrency为单轮
+	// 并发检查数上限（非正值时使用5），ctx取消后监控停止
+	StartTransactionMonitor(ctx context.Context, interval time.Duration, maxConcurrency int)
+
+	// StartExpirySweeper 启动过期订单清扫。
+	// 定期把已过有效期仍待支付的充值订单置为已取消并通知用户，
+	// interval非正值时使用5分钟，ctx取消后清扫停止
+	StartExpirySweeper(ctx context.Context, interval time.Duration)
 }
 
 type service struct {
@@ -55,6 +90,7 @@ type service struct {
 	withdrawOrderRepo paymentrepo.WithdrawOrderRepository
 	transactionRepo   paymentrepo.TransactionRepository
 	depositAddrRepo   paymentrepo.DepositAddressRepository
+	idempotencyRepo   paymentrepo.IdempotencyKeyRepository
 	userRepo          userrepo.Repository
 
 	// 外部服务依赖
@@ -65,6 +101,13 @@ type service struct {
 	tronAPIURL      string
 	etherscanAPIURL string
 	etherscanAPIKey string
+
+	// 充值地址二维码缓存（地址 -> base64 PNG）
+	qrMu    sync.Mutex
+	qrCache map[string]string
+
+	// monitorRunning 交易监控是否正在执行（防止单次检查超过间隔时重叠）
+	monitorRunning int32
 }
 
 // New 创建支付服务实例
@@ -73,6 +116,7 @@ func New(
 	withdrawOrderRepo paymentrepo.WithdrawOrderRepository,
 	transactionRepo paymentrepo.TransactionRepository,
 	depositAddrRepo paymentrepo.DepositAddressRepository,
+	idempotencyRepo paymentrepo.IdempotencyKeyRepository,
 	userRepo userrepo.Repository,
 	hdWallet *services.HDWallet,
 	transferService *services.USDTTransferService,
@@ -83,20 +127,27 @@ func New(
 		withdrawOrderRepo: withdrawOrderRepo,
 		transactionRepo:   transactionRepo,
 		depositAddrRepo:   depositAddrRepo,
+		idempotencyRepo:   idempotencyRepo,
 		userRepo:          userRepo,
 		hdWallet:          hdWallet,
 		transferService:   transferService,
 		tronAPIURL:        "https://api.trongrid.io",
 		etherscanAPIURL:   "https://api.etherscan.io/api",
 		etherscanAPIKey:   etherscanAPIKey,
+		qrCache:           make(map[string]string),
 	}
 }
 
 // CreateRechargeOrder 创建充值订单
-func (s *service) CreateRechargeOrder(ctx context.Context, userID uint, amount float64, chainType string) (*models.RechargeOrder, error) {
+func (s *service) CreateRechargeOrder(ctx context.Context, userID uint, amount float64, chainType string, idempotencyKey string) (*models.RechargeOrder, error) {
+	// ✅ 业务逻辑：幂等检查（重试请求返回已创建的订单）
+	if orderID := s.replayIdempotentOrder(ctx, userID, idempotencyKey, "recharge"); orderID != "" {
+		return s.rechargeOrderRepo.GetByOrderIDAndUser(ctx, orderID, userID)
+	}
+
 	// ✅ 业务逻辑：参数验证
 	if amount <= 0 {
-		return nil, errors.New("充值金额必须大于0")
+		return nil, errs.BadRequest("充值金额必须大于0")
 	}
 
 	// TODO: 从系统配置获取限额
@@ -111,11 +162,11 @@ func (s *service) CreateRechargeOrder(ctx context.Context, userID uint, amount f
 	}
 
 	if chainType != "trc20" && chainType != "erc20" {
-		return nil, errors.New("链类型必须是trc20或erc20")
+		return nil, errs.BadRequest("链类型必须是trc20或erc20")
 	}
 
 	// ✅ 业务逻辑：生成订单号
-	orderID := fmt.Sprintf("R%s", strings.ToUpper(uuid.New().String()[:15]))
+	orderID := newOrderID("R")
 
 	// ✅ 业务逻辑：生成充值地址
 	depositAddr, err := s.getDepositAddress(ctx, userID, chainType)
@@ -128,9 +179,11 @@ func (s *service) CreateRechargeOrder(ctx context.Context, userID uint, amount f
 	expireAt := now + 30*60
 
 	channel := fmt.Sprintf("usdt_%s", chainType)
-	requiredConf := 12
+
+	// 确认次数从系统配置读取，仅影响新建订单（已有订单保留其存储的 RequiredConf）
+	requiredConf := services.GetSystemConfigInt("required_conf_erc20", 12)
 	if chainType == "trc20" {
-		requiredConf = 20
+		requiredConf = services.GetSystemConfigInt("required_conf_trc20", 20)
 	}
 
 	order := &models.RechargeOrder{
@@ -146,8 +199,25 @@ func (s *service) CreateRechargeOrder(ctx context.Context, userID uint, amount f
 	}
 
 	// ✅ 通过 Repository 创建订单
-	if err := s.rechargeOrderRepo.Create(ctx, order); err != nil {
-		return nil, fmt.Errorf("创建充值订单失败: %w", err)
+	// 订单号碰撞（uk_order_id冲突）时换新ID重试，而非把DB错误抛给用户
+	var createErr error
+	for attempt := 0; attempt < idgen.MaxCreateAttempts; attempt++ {
+		createErr = s.rechargeOrderRepo.Create(ctx, order)
+		if createErr == nil || !database.IsDuplicateKeyError(createErr) {
+			break
+		}
+		order.OrderID = newOrderID("R")
+	}
+	if createErr != nil {
+		return nil, fmt.Errorf("创建充值订单失败: %w", createErr)
+	}
+	orderID = order.OrderID
+
+	// 记录幂等键；并发重试下唯一键冲突时取消重复订单，以先创建的订单为准
+	if existing := s.storeIdempotencyKey(ctx, userID, idempotencyKey, "recharge", orderID); existing != "" {
+		order.Status = 3 // 已取消
+		s.rechargeOrderRepo.Update(ctx, order)
+		return s.rechargeOrderRepo.GetByOrderIDAndUser(ctx, existing, userID)
 	}
 
 	logger.Logger.Info("创建充值订单",
@@ -192,7 +262,7 @@ func (s *service) CheckTransaction(ctx context.Context, orderID string) error {
 	// ✅ 通过 Repository 获取订单
 	order, err := s.rechargeOrderRepo.GetByOrderID(ctx, orderID)
 	if err != nil {
-		return errors.New("订单不存在")
+		return errs.NotFound("订单不存在")
 	}
 
 	// 如果已经支付，不需要再检查
@@ -204,20 +274,25 @@ func (s *service) CheckTransaction(ctx context.Context, orderID string) error {
 	if time.Now().Unix() > order.ExpireAt {
 		order.Status = 3 // 已取消
 		s.rechargeOrderRepo.Update(ctx, order)
-		return errors.New("订单已过期")
+		return errs.BadRequest("订单已过期")
 	}
 
-	// 根据链类型检查交易
+	// 根据链类型检查交易（带重试：链上查询是网络调用，瞬时故障较常见）
 	var txHash string
 	var confirmCount int
-
-	if order.ChainType == "trc20" {
-		txHash, confirmCount, err = s.checkTRC20Transaction(order.DepositAddr, order.Amount)
-	} else if order.ChainType == "erc20" {
-		txHash, confirmCount, err = s.checkERC20Transaction(order.DepositAddr, order.Amount)
-	} else {
-		return errors.New("不支持的链类型")
-	}
+	var receivedAmount float64
+
+	err = retry.Do(ctx, chainLookupRetryOpts, func() error {
+		var checkErr error
+		if order.ChainType == "trc20" {
+			txHash, confirmCount, receivedAmount, checkErr = s.checkTRC20Transaction(order.DepositAddr, order.CreatedAt)
+		} else if order.ChainType == "erc20" {
+			txHash, confirmCount, receivedAmount, checkErr = s.checkERC20Transaction(order.DepositAddr, order.CreatedAt)
+		} else {
+			checkErr = errUnsupportedChain
+		}
+		return checkErr
+	})
 
 	if err != nil {
 		return err
@@ -228,6 +303,17 @@ func (s *service) CheckTransaction(ctx context.Context, orderID string) error {
 		order.TxHash = txHash
 		order.ChannelID = txHash
 		order.ConfirmCount = confirmCount
+		order.ReceivedAmount = receivedAmount
+
+		// 到账金额与订单金额不一致时记录差异，便于后台核对
+		if receivedAmount < order.Amount*0.99 || receivedAmount > order.Amount*1.01 {
+			logger.Logger.Warn("充值到账金额与订单金额不一致",
+				zap.String("order_id", orderID),
+				zap.Float64("amount", order.Amount),
+				zap.Float64("received_amount", receivedAmount),
+				zap.String("tx_hash", txHash),
+			)
+		}
 
 		// 如果确认次数足够，完成充值
 		if confirmCount >= order.RequiredConf {
@@ -241,31 +327,36 @@ func (s *service) CheckTransaction(ctx context.Context, orderID string) error {
 }
 
 // CreateWithdrawOrder 创建提现订单
-func (s *service) CreateWithdrawOrder(ctx context.Context, userID uint, amount float64, chainType string, toAddress string) (*models.WithdrawOrder, error) {
+func (s *service) CreateWithdrawOrder(ctx context.Context, userID uint, amount float64, chainType string, toAddress string, idempotencyKey string) (*models.WithdrawOrder, error) {
+	// ✅ 业务逻辑：幂等检查（重试请求返回已创建的订单）
+	if orderID := s.replayIdempotentOrder(ctx, userID, idempotencyKey, "withdraw"); orderID != "" {
+		return s.withdrawOrderRepo.GetByOrderIDAndUser(ctx, orderID, userID)
+	}
+
 	// ✅ 业务逻辑：参数验证
 	if amount <= 0 {
-		return nil, errors.New("提现金额必须大于0")
+		return nil, errs.BadRequest("提现金额必须大于0")
 	}
 
 	if chainType != "trc20" && chainType != "erc20" {
-		return nil, errors.New("链类型必须是trc20或erc20")
+		return nil, errs.BadRequest("链类型必须是trc20或erc20")
 	}
 
 	// 验证地址格式
 	if chainType == "trc20" {
 		if !strings.HasPrefix(toAddress, "T") || len(toAddress) != 34 {
-			return nil, errors.New("TRC20地址格式错误，应为T开头的34位地址")
+			return nil, errs.BadRequest("TRC20地址格式错误，应为T开头的34位地址")
 		}
 	} else {
 		if !strings.HasPrefix(toAddress, "0x") || len(toAddress) != 42 {
-			return nil, errors.New("ERC20地址格式错误，应为0x开头的42位地址")
+			return nil, errs.BadRequest("ERC20地址格式错误，应为0x开头的42位地址")
 		}
 	}
 
 	// ✅ 通过 Repository 检查用户余额
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, errs.NotFound("用户不存在")
 	}
 
 	// TODO: 从系统配置获取限额
@@ -296,7 +387,7 @@ func (s *service) CreateWithdrawOrder(ctx context.Context, userID uint, amount f
 	}
 
 	// ✅ 业务逻辑：生成订单号
-	orderID := fmt.Sprintf("W%s", strings.ToUpper(uuid.New().String()[:15]))
+	orderID := newOrderID("W")
 	channel := fmt.Sprintf("usdt_%s", chainType)
 
 	order := &models.WithdrawOrder{
@@ -312,8 +403,26 @@ func (s *service) CreateWithdrawOrder(ctx context.Context, userID uint, amount f
 	}
 
 	// ✅ 通过 Repository 创建订单
-	if err := s.withdrawOrderRepo.Create(ctx, order); err != nil {
-		return nil, fmt.Errorf("创建提现订单失败: %w", err)
+	// 订单号碰撞（uk_order_id冲突）时换新ID重试，而非把DB错误抛给用户
+	var createErr error
+	for attempt := 0; attempt < idgen.MaxCreateAttempts; attempt++ {
+		createErr = s.withdrawOrderRepo.Create(ctx, order)
+		if createErr == nil || !database.IsDuplicateKeyError(createErr) {
+			break
+		}
+		order.OrderID = newOrderID("W")
+	}
+	if createErr != nil {
+		return nil, fmt.Errorf("创建提现订单失败: %w", createErr)
+	}
+	orderID = order.OrderID
+
+	// 记录幂等键；并发重试下唯一键冲突时取消重复订单，以先创建的订单为准
+	if existing := s.storeIdempotencyKey(ctx, userID, idempotencyKey, "withdraw", orderID); existing != "" {
+		order.Status = 3 // 已拒绝
+		order.Remark = "重复请求，自动取消"
+		s.withdrawOrderRepo.Update(ctx, order)
+		return s.withdrawOrderRepo.GetByOrderIDAndUser(ctx, existing, userID)
 	}
 
 	logger.Logger.Info("创建提现订单",
@@ -353,17 +462,39 @@ func (s *service) GetUserWithdrawOrders(ctx context.Context, userID uint, page,
 	return s.withdrawOrderRepo.ListByUser(ctx, userID, offset, pageSize)
 }
 
+// GetUserTransactions 获取用户的交易流水（txType为空时返回全部类型）
+func (s *service) GetUserTransactions(ctx context.Context, userID uint, txType string, page, pageSize int) ([]models.Transaction, int64, error) {
+	// ✅ 业务逻辑：参数验证
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	switch txType {
+	case "", "recharge", "withdraw", "game_win", "game_loss":
+	default:
+		return nil, 0, errs.BadRequest("不支持的交易类型")
+	}
+
+	offset := (page - 1) * pageSize
+
+	// ✅ 通过 Repository 查询
+	return s.transactionRepo.ListByUser(ctx, userID, txType, offset, pageSize)
+}
+
 // AuditWithdrawOrder 审核提现订单
 func (s *service) AuditWithdrawOrder(ctx context.Context, auditorID uint, orderID string, approve bool, remark string) error {
 	// ✅ 通过 Repository 获取订单
 	order, err := s.withdrawOrderRepo.GetByOrderID(ctx, orderID)
 	if err != nil {
-		return errors.New("订单不存在")
+		return errs.NotFound("订单不存在")
 	}
 
 	// 如果已经审核过，不允许重复审核
 	if order.Status != 1 {
-		return errors.New("订单已审核，无法重复审核")
+		return errs.BadRequest("订单已审核，无法重复审核")
 	}
 
 	now := time.Now().Unix()
@@ -377,22 +508,171 @@ func (s *service) AuditWithdrawOrder(ctx context.Context, auditorID uint, orderI
 	}
 }
 
-// StartTransactionMonitor 启动交易监控
-func (s *service) StartTransactionMonitor() {
-	ticker := time.NewTicker(30 * time.Second)
+// StartTransactionMonitor 启动交易监控。
+// ctx取消后所有监控goroutine退出；单次检查耗时超过间隔时跳过本轮，
+// 执行情况（次数/耗时点/错误）记录到全局监控指标
+func (s *service) StartTransactionMonitor(ctx context.Context, interval time.Duration, maxConcurrency int) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = 5
+	}
+
+	// 启动时先对账一次，处理卡在"转账中"的提现订单
+	go s.reconcileTransferringOrders()
+
 	go func() {
-		for range ticker.C {
-			s.checkPendingOrders()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Logger.Info("交易监控已停止")
+				return
+			case <-ticker.C:
+				// 上一轮还在执行时跳过，避免慢检查堆积
+				if !atomic.CompareAndSwapInt32(&s.monitorRunning, 0, 1) {
+					metrics.GetGlobalMetrics().RecordTxMonitorSkip()
+					continue
+				}
+				err := s.checkPendingOrders(ctx, maxConcurrency)
+				atomic.StoreInt32(&s.monitorRunning, 0)
+				metrics.GetGlobalMetrics().RecordTxMonitorRun(err)
+			}
 		}
 	}()
+
+	// 定期清理过期的幂等键
+	if s.idempotencyRepo != nil {
+		go func() {
+			cleanupTicker := time.NewTicker(time.Hour)
+			defer cleanupTicker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-cleanupTicker.C:
+					if err := s.idempotencyRepo.DeleteExpired(ctx, time.Now().Unix()); err != nil {
+						logger.Logger.Warn("清理过期幂等键失败", zap.Error(err))
+					}
+				}
+			}
+		}()
+	}
 }
 
 // ==================== 私有方法 ====================
 
+// idempotencyKeyTTL 幂等键有效期，超过后相同的键会创建新订单
+const idempotencyKeyTTL = 24 * time.Hour
+
+// replayIdempotentOrder 查询幂等键对应的已创建订单号（无记录、类型不符或已过期返回空）
+func (s *service) replayIdempotentOrder(ctx context.Context, userID uint, key, orderType string) string {
+	if key == "" || s.idempotencyRepo == nil {
+		return ""
+	}
+
+	record, err := s.idempotencyRepo.GetByUserAndKey(ctx, userID, key)
+	if err != nil || record == nil {
+		return ""
+	}
+	if record.OrderType != orderType || record.ExpireAt < time.Now().Unix() {
+		return ""
+	}
+
+	logger.Logger.Info("幂等键命中，返回已创建的订单",
+		zap.Uint("user_id", userID),
+		zap.String("order_type", orderType),
+		zap.String("order_id", record.OrderID),
+	)
+	return record.OrderID
+}
+
+// storeIdempotencyKey 记录幂等键；唯一键冲突（并发重试）时返回先到请求创建的订单号
+func (s *service) storeIdempotencyKey(ctx context.Context, userID uint, key, orderType, orderID string) string {
+	if key == "" || s.idempotencyRepo == nil {
+		return ""
+	}
+
+	record := &models.PaymentIdempotencyKey{
+		UserID:    userID,
+		Key:       key,
+		OrderType: orderType,
+		OrderID:   orderID,
+		ExpireAt:  time.Now().Add(idempotencyKeyTTL).Unix(),
+	}
+	if err := s.idempotencyRepo.Create(ctx, record); err != nil {
+		if strings.Contains(err.Error(), "Duplicate") {
+			if existing, getErr := s.idempotencyRepo.GetByUserAndKey(ctx, userID, key); getErr == nil && existing.OrderType == orderType {
+				return existing.OrderID
+			}
+		}
+		logger.Logger.Warn("记录支付幂等键失败",
+			zap.Uint("user_id", userID),
+			zap.String("order_id", orderID),
+			zap.Error(err),
+		)
+	}
+	return ""
+}
+
+// GetDepositAddress 获取用户充值地址（含派生路径和二维码）
+func (s *service) GetDepositAddress(ctx context.Context, userID uint, chainType string) (*DepositAddressInfo, error) {
+	if chainType != "trc20" && chainType != "erc20" {
+		return nil, errs.BadRequest("链类型必须是trc20或erc20")
+	}
+
+	// 复用已有逻辑（已持久化 UserDepositAddress）
+	address, err := s.getDepositAddress(ctx, userID, chainType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 派生路径可以由用户ID直接推出（account=0）
+	var path string
+	if chainType == "trc20" {
+		path = services.GetTronPath(0, uint32(userID))
+	} else {
+		path = services.GetEthereumPath(0, uint32(userID))
+	}
+
+	qr, err := s.depositAddressQR(address)
+	if err != nil {
+		return nil, fmt.Errorf("生成二维码失败: %w", err)
+	}
+
+	return &DepositAddressInfo{
+		Address:   address,
+		ChainType: chainType,
+		Path:      path,
+		QRCode:    qr,
+	}, nil
+}
+
+// depositAddressQR 生成充值地址二维码（带缓存）
+func (s *service) depositAddressQR(address string) (string, error) {
+	s.qrMu.Lock()
+	defer s.qrMu.Unlock()
+
+	if qr, ok := s.qrCache[address]; ok {
+		return qr, nil
+	}
+
+	png, err := qrcode.Encode(address, qrcode.Medium, 256)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(png)
+	s.qrCache[address] = encoded
+	return encoded, nil
+}
+
 // getDepositAddress 获取充值地址
 func (s *service) getDepositAddress(ctx context.Context, userID uint, chainType string) (string, error) {
 	if s.hdWallet == nil {
-		return "", errors.New("HD钱包未初始化")
+		return "", ErrHDWalletNotConfigured
 	}
 
 	// ✅ 通过 Repository 查询是否已有地址
@@ -460,11 +740,17 @@ func (s *service) getDepositAddress(ctx context.Context, userID uint, chainType
 func (s *service) completeRecharge(ctx context.Context, order *models.RechargeOrder) error {
 	// 检查订单状态
 	if order.Status == 2 {
-		return errors.New("订单已处理")
+		return errs.BadRequest("订单已处理")
 	}
 
 	now := time.Now().Unix()
 
+	// 以链上实际到账金额入账，未记录到账金额时回退到订单金额
+	creditAmount := order.ReceivedAmount
+	if creditAmount <= 0 {
+		creditAmount = order.Amount
+	}
+
 	// 更新订单状态
 	order.Status = 2
 	order.PaidAt = &now
@@ -479,7 +765,7 @@ func (s *service) completeRecharge(ctx context.Context, order *models.RechargeOr
 	}
 
 	// ✅ 通过 Repository 更新余额
-	newBalance := user.Balance + order.Amount
+	newBalance := user.Balance + creditAmount
 	if err := s.userRepo.UpdateBalance(ctx, order.UserID, newBalance); err != nil {
 		return fmt.Errorf("更新用户余额失败: %w", err)
 	}
@@ -489,7 +775,7 @@ func (s *service) completeRecharge(ctx context.Context, order *models.RechargeOr
 		OrderID:   order.OrderID,
 		UserID:    order.UserID,
 		Type:      "recharge",
-		Amount:    order.Amount,
+		Amount:    creditAmount,
 		Status:    2,
 		Channel:   order.Channel,
 		ChannelID: order.TxHash,
@@ -500,12 +786,16 @@ func (s *service) completeRecharge(ctx context.Context, order *models.RechargeOr
 	}
 
 	// 发送充值成功通知
-	services.SendOrderNotification(order.UserID, "recharge", order.OrderID, "paid", order.Amount, "")
+	services.SendOrderNotification(order.UserID, "recharge", order.OrderID, "paid", creditAmount, "")
+
+	// 推送外部回调
+	services.SendOrderWebhook(order.UserID, "recharge", order.OrderID, "paid", creditAmount, order.TxHash)
 
 	logger.Logger.Info("充值完成",
 		zap.String("order_id", order.OrderID),
 		zap.Uint("user_id", order.UserID),
 		zap.Float64("amount", order.Amount),
+		zap.Float64("credit_amount", creditAmount),
 		zap.String("tx_hash", order.TxHash),
 	)
 
@@ -517,12 +807,12 @@ func (s *service) approveWithdraw(ctx context.Context, order *models.WithdrawOrd
 	// ✅ 通过 Repository 获取用户
 	user, err := s.userRepo.GetByID(ctx, order.UserID)
 	if err != nil {
-		return errors.New("用户不存在")
+		return errs.NotFound("用户不存在")
 	}
 
 	// 检查余额是否足够
 	if user.Balance < order.Amount {
-		return errors.New("用户余额不足")
+		return errs.BadRequest("用户余额不足")
 	}
 
 	// ✅ 通过 Repository 更新余额
@@ -531,28 +821,53 @@ func (s *service) approveWithdraw(ctx context.Context, order *models.WithdrawOrd
 		return fmt.Errorf("扣除余额失败: %w", err)
 	}
 
-	// 更新订单状态为已通过
-	order.Status = 2
+	// 先标记为"转账中"并落库，确保链上发送前有持久化的中间状态
+	// 如果之后任何一步失败，对账程序可以根据该状态恢复
+	order.Status = 4 // 转账中
 	order.AuditAt = &now
 	order.AuditorID = auditorID
 	order.Remark = remark
+	if err := s.withdrawOrderRepo.Update(ctx, order); err != nil {
+		// 尚未发送链上交易，直接回滚余额
+		s.userRepo.UpdateBalance(ctx, order.UserID, user.Balance)
+		return fmt.Errorf("更新订单状态失败: %w", err)
+	}
 
 	// 执行USDT转账
 	txHash, err := s.transferUSDT(order)
 	if err != nil {
-		// 转账失败，回滚余额
+		// 转账未发出，回滚余额并恢复订单为待审核，允许重新审核
 		s.userRepo.UpdateBalance(ctx, order.UserID, user.Balance)
+		order.Status = 1 // 待审核
+		order.Remark = fmt.Sprintf("转账失败: %v", err)
+		if updateErr := s.withdrawOrderRepo.Update(ctx, order); updateErr != nil {
+			logger.Logger.Error("恢复订单状态失败，订单停留在转账中，等待对账处理",
+				zap.String("order_id", order.OrderID),
+				zap.Error(updateErr),
+			)
+		}
 		return fmt.Errorf("转账失败: %w", err)
 	}
 
+	// 链上已发送，标记为最终状态
+	// 注意：此处失败不能回滚余额（资金已上链），留给对账程序根据TxHash修复
 	order.TxHash = txHash
+	order.Status = 2 // 已通过
 	if err := s.withdrawOrderRepo.Update(ctx, order); err != nil {
-		return fmt.Errorf("更新订单状态失败: %w", err)
+		logger.Logger.Error("转账已上链但订单标记失败，等待对账处理",
+			zap.String("order_id", order.OrderID),
+			zap.String("tx_hash", txHash),
+			zap.Error(err),
+		)
+		return fmt.Errorf("更新订单状态失败（转账已上链，交易哈希%s）: %w", txHash, err)
 	}
 
 	// 发送通知
 	services.SendOrderNotification(order.UserID, "withdraw", order.OrderID, "approved", order.Amount, "")
 
+	// 推送外部回调
+	services.SendOrderWebhook(order.UserID, "withdraw", order.OrderID, "approved", order.Amount, txHash)
+
 	logger.Logger.Info("提现订单审核通过",
 		zap.String("order_id", order.OrderID),
 		zap.Uint("auditor_id", auditorID),
@@ -576,6 +891,9 @@ func (s *service) rejectWithdraw(ctx context.Context, order *models.WithdrawOrde
 	// 发送通知
 	services.SendOrderNotification(order.UserID, "withdraw", order.OrderID, "rejected", order.Amount, remark)
 
+	// 推送外部回调
+	services.SendOrderWebhook(order.UserID, "withdraw", order.OrderID, "rejected", order.Amount, "")
+
 	logger.Logger.Info("提现订单审核拒绝",
 		zap.String("order_id", order.OrderID),
 		zap.Uint("auditor_id", auditorID),
@@ -588,7 +906,7 @@ func (s *service) rejectWithdraw(ctx context.Context, order *models.WithdrawOrde
 // transferUSDT 执行USDT转账
 func (s *service) transferUSDT(order *models.WithdrawOrder) (string, error) {
 	if s.transferService == nil || s.hdWallet == nil {
-		return "", errors.New("转账服务未初始化")
+		return "", errs.BadRequest("转账服务未初始化")
 	}
 
 	// 派生主钱包地址和私钥
@@ -646,18 +964,112 @@ func (s *service) transferUSDT(order *models.WithdrawOrder) (string, error) {
 	return txHash, nil
 }
 
-// checkPendingOrders 检查待支付的订单
-func (s *service) checkPendingOrders() {
+// reconcileTransferringOrders 对账卡在"转账中"的提现订单
+// 进程崩溃或订单落库失败时，订单可能停留在转账中状态；
+// 根据是否已有链上交易决定最终状态，防止双花或资金丢失
+func (s *service) reconcileTransferringOrders() {
 	ctx := context.Background()
 
+	orders, err := s.withdrawOrderRepo.ListByStatus(ctx, 4) // 转账中
+	if err != nil {
+		logger.Logger.Warn("查询转账中订单失败", zap.Error(err))
+		return
+	}
+
+	for i := range orders {
+		order := &orders[i]
+
+		if order.TxHash != "" {
+			// 已有交易哈希：链上已发送，直接标记为已通过
+			order.Status = 2
+			if err := s.withdrawOrderRepo.Update(ctx, order); err != nil {
+				logger.Logger.Error("对账更新订单失败",
+					zap.String("order_id", order.OrderID),
+					zap.Error(err),
+				)
+				continue
+			}
+			logger.Logger.Info("对账完成：订单已上链，标记为已通过",
+				zap.String("order_id", order.OrderID),
+				zap.String("tx_hash", order.TxHash),
+			)
+			continue
+		}
+
+		// 无交易哈希：查询链上是否存在对应转账（从审核时间之后开始找）
+		sinceTs := order.CreatedAt
+		if order.AuditAt != nil && *order.AuditAt > 0 {
+			sinceTs = *order.AuditAt
+		}
+		var txHash string
+		if order.ChainType == "trc20" {
+			txHash, _, _, _ = s.checkTRC20Transaction(order.ToAddress, sinceTs)
+		} else if order.ChainType == "erc20" {
+			txHash, _, _, _ = s.checkERC20Transaction(order.ToAddress, sinceTs)
+		}
+
+		if txHash != "" {
+			// 链上找到转账：补记哈希并标记为已通过
+			order.TxHash = txHash
+			order.Status = 2
+			if err := s.withdrawOrderRepo.Update(ctx, order); err != nil {
+				logger.Logger.Error("对账更新订单失败",
+					zap.String("order_id", order.OrderID),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+
+		// 链上未找到转账：余额已扣但资金未发出，退回余额并恢复为待审核
+		user, err := s.userRepo.GetByID(ctx, order.UserID)
+		if err != nil {
+			logger.Logger.Error("对账查询用户失败",
+				zap.String("order_id", order.OrderID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if err := s.userRepo.UpdateBalance(ctx, order.UserID, user.Balance+order.Amount); err != nil {
+			logger.Logger.Error("对账退回余额失败",
+				zap.String("order_id", order.OrderID),
+				zap.Error(err),
+			)
+			continue
+		}
+		order.Status = 1 // 待审核
+		order.Remark = "对账恢复：链上未找到转账，已退回余额"
+		if err := s.withdrawOrderRepo.Update(ctx, order); err != nil {
+			logger.Logger.Error("对账恢复订单失败",
+				zap.String("order_id", order.OrderID),
+				zap.Error(err),
+			)
+			continue
+		}
+		logger.Logger.Info("对账完成：链上未找到转账，订单恢复为待审核",
+			zap.String("order_id", order.OrderID),
+		)
+	}
+}
+
+// checkPendingOrders 检查待支付的订单。
+// 并发检查数由信号量限制，且等待本轮全部完成后才返回，
+// 配合调用方的重叠保护，慢提供商不会导致请求量成倍堆积
+func (s *service) checkPendingOrders(ctx context.Context, maxConcurrency int) error {
 	// ✅ 通过 Repository 查询待支付订单
 	orders, err := s.rechargeOrderRepo.ListPending(ctx, time.Now().Unix())
 	if err != nil {
-		return
+		return fmt.Errorf("查询待支付订单失败: %w", err)
 	}
 
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
 	for _, order := range orders {
+		wg.Add(1)
+		sem <- struct{}{}
 		go func(o models.RechargeOrder) {
+			defer wg.Done()
+			defer func() { <-sem }()
 			if err := s.CheckTransaction(ctx, o.OrderID); err != nil {
 				logger.Logger.Debug("检查交易失败",
 					zap.String("order_id", o.OrderID),
@@ -666,16 +1078,93 @@ func (s *service) checkPendingOrders() {
 			}
 		}(order)
 	}
+	wg.Wait()
+	return nil
+}
+
+// StartExpirySweeper 启动过期订单清扫（与交易监控相互独立）。
+// 交易监控只在检查到单个订单时才标记过期，监控停摆或订单长期
+// 未被检查时订单会卡在待支付状态，这里统一兜底流转
+func (s *service) StartExpirySweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Logger.Info("过期订单清扫已停止")
+				return
+			case <-ticker.C:
+				if err := s.sweepExpiredOrders(ctx); err != nil {
+					logger.Logger.Warn("过期订单清扫失败", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// sweepExpiredOrders 取消所有已过有效期仍待支付的充值订单并通知用户。
+// 充值地址按用户/链类型长期复用，没有需要释放的占用状态
+func (s *service) sweepExpiredOrders(ctx context.Context) error {
+	orders, err := s.rechargeOrderRepo.ListExpiredPending(ctx, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("查询过期订单失败: %w", err)
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+
+	var cancelled int
+	for i := range orders {
+		order := &orders[i]
+		order.Status = 3 // 已取消
+		if err := s.rechargeOrderRepo.Update(ctx, order); err != nil {
+			logger.Logger.Warn("取消过期订单失败",
+				zap.String("order_id", order.OrderID),
+				zap.Error(err),
+			)
+			continue
+		}
+		cancelled++
+		services.SendOrderNotification(order.UserID, "recharge", order.OrderID, "expired", order.Amount, "")
+	}
+
+	logger.Logger.Info("过期订单清扫完成",
+		zap.Int("expired", len(orders)),
+		zap.Int("cancelled", cancelled),
+	)
+	return nil
+}
+
+// errTxNotFound 链上未找到匹配交易（确定性结果，不参与重试）
+var errTxNotFound = errors.New("未找到匹配的交易")
+
+// errUnsupportedChain 不支持的链类型（参数错误，不参与重试）
+var errUnsupportedChain = errors.New("不支持的链类型")
+
+// chainLookupRetryOpts 链上查询的重试策略
+// 网络/限流等瞬时错误重试，确定性结果（未找到、链类型错误）直接返回
+var chainLookupRetryOpts = retry.Options{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	Retryable: func(err error) bool {
+		return !errors.Is(err, errTxNotFound) && !errors.Is(err, errUnsupportedChain)
+	},
 }
 
 // checkTRC20Transaction 检查TRC20交易
-func (s *service) checkTRC20Transaction(depositAddr string, amount float64) (string, int, error) {
+// 返回sinceTs之后最新一笔转入该地址的USDT交易哈希、确认数和实际到账金额
+func (s *service) checkTRC20Transaction(depositAddr string, sinceTs int64) (string, int, float64, error) {
 	// TODO: 实现 TRC20 交易检查逻辑（调用 TronGrid API）
-	return "", 0, errors.New("未找到匹配的交易")
+	return "", 0, 0, errTxNotFound
 }
 
 // checkERC20Transaction 检查ERC20交易
-func (s *service) checkERC20Transaction(depositAddr string, amount float64) (string, int, error) {
+// 返回sinceTs之后最新一笔转入该地址的USDT交易哈希、确认数和实际到账金额
+func (s *service) checkERC20Transaction(depositAddr string, sinceTs int64) (string, int, float64, error) {
 	// TODO: 实现 ERC20 交易检查逻辑（调用 Etherscan API）
-	return "", 0, errors.New("未找到匹配的交易")
+	return "", 0, 0, errTxNotFound
 }
@@ -6,19 +6,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/idgen"
 	"github.com/kaifa/game-platform/internal/lock"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/metrics"
 	roomrepo "github.com/kaifa/game-platform/internal/repository/room"
 	userrepo "github.com/kaifa/game-platform/internal/repository/user"
+	"github.com/kaifa/game-platform/internal/retry"
 	gamesvc "github.com/kaifa/game-platform/internal/service/game"
+	"github.com/kaifa/game-platform/internal/tracing"
 	"github.com/kaifa/game-platform/internal/worker"
+	"github.com/kaifa/game-platform/pkg/errs"
 	"github.com/kaifa/game-platform/pkg/models"
 	"github.com/kaifa/game-platform/pkg/services"
 	"github.com/kaifa/game-platform/pkg/utils"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // Service 抽象房间业务服务接口。
@@ -27,11 +37,18 @@ type Service interface {
 	CreateRoom(ctx context.Context, ownerID uint, req *CreateRoomRequest) (*models.GameRoom, error)
 	JoinRoom(ctx context.Context, userID uint, roomID, password string) (*models.GameRoom, error)
 	LeaveRoom(ctx context.Context, userID uint, roomID string) error
-	GetRoom(ctx context.Context, roomID string) (*models.GameRoom, error)
-	ListRooms(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, error)
+	KickPlayer(ctx context.Context, ownerID uint, roomID string, targetUserID uint) error
+	TransferOwner(ctx context.Context, ownerID uint, roomID string, newOwnerID uint) error
+	GetRoom(ctx context.Context, roomID string) (*RoomInfo, error)
+	ListRooms(ctx context.Context, filter roomrepo.ListFilter) ([]*RoomInfo, int64, error)
 	Ready(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error)
 	CancelReady(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error)
 	StartGame(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error)
+	QuickMatch(ctx context.Context, userID uint, gameType, roomType string) (*models.GameRoom, bool, error)
+	StartIdleReaper(idleTimeout time.Duration)
+	StartReadyTimeoutChecker(warnAfter, kickAfter time.Duration)
+	// NotifyRoomAction 把自定义动作广播到游戏服务器（供游戏管理器发回合计时等事件）
+	NotifyRoomAction(ctx context.Context, roomID, action string, userID uint, data map[string]interface{})
 }
 
 type service struct {
@@ -50,6 +67,19 @@ type service struct {
 	// 其他
 	redis     *redis.Client
 	notifyURL string
+
+	// 房主离开等待中房间时：true解散房间，false转让给下一位玩家
+	dissolveOnOwnerLeave bool
+
+	// 单用户最大活跃房间数（0表示不限制）
+	maxRoomsPerUser int
+
+	// 允许同一用户同时参与多局游戏（仅测试环境使用，生产应保持false）
+	allowMultiSession bool
+
+	// 最近一次Redis同步失败的时间戳（Unix纳秒，原子访问）。
+	// 失败后的冷却窗口内直接跳过同步，避免Redis故障时房间操作被拖慢
+	syncFailedAt int64
 }
 
 // New 创建房间服务实例。
@@ -62,17 +92,55 @@ func New(
 	distLock lock.Lock, // ✅ 注入分布式锁
 	localLock lock.RWLock, // ✅ 注入本地锁
 	notifyPool *worker.Pool, // ✅ 注入通知池
+	dissolveOnOwnerLeave bool, // 房主离开时是否解散房间
+	maxRoomsPerUser int, // 单用户最大活跃房间数
+	allowMultiSession bool, // 允许同时参与多局（仅测试用）
 ) Service {
 	return &service{
-		repo:        repo,
-		userRepo:    userRepo,
-		gameManager: gameManager,
-		redis:       redisClient,
-		notifyURL:   notifyURL,
-		distLock:    distLock,
-		localLock:   localLock,
-		notifyPool:  notifyPool,
+		repo:                 repo,
+		userRepo:             userRepo,
+		gameManager:          gameManager,
+		redis:                redisClient,
+		notifyURL:            notifyURL,
+		distLock:             distLock,
+		localLock:            localLock,
+		notifyPool:           notifyPool,
+		dissolveOnOwnerLeave: dissolveOnOwnerLeave,
+		maxRoomsPerUser:      maxRoomsPerUser,
+		allowMultiSession:    allowMultiSession,
+	}
+}
+
+// newRoomID 房间号生成函数（测试时可替换为种子生成器制造碰撞）
+var newRoomID = idgen.RoomID
+
+// maxVersionRetries 乐观锁冲突时的最大重试次数
+const maxVersionRetries = 3
+
+// withVersionRetry 执行一段读-改-写逻辑，乐观锁冲突时整体重试
+// 冲突说明其他实例同时修改了该房间，重新读取后通常能成功
+func withVersionRetry(fn func() error) error {
+	var err error
+	for i := 0; i < maxVersionRetries; i++ {
+		err = fn()
+		if !errors.Is(err, roomrepo.ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// lockWithVersionRetry 在本地写锁内执行fn，乐观锁冲突时重试
+// 本地锁防住单实例内的并发，乐观锁防住多实例间的并发
+func (s *service) lockWithVersionRetry(roomID string, fn func() error) error {
+	var err error
+	for i := 0; i < maxVersionRetries; i++ {
+		err = s.localLock.WithLock(roomID, fn)
+		if !errors.Is(err, roomrepo.ErrVersionConflict) {
+			return err
+		}
 	}
+	return err
 }
 
 // CreateRoomRequest 定义房间创建入参模型。
@@ -83,26 +151,68 @@ type CreateRoomRequest struct {
 	BaseBet    float64 `json:"base_bet"`
 	MaxPlayers int     `json:"max_players"`
 	Password   string  `json:"password"`
+	AutoStart  bool    `json:"auto_start"` // 全员准备后自动开局（无需房主手动开始）
+
+	// 首出规则（仅跑得快）：lowest_card/seat_one/previous_winner，留空按最小牌先出
+	FirstPlayerRule string `json:"first_player_rule"`
 }
 
 func (s *service) CreateRoom(ctx context.Context, ownerID uint, req *CreateRoomRequest) (*models.GameRoom, error) {
+	// 维护模式下暂停新开房间，进行中的对局不受影响
+	if services.MaintenanceBlocked(ownerID) {
+		return nil, errs.Unavailable("系统维护中，暂时无法创建房间")
+	}
+
 	validGameTypes := map[string]bool{"texas": true, "bull": true, "running": true}
 	if !validGameTypes[req.GameType] {
-		return nil, errors.New("无效的游戏类型")
+		return nil, errs.BadRequest("无效的游戏类型")
 	}
 
 	validRoomTypes := map[string]bool{"quick": true, "middle": true, "high": true}
 	if !validRoomTypes[req.RoomType] {
-		return nil, errors.New("无效的房间类型")
+		return nil, errs.BadRequest("无效的房间类型")
+	}
+
+	validFirstPlayerRules := map[string]bool{
+		"":                                     true,
+		services.FirstPlayerRuleLowestCard:     true,
+		services.FirstPlayerRuleSeatOne:        true,
+		services.FirstPlayerRulePreviousWinner: true,
+	}
+	if !validFirstPlayerRules[req.FirstPlayerRule] {
+		return nil, errs.BadRequest("无效的首出规则")
 	}
 
-	if req.MaxPlayers < 2 || req.MaxPlayers > 10 {
-		return nil, errors.New("人数必须在2-10之间")
+	// 按游戏引擎声明的人数范围校验，防止创建开局时必然失败的房间
+	// （如跑得快最多4人，10人房间会在StartGame发牌时报错）
+	minPlayers, maxPlayers := 2, 10
+	if s.gameManager != nil {
+		if min, max, ok := s.gameManager.PlayerRange(req.GameType); ok {
+			minPlayers, maxPlayers = min, max
+		}
+	}
+	if req.MaxPlayers < minPlayers || req.MaxPlayers > maxPlayers {
+		return nil, fmt.Errorf("%s游戏人数必须在%d-%d之间", req.GameType, minPlayers, maxPlayers)
+	}
+
+	if err := validateBaseBet(req.RoomType, req.BaseBet); err != nil {
+		return nil, err
 	}
 
 	user, err := s.userRepo.GetByID(ctx, ownerID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, errs.NotFound("用户不存在")
+	}
+
+	// 限制单用户活跃房间数，防止恶意刷房间刷屏大厅
+	if s.maxRoomsPerUser > 0 {
+		count, err := s.repo.CountActiveByCreator(ctx, ownerID)
+		if err != nil {
+			return nil, fmt.Errorf("查询用户房间数失败: %w", err)
+		}
+		if count >= int64(s.maxRoomsPerUser) {
+			return nil, fmt.Errorf("你已创建%d个房间，最多同时拥有%d个，请先解散或完成已有房间", count, s.maxRoomsPerUser)
+		}
 	}
 
 	player := services.PlayerInfo{
@@ -115,7 +225,7 @@ func (s *service) CreateRoom(ctx context.Context, ownerID uint, req *CreateRoomR
 	}
 	playersJSON, _ := json.Marshal([]services.PlayerInfo{player})
 
-	roomID := fmt.Sprintf("R%s", uuid.New().String()[:8])
+	roomID := newRoomID()
 
 	var passwordHash string
 	hasPassword := req.Password != ""
@@ -127,60 +237,77 @@ func (s *service) CreateRoom(ctx context.Context, ownerID uint, req *CreateRoomR
 	}
 
 	room := models.GameRoom{
-		RoomID:         roomID,
-		GameType:       req.GameType,
-		RoomType:       req.RoomType,
-		BaseBet:        req.BaseBet,
-		MaxPlayers:     req.MaxPlayers,
-		CurrentPlayers: 1,
-		Status:         1,
-		Password:       passwordHash,
-		HasPassword:    hasPassword,
-		Players:        models.JSON(playersJSON),
-		CreatorID:      ownerID,
+		RoomID:          roomID,
+		GameType:        req.GameType,
+		RoomType:        req.RoomType,
+		BaseBet:         req.BaseBet,
+		MaxPlayers:      req.MaxPlayers,
+		CurrentPlayers:  1,
+		Status:          1,
+		Password:        passwordHash,
+		HasPassword:     hasPassword,
+		AutoStart:       req.AutoStart,
+		FirstPlayerRule: req.FirstPlayerRule,
+		Players:         models.JSON(playersJSON),
+		CreatorID:       ownerID,
 	}
 
-	if err := s.repo.Create(ctx, &room); err != nil {
-		return nil, fmt.Errorf("创建房间失败: %w", err)
+	// 房间号碰撞（uk_room_id冲突）时换新ID重试，而非把DB错误抛给用户
+	var createErr error
+	for attempt := 0; attempt < idgen.MaxCreateAttempts; attempt++ {
+		createErr = s.repo.Create(ctx, &room)
+		if createErr == nil || !database.IsDuplicateKeyError(createErr) {
+			break
+		}
+		room.RoomID = newRoomID()
 	}
+	if createErr != nil {
+		return nil, fmt.Errorf("创建房间失败: %w", createErr)
+	}
+	roomID = room.RoomID
 
 	s.syncRoomToRedis(ctx, &room)
-	go s.notifyGameServer(ctx, roomID, "room_created", ownerID, &room)
+	s.notifyGameServer(ctx, roomID, "room_created", ownerID, &room)
 
 	return &room, nil
 }
 
 func (s *service) JoinRoom(ctx context.Context, userID uint, roomID, password string) (*models.GameRoom, error) {
+	// 维护模式下暂停加入房间
+	if services.MaintenanceBlocked(userID) {
+		return nil, errs.Unavailable("系统维护中，暂时无法加入房间")
+	}
+
 	// ✅ 使用本地写锁保护加入房间操作（防止并发加入导致超员）
 	var finalRoom *models.GameRoom
 	var finalErr error
 
-	err := s.localLock.WithLock(roomID, func() error {
+	err := s.lockWithVersionRetry(roomID, func() error {
 		room, err := s.repo.GetByRoomID(ctx, roomID)
 		if err != nil {
-			finalErr = errors.New("房间不存在")
+			finalErr = errs.NotFound("房间不存在")
 			return finalErr
 		}
 
 		if room.HasPassword {
 			if password == "" {
-				finalErr = errors.New("房间需要密码")
+				finalErr = errs.Forbidden("房间需要密码")
 				return finalErr
 			}
 			if err := utils.CheckPassword(room.Password, password); err != nil {
-				finalErr = errors.New("房间密码错误")
+				finalErr = errs.Forbidden("房间密码错误")
 				return finalErr
 			}
 		}
 
 		if room.Status != 1 {
-			finalErr = errors.New("房间已开始或已结束")
+			finalErr = errs.BadRequest("房间已开始或已结束")
 			return finalErr
 		}
 
 		// ✅ 在锁保护下检查人数（防止竞态条件）
 		if room.CurrentPlayers >= room.MaxPlayers {
-			finalErr = errors.New("房间已满")
+			finalErr = errs.BadRequest("房间已满")
 			return finalErr
 		}
 
@@ -198,9 +325,15 @@ func (s *service) JoinRoom(ctx context.Context, userID uint, roomID, password st
 			}
 		}
 
+		// 不允许同时参与多局：已在其他游戏中的房间里就不能再入座
+		if err := s.ensureNoActiveGame(ctx, userID, roomID); err != nil {
+			finalErr = err
+			return finalErr
+		}
+
 		user, err := s.userRepo.GetByID(ctx, userID)
 		if err != nil {
-			finalErr = errors.New("用户不存在")
+			finalErr = errs.NotFound("用户不存在")
 			return finalErr
 		}
 
@@ -216,6 +349,7 @@ func (s *service) JoinRoom(ctx context.Context, userID uint, roomID, password st
 		playersJSON, _ := json.Marshal(players)
 		room.Players = models.JSON(playersJSON)
 		room.CurrentPlayers = len(players)
+		room.LastActivityAt = time.Now().Unix()
 
 		// ✅ 在锁保护下更新（原子操作）
 		if err := s.repo.Update(ctx, room); err != nil {
@@ -239,13 +373,43 @@ func (s *service) JoinRoom(ctx context.Context, userID uint, roomID, password st
 	return finalRoom, nil
 }
 
+// ensureNoActiveGame 校验用户没有参与其他进行中的对局。
+// 同一用户同时在多局游戏中会让托管与结算互相干扰，因此默认禁止；
+// allowMultiSession 打开时跳过检查（仅测试环境使用）。
+func (s *service) ensureNoActiveGame(ctx context.Context, userID uint, roomID string) error {
+	if s.allowMultiSession {
+		return nil
+	}
+
+	activeRoom, err := s.repo.FindInProgressByPlayer(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("查询进行中对局失败: %w", err)
+	}
+	if activeRoom != nil && activeRoom.RoomID != roomID {
+		return fmt.Errorf("用户%d在房间%s的对局还未结束，无法参与新对局", userID, activeRoom.RoomID)
+	}
+	return nil
+}
+
+// LeaveRoom 离开房间（幂等：房间不存在或不在房间中都返回成功）
 func (s *service) LeaveRoom(ctx context.Context, userID uint, roomID string) error {
+	// ✅ 与加入房间使用同一把本地写锁，防止并发修改Players JSON
+	return s.lockWithVersionRetry(roomID, func() error {
+		return s.leaveRoomOnce(ctx, userID, roomID)
+	})
+}
+
+func (s *service) leaveRoomOnce(ctx context.Context, userID uint, roomID string) error {
 	room, err := s.repo.GetByRoomID(ctx, roomID)
 	if err != nil {
-		return errors.New("房间不存在")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 房间已被删除（并发离开/解散），视为离开成功
+			return nil
+		}
+		return fmt.Errorf("查询房间失败: %w", err)
 	}
 	if room.Status == 2 {
-		return errors.New("游戏中不能离开")
+		return errs.BadRequest("游戏中不能离开")
 	}
 
 	var players []services.PlayerInfo
@@ -263,22 +427,26 @@ func (s *service) LeaveRoom(ctx context.Context, userID uint, roomID string) err
 		}
 	}
 	if !removed {
-		return errors.New("不在该房间中")
+		// 幂等：不在房间中（重复离开）视为离开成功
+		return nil
 	}
 
 	if len(newPlayers) == 0 {
-		if err := s.repo.DeleteByRoomID(ctx, roomID); err != nil {
-			return err
-		}
-		s.deleteRoomFromRedis(ctx, roomID)
-		go s.notifyGameServer(ctx, roomID, "room_deleted", userID, nil)
-		return nil
+		return s.deleteRoom(ctx, roomID, userID)
+	}
+
+	ownerLeft := room.CreatorID == userID
+
+	// 房主离开且配置为解散时，直接解散房间
+	if ownerLeft && s.dissolveOnOwnerLeave {
+		return s.deleteRoom(ctx, roomID, userID)
 	}
 
 	playersJSON, _ := json.Marshal(newPlayers)
 	room.Players = models.JSON(playersJSON)
 	room.CurrentPlayers = len(newPlayers)
-	if room.CreatorID == userID {
+	room.LastActivityAt = time.Now().Unix()
+	if ownerLeft {
 		room.CreatorID = newPlayers[0].UserID
 	}
 
@@ -287,29 +455,240 @@ func (s *service) LeaveRoom(ctx context.Context, userID uint, roomID string) err
 	}
 
 	s.syncRoomToRedis(ctx, room)
-	go s.notifyGameServer(ctx, roomID, "leave", userID, nil)
+	s.notifyGameServer(ctx, roomID, "leave", userID, nil)
+	if ownerLeft {
+		// 通知新房主已自动接任
+		s.notifyGameServer(ctx, roomID, "owner_transferred", room.CreatorID, room)
+	}
 	return nil
 }
 
-func (s *service) GetRoom(ctx context.Context, roomID string) (*models.GameRoom, error) {
+// deleteRoom 删除房间（分布式锁保护，重复删除为空操作）
+func (s *service) deleteRoom(ctx context.Context, roomID string, userID uint) error {
+	lockKey := fmt.Sprintf("room:%s:delete", roomID)
+	return s.distLock.WithLock(ctx, lockKey, lock.DefaultTTL(), func() error {
+		// DeleteByRoomID 对已删除的房间不报错，天然幂等
+		if err := s.repo.DeleteByRoomID(ctx, roomID); err != nil {
+			return fmt.Errorf("删除房间失败: %w", err)
+		}
+		s.deleteRoomFromRedis(ctx, roomID)
+		s.notifyGameServer(ctx, roomID, "room_deleted", userID, nil)
+		return nil
+	})
+}
+
+// KickPlayer 房主将指定玩家踢出房间（仅限等待中的房间）
+func (s *service) KickPlayer(ctx context.Context, ownerID uint, roomID string, targetUserID uint) error {
+	if ownerID == targetUserID {
+		return errs.BadRequest("不能踢出自己")
+	}
+
+	// ✅ 使用本地写锁保护玩家列表修改（与加入房间互斥）
+	var finalErr error
+	err := s.lockWithVersionRetry(roomID, func() error {
+		room, err := s.repo.GetByRoomID(ctx, roomID)
+		if err != nil {
+			finalErr = errs.NotFound("房间不存在")
+			return finalErr
+		}
+
+		if room.CreatorID != ownerID {
+			finalErr = errs.Forbidden("只有房主可以踢出玩家")
+			return finalErr
+		}
+		if room.Status != 1 {
+			finalErr = errs.BadRequest("只能在等待中的房间踢出玩家")
+			return finalErr
+		}
+
+		var players []services.PlayerInfo
+		if err := json.Unmarshal(room.Players, &players); err != nil {
+			finalErr = fmt.Errorf("解析玩家列表失败: %w", err)
+			return finalErr
+		}
+
+		newPlayers := make([]services.PlayerInfo, 0, len(players))
+		removed := false
+		for _, p := range players {
+			if p.UserID != targetUserID {
+				newPlayers = append(newPlayers, p)
+			} else {
+				removed = true
+			}
+		}
+		if !removed {
+			finalErr = errs.NotFound("该玩家不在房间中")
+			return finalErr
+		}
+
+		playersJSON, _ := json.Marshal(newPlayers)
+		room.Players = models.JSON(playersJSON)
+		room.CurrentPlayers = len(newPlayers)
+		room.LastActivityAt = time.Now().Unix()
+
+		if err := s.repo.Update(ctx, room); err != nil {
+			finalErr = fmt.Errorf("踢出玩家失败: %w", err)
+			return finalErr
+		}
+
+		s.syncRoomToRedis(ctx, room)
+
+		// 通知游戏服务器，被踢玩家的客户端据此断开房间
+		s.notifyGameServer(ctx, roomID, "kicked", targetUserID, room)
+		return nil
+	})
+
+	if err != nil {
+		return finalErr
+	}
+	return nil
+}
+
+// TransferOwner 房主主动转让房主给房间内的其他玩家（仅限等待中的房间）
+func (s *service) TransferOwner(ctx context.Context, ownerID uint, roomID string, newOwnerID uint) error {
+	if ownerID == newOwnerID {
+		return errs.BadRequest("不能转让给自己")
+	}
+
+	// ✅ 使用本地写锁保护房主变更（与加入/离开互斥）
+	var finalErr error
+	err := s.lockWithVersionRetry(roomID, func() error {
+		room, err := s.repo.GetByRoomID(ctx, roomID)
+		if err != nil {
+			finalErr = errs.NotFound("房间不存在")
+			return finalErr
+		}
+
+		if room.CreatorID != ownerID {
+			finalErr = errs.Forbidden("只有房主可以转让房主")
+			return finalErr
+		}
+		if room.Status != 1 {
+			finalErr = errs.BadRequest("只能在等待中的房间转让房主")
+			return finalErr
+		}
+
+		var players []services.PlayerInfo
+		if err := json.Unmarshal(room.Players, &players); err != nil {
+			finalErr = fmt.Errorf("解析玩家列表失败: %w", err)
+			return finalErr
+		}
+
+		found := false
+		for _, p := range players {
+			if p.UserID == newOwnerID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			finalErr = errs.NotFound("该玩家不在房间中")
+			return finalErr
+		}
+
+		room.CreatorID = newOwnerID
+		room.LastActivityAt = time.Now().Unix()
+		if err := s.repo.Update(ctx, room); err != nil {
+			finalErr = fmt.Errorf("转让房主失败: %w", err)
+			return finalErr
+		}
+
+		s.syncRoomToRedis(ctx, room)
+		s.notifyGameServer(ctx, roomID, "owner_transferred", newOwnerID, room)
+		return nil
+	})
+
+	if err != nil {
+		return finalErr
+	}
+	return nil
+}
+
+// RoomInfo 房间信息DTO，返回给客户端的统一房间结构
+// Players已解析为类型化列表，客户端无需再处理JSON字符串或单独查询玩家资料
+type RoomInfo struct {
+	ID             uint                  `json:"id"`
+	RoomID         string                `json:"room_id"`
+	GameType       string                `json:"game_type"`
+	RoomType       string                `json:"room_type"`
+	BaseBet        float64               `json:"base_bet"`
+	MaxPlayers     int                   `json:"max_players"`
+	CurrentPlayers int                   `json:"current_players"`
+	Status         int8                  `json:"status"`
+	HasPassword    bool                  `json:"has_password"`
+	CreatorID      uint                  `json:"creator_id"`
+	Players        []services.PlayerInfo `json:"players"`
+	ReadyCount     int                   `json:"ready_count"`
+	CreatedAt      int64                 `json:"created_at"`
+}
+
+// toRoomInfo 将模型转换为DTO（密码哈希等敏感字段不暴露）
+func toRoomInfo(room *models.GameRoom) *RoomInfo {
+	var players []services.PlayerInfo
+	_ = json.Unmarshal(room.Players, &players)
+
+	readyCount := 0
+	for _, p := range players {
+		if p.Ready {
+			readyCount++
+		}
+	}
+
+	return &RoomInfo{
+		ID:             room.ID,
+		RoomID:         room.RoomID,
+		GameType:       room.GameType,
+		RoomType:       room.RoomType,
+		BaseBet:        room.BaseBet,
+		MaxPlayers:     room.MaxPlayers,
+		CurrentPlayers: room.CurrentPlayers,
+		Status:         room.Status,
+		HasPassword:    room.HasPassword,
+		CreatorID:      room.CreatorID,
+		Players:        players,
+		ReadyCount:     readyCount,
+		CreatedAt:      room.CreatedAt,
+	}
+}
+
+func (s *service) GetRoom(ctx context.Context, roomID string) (*RoomInfo, error) {
 	room, err := s.repo.GetByRoomID(ctx, roomID)
 	if err != nil {
-		return nil, errors.New("房间不存在")
+		return nil, errs.NotFound("房间不存在")
 	}
-	return room, nil
+	return toRoomInfo(room), nil
 }
 
-func (s *service) ListRooms(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, error) {
-	return s.repo.List(ctx, filter)
+func (s *service) ListRooms(ctx context.Context, filter roomrepo.ListFilter) ([]*RoomInfo, int64, error) {
+	rooms, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	infos := make([]*RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		infos = append(infos, toRoomInfo(room))
+	}
+	return infos, total, nil
 }
 
 func (s *service) Ready(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error) {
+	var room *models.GameRoom
+	err := withVersionRetry(func() error {
+		var onceErr error
+		room, onceErr = s.readyOnce(ctx, userID, roomID)
+		return onceErr
+	})
+	return room, err
+}
+
+func (s *service) readyOnce(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error) {
 	room, err := s.repo.GetByRoomID(ctx, roomID)
 	if err != nil {
-		return nil, errors.New("房间不存在")
+		return nil, errs.NotFound("房间不存在")
 	}
 	if room.Status != 1 {
-		return nil, errors.New("只能等待中房间准备")
+		return nil, errs.BadRequest("只能等待中房间准备")
 	}
 
 	var players []services.PlayerInfo
@@ -321,33 +700,60 @@ func (s *service) Ready(ctx context.Context, userID uint, roomID string) (*model
 	for i := range players {
 		if players[i].UserID == userID {
 			players[i].Ready = true
+			players[i].ReadyAt = time.Now().Unix()
+			players[i].UnreadyWarnedAt = 0
 			found = true
 			break
 		}
 	}
 	if !found {
-		return nil, errors.New("不在该房间中")
+		return nil, errs.BadRequest("不在该房间中")
 	}
 
 	playersJSON, _ := json.Marshal(players)
 	room.Players = models.JSON(playersJSON)
+	room.LastActivityAt = time.Now().Unix()
 
 	if err := s.repo.Update(ctx, room); err != nil {
 		return nil, fmt.Errorf("准备失败: %w", err)
 	}
 
 	s.syncRoomToRedis(ctx, room)
-	go s.notifyGameServer(ctx, roomID, "ready", userID, room)
+	s.notifyGameServer(ctx, roomID, "ready", userID, room)
+
+	// 自动开局：全员准备且满足最低人数时由系统触发开始流程。
+	// startGame内部持分布式锁并校验Status==1，与房主手动开始并发时只会成功一次
+	if room.AutoStart {
+		if canStart, _ := s.canStartGame(room); canStart {
+			if _, err := s.startGame(ctx, room.CreatorID, roomID, false); err != nil {
+				logger.Logger.Warn("自动开局失败",
+					zap.String("room_id", roomID),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
 	return room, nil
 }
 
 func (s *service) CancelReady(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error) {
+	var room *models.GameRoom
+	err := withVersionRetry(func() error {
+		var onceErr error
+		room, onceErr = s.cancelReadyOnce(ctx, userID, roomID)
+		return onceErr
+	})
+	return room, err
+}
+
+func (s *service) cancelReadyOnce(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error) {
 	room, err := s.repo.GetByRoomID(ctx, roomID)
 	if err != nil {
-		return nil, errors.New("房间不存在")
+		return nil, errs.NotFound("房间不存在")
 	}
 	if room.Status != 1 {
-		return nil, errors.New("只能等待中房间取消准备")
+		return nil, errs.BadRequest("只能等待中房间取消准备")
 	}
 
 	var players []services.PlayerInfo
@@ -359,42 +765,55 @@ func (s *service) CancelReady(ctx context.Context, userID uint, roomID string) (
 	for i := range players {
 		if players[i].UserID == userID {
 			players[i].Ready = false
+			players[i].ReadyAt = 0
 			found = true
 			break
 		}
 	}
 	if !found {
-		return nil, errors.New("不在该房间中")
+		return nil, errs.BadRequest("不在该房间中")
 	}
 
 	playersJSON, _ := json.Marshal(players)
 	room.Players = models.JSON(playersJSON)
+	room.LastActivityAt = time.Now().Unix()
 
 	if err := s.repo.Update(ctx, room); err != nil {
 		return nil, fmt.Errorf("取消准备失败: %w", err)
 	}
 
 	s.syncRoomToRedis(ctx, room)
-	go s.notifyGameServer(ctx, roomID, "cancel_ready", userID, room)
+	s.notifyGameServer(ctx, roomID, "cancel_ready", userID, room)
 	return room, nil
 }
 
 func (s *service) StartGame(ctx context.Context, userID uint, roomID string) (*models.GameRoom, error) {
+	return s.startGame(ctx, userID, roomID, true)
+}
+
+// startGame 开始游戏的实际流程。enforceCreator为false时跳过房主校验
+// （自动开局由系统触发，不依赖房主在线）。
+func (s *service) startGame(ctx context.Context, userID uint, roomID string, enforceCreator bool) (*models.GameRoom, error) {
+	// 维护模式下暂停新开对局（已开始的对局照常进行和结算）
+	if services.MaintenanceBlocked(userID) {
+		return nil, errs.Unavailable("系统维护中，暂时无法开始游戏")
+	}
+
 	// ✅ 使用分布式锁保护开始游戏操作（防止重复开始）
 	lockKey := fmt.Sprintf("room:%s:start", roomID)
 
 	var finalRoom *models.GameRoom
 	var finalErr error
 
-	err := s.distLock.WithLock(ctx, lockKey, 10*time.Second, func() error {
+	err := s.distLock.WithLock(ctx, lockKey, lock.LongTTL(), func() error {
 		room, err := s.repo.GetByRoomID(ctx, roomID)
 		if err != nil {
-			finalErr = errors.New("房间不存在")
+			finalErr = errs.NotFound("房间不存在")
 			return finalErr
 		}
 
-		if room.CreatorID != userID {
-			finalErr = errors.New("只有创建者可以开始游戏")
+		if enforceCreator && room.CreatorID != userID {
+			finalErr = errs.BadRequest("只有创建者可以开始游戏")
 			return finalErr
 		}
 
@@ -404,19 +823,31 @@ func (s *service) StartGame(ctx context.Context, userID uint, roomID string) (*m
 			return finalErr
 		}
 		if !canStart {
-			finalErr = errors.New("还有玩家未准备")
+			finalErr = errs.BadRequest("还有玩家未准备")
 			return finalErr
 		}
 
 		// ✅ 在锁保护下检查状态（防止重复开始）
 		if room.Status != 1 {
-			finalErr = errors.New("房间状态不正确")
+			finalErr = errs.BadRequest("房间状态不正确")
 			return finalErr
 		}
 
+		// 开局前兜底检查：任何玩家都不能还挂在其他进行中的对局里
+		// （加入后其他房间才开局的情况在JoinRoom检查不到）
+		var players []services.PlayerInfo
+		if err := json.Unmarshal(room.Players, &players); err == nil {
+			for _, p := range players {
+				if err := s.ensureNoActiveGame(ctx, p.UserID, roomID); err != nil {
+					finalErr = err
+					return finalErr
+				}
+			}
+		}
+
 		// ✅ 使用注入的 GameManager
 		if s.gameManager == nil {
-			finalErr = errors.New("游戏管理器未初始化")
+			finalErr = errs.BadRequest("游戏管理器未初始化")
 			return finalErr
 		}
 
@@ -440,18 +871,330 @@ func (s *service) StartGame(ctx context.Context, userID uint, roomID string) (*m
 	})
 
 	if err != nil {
-		return nil, finalErr
+		if finalErr != nil {
+			return nil, finalErr
+		}
+		// 锁获取失败：区分竞争超时与系统错误
+		return nil, lock.FriendlyError(err)
 	}
 
 	return finalRoom, nil
 }
 
+// baseBetBounds 各房间类型的默认底注范围（可被SystemConfig覆盖）
+var baseBetBounds = map[string][2]float64{
+	"quick":  {1, 50},
+	"middle": {50, 500},
+	"high":   {500, 5000},
+}
+
+// validateBaseBet 校验底注在房间类型允许的范围内
+// 范围优先读取SystemConfig（room_base_bet_min_<type>/room_base_bet_max_<type>），未配置时用默认值
+func validateBaseBet(roomType string, baseBet float64) error {
+	if math.IsNaN(baseBet) || math.IsInf(baseBet, 0) || baseBet <= 0 {
+		return errs.BadRequest("底注必须是大于0的有效数值")
+	}
+
+	bounds, ok := baseBetBounds[roomType]
+	if !ok {
+		return errs.BadRequest("无效的房间类型")
+	}
+
+	min := services.GetSystemConfigFloat(fmt.Sprintf("room_base_bet_min_%s", roomType), bounds[0])
+	max := services.GetSystemConfigFloat(fmt.Sprintf("room_base_bet_max_%s", roomType), bounds[1])
+
+	if baseBet < min || baseBet > max {
+		return fmt.Errorf("%s房间底注必须在%.2f-%.2f之间", roomType, min, max)
+	}
+	return nil
+}
+
+// quickMatchBaseBets 快速匹配创建房间时各房间类型的默认底注
+var quickMatchBaseBets = map[string]float64{
+	"quick":  10,
+	"middle": 100,
+	"high":   500,
+}
+
+// QuickMatch 快速匹配：加入符合条件的等待中房间，没有则自动创建
+// 返回的bool表示是否新建了房间（true新建，false加入已有房间）
+func (s *service) QuickMatch(ctx context.Context, userID uint, gameType, roomType string) (*models.GameRoom, bool, error) {
+	validGameTypes := map[string]bool{"texas": true, "bull": true, "running": true}
+	if !validGameTypes[gameType] {
+		return nil, false, errs.BadRequest("无效的游戏类型")
+	}
+	validRoomTypes := map[string]bool{"quick": true, "middle": true, "high": true}
+	if !validRoomTypes[roomType] {
+		return nil, false, errs.BadRequest("无效的房间类型")
+	}
+
+	// ✅ 使用分布式锁串行化同类型的快速匹配
+	// 防止两个玩家同时匹配到只剩一个空位的房间
+	lockKey := fmt.Sprintf("room:quickmatch:%s:%s", gameType, roomType)
+
+	var finalRoom *models.GameRoom
+	var created bool
+	var finalErr error
+
+	err := s.distLock.WithLock(ctx, lockKey, lock.LongTTL(), func() error {
+		rooms, _, err := s.repo.List(ctx, roomrepo.ListFilter{
+			GameType: gameType,
+			Status:   1, // 等待中
+			Limit:    100,
+		})
+		if err != nil {
+			finalErr = fmt.Errorf("查询房间失败: %w", err)
+			return finalErr
+		}
+
+		// 依次尝试加入未满、无密码的同类型房间
+		for _, candidate := range rooms {
+			if candidate.RoomType != roomType || candidate.HasPassword {
+				continue
+			}
+			if candidate.CurrentPlayers >= candidate.MaxPlayers {
+				continue
+			}
+
+			room, err := s.JoinRoom(ctx, userID, candidate.RoomID, "")
+			if err != nil {
+				// 可能刚好被手动加入的玩家占满，继续尝试下一个
+				continue
+			}
+			finalRoom = room
+			return nil
+		}
+
+		// 没有可加入的房间，创建新房间
+		room, err := s.CreateRoom(ctx, userID, &CreateRoomRequest{
+			GameType:   gameType,
+			RoomType:   roomType,
+			BaseBet:    quickMatchBaseBets[roomType],
+			MaxPlayers: 4,
+		})
+		if err != nil {
+			finalErr = err
+			return finalErr
+		}
+		finalRoom = room
+		created = true
+		return nil
+	})
+
+	if err != nil {
+		if finalErr != nil {
+			return nil, false, finalErr
+		}
+		// 锁获取失败：区分竞争超时与系统错误
+		return nil, false, lock.FriendlyError(err)
+	}
+
+	return finalRoom, created, nil
+}
+
+// StartIdleReaper 启动闲置房间清理（定时删除长时间无活动的等待中房间）
+// 只处理Status==1的房间，游戏中（Status==2）的房间不会被清理
+func (s *service) StartIdleReaper(idleTimeout time.Duration) {
+	if idleTimeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	go func() {
+		for range ticker.C {
+			s.reapIdleRooms(idleTimeout)
+		}
+	}()
+}
+
+// reapIdleRooms 清理一轮闲置房间
+func (s *service) reapIdleRooms(idleTimeout time.Duration) {
+	ctx := context.Background()
+	cutoff := time.Now().Add(-idleTimeout).Unix()
+
+	rooms, err := s.repo.ListIdleWaiting(ctx, cutoff)
+	if err != nil {
+		logger.Logger.Warn("查询闲置房间失败", zap.Error(err))
+		return
+	}
+
+	for _, room := range rooms {
+		roomID := room.RoomID
+		// 锁内复查状态和活动时间，避免清理刚有玩家操作的房间
+		s.localLock.WithLock(roomID, func() error {
+			current, err := s.repo.GetByRoomID(ctx, roomID)
+			if err != nil {
+				return nil
+			}
+			if current.Status != 1 || current.LastActivityAt >= cutoff {
+				return nil
+			}
+
+			if err := s.repo.DeleteByRoomID(ctx, roomID); err != nil {
+				logger.Logger.Error("清理闲置房间失败",
+					zap.String("room_id", roomID),
+					zap.Error(err),
+				)
+				return nil
+			}
+			s.deleteRoomFromRedis(ctx, roomID)
+			s.notifyGameServer(ctx, roomID, "room_deleted", 0, nil)
+
+			logger.Logger.Info("闲置房间已清理",
+				zap.String("room_id", roomID),
+				zap.Int64("last_activity_at", current.LastActivityAt),
+			)
+			return nil
+		})
+	}
+}
+
+// readyCheckInterval 准备超时检查的轮询间隔
+const readyCheckInterval = 10 * time.Second
+
+// StartReadyTimeoutChecker 启动准备超时检查。
+// 已有玩家准备后，迟迟不准备的玩家先被提醒（warnAfter），
+// 继续拖延到kickAfter后被移出房间，避免一人挂机把房间卡死。
+// 任一阈值为0时不启动
+func (s *service) StartReadyTimeoutChecker(warnAfter, kickAfter time.Duration) {
+	if warnAfter <= 0 || kickAfter <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(readyCheckInterval)
+	go func() {
+		for range ticker.C {
+			s.checkReadyTimeouts(warnAfter, kickAfter)
+		}
+	}()
+}
+
+// checkReadyTimeouts 扫描一轮等待中房间，处理准备超时的玩家
+func (s *service) checkReadyTimeouts(warnAfter, kickAfter time.Duration) {
+	ctx := context.Background()
+
+	rooms, _, err := s.repo.List(ctx, roomrepo.ListFilter{Status: 1, Limit: 500})
+	if err != nil {
+		logger.Logger.Warn("查询等待中房间失败", zap.Error(err))
+		return
+	}
+
+	for _, room := range rooms {
+		roomID := room.RoomID
+		if err := s.lockWithVersionRetry(roomID, func() error {
+			return s.checkRoomReadyTimeout(ctx, roomID, warnAfter, kickAfter)
+		}); err != nil {
+			logger.Logger.Warn("处理准备超时失败",
+				zap.String("room_id", roomID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// checkRoomReadyTimeout 检查单个房间的准备超时（调用方需持有房间锁）。
+// 以最后一名已准备玩家的准备时间为起点计时：超过warnAfter提醒未准备玩家，
+// 已提醒且超过kickAfter的玩家被移出房间
+func (s *service) checkRoomReadyTimeout(ctx context.Context, roomID string, warnAfter, kickAfter time.Duration) error {
+	room, err := s.repo.GetByRoomID(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if room.Status != 1 {
+		return nil
+	}
+
+	var players []services.PlayerInfo
+	if err := json.Unmarshal(room.Players, &players); err != nil {
+		return fmt.Errorf("解析玩家列表失败: %w", err)
+	}
+
+	// 计时起点：最后一名已准备玩家的准备时间。
+	// 没人准备或全员已准备（等房主开始）都不属于被单人卡住的情况
+	var anchor int64
+	unreadyCount := 0
+	for _, p := range players {
+		if p.Ready {
+			if p.ReadyAt > anchor {
+				anchor = p.ReadyAt
+			}
+		} else {
+			unreadyCount++
+		}
+	}
+	if anchor == 0 || unreadyCount == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	waited := time.Duration(now-anchor) * time.Second
+
+	var warned []uint
+	var removed []uint
+	newPlayers := make([]services.PlayerInfo, 0, len(players))
+	for _, p := range players {
+		switch {
+		case p.Ready:
+			newPlayers = append(newPlayers, p)
+		case p.UnreadyWarnedAt > 0 && waited >= kickAfter:
+			removed = append(removed, p.UserID)
+		case p.UnreadyWarnedAt == 0 && waited >= warnAfter:
+			p.UnreadyWarnedAt = now
+			warned = append(warned, p.UserID)
+			newPlayers = append(newPlayers, p)
+		default:
+			newPlayers = append(newPlayers, p)
+		}
+	}
+	if len(warned) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// 被移出的房主把房主转给剩余的第一位玩家（有人已准备，房间不会被清空）
+	ownerRemoved := false
+	for _, userID := range removed {
+		if room.CreatorID == userID {
+			ownerRemoved = true
+		}
+	}
+	if ownerRemoved {
+		room.CreatorID = newPlayers[0].UserID
+	}
+
+	playersJSON, _ := json.Marshal(newPlayers)
+	room.Players = models.JSON(playersJSON)
+	room.CurrentPlayers = len(newPlayers)
+
+	if err := s.repo.Update(ctx, room); err != nil {
+		return fmt.Errorf("更新房间失败: %w", err)
+	}
+
+	s.syncRoomToRedis(ctx, room)
+	for _, userID := range warned {
+		s.notifyGameServer(ctx, roomID, "ready_timeout_warning", userID, room)
+	}
+	for _, userID := range removed {
+		s.notifyGameServer(ctx, roomID, "ready_timeout_removed", userID, room)
+		logger.Logger.Info("玩家准备超时被移出房间",
+			zap.String("room_id", roomID),
+			zap.Uint("user_id", userID),
+		)
+	}
+	if ownerRemoved {
+		s.notifyGameServer(ctx, roomID, "owner_transferred", room.CreatorID, room)
+	}
+	return nil
+}
+
 func (s *service) canStartGame(room *models.GameRoom) (bool, error) {
 	if room.Status != 1 {
-		return false, errors.New("房间状态不正确")
+		return false, errs.BadRequest("房间状态不正确")
 	}
 	if room.CurrentPlayers < 2 {
-		return false, errors.New("至少需要2人才能开始")
+		return false, errs.BadRequest("至少需要2人才能开始")
 	}
 
 	var players []services.PlayerInfo
@@ -536,36 +1279,26 @@ func (s *service) asyncNotifyGameServer(ctx context.Context, roomID, action stri
 	}
 
 	// ✅ 使用 Worker Pool 提交任务（限制并发，防止过载）
-	if s.notifyPool != nil {
-		s.notifyPool.Submit(func(taskCtx context.Context) error {
-			// ✅ 创建带超时的 HTTP 请求
-			httpReq, err := http.NewRequestWithContext(taskCtx, "POST", s.notifyURL, bytes.NewBuffer(jsonData))
-			if err != nil {
-				return err
-			}
-			httpReq.Header.Set("Content-Type", "application/json")
-
-			// ✅ 使用带超时的 HTTP 客户端
-			client := &http.Client{
-				Timeout: 5 * time.Second,
-			}
-
-			resp, err := client.Do(httpReq)
-			if err != nil {
-				return fmt.Errorf("通知游戏服务器失败: %w", err)
-			}
-			defer resp.Body.Close()
-
-			return nil
+	// 池满或已关闭时降级为直接发送，避免通知静默丢失
+	if s.notifyPool == nil || !s.notifyPool.TrySubmit(func(taskCtx context.Context) error {
+		return retry.Do(taskCtx, notifyRetryOpts, func() error {
+			return s.postNotify(taskCtx, jsonData)
 		})
-	} else {
-		// 降级方案：直接发送（如果 Worker Pool 未初始化）
+	}) {
+		// 降级方案：直接发送（派生请求上下文，请求取消时一并终止）
 		go func() {
-			_, _ = http.Post(s.notifyURL, "application/json", bytes.NewBuffer(jsonData))
+			sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			_ = s.postNotify(sendCtx, jsonData)
 		}()
 	}
 }
 
+// NotifyRoomAction 复用通知管线（Worker Pool + 重试）对外发送自定义动作
+func (s *service) NotifyRoomAction(ctx context.Context, roomID, action string, userID uint, data map[string]interface{}) {
+	s.notifyGameServerWithData(ctx, roomID, action, userID, data)
+}
+
 func (s *service) notifyGameServerWithData(ctx context.Context, roomID, action string, userID uint, roomData map[string]interface{}) {
 	if s.notifyURL == "" {
 		return
@@ -580,32 +1313,102 @@ func (s *service) notifyGameServerWithData(ctx context.Context, roomID, action s
 	if err != nil {
 		return
 	}
+
+	// ✅ 与 asyncNotifyGameServer 一致，通过 Worker Pool 发送（关闭时可排空在途通知）
+	// 池满或已关闭时降级为直接发送，避免通知静默丢失
+	if s.notifyPool != nil && s.notifyPool.TrySubmit(func(taskCtx context.Context) error {
+		return retry.Do(taskCtx, notifyRetryOpts, func() error {
+			return s.postNotify(taskCtx, jsonData)
+		})
+	}) {
+		return
+	}
+
+	// 降级方案：直接发送（带派生超时，请求取消时一并终止）
 	go func() {
-		_, _ = http.Post(s.notifyURL, "application/json", bytes.NewBuffer(jsonData))
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_ = s.postNotify(sendCtx, jsonData)
 	}()
 }
 
+// notifyRetryOpts 游戏服务器通知的重试策略（短退避，尽快送达）
+var notifyRetryOpts = retry.Options{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+}
+
+// postNotify 向游戏服务器发送一次通知请求
+func (s *service) postNotify(ctx context.Context, jsonData []byte) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.notifyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	// 透传请求追踪ID，游戏服务器用同一ID记录日志
+	if requestID := tracing.RequestID(ctx); requestID != "" {
+		httpReq.Header.Set(tracing.HeaderRequestID, requestID)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("通知游戏服务器失败: %w", err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// syncFailCooldown Redis同步失败后的冷却窗口，窗口内不再尝试同步
+const syncFailCooldown = 10 * time.Second
+
+// syncRoomToRedis 把房间快照写入Redis（仅作缓存，MySQL才是数据源）。
+// 同步失败不影响房间操作本身：记录指标并进入冷却，冷却期内快速跳过。
 func (s *service) syncRoomToRedis(ctx context.Context, room *models.GameRoom) {
 	if s.redis == nil {
 		return
 	}
+
+	// Redis刚失败过则直接跳过，避免每次房间操作都等一遍超时
+	if failedAt := atomic.LoadInt64(&s.syncFailedAt); failedAt > 0 &&
+		time.Since(time.Unix(0, failedAt)) < syncFailCooldown {
+		metrics.GetGlobalMetrics().RecordRoomSyncSkip()
+		return
+	}
+
+	// 派生超时，避免请求挂起时Redis同步无限等待
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
 	key := fmt.Sprintf("room:%s", room.RoomID)
 	roomData := map[string]interface{}{
 		"room_id":         room.RoomID,
 		"game_type":       room.GameType,
 		"room_type":       room.RoomType,
-		"base_bet":        room.BaseBet,
-		"max_players":     room.MaxPlayers,
-		"current_players": room.CurrentPlayers,
-		"status":          room.Status,
-		"creator_id":      room.CreatorID,
-		"updated_at":      room.UpdatedAt,
-	}
-	for field, value := range roomData {
-		_ = s.redis.HSet(ctx, key, field, fmt.Sprintf("%v", value)).Err()
-	}
-	_ = s.redis.HSet(ctx, key, "players", string(room.Players)).Err()
-	_ = s.redis.Expire(ctx, key, time.Hour).Err()
+		"base_bet":        fmt.Sprintf("%v", room.BaseBet),
+		"max_players":     fmt.Sprintf("%v", room.MaxPlayers),
+		"current_players": fmt.Sprintf("%v", room.CurrentPlayers),
+		"status":          fmt.Sprintf("%v", room.Status),
+		"creator_id":      fmt.Sprintf("%v", room.CreatorID),
+		"updated_at":      fmt.Sprintf("%v", room.UpdatedAt),
+		"players":         string(room.Players),
+	}
+
+	// 单次pipeline批量写入，替代逐字段HSet
+	pipe := s.redis.Pipeline()
+	pipe.HSet(ctx, key, roomData)
+	pipe.Expire(ctx, key, time.Hour)
+	_, err := pipe.Exec(ctx)
+	metrics.GetGlobalMetrics().RecordRoomSync(err)
+	if err != nil {
+		atomic.StoreInt64(&s.syncFailedAt, time.Now().UnixNano())
+		logger.Logger.Warn("房间Redis同步失败，进入冷却",
+			zap.String("room_id", room.RoomID),
+			zap.Duration("cooldown", syncFailCooldown),
+			zap.Error(err))
+		return
+	}
+	atomic.StoreInt64(&s.syncFailedAt, 0)
 }
 
 func (s *service) deleteRoomFromRedis(ctx context.Context, roomID string) {
@@ -0,0 +1,358 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/idgen"
+	"github.com/kaifa/game-platform/internal/lock"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/metrics"
+	roomrepo "github.com/kaifa/game-platform/internal/repository/room"
+	userrepo "github.com/kaifa/game-platform/internal/repository/user"
+	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/kaifa/game-platform/pkg/services"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func init() {
+	// 测试环境不初始化完整日志系统
+	logger.Logger = zap.NewNop()
+	logger.Sugar = logger.Logger.Sugar()
+}
+
+// fakeRoomRepo 内存房间仓储，room_id冲突时返回MySQL风格的唯一键错误
+type fakeRoomRepo struct {
+	rooms map[string]*models.GameRoom
+}
+
+func newFakeRoomRepo() *fakeRoomRepo {
+	return &fakeRoomRepo{rooms: make(map[string]*models.GameRoom)}
+}
+
+func (r *fakeRoomRepo) Create(ctx context.Context, room *models.GameRoom) error {
+	if _, ok := r.rooms[room.RoomID]; ok {
+		return errors.New("Error 1062 (23000): Duplicate entry '" + room.RoomID + "' for key 'game_rooms.uk_room_id'")
+	}
+	r.rooms[room.RoomID] = room
+	return nil
+}
+
+func (r *fakeRoomRepo) Update(ctx context.Context, room *models.GameRoom) error {
+	r.rooms[room.RoomID] = room
+	return nil
+}
+
+func (r *fakeRoomRepo) DeleteByRoomID(ctx context.Context, roomID string) error {
+	delete(r.rooms, roomID)
+	return nil
+}
+
+func (r *fakeRoomRepo) GetByRoomID(ctx context.Context, roomID string) (*models.GameRoom, error) {
+	room, ok := r.rooms[roomID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return room, nil
+}
+
+func (r *fakeRoomRepo) List(ctx context.Context, filter roomrepo.ListFilter) ([]*models.GameRoom, int64, error) {
+	var rooms []*models.GameRoom
+	for _, room := range r.rooms {
+		if filter.Status != 0 && room.Status != filter.Status {
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, int64(len(rooms)), nil
+}
+
+func (r *fakeRoomRepo) Count(ctx context.Context, filter roomrepo.ListFilter) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRoomRepo) ListIdleWaiting(ctx context.Context, cutoff int64) ([]*models.GameRoom, error) {
+	return nil, nil
+}
+
+func (r *fakeRoomRepo) CountActiveByCreator(ctx context.Context, creatorID uint) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRoomRepo) FindInProgressByPlayer(ctx context.Context, userID uint) (*models.GameRoom, error) {
+	return nil, nil
+}
+
+// fakeUserRepo 内存用户仓储，仅实现CreateRoom用到的GetByID
+type fakeUserRepo struct{}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	return &models.User{ID: id, UID: int64(id), Nickname: "测试用户"}, nil
+}
+
+func (r *fakeUserRepo) GetByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *models.User) error { return nil }
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *models.User) error { return nil }
+
+func (r *fakeUserRepo) CreateWallet(ctx context.Context, wallet *models.UserWallet) error {
+	return nil
+}
+
+func (r *fakeUserRepo) CreateLoginLog(ctx context.Context, log *models.UserLogin) error { return nil }
+
+func (r *fakeUserRepo) GetWallet(ctx context.Context, userID uint) (*models.UserWallet, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) UpdateBalance(ctx context.Context, userID uint, newBalance float64) error {
+	return nil
+}
+
+func (r *fakeUserRepo) BatchUpdateBalances(ctx context.Context, balances map[uint]float64) error {
+	return nil
+}
+
+var _ roomrepo.Repository = (*fakeRoomRepo)(nil)
+var _ userrepo.Repository = (*fakeUserRepo)(nil)
+
+func newTestService(repo roomrepo.Repository) Service {
+	return New(repo, &fakeUserRepo{}, nil, nil, "", nil, nil, nil, false, 0, false)
+}
+
+func testCreateRequest() *CreateRoomRequest {
+	return &CreateRoomRequest{
+		GameType:   "texas",
+		RoomType:   "quick",
+		BaseBet:    10,
+		MaxPlayers: 4,
+	}
+}
+
+// seedRoomIDs 用固定序列替换房间号生成器（种子生成器），测试结束后恢复
+func seedRoomIDs(t *testing.T, ids []string) {
+	t.Helper()
+
+	orig := newRoomID
+	idx := 0
+	newRoomID = func() string {
+		id := ids[idx%len(ids)]
+		idx++
+		return id
+	}
+	t.Cleanup(func() { newRoomID = orig })
+}
+
+// TestCreateRoomRetriesOnIDCollision 房间号碰撞时应换新ID重试而非报错
+func TestCreateRoomRetriesOnIDCollision(t *testing.T) {
+	repo := newFakeRoomRepo()
+	svc := newTestService(repo)
+
+	// 种子生成器：第二次创建时先撞上已有房间号，重试后拿到唯一ID
+	seedRoomIDs(t, []string{"RCOLLIDE", "RCOLLIDE", "RUNIQUE01"})
+
+	first, err := svc.CreateRoom(context.Background(), 1, testCreateRequest())
+	if err != nil {
+		t.Fatalf("首次创建失败: %v", err)
+	}
+	if first.RoomID != "RCOLLIDE" {
+		t.Fatalf("首个房间号 = %s, 期望 RCOLLIDE", first.RoomID)
+	}
+
+	second, err := svc.CreateRoom(context.Background(), 2, testCreateRequest())
+	if err != nil {
+		t.Fatalf("碰撞后创建失败: %v", err)
+	}
+	if second.RoomID != "RUNIQUE01" {
+		t.Fatalf("碰撞重试后的房间号 = %s, 期望 RUNIQUE01", second.RoomID)
+	}
+	if len(repo.rooms) != 2 {
+		t.Fatalf("仓储中房间数 = %d, 期望 2", len(repo.rooms))
+	}
+}
+
+// TestCreateRoomCollisionExhausted 重试次数内始终碰撞时应返回错误
+func TestCreateRoomCollisionExhausted(t *testing.T) {
+	repo := newFakeRoomRepo()
+	svc := newTestService(repo)
+
+	// 生成器只会产出同一个ID，重试必然耗尽
+	seedRoomIDs(t, []string{"RCOLLIDE"})
+
+	if _, err := svc.CreateRoom(context.Background(), 1, testCreateRequest()); err != nil {
+		t.Fatalf("首次创建失败: %v", err)
+	}
+
+	_, err := svc.CreateRoom(context.Background(), 2, testCreateRequest())
+	if err == nil {
+		t.Fatal("重试耗尽后应返回错误")
+	}
+	if !strings.Contains(err.Error(), "创建房间失败") {
+		t.Fatalf("错误信息 = %v, 期望包含 创建房间失败", err)
+	}
+	if len(repo.rooms) != 1 {
+		t.Fatalf("仓储中房间数 = %d, 期望 1", len(repo.rooms))
+	}
+}
+
+// TestCreateRoomDefaultGenerator 默认生成器应产出R前缀的房间号
+func TestCreateRoomDefaultGenerator(t *testing.T) {
+	if id := idgen.RoomID(); !strings.HasPrefix(id, "R") {
+		t.Fatalf("房间号 = %s, 期望R前缀", id)
+	}
+}
+
+// seedWaitingRoom 构造一个等待中的房间并写入仓储
+func seedWaitingRoom(repo *fakeRoomRepo, roomID string, creatorID uint, players []services.PlayerInfo) *models.GameRoom {
+	playersJSON, _ := json.Marshal(players)
+	room := &models.GameRoom{
+		RoomID:         roomID,
+		GameType:       "running",
+		RoomType:       "quick",
+		MaxPlayers:     4,
+		CurrentPlayers: len(players),
+		Status:         1,
+		CreatorID:      creatorID,
+		Players:        models.JSON(playersJSON),
+	}
+	repo.rooms[roomID] = room
+	return room
+}
+
+// roomPlayers 解析房间玩家列表
+func roomPlayers(t *testing.T, room *models.GameRoom) []services.PlayerInfo {
+	t.Helper()
+	var players []services.PlayerInfo
+	if err := json.Unmarshal(room.Players, &players); err != nil {
+		t.Fatalf("解析玩家列表失败: %v", err)
+	}
+	return players
+}
+
+// TestReadyTimeoutWarnsThenRemoves 未准备玩家先被提醒，再次超时后被移出房间
+func TestReadyTimeoutWarnsThenRemoves(t *testing.T) {
+	repo := newFakeRoomRepo()
+	svc := New(repo, &fakeUserRepo{}, nil, nil, "", nil, lock.NewLocalRWLock(), nil, false, 0, false).(*service)
+
+	now := time.Now().Unix()
+	seedWaitingRoom(repo, "RTIMEOUT1", 1, []services.PlayerInfo{
+		{UserID: 1, Position: 1, Ready: true, ReadyAt: now - 120},
+		{UserID: 2, Position: 2, Ready: true, ReadyAt: now - 100},
+		{UserID: 3, Position: 3, Ready: false},
+	})
+
+	// 第一轮：等待已超过提醒阈值，未准备玩家被标记提醒但仍在房间
+	svc.checkReadyTimeouts(30*time.Second, 90*time.Second)
+	players := roomPlayers(t, repo.rooms["RTIMEOUT1"])
+	if len(players) != 3 {
+		t.Fatalf("提醒后玩家数 = %d, 期望 3", len(players))
+	}
+	if players[2].UnreadyWarnedAt == 0 {
+		t.Fatal("未准备玩家应被标记提醒")
+	}
+
+	// 第二轮：已提醒且超过移出阈值，未准备玩家被移出
+	svc.checkReadyTimeouts(30*time.Second, 90*time.Second)
+	room := repo.rooms["RTIMEOUT1"]
+	players = roomPlayers(t, room)
+	if len(players) != 2 {
+		t.Fatalf("移出后玩家数 = %d, 期望 2", len(players))
+	}
+	for _, p := range players {
+		if p.UserID == 3 {
+			t.Fatal("超时玩家应已被移出")
+		}
+	}
+	if room.CurrentPlayers != 2 {
+		t.Fatalf("CurrentPlayers = %d, 期望 2", room.CurrentPlayers)
+	}
+}
+
+// TestReadyTimeoutRemovesOwnerTransfers 房主准备超时被移出时房主转给剩余玩家
+func TestReadyTimeoutRemovesOwnerTransfers(t *testing.T) {
+	repo := newFakeRoomRepo()
+	svc := New(repo, &fakeUserRepo{}, nil, nil, "", nil, lock.NewLocalRWLock(), nil, false, 0, false).(*service)
+
+	now := time.Now().Unix()
+	seedWaitingRoom(repo, "RTIMEOUT2", 3, []services.PlayerInfo{
+		{UserID: 1, Position: 1, Ready: true, ReadyAt: now - 200},
+		{UserID: 2, Position: 2, Ready: true, ReadyAt: now - 200},
+		{UserID: 3, Position: 3, Ready: false, UnreadyWarnedAt: now - 100},
+	})
+
+	svc.checkReadyTimeouts(30*time.Second, 90*time.Second)
+	room := repo.rooms["RTIMEOUT2"]
+	if len(roomPlayers(t, room)) != 2 {
+		t.Fatal("已提醒且超时的房主应被移出")
+	}
+	if room.CreatorID != 1 {
+		t.Fatalf("房主应转给剩余的第一位玩家, CreatorID = %d", room.CreatorID)
+	}
+}
+
+// TestReadyTimeoutNoActionWithinWindow 等待未超过提醒阈值时不做任何处理
+func TestReadyTimeoutNoActionWithinWindow(t *testing.T) {
+	repo := newFakeRoomRepo()
+	svc := New(repo, &fakeUserRepo{}, nil, nil, "", nil, lock.NewLocalRWLock(), nil, false, 0, false).(*service)
+
+	now := time.Now().Unix()
+	seedWaitingRoom(repo, "RTIMEOUT3", 1, []services.PlayerInfo{
+		{UserID: 1, Position: 1, Ready: true, ReadyAt: now - 10},
+		{UserID: 2, Position: 2, Ready: false},
+	})
+
+	svc.checkReadyTimeouts(30*time.Second, 90*time.Second)
+	players := roomPlayers(t, repo.rooms["RTIMEOUT3"])
+	if len(players) != 2 || players[1].UnreadyWarnedAt != 0 {
+		t.Fatal("阈值内不应提醒或移出玩家")
+	}
+}
+
+// TestSyncRoomToRedisOutageCooldown Redis不可用时：首次同步快速失败并进入冷却，
+// 冷却期内的后续同步直接跳过，不再访问Redis
+func TestSyncRoomToRedisOutageCooldown(t *testing.T) {
+	repo := newFakeRoomRepo()
+	svc := New(repo, &fakeUserRepo{}, nil, redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1", // 无服务监听，连接立即被拒绝
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	}), "", nil, lock.NewLocalRWLock(), nil, false, 0, false).(*service)
+
+	before := metrics.GetGlobalMetrics().GetRoomSyncStats()
+	room := &models.GameRoom{RoomID: "RSYNC001", GameType: "running", Status: 1}
+
+	start := time.Now()
+	svc.syncRoomToRedis(context.Background(), room)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("同步失败耗时过长: %v", elapsed)
+	}
+	if svc.syncFailedAt == 0 {
+		t.Fatal("同步失败后应记录失败时间进入冷却")
+	}
+
+	// 冷却期内再次同步应直接跳过
+	svc.syncRoomToRedis(context.Background(), room)
+	after := metrics.GetGlobalMetrics().GetRoomSyncStats()
+	if got := after["error_count"].(int64) - before["error_count"].(int64); got != 1 {
+		t.Errorf("失败计数增量 = %d, 期望 1", got)
+	}
+	if got := after["skip_count"].(int64) - before["skip_count"].(int64); got != 1 {
+		t.Errorf("跳过计数增量 = %d, 期望 1", got)
+	}
+	if after["last_error"].(string) == "" {
+		t.Error("应记录最近一次错误信息")
+	}
+}
@@ -2,12 +2,22 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/kaifa/game-platform/internal/mailer"
 	userrepo "github.com/kaifa/game-platform/internal/repository/user"
 	"github.com/kaifa/game-platform/pkg/models"
 	"github.com/kaifa/game-platform/pkg/utils"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -24,16 +34,46 @@ type Service interface {
 
 	// GetUserProfile 获取用户信息（包含钱包）
 	GetUserProfile(ctx context.Context, userID uint) (map[string]interface{}, error)
+
+	// UpdateProfile 更新用户昵称/头像（空字符串表示不修改）。
+	// 注意：房间 Players JSON 中快照的昵称/头像不会随之更新，
+	// 已在房间中的玩家信息以进入房间时为准。
+	UpdateProfile(ctx context.Context, userID uint, nickname, avatar string) (*models.User, error)
+
+	// GetWallet 获取用户钱包（可用余额/冻结金额/累计充提），
+	// 没有钱包记录时补建一条全0记录
+	GetWallet(ctx context.Context, userID uint) (*models.UserWallet, error)
+
+	// RequestEmailVerification 请求邮箱验证：生成一次性令牌发送到目标邮箱
+	RequestEmailVerification(ctx context.Context, userID uint, email string) error
+
+	// ConfirmEmailVerification 用邮件中的令牌确认邮箱
+	ConfirmEmailVerification(ctx context.Context, token string) error
+
+	// RequestPasswordReset 请求重置密码：向已验证的邮箱发送重置令牌
+	RequestPasswordReset(ctx context.Context, email string) error
+
+	// ConfirmPasswordReset 用重置令牌设置新密码
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
 }
 
 type service struct {
-	repo userrepo.Repository
+	repo   userrepo.Repository
+	rdb    *redis.Client // 可选，nil时邮箱验证/密码重置不可用
+	mailer mailer.Mailer
 }
 
 // New 创建用户服务实例
-func New(repo userrepo.Repository) Service {
+// rdb用于存放邮箱验证/密码重置令牌，传nil时相关功能返回不可用错误；
+// m为nil时使用日志邮件器（只记日志不发信）
+func New(repo userrepo.Repository, rdb *redis.Client, m mailer.Mailer) Service {
+	if m == nil {
+		m = mailer.NewLogMailer()
+	}
 	return &service{
-		repo: repo,
+		repo:   repo,
+		rdb:    rdb,
+		mailer: m,
 	}
 }
 
@@ -156,6 +196,58 @@ func (s *service) GetUserByID(ctx context.Context, userID uint) (*models.User, e
 	return s.repo.GetByID(ctx, userID)
 }
 
+// nicknamePattern 昵称允许中文、字母、数字、下划线和连字符
+var nicknamePattern = regexp.MustCompile(`^[\p{Han}a-zA-Z0-9_-]+$`)
+
+// UpdateProfile 更新用户昵称/头像（空字符串表示不修改）
+func (s *service) UpdateProfile(ctx context.Context, userID uint, nickname, avatar string) (*models.User, error) {
+	// ✅ 业务逻辑：参数验证
+	if nickname != "" {
+		length := utf8.RuneCountInString(nickname)
+		if length < 2 || length > 20 {
+			return nil, errors.New("昵称长度必须在2-20个字符之间")
+		}
+		if !nicknamePattern.MatchString(nickname) {
+			return nil, errors.New("昵称只能包含中文、字母、数字、下划线和连字符")
+		}
+	}
+	if avatar != "" {
+		if len(avatar) > 255 {
+			return nil, errors.New("头像URL过长")
+		}
+		u, err := url.Parse(avatar)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("头像必须是有效的http/https URL")
+		}
+	}
+	if nickname == "" && avatar == "" {
+		return nil, errors.New("没有需要更新的内容")
+	}
+
+	// ✅ 通过 Repository 查询用户
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	if nickname != "" {
+		user.Nickname = nickname
+	}
+	if avatar != "" {
+		user.Avatar = avatar
+	}
+
+	// ✅ 通过 Repository 更新
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("更新用户信息失败: %w", err)
+	}
+
+	return user, nil
+}
+
 // GetUserProfile 获取用户信息（包含钱包）
 func (s *service) GetUserProfile(ctx context.Context, userID uint) (map[string]interface{}, error) {
 	// ✅ 通过 Repository 查询用户
@@ -182,3 +274,238 @@ func (s *service) GetUserProfile(ctx context.Context, userID uint) (map[string]i
 		"wallet": wallet,
 	}, nil
 }
+
+// GetWallet 获取用户钱包（可用余额/冻结金额/累计充提）。
+// 老用户可能没有钱包记录，此时落一条全0记录再返回，
+// 保证客户端拿到的字段结构始终一致。
+func (s *service) GetWallet(ctx context.Context, userID uint) (*models.UserWallet, error) {
+	// ✅ 通过 Repository 查询钱包
+	wallet, err := s.repo.GetWallet(ctx, userID)
+	if err == nil {
+		return wallet, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("查询钱包失败: %w", err)
+	}
+
+	// 确认用户存在再补建钱包，避免给不存在的用户落记录
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	wallet = &models.UserWallet{UserID: userID}
+	if err := s.repo.CreateWallet(ctx, wallet); err != nil {
+		return nil, fmt.Errorf("创建钱包失败: %w", err)
+	}
+	return wallet, nil
+}
+
+// 邮箱令牌相关参数
+const (
+	emailTokenTTL      = 30 * time.Minute // 令牌有效期
+	emailRequestWindow = time.Minute      // 同一目标的最小请求间隔
+)
+
+// emailPattern 简单的邮箱格式校验
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// generateEmailToken 生成一次性令牌，返回(明文令牌, 存储用的哈希)。
+// Redis中只存哈希，即使存储泄露也无法伪造邮件中的令牌
+func generateEmailToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("生成令牌失败: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	return token, hashEmailToken(token), nil
+}
+
+// hashEmailToken 计算令牌的存储哈希
+func hashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// rateLimitEmailRequest 限制同一目标的令牌请求频率
+func (s *service) rateLimitEmailRequest(ctx context.Context, key string) error {
+	ok, err := s.rdb.SetNX(ctx, key, 1, emailRequestWindow).Result()
+	if err != nil {
+		return fmt.Errorf("检查请求频率失败: %w", err)
+	}
+	if !ok {
+		return errors.New("请求过于频繁，请稍后再试")
+	}
+	return nil
+}
+
+// RequestEmailVerification 请求邮箱验证。
+// 生成一次性令牌，哈希后带30分钟有效期存入Redis，明文令牌通过邮件发出
+func (s *service) RequestEmailVerification(ctx context.Context, userID uint, email string) error {
+	if s.rdb == nil {
+		return errors.New("邮件功能未启用")
+	}
+	if !emailPattern.MatchString(email) || len(email) > 100 {
+		return errors.New("邮箱格式不正确")
+	}
+
+	// ✅ 确认用户存在
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	// ✅ 频率限制
+	if err := s.rateLimitEmailRequest(ctx, fmt.Sprintf("user:email:rate:verify:%d", userID)); err != nil {
+		return err
+	}
+
+	token, tokenHash, err := generateEmailToken()
+	if err != nil {
+		return err
+	}
+
+	// 令牌哈希 → "用户ID:邮箱"，确认时原样取回
+	value := fmt.Sprintf("%d:%s", userID, email)
+	if err := s.rdb.Set(ctx, "user:email:verify:"+tokenHash, value, emailTokenTTL).Err(); err != nil {
+		return fmt.Errorf("保存令牌失败: %w", err)
+	}
+
+	body := fmt.Sprintf("您正在验证邮箱，验证令牌为：%s（30分钟内有效）。如非本人操作请忽略本邮件。", token)
+	if err := s.mailer.Send(ctx, email, "邮箱验证", body); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailVerification 用邮件中的令牌确认邮箱。
+// 令牌一次性使用，确认后写入用户的邮箱并标记已验证
+func (s *service) ConfirmEmailVerification(ctx context.Context, token string) error {
+	if s.rdb == nil {
+		return errors.New("邮件功能未启用")
+	}
+	if token == "" {
+		return errors.New("令牌不能为空")
+	}
+
+	// ✅ 取出并删除令牌（一次性）
+	value, err := s.rdb.GetDel(ctx, "user:email:verify:"+hashEmailToken(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return errors.New("令牌无效或已过期")
+		}
+		return fmt.Errorf("查询令牌失败: %w", err)
+	}
+
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return errors.New("令牌数据异常")
+	}
+	var userID uint
+	if _, err := fmt.Sscanf(parts[0], "%d", &userID); err != nil {
+		return errors.New("令牌数据异常")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	user.Email = parts[1]
+	user.EmailVerified = 1
+	if err := s.repo.Update(ctx, user); err != nil {
+		return fmt.Errorf("更新用户信息失败: %w", err)
+	}
+	return nil
+}
+
+// RequestPasswordReset 请求重置密码。
+// 邮箱不存在或未验证时同样返回成功，避免被用来探测注册邮箱
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	if s.rdb == nil {
+		return errors.New("邮件功能未启用")
+	}
+	if !emailPattern.MatchString(email) {
+		return errors.New("邮箱格式不正确")
+	}
+
+	// ✅ 频率限制（按邮箱，无论是否命中用户）
+	if err := s.rateLimitEmailRequest(ctx, "user:email:rate:reset:"+email); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+	if user.EmailVerified != 1 {
+		return nil
+	}
+
+	token, tokenHash, err := generateEmailToken()
+	if err != nil {
+		return err
+	}
+	if err := s.rdb.Set(ctx, "user:email:reset:"+tokenHash,
+		fmt.Sprintf("%d", user.ID), emailTokenTTL).Err(); err != nil {
+		return fmt.Errorf("保存令牌失败: %w", err)
+	}
+
+	body := fmt.Sprintf("您正在重置密码，重置令牌为：%s（30分钟内有效）。如非本人操作请忽略本邮件。", token)
+	if err := s.mailer.Send(ctx, email, "重置密码", body); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// ConfirmPasswordReset 用重置令牌设置新密码
+func (s *service) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	if s.rdb == nil {
+		return errors.New("邮件功能未启用")
+	}
+	if token == "" {
+		return errors.New("令牌不能为空")
+	}
+	if len(newPassword) < 6 {
+		return errors.New("密码长度不能少于6位")
+	}
+
+	// ✅ 取出并删除令牌（一次性）
+	value, err := s.rdb.GetDel(ctx, "user:email:reset:"+hashEmailToken(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return errors.New("令牌无效或已过期")
+		}
+		return fmt.Errorf("查询令牌失败: %w", err)
+	}
+
+	var userID uint
+	if _, err := fmt.Sscanf(value, "%d", &userID); err != nil {
+		return errors.New("令牌数据异常")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %w", err)
+	}
+	user.Password = hashedPassword
+	if err := s.repo.Update(ctx, user); err != nil {
+		return fmt.Errorf("更新密码失败: %w", err)
+	}
+	return nil
+}
@@ -2,24 +2,42 @@ package userstats
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	gameplayerrepo "github.com/kaifa/game-platform/internal/repository/gameplayer"
+	"github.com/redis/go-redis/v9"
 )
 
+// statsCacheTTL 统计缓存时长（读多写少，短TTL容忍结算后的轻微延迟）
+const statsCacheTTL = 30 * time.Second
+
+// gameTypes 参与统计的游戏类型
+var gameTypes = []string{"running", "texas", "bull"}
+
 // Service 定义用户统计业务服务接口
 type Service interface {
-	// GetUserStats 获取用户游戏统计
+	// GetUserStats 获取用户游戏统计（总统计+各游戏类型，带Redis缓存）
 	GetUserStats(ctx context.Context, userID uint) (*UserStatsResponse, error)
+
+	// GetSummary 获取用户总体统计（总局数/胜场/胜率/净盈亏）
+	GetSummary(ctx context.Context, userID uint) (*gameplayerrepo.TotalStats, error)
+
+	// GetByGameType 按游戏类型获取统计
+	GetByGameType(ctx context.Context, userID uint) (map[string]gameplayerrepo.GameTypeStats, error)
 }
 
 type service struct {
 	gamePlayerRepo gameplayerrepo.Repository
+	rdb            *redis.Client // 可选，nil时不缓存
 }
 
 // New 创建用户统计服务实例
-func New(gamePlayerRepo gameplayerrepo.Repository) Service {
+func New(gamePlayerRepo gameplayerrepo.Repository, rdb *redis.Client) Service {
 	return &service{
 		gamePlayerRepo: gamePlayerRepo,
+		rdb:            rdb,
 	}
 }
 
@@ -30,30 +48,66 @@ type UserStatsResponse struct {
 	Games  map[string]gameplayerrepo.GameTypeStats `json:"games"` // 各游戏类型统计
 }
 
-// GetUserStats 获取用户游戏统计
-func (s *service) GetUserStats(ctx context.Context, userID uint) (*UserStatsResponse, error) {
-	stats := &UserStatsResponse{
-		UserID: userID,
-		Games:  make(map[string]gameplayerrepo.GameTypeStats),
-	}
+// GetSummary 获取用户总体统计（总局数/胜场/胜率/净盈亏）
+func (s *service) GetSummary(ctx context.Context, userID uint) (*gameplayerrepo.TotalStats, error) {
+	// ✅ 通过 Repository 查询
+	return s.gamePlayerRepo.GetTotalStats(ctx, userID)
+}
+
+// GetByGameType 按游戏类型获取统计
+func (s *service) GetByGameType(ctx context.Context, userID uint) (map[string]gameplayerrepo.GameTypeStats, error) {
+	games := make(map[string]gameplayerrepo.GameTypeStats)
 
-	// ✅ 业务逻辑：获取所有游戏类型的统计
-	gameTypes := []string{"running", "texas", "bull"}
 	for _, gameType := range gameTypes {
-		// ✅ 通过 Repository 查询
+		// ✅ 通过 Repository 查询（单个类型失败不影响其他类型）
 		gameStats, err := s.gamePlayerRepo.GetGameTypeStats(ctx, userID, gameType)
 		if err != nil {
 			continue
 		}
-		stats.Games[gameType] = *gameStats
+		games[gameType] = *gameStats
+	}
+
+	return games, nil
+}
+
+// GetUserStats 获取用户游戏统计（总统计+各游戏类型，带Redis缓存）
+func (s *service) GetUserStats(ctx context.Context, userID uint) (*UserStatsResponse, error) {
+	cacheKey := fmt.Sprintf("user:stats:%d", userID)
+
+	// 先查缓存
+	if s.rdb != nil {
+		if data, err := s.rdb.Get(ctx, cacheKey).Bytes(); err == nil {
+			var cached UserStatsResponse
+			if err := json.Unmarshal(data, &cached); err == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	stats := &UserStatsResponse{
+		UserID: userID,
 	}
 
-	// ✅ 通过 Repository 查询总统计
-	totalStats, err := s.gamePlayerRepo.GetTotalStats(ctx, userID)
+	// ✅ 业务逻辑：各游戏类型统计
+	games, err := s.GetByGameType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Games = games
+
+	// ✅ 业务逻辑：总统计
+	totalStats, err := s.GetSummary(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	stats.Total = *totalStats
 
+	// 写入缓存（尽力而为）
+	if s.rdb != nil {
+		if data, err := json.Marshal(stats); err == nil {
+			s.rdb.Set(ctx, cacheKey, data, statsCacheTTL)
+		}
+	}
+
 	return stats, nil
 }
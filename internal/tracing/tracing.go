@@ -0,0 +1,25 @@
+package tracing
+
+import "context"
+
+// HeaderRequestID 请求追踪ID的HTTP头名称
+const HeaderRequestID = "X-Request-ID"
+
+// ctxKey 避免与其他包的context键冲突
+type ctxKey struct{}
+
+// WithRequestID 将请求ID写入context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, requestID)
+}
+
+// RequestID 从context读取请求ID，未设置时返回空串
+func RequestID(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
@@ -22,11 +22,17 @@ type Pool struct {
 	workerNum int
 	wg        sync.WaitGroup
 
+	// 关闭状态（防止关闭后继续提交导致panic）
+	closed   bool
+	closedMu sync.RWMutex
+
 	// 统计信息
-	totalTasks   int64
-	successTasks int64
-	failedTasks  int64
-	mu           sync.Mutex
+	totalTasks    int64
+	successTasks  int64
+	failedTasks   int64
+	activeTasks   int64 // 正在执行的任务数
+	rejectedTasks int64 // 队列满/已关闭被拒绝的任务数
+	mu            sync.Mutex
 }
 
 // NewPool 创建 Worker Pool
@@ -93,6 +99,7 @@ func (p *Pool) executeTask(workerID int, task Task) {
 	// 更新统计
 	p.mu.Lock()
 	p.totalTasks++
+	p.activeTasks++
 	p.mu.Unlock()
 
 	// 创建带超时的 context（默认30秒）
@@ -106,6 +113,7 @@ func (p *Pool) executeTask(workerID int, task Task) {
 
 	// 更新统计
 	p.mu.Lock()
+	p.activeTasks--
 	if err != nil {
 		p.failedTasks++
 	} else {
@@ -128,45 +136,98 @@ func (p *Pool) executeTask(workerID int, task Task) {
 	}
 }
 
+// isClosed 判断 Pool 是否已进入关闭流程
+func (p *Pool) isClosed() bool {
+	p.closedMu.RLock()
+	defer p.closedMu.RUnlock()
+	return p.closed
+}
+
+// recordRejected 记录被拒绝的任务
+func (p *Pool) recordRejected() {
+	p.mu.Lock()
+	p.rejectedTasks++
+	p.mu.Unlock()
+}
+
 // Submit 提交任务到队列
-// 非阻塞，如果队列满则返回错误
+// 短暂等待（100ms），队列仍满则拒绝并返回错误
 func (p *Pool) Submit(task Task) error {
+	if p.isClosed() {
+		p.recordRejected()
+		return errors.New("Worker Pool 已关闭")
+	}
+
 	select {
 	case p.taskQueue <- task:
 		return nil
 	case <-time.After(100 * time.Millisecond):
+		p.recordRejected()
 		return errors.New("任务队列已满，请稍后重试")
 	case <-p.ctx.Done():
+		p.recordRejected()
 		return errors.New("Worker Pool 已关闭")
 	}
 }
 
+// TrySubmit 提交任务（非阻塞，有界队列模式）
+// 队列满或已关闭时立即返回false，调用方可降级为同步发送
+func (p *Pool) TrySubmit(task Task) bool {
+	if p.isClosed() {
+		p.recordRejected()
+		return false
+	}
+
+	select {
+	case p.taskQueue <- task:
+		return true
+	default:
+		p.recordRejected()
+		return false
+	}
+}
+
 // SubmitWithTimeout 提交任务（带超时）
 // 阻塞直到任务被接受或超时
 func (p *Pool) SubmitWithTimeout(task Task, timeout time.Duration) error {
+	if p.isClosed() {
+		p.recordRejected()
+		return errors.New("Worker Pool 已关闭")
+	}
+
 	select {
 	case p.taskQueue <- task:
 		return nil
 	case <-time.After(timeout):
+		p.recordRejected()
 		return errors.New("提交任务超时")
 	case <-p.ctx.Done():
+		p.recordRejected()
 		return errors.New("Worker Pool 已关闭")
 	}
 }
 
 // Shutdown 关闭 Worker Pool（优雅关闭）
+// 先停止接收新任务，在deadline内排空队列中的在途任务，超时则强制终止
 func (p *Pool) Shutdown(timeout time.Duration) error {
 	logger.Logger.Info("开始关闭 Worker Pool",
 		zap.Int("worker_num", p.workerNum),
+		zap.Int("pending_tasks", len(p.taskQueue)),
 	)
 
-	// 1. 停止接收新任务
-	p.cancel()
+	// 1. 停止接收新任务（先置关闭标记，避免向已关闭channel发送）
+	p.closedMu.Lock()
+	if p.closed {
+		p.closedMu.Unlock()
+		return errors.New("Worker Pool 已关闭")
+	}
+	p.closed = true
+	p.closedMu.Unlock()
 
-	// 2. 关闭任务队列（等待现有任务完成）
+	// 2. 关闭任务队列，workers 会排空剩余任务后退出
 	close(p.taskQueue)
 
-	// 3. 等待所有 worker 完成（带超时）
+	// 3. 等待所有 worker 排空完成（带超时）
 	done := make(chan struct{})
 	go func() {
 		p.wg.Wait()
@@ -175,15 +236,20 @@ func (p *Pool) Shutdown(timeout time.Duration) error {
 
 	select {
 	case <-done:
+		p.cancel()
 		logger.Logger.Info("Worker Pool 已关闭",
 			zap.Int64("total_tasks", p.totalTasks),
 			zap.Int64("success_tasks", p.successTasks),
 			zap.Int64("failed_tasks", p.failedTasks),
+			zap.Int64("rejected_tasks", p.rejectedTasks),
 		)
 		return nil
 	case <-time.After(timeout):
-		logger.Logger.Warn("Worker Pool 关闭超时",
+		// 超时：取消在途任务的context，强制终止
+		p.cancel()
+		logger.Logger.Warn("Worker Pool 关闭超时，强制终止在途任务",
 			zap.Duration("timeout", timeout),
+			zap.Int("pending_tasks", len(p.taskQueue)),
 		)
 		return errors.New("关闭超时")
 	}
@@ -195,12 +261,15 @@ func (p *Pool) Stats() map[string]interface{} {
 	defer p.mu.Unlock()
 
 	return map[string]interface{}{
-		"worker_num":    p.workerNum,
-		"queue_size":    len(p.taskQueue),
-		"queue_cap":     cap(p.taskQueue),
-		"total_tasks":   p.totalTasks,
-		"success_tasks": p.successTasks,
-		"failed_tasks":  p.failedTasks,
+		"worker_num":      p.workerNum,
+		"queue_size":      len(p.taskQueue),
+		"queue_cap":       cap(p.taskQueue),
+		"total_tasks":     p.totalTasks,
+		"success_tasks":   p.successTasks,
+		"failed_tasks":    p.failedTasks,
+		"active_tasks":    p.activeTasks,
+		"completed_tasks": p.successTasks + p.failedTasks,
+		"rejected_tasks":  p.rejectedTasks,
 	}
 }
 
@@ -0,0 +1,23 @@
+-- 订单状态回调推送记录表
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `order_id` VARCHAR(64) NOT NULL COMMENT '订单号',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `order_type` VARCHAR(20) NOT NULL COMMENT '订单类型:recharge/withdraw',
+  `order_status` VARCHAR(20) NOT NULL COMMENT '订单状态:paid/approved/rejected',
+  `amount` DECIMAL(10,2) NOT NULL COMMENT '金额',
+  `tx_hash` VARCHAR(128) COMMENT '交易哈希',
+  `url` VARCHAR(255) NOT NULL COMMENT '回调地址',
+  `payload` TEXT COMMENT '回调载荷(JSON)',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '状态:1待推送,2成功,3失败',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT '尝试次数',
+  `last_error` VARCHAR(255) COMMENT '最近一次错误',
+  `delivered_at` BIGINT COMMENT '推送成功时间',
+  `created_at` BIGINT NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `updated_at` BIGINT NOT NULL DEFAULT 0 COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_order_id` (`order_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status` (`status`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单状态回调推送记录';
@@ -0,0 +1,18 @@
+-- USDT归集记录表
+CREATE TABLE IF NOT EXISTS `collection_records` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型:trc20/erc20',
+  `address` VARCHAR(100) COMMENT '归集来源地址',
+  `amount` DECIMAL(20,6) NOT NULL DEFAULT 0 COMMENT '归集金额',
+  `tx_hash` VARCHAR(128) COMMENT '交易哈希',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '状态:2成功,3失败',
+  `error_msg` VARCHAR(255) COMMENT '失败原因',
+  `created_at` BIGINT NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `updated_at` BIGINT NOT NULL DEFAULT 0 COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_address` (`address`),
+  KEY `idx_status` (`status`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='USDT归集记录';
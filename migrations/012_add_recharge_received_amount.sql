@@ -0,0 +1,3 @@
+-- 充值订单增加链上实际到账金额（处理多付/少付场景）
+ALTER TABLE `recharge_orders`
+  ADD COLUMN `received_amount` DECIMAL(10,2) NOT NULL DEFAULT 0 COMMENT '链上实际到账金额' AFTER `amount`;
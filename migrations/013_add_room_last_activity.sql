@@ -0,0 +1,6 @@
+-- 游戏房间增加最后活动时间（用于闲置房间自动清理）
+ALTER TABLE `game_rooms`
+  ADD COLUMN `last_activity_at` BIGINT NOT NULL DEFAULT 0 COMMENT '最后活动时间' AFTER `creator_id`;
+
+-- 存量房间以更新时间作为初始活动时间
+UPDATE `game_rooms` SET `last_activity_at` = `updated_at` WHERE `last_activity_at` = 0;
@@ -0,0 +1,3 @@
+-- 游戏房间增加乐观锁版本号（跨实例并发更新保护）
+ALTER TABLE `game_rooms`
+  ADD COLUMN `version` BIGINT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号' AFTER `last_activity_at`;
@@ -0,0 +1,2 @@
+-- 公告定时发布：记录实际发送时间，调度器据此判断是否已发送
+ALTER TABLE announcements ADD COLUMN published_at BIGINT NULL COMMENT '实际发送时间(定时发布用)';
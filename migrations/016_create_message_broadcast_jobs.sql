@@ -0,0 +1,18 @@
+-- 批量消息发送任务表（进度跟踪，重启后可续发）
+CREATE TABLE IF NOT EXISTS `message_broadcast_jobs` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `type` VARCHAR(20) DEFAULT 'info' COMMENT '消息类型:info/warning/error/success/system/order',
+  `title` VARCHAR(200) NOT NULL COMMENT '消息标题',
+  `content` TEXT NOT NULL COMMENT '消息内容',
+  `related_id` VARCHAR(64) COMMENT '关联ID(如订单号)',
+  `target_users` TEXT COMMENT '目标用户:all=全部,user_id1,user_id2=指定用户',
+  `total` BIGINT NOT NULL DEFAULT 0 COMMENT '目标用户总数',
+  `sent` BIGINT NOT NULL DEFAULT 0 COMMENT '已发送数量',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT '状态:0待处理,1发送中,2完成,3失败',
+  `error` VARCHAR(500) COMMENT '失败原因',
+  `created_by` BIGINT UNSIGNED DEFAULT 0 COMMENT '创建人ID',
+  `created_at` BIGINT NOT NULL DEFAULT 0 COMMENT '创建时间',
+  `updated_at` BIGINT NOT NULL DEFAULT 0 COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='批量消息发送任务';
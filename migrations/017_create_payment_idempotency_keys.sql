@@ -0,0 +1,13 @@
+-- 支付订单幂等键表（重试请求返回原订单，防止重复下单）
+CREATE TABLE IF NOT EXISTS `payment_idempotency_keys` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+  `key` VARCHAR(64) NOT NULL COMMENT '幂等键',
+  `order_type` VARCHAR(20) NOT NULL COMMENT '订单类型:recharge/withdraw',
+  `order_id` VARCHAR(64) NOT NULL COMMENT '订单号',
+  `expire_at` BIGINT NOT NULL COMMENT '过期时间',
+  `created_at` BIGINT NOT NULL DEFAULT 0 COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_key` (`user_id`, `key`),
+  KEY `idx_expire_at` (`expire_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='支付订单幂等键';
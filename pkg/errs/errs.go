@@ -0,0 +1,78 @@
+package errs
+
+import "fmt"
+
+// Error 业务错误：稳定的数字错误码 + 用户可见消息 + 可选内部细节。
+// 错误码为5位数字，前3位对应HTTP状态码（如40400 → 404），
+// 后2位用于区分同类错误，客户端可据此做机器判断；
+// Message是默认展示文案（中文），Detail仅用于日志排查，不下发客户端
+type Error struct {
+	Code    int    // 业务错误码
+	Message string // 用户可见消息
+	Detail  string // 内部细节（可选）
+}
+
+// Error 实现error接口，返回用户可见消息
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// HTTPStatus 业务错误码对应的HTTP状态码（取错误码前3位）
+func (e *Error) HTTPStatus() int {
+	if e.Code >= 10000 && e.Code <= 59999 {
+		return e.Code / 100
+	}
+	return 500
+}
+
+// WithDetail 附加内部细节，返回新错误（不修改原错误，便于复用预定义错误）
+func (e *Error) WithDetail(format string, args ...interface{}) *Error {
+	clone := *e
+	clone.Detail = fmt.Sprintf(format, args...)
+	return &clone
+}
+
+// New 创建指定错误码的业务错误
+func New(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// 常用错误类别的基础错误码
+const (
+	CodeBadRequest  = 40000 // 参数或业务状态不满足
+	CodeForbidden   = 40300 // 无权执行该操作
+	CodeNotFound    = 40400 // 目标资源不存在
+	CodeConflict    = 40900 // 与当前状态冲突
+	CodeInternal    = 50000 // 内部错误
+	CodeUnavailable = 50300 // 服务暂不可用（维护中等）
+)
+
+// BadRequest 参数或业务状态不满足（HTTP 400）
+func BadRequest(message string) *Error {
+	return New(CodeBadRequest, message)
+}
+
+// Forbidden 无权执行该操作（HTTP 403）
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+// NotFound 目标资源不存在（HTTP 404）
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+// Conflict 与当前状态冲突（HTTP 409）
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+// Internal 内部错误（HTTP 500）
+func Internal(message string) *Error {
+	return New(CodeInternal, message)
+}
+
+// Unavailable 服务暂不可用（HTTP 503）
+func Unavailable(message string) *Error {
+	return New(CodeUnavailable, message)
+}
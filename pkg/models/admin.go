@@ -48,13 +48,14 @@ func (Admin) TableName() string {
 
 // AdminRole 管理员角色模型
 type AdminRole struct {
-	ID          uint   `gorm:"primarykey" json:"id"`
-	RoleCode    string `gorm:"uniqueIndex;size:50;not null;comment:角色代码" json:"role_code"`
-	RoleName    string `gorm:"size:50;not null;comment:角色名称" json:"role_name"`
-	Description string `gorm:"size:255;default:'';comment:角色描述" json:"description"`
-	Status      int8   `gorm:"default:1;comment:状态:1启用,2禁用" json:"status"`
-	CreatedAt   int64  `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
-	UpdatedAt   int64  `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	ID          uint           `gorm:"primarykey" json:"id"`
+	RoleCode    string         `gorm:"uniqueIndex;size:50;not null;comment:角色代码" json:"role_code"`
+	RoleName    string         `gorm:"size:50;not null;comment:角色名称" json:"role_name"`
+	Description string         `gorm:"size:255;default:'';comment:角色描述" json:"description"`
+	Status      int8           `gorm:"default:1;comment:状态:1启用,2禁用" json:"status"`
+	CreatedAt   int64          `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt   int64          `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// 关联关系
 	Permissions []AdminPermission `gorm:"many2many:role_permission_relations;joinForeignKey:role_id;joinReferences:permission_id" json:"permissions,omitempty"`
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// CollectionRecord USDT归集记录
+type CollectionRecord struct {
+	ID        uint    `gorm:"primarykey" json:"id"`
+	UserID    uint    `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	ChainType string  `gorm:"size:20;not null;comment:链类型:trc20/erc20" json:"chain_type"`
+	Address   string  `gorm:"size:100;index;comment:归集来源地址" json:"address"`
+	Amount    float64 `gorm:"type:decimal(20,6);not null;default:0;comment:归集金额" json:"amount"`
+	TxHash    string  `gorm:"size:128;comment:交易哈希" json:"tx_hash"`
+	Status    int8    `gorm:"default:1;comment:状态:2成功,3失败" json:"status"`
+	ErrorMsg  string  `gorm:"size:255;comment:失败原因" json:"error_msg"`
+	CreatedAt int64   `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt int64   `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+}
+
+// BeforeCreate GORM创建前钩子
+func (c *CollectionRecord) BeforeCreate(tx *gorm.DB) error {
+	now := tx.Statement.DB.NowFunc().Unix()
+	if c.CreatedAt == 0 {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt == 0 {
+		c.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate GORM更新前钩子
+func (c *CollectionRecord) BeforeUpdate(tx *gorm.DB) error {
+	c.UpdatedAt = tx.Statement.DB.NowFunc().Unix()
+	return nil
+}
+
+// TableName 表名
+func (CollectionRecord) TableName() string {
+	return "collection_records"
+}
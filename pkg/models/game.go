@@ -48,20 +48,24 @@ func (j *JSON) UnmarshalJSON(data []byte) error {
 
 // GameRoom 游戏房间
 type GameRoom struct {
-	ID             uint    `gorm:"primarykey" json:"id"`
-	RoomID         string  `gorm:"uniqueIndex;size:50;not null;comment:房间ID" json:"room_id"`
-	GameType       string  `gorm:"size:20;not null;comment:游戏类型" json:"game_type"`
-	RoomType       string  `gorm:"size:20;comment:房间类型:quick/middle/high" json:"room_type"`
-	BaseBet        float64 `gorm:"type:decimal(10,2);comment:底注" json:"base_bet"`
-	MaxPlayers     int     `gorm:"default:4;comment:最大人数" json:"max_players"`
-	CurrentPlayers int     `gorm:"default:0;comment:当前人数" json:"current_players"`
-	Status         int8    `gorm:"default:1;comment:状态:1等待,2游戏中,3已结束" json:"status"`
-	Password       string  `gorm:"size:20;default:'';comment:房间密码" json:"-"`    // 密码不返回给客户端
-	HasPassword    bool    `gorm:"default:0;comment:是否有密码" json:"has_password"` // 是否设置了密码
-	Players        JSON    `gorm:"type:json;comment:玩家列表" json:"players"`
-	CreatorID      uint    `gorm:"comment:创建者ID" json:"creator_id"`
-	CreatedAt      int64   `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
-	UpdatedAt      int64   `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	ID              uint    `gorm:"primarykey" json:"id"`
+	RoomID          string  `gorm:"uniqueIndex;size:50;not null;comment:房间ID" json:"room_id"`
+	GameType        string  `gorm:"size:20;not null;comment:游戏类型" json:"game_type"`
+	RoomType        string  `gorm:"size:20;comment:房间类型:quick/middle/high" json:"room_type"`
+	BaseBet         float64 `gorm:"type:decimal(10,2);comment:底注" json:"base_bet"`
+	MaxPlayers      int     `gorm:"default:4;comment:最大人数" json:"max_players"`
+	CurrentPlayers  int     `gorm:"default:0;comment:当前人数" json:"current_players"`
+	Status          int8    `gorm:"default:1;comment:状态:1等待,2游戏中,3已结束" json:"status"`
+	Password        string  `gorm:"size:20;default:'';comment:房间密码" json:"-"`    // 密码不返回给客户端
+	HasPassword     bool    `gorm:"default:0;comment:是否有密码" json:"has_password"` // 是否设置了密码
+	AutoStart       bool    `gorm:"default:0;comment:全员准备后自动开局" json:"auto_start"`
+	FirstPlayerRule string  `gorm:"size:20;default:'';comment:首出规则:lowest_card/seat_one/previous_winner" json:"first_player_rule"`
+	Players         JSON    `gorm:"type:json;comment:玩家列表" json:"players"`
+	CreatorID       uint    `gorm:"comment:创建者ID" json:"creator_id"`
+	LastActivityAt  int64   `gorm:"type:bigint;not null;default:0;comment:最后活动时间" json:"last_activity_at"`
+	Version         int64   `gorm:"not null;default:1;comment:乐观锁版本号" json:"version"`
+	CreatedAt       int64   `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt       int64   `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
 }
 
 // BeforeCreate GORM创建前钩子
@@ -73,6 +77,12 @@ func (g *GameRoom) BeforeCreate(tx *gorm.DB) error {
 	if g.UpdatedAt == 0 {
 		g.UpdatedAt = now
 	}
+	if g.LastActivityAt == 0 {
+		g.LastActivityAt = now
+	}
+	if g.Version == 0 {
+		g.Version = 1
+	}
 	return nil
 }
 
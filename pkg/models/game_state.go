@@ -4,17 +4,20 @@ import "encoding/json"
 
 // GameState 游戏状态
 type GameState struct {
-	RoomID        string                   `json:"room_id"`        // 房间ID
-	GameType      string                   `json:"game_type"`      // 游戏类型
-	Status        int                      `json:"status"`         // 游戏状态: 0等待, 1进行中, 2结算中, 3已结束
-	Round         int                      `json:"round"`          // 当前回合数
-	CurrentPlayer uint                     `json:"current_player"` // 当前出牌玩家ID
-	LastCards     []int                    `json:"last_cards"`     // 上次出的牌
-	LastPlayer    uint                     `json:"last_player"`    // 上次出牌的玩家ID
-	PassCount     int                      `json:"pass_count"`     // 连续过牌次数
-	Players       map[uint]*PlayerGameInfo `json:"players"`        // 玩家游戏信息
-	Deck          []int                    `json:"deck,omitempty"` // 牌堆（仅用于调试）
-	StartTime     int64                    `json:"start_time"`     // 游戏开始时间
+	RoomID          string                   `json:"room_id"`                     // 房间ID
+	GameType        string                   `json:"game_type"`                   // 游戏类型
+	FirstPlayerRule string                   `json:"first_player_rule,omitempty"` // 首出规则（跑得快）
+	Status          int                      `json:"status"`                      // 游戏状态: 0等待, 1进行中, 2结算中, 3已结束
+	Round           int                      `json:"round"`                       // 当前回合数
+	CurrentPlayer   uint                     `json:"current_player"`              // 当前出牌玩家ID
+	Banker          uint                     `json:"banker,omitempty"`            // 庄家ID（牛牛游戏）
+	LastCards       []int                    `json:"last_cards"`                  // 上次出的牌
+	LastPlayer      uint                     `json:"last_player"`                 // 上次出牌的玩家ID
+	PassCount       int                      `json:"pass_count"`                  // 连续过牌次数
+	Players         map[uint]*PlayerGameInfo `json:"players"`                     // 玩家游戏信息
+	Deck            []int                    `json:"deck,omitempty"`              // 牌堆（仅用于调试）
+	StartTime       int64                    `json:"start_time"`                  // 游戏开始时间
+	Seq             int64                    `json:"seq"`                         // 状态序列号（每次变更递增，客户端据此检测增量丢失）
 }
 
 // PlayerGameInfo 玩家游戏信息
@@ -28,10 +31,57 @@ type PlayerGameInfo struct {
 	Rank       int   `json:"rank"`        // 名次（1,2,3,4）
 
 	// 牛牛游戏专用字段
-	PlayedCards []int `json:"played_cards,omitempty"` // 玩家出的牌（牛牛游戏：5张牌）
-	BullType    int   `json:"bull_type,omitempty"`    // 牛牛类型：0=无牛, 1-9=有牛, 10=牛牛, 11=四花, 12=五花, 13=炸弹, 14=五小牛
-	BullNum     int   `json:"bull_num,omitempty"`     // 牛数（当有牛时）
-	MaxCard     int   `json:"max_card,omitempty"`     // 最大牌点数
+	PlayedCards   []int `json:"played_cards,omitempty"`   // 玩家出的牌（牛牛游戏：5张牌）
+	BullType      int   `json:"bull_type,omitempty"`      // 牛牛类型：0=无牛, 1-9=有牛, 10=牛牛, 11=四花, 12=五花, 13=炸弹, 14=五小牛
+	BullNum       int   `json:"bull_num,omitempty"`       // 牛数（当有牛时）
+	MaxCard       int   `json:"max_card,omitempty"`       // 最大牌点数
+	IsBanker      bool  `json:"is_banker,omitempty"`      // 是否为庄家
+	BetMultiplier int   `json:"bet_multiplier,omitempty"` // 闲家押注倍数（亮牌前选择，默认1）
+}
+
+// GameStateDelta 游戏状态增量：只描述一次动作引起的变化，不含任何手牌，
+// 可以安全地整体广播。客户端按 Seq 递增重建状态；发现序列号跳跃时
+// 应重新拉取完整游戏状态（get_game_state）。
+type GameStateDelta struct {
+	RoomID        string       `json:"room_id"`
+	Seq           int64        `json:"seq"`             // 对应增量应用后的状态序列号
+	Action        string       `json:"action"`          // play_cards / pass
+	ActorID       uint         `json:"actor_id"`        // 本次动作的玩家
+	Cards         []int        `json:"cards,omitempty"` // 本次出的牌（过牌时为空）
+	CurrentPlayer uint         `json:"current_player"`
+	LastPlayer    uint         `json:"last_player"`
+	PassCount     int          `json:"pass_count"`
+	Round         int          `json:"round"`
+	Status        int          `json:"status"`
+	CardCounts    map[uint]int `json:"card_counts"`     // 各玩家最新手牌数
+	Ranks         map[uint]int `json:"ranks,omitempty"` // 已确定的名次
+}
+
+// BuildDelta 从动作与变更后的状态构造增量
+func (gs *GameState) BuildDelta(action string, actorID uint, cards []int) *GameStateDelta {
+	delta := &GameStateDelta{
+		RoomID:        gs.RoomID,
+		Seq:           gs.Seq,
+		Action:        action,
+		ActorID:       actorID,
+		Cards:         append([]int(nil), cards...),
+		CurrentPlayer: gs.CurrentPlayer,
+		LastPlayer:    gs.LastPlayer,
+		PassCount:     gs.PassCount,
+		Round:         gs.Round,
+		Status:        gs.Status,
+		CardCounts:    make(map[uint]int, len(gs.Players)),
+	}
+	for uid, playerInfo := range gs.Players {
+		delta.CardCounts[uid] = playerInfo.CardCount
+		if playerInfo.Rank > 0 {
+			if delta.Ranks == nil {
+				delta.Ranks = make(map[uint]int)
+			}
+			delta.Ranks[uid] = playerInfo.Rank
+		}
+	}
+	return delta
 }
 
 // ToJSON 转换为JSON
@@ -44,20 +94,28 @@ func (gs *GameState) FromJSON(data json.RawMessage) error {
 	return json.Unmarshal(data, gs)
 }
 
-// FilterForUser 为指定用户过滤游戏状态（隐藏其他玩家手牌）
+// FilterForUser 为指定用户过滤游戏状态。
+// 约定：
+//   - userID 为真实用户时，只返回该用户自己的 Cards，其他玩家仅保留 CardCount；
+//   - userID 为 0（旁观者/未登录）时，不返回任何人的 Cards；
+//   - 返回的是深拷贝，调用方修改返回值不会影响存储的状态；
+//   - Deck（调试用牌堆）永远不返回。
+//
+// 已公开的信息（LastCards、牛牛的 PlayedCards 及牌型）对所有人可见。
 func (gs *GameState) FilterForUser(userID uint) *GameState {
-	// 创建新的游戏状态副本
 	filtered := &GameState{
 		RoomID:        gs.RoomID,
 		GameType:      gs.GameType,
 		Status:        gs.Status,
 		Round:         gs.Round,
 		CurrentPlayer: gs.CurrentPlayer,
-		LastCards:     gs.LastCards, // 已出的牌可以显示
+		Banker:        gs.Banker,
+		LastCards:     append([]int(nil), gs.LastCards...), // 已出的牌可以显示
 		LastPlayer:    gs.LastPlayer,
 		PassCount:     gs.PassCount,
 		Players:       make(map[uint]*PlayerGameInfo),
 		StartTime:     gs.StartTime,
+		Seq:           gs.Seq,
 		// Deck 不返回（调试用）
 	}
 
@@ -70,18 +128,19 @@ func (gs *GameState) FilterForUser(userID uint) *GameState {
 			IsPassed:   playerInfo.IsPassed,
 			IsFinished: playerInfo.IsFinished,
 			Rank:       playerInfo.Rank,
+			// 牛牛已亮出的牌、牌型、庄闲身份和押注倍数是公开信息
+			PlayedCards:   append([]int(nil), playerInfo.PlayedCards...),
+			BullType:      playerInfo.BullType,
+			BullNum:       playerInfo.BullNum,
+			MaxCard:       playerInfo.MaxCard,
+			IsBanker:      playerInfo.IsBanker,
+			BetMultiplier: playerInfo.BetMultiplier,
 		}
 
-		// 只返回当前用户的完整手牌，其他玩家的手牌隐藏
-		if uid == userID {
-			// 自己的手牌完整返回
-			filteredPlayer.Cards = make([]int, len(playerInfo.Cards))
-			copy(filteredPlayer.Cards, playerInfo.Cards)
-		} else if userID == 0 {
-			// userID为0表示隐藏所有手牌（未登录用户）
-			filteredPlayer.Cards = []int{}
+		// 只有真实用户能看到自己的手牌；userID为0时所有手牌都隐藏
+		if userID != 0 && uid == userID {
+			filteredPlayer.Cards = append([]int{}, playerInfo.Cards...)
 		} else {
-			// 其他玩家的手牌隐藏，返回空数组
 			filteredPlayer.Cards = []int{}
 		}
 
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testGameState() *GameState {
+	return &GameState{
+		RoomID:        "R1",
+		GameType:      "running",
+		Status:        1,
+		Round:         2,
+		CurrentPlayer: 1,
+		LastCards:     []int{105, 106},
+		LastPlayer:    2,
+		Deck:          []int{301, 302},
+		Players: map[uint]*PlayerGameInfo{
+			1: {UserID: 1, Position: 1, Cards: []int{103, 104}, CardCount: 2},
+			2: {UserID: 2, Position: 2, Cards: []int{203, 204}, CardCount: 2},
+			3: {UserID: 3, Position: 3, Cards: []int{303, 304}, CardCount: 2, PlayedCards: []int{307}},
+		},
+	}
+}
+
+// TestFilterForUserRevealsOwnCardsOnly 真实用户只能看到自己的手牌，其他玩家只剩牌数
+func TestFilterForUserRevealsOwnCardsOnly(t *testing.T) {
+	gs := testGameState()
+
+	filtered := gs.FilterForUser(1)
+
+	if !reflect.DeepEqual(filtered.Players[1].Cards, []int{103, 104}) {
+		t.Errorf("自己的手牌未完整返回: %v", filtered.Players[1].Cards)
+	}
+	for _, uid := range []uint{2, 3} {
+		if len(filtered.Players[uid].Cards) != 0 {
+			t.Errorf("玩家%d的手牌泄漏给了玩家1: %v", uid, filtered.Players[uid].Cards)
+		}
+		if filtered.Players[uid].CardCount != 2 {
+			t.Errorf("玩家%d的牌数丢失: %d", uid, filtered.Players[uid].CardCount)
+		}
+	}
+
+	// 已公开信息应保留
+	if !reflect.DeepEqual(filtered.LastCards, []int{105, 106}) {
+		t.Errorf("LastCards未保留: %v", filtered.LastCards)
+	}
+	if !reflect.DeepEqual(filtered.Players[3].PlayedCards, []int{307}) {
+		t.Errorf("已亮出的牌未保留: %v", filtered.Players[3].PlayedCards)
+	}
+}
+
+// TestFilterForUserZeroHidesAllCards userID为0（旁观者）时不返回任何人的手牌
+func TestFilterForUserZeroHidesAllCards(t *testing.T) {
+	gs := testGameState()
+
+	filtered := gs.FilterForUser(0)
+
+	for uid, player := range filtered.Players {
+		if len(player.Cards) != 0 {
+			t.Errorf("旁观视角泄漏了玩家%d的手牌: %v", uid, player.Cards)
+		}
+	}
+}
+
+// TestFilterForUserNeverReturnsDeck 调试用牌堆任何视角都不返回
+func TestFilterForUserNeverReturnsDeck(t *testing.T) {
+	gs := testGameState()
+
+	for _, userID := range []uint{0, 1, 2} {
+		if filtered := gs.FilterForUser(userID); filtered.Deck != nil {
+			t.Errorf("userID=%d的视角泄漏了牌堆: %v", userID, filtered.Deck)
+		}
+	}
+}
+
+// TestFilterForUserReturnsDeepCopy 修改过滤结果不能影响存储的原始状态
+func TestFilterForUserReturnsDeepCopy(t *testing.T) {
+	gs := testGameState()
+
+	filtered := gs.FilterForUser(1)
+
+	filtered.Players[1].Cards[0] = 999
+	filtered.Players[2].CardCount = 99
+	filtered.LastCards[0] = 999
+	delete(filtered.Players, 3)
+
+	if gs.Players[1].Cards[0] != 103 {
+		t.Error("修改过滤结果的手牌影响了原始状态")
+	}
+	if gs.Players[2].CardCount != 2 {
+		t.Error("修改过滤结果的牌数影响了原始状态")
+	}
+	if gs.LastCards[0] != 105 {
+		t.Error("修改过滤结果的LastCards影响了原始状态")
+	}
+	if _, ok := gs.Players[3]; !ok {
+		t.Error("删除过滤结果的玩家影响了原始状态")
+	}
+}
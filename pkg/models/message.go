@@ -6,18 +6,20 @@ import (
 
 // Announcement 系统公告
 type Announcement struct {
-	ID          uint   `gorm:"primarykey" json:"id"`
-	Title       string `gorm:"size:200;not null;comment:公告标题" json:"title"`
-	Content     string `gorm:"type:text;not null;comment:公告内容" json:"content"`
-	Type        string `gorm:"size:20;default:'info';comment:公告类型:info/warning/error/success" json:"type"`
-	Priority    int    `gorm:"default:0;comment:优先级:0普通,1重要,2紧急" json:"priority"`
-	Status      int    `gorm:"default:1;comment:状态:1发布,2下架" json:"status"`
-	StartTime   *int64 `gorm:"type:bigint;comment:开始时间" json:"start_time"`
-	EndTime     *int64 `gorm:"type:bigint;comment:结束时间" json:"end_time"`
-	TargetUsers string `gorm:"type:text;comment:目标用户:all=全部,user_id1,user_id2=指定用户" json:"target_users"`
-	CreatedBy   uint   `gorm:"comment:创建人ID" json:"created_by"`
-	CreatedAt   int64  `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
-	UpdatedAt   int64  `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Title       string         `gorm:"size:200;not null;comment:公告标题" json:"title"`
+	Content     string         `gorm:"type:text;not null;comment:公告内容" json:"content"`
+	Type        string         `gorm:"size:20;default:'info';comment:公告类型:info/warning/error/success" json:"type"`
+	Priority    int            `gorm:"default:0;comment:优先级:0普通,1重要,2紧急" json:"priority"`
+	Status      int            `gorm:"default:1;comment:状态:1发布,2下架" json:"status"`
+	StartTime   *int64         `gorm:"type:bigint;comment:开始时间" json:"start_time"`
+	EndTime     *int64         `gorm:"type:bigint;comment:结束时间" json:"end_time"`
+	TargetUsers string         `gorm:"type:text;comment:目标用户:all=全部,user_id1,user_id2=指定用户" json:"target_users"`
+	PublishedAt *int64         `gorm:"type:bigint;comment:实际发送时间(定时发布用)" json:"published_at"`
+	CreatedBy   uint           `gorm:"comment:创建人ID" json:"created_by"`
+	CreatedAt   int64          `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt   int64          `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // BeforeCreate GORM创建前钩子
@@ -45,16 +47,17 @@ func (Announcement) TableName() string {
 
 // UserMessage 用户消息
 type UserMessage struct {
-	ID        uint   `gorm:"primarykey" json:"id"`
-	UserID    uint   `gorm:"index;not null;comment:用户ID" json:"user_id"`
-	Type      string `gorm:"size:20;default:'info';comment:消息类型:info/warning/error/success/system/order" json:"type"`
-	Title     string `gorm:"size:200;not null;comment:消息标题" json:"title"`
-	Content   string `gorm:"type:text;not null;comment:消息内容" json:"content"`
-	RelatedID string `gorm:"size:64;comment:关联ID(如订单号)" json:"related_id"`
-	IsRead    bool   `gorm:"default:0;comment:是否已读" json:"is_read"`
-	ReadAt    *int64 `gorm:"type:bigint;comment:阅读时间" json:"read_at"`
-	CreatedAt int64  `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
-	UpdatedAt int64  `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	ID        uint           `gorm:"primarykey" json:"id"`
+	UserID    uint           `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Type      string         `gorm:"size:20;default:'info';comment:消息类型:info/warning/error/success/system/order" json:"type"`
+	Title     string         `gorm:"size:200;not null;comment:消息标题" json:"title"`
+	Content   string         `gorm:"type:text;not null;comment:消息内容" json:"content"`
+	RelatedID string         `gorm:"size:64;comment:关联ID(如订单号)" json:"related_id"`
+	IsRead    bool           `gorm:"default:0;comment:是否已读" json:"is_read"`
+	ReadAt    *int64         `gorm:"type:bigint;comment:阅读时间" json:"read_at"`
+	CreatedAt int64          `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt int64          `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // BeforeCreate GORM创建前钩子
@@ -79,3 +82,44 @@ func (u *UserMessage) BeforeUpdate(tx *gorm.DB) error {
 func (UserMessage) TableName() string {
 	return "user_messages"
 }
+
+// MessageBroadcastJob 批量消息发送任务（按批次记录进度，重启后可续发）
+type MessageBroadcastJob struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Type        string         `gorm:"size:20;default:'info';comment:消息类型:info/warning/error/success/system/order" json:"type"`
+	Title       string         `gorm:"size:200;not null;comment:消息标题" json:"title"`
+	Content     string         `gorm:"type:text;not null;comment:消息内容" json:"content"`
+	RelatedID   string         `gorm:"size:64;comment:关联ID(如订单号)" json:"related_id"`
+	TargetUsers string         `gorm:"type:text;comment:目标用户:all=全部,user_id1,user_id2=指定用户" json:"target_users"`
+	Total       int64          `gorm:"default:0;comment:目标用户总数" json:"total"`
+	Sent        int64          `gorm:"default:0;comment:已发送数量" json:"sent"`
+	Status      int            `gorm:"default:0;comment:状态:0待处理,1发送中,2完成,3失败" json:"status"`
+	Error       string         `gorm:"size:500;comment:失败原因" json:"error"`
+	CreatedBy   uint           `gorm:"comment:创建人ID" json:"created_by"`
+	CreatedAt   int64          `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt   int64          `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate GORM创建前钩子
+func (m *MessageBroadcastJob) BeforeCreate(tx *gorm.DB) error {
+	now := tx.Statement.DB.NowFunc().Unix()
+	if m.CreatedAt == 0 {
+		m.CreatedAt = now
+	}
+	if m.UpdatedAt == 0 {
+		m.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate GORM更新前钩子
+func (m *MessageBroadcastJob) BeforeUpdate(tx *gorm.DB) error {
+	m.UpdatedAt = tx.Statement.DB.NowFunc().Unix()
+	return nil
+}
+
+// TableName 表名
+func (MessageBroadcastJob) TableName() string {
+	return "message_broadcast_jobs"
+}
@@ -9,7 +9,7 @@ type Transaction struct {
 	ID        uint    `gorm:"primarykey" json:"id"`
 	OrderID   string  `gorm:"uniqueIndex;size:64;not null;comment:订单号" json:"order_id"`
 	UserID    uint    `gorm:"index;not null;comment:用户ID" json:"user_id"`
-	Type      string  `gorm:"size:20;not null;comment:类型:recharge/withdraw/game" json:"type"`
+	Type      string  `gorm:"size:20;not null;comment:类型:recharge/withdraw/game_win/game_loss" json:"type"`
 	Amount    float64 `gorm:"type:decimal(10,2);not null;comment:金额" json:"amount"`
 	Status    int8    `gorm:"default:1;comment:状态:1待处理,2成功,3失败" json:"status"`
 	Channel   string  `gorm:"size:20;comment:支付渠道:alipay/wechat" json:"channel"`
@@ -44,22 +44,23 @@ func (Transaction) TableName() string {
 
 // RechargeOrder 充值订单
 type RechargeOrder struct {
-	ID           uint    `gorm:"primarykey" json:"id"`
-	OrderID      string  `gorm:"uniqueIndex;size:64;not null;comment:订单号" json:"order_id"`
-	UserID       uint    `gorm:"index;not null;comment:用户ID" json:"user_id"`
-	Amount       float64 `gorm:"type:decimal(10,2);not null;comment:充值金额" json:"amount"`
-	Status       int8    `gorm:"default:1;comment:状态:1待支付,2已支付,3已取消" json:"status"`
-	Channel      string  `gorm:"size:20;comment:支付渠道:usdt_trc20/usdt_erc20" json:"channel"`
-	ChannelID    string  `gorm:"size:100;comment:第三方订单号" json:"channel_id"`
-	ChainType    string  `gorm:"size:20;comment:链类型:trc20/erc20" json:"chain_type"`
-	DepositAddr  string  `gorm:"size:100;index;comment:充值地址" json:"deposit_addr"`
-	TxHash       string  `gorm:"size:128;index;comment:交易哈希" json:"tx_hash"`
-	ConfirmCount int     `gorm:"default:0;comment:确认次数" json:"confirm_count"`
-	RequiredConf int     `gorm:"default:12;comment:需要确认次数" json:"required_conf"`
-	PaidAt       *int64  `gorm:"type:bigint;default:0;comment:支付时间" json:"paid_at"`
-	ExpireAt     int64   `gorm:"type:bigint;not null;default:0;comment:过期时间" json:"expire_at"`
-	CreatedAt    int64   `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
-	UpdatedAt    int64   `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	ID             uint    `gorm:"primarykey" json:"id"`
+	OrderID        string  `gorm:"uniqueIndex;size:64;not null;comment:订单号" json:"order_id"`
+	UserID         uint    `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Amount         float64 `gorm:"type:decimal(10,2);not null;comment:充值金额" json:"amount"`
+	ReceivedAmount float64 `gorm:"type:decimal(10,2);not null;default:0;comment:链上实际到账金额" json:"received_amount"`
+	Status         int8    `gorm:"default:1;comment:状态:1待支付,2已支付,3已取消" json:"status"`
+	Channel        string  `gorm:"size:20;comment:支付渠道:usdt_trc20/usdt_erc20" json:"channel"`
+	ChannelID      string  `gorm:"size:100;comment:第三方订单号" json:"channel_id"`
+	ChainType      string  `gorm:"size:20;comment:链类型:trc20/erc20" json:"chain_type"`
+	DepositAddr    string  `gorm:"size:100;index;comment:充值地址" json:"deposit_addr"`
+	TxHash         string  `gorm:"size:128;index;comment:交易哈希" json:"tx_hash"`
+	ConfirmCount   int     `gorm:"default:0;comment:确认次数" json:"confirm_count"`
+	RequiredConf   int     `gorm:"default:12;comment:需要确认次数" json:"required_conf"`
+	PaidAt         *int64  `gorm:"type:bigint;default:0;comment:支付时间" json:"paid_at"`
+	ExpireAt       int64   `gorm:"type:bigint;not null;default:0;comment:过期时间" json:"expire_at"`
+	CreatedAt      int64   `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt      int64   `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
 }
 
 // BeforeCreate GORM创建前钩子
@@ -93,7 +94,7 @@ type WithdrawOrder struct {
 	Amount       float64        `gorm:"type:decimal(10,2);not null;comment:提现金额" json:"amount"`
 	Fee          float64        `gorm:"type:decimal(10,2);not null;default:0;comment:手续费" json:"fee"`
 	ActualAmount float64        `gorm:"type:decimal(10,2);not null;comment:实际到账金额" json:"actual_amount"`
-	Status       int8           `gorm:"default:1;comment:状态:1待审核,2已通过,3已拒绝" json:"status"`
+	Status       int8           `gorm:"default:1;comment:状态:1待审核,2已通过,3已拒绝,4转账中" json:"status"`
 	Channel      string         `gorm:"size:20;comment:支付渠道:usdt_trc20/usdt_erc20" json:"channel"`
 	ChainType    string         `gorm:"size:20;comment:链类型:trc20/erc20" json:"chain_type"`
 	ToAddress    string         `gorm:"size:100;index;comment:提现地址" json:"to_address"`
@@ -165,3 +166,27 @@ func (u *UserDepositAddress) BeforeUpdate(tx *gorm.DB) error {
 func (UserDepositAddress) TableName() string {
 	return "user_deposit_addresses"
 }
+
+// PaymentIdempotencyKey 支付订单幂等键（同一用户携带相同幂等键的重试请求返回原订单）
+type PaymentIdempotencyKey struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	UserID    uint   `gorm:"uniqueIndex:uk_user_key;not null;comment:用户ID" json:"user_id"`
+	Key       string `gorm:"uniqueIndex:uk_user_key;size:64;not null;comment:幂等键" json:"key"`
+	OrderType string `gorm:"size:20;not null;comment:订单类型:recharge/withdraw" json:"order_type"`
+	OrderID   string `gorm:"size:64;not null;comment:订单号" json:"order_id"`
+	ExpireAt  int64  `gorm:"type:bigint;not null;comment:过期时间" json:"expire_at"`
+	CreatedAt int64  `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+}
+
+// BeforeCreate GORM创建前钩子
+func (p *PaymentIdempotencyKey) BeforeCreate(tx *gorm.DB) error {
+	if p.CreatedAt == 0 {
+		p.CreatedAt = tx.Statement.DB.NowFunc().Unix()
+	}
+	return nil
+}
+
+// TableName 表名
+func (PaymentIdempotencyKey) TableName() string {
+	return "payment_idempotency_keys"
+}
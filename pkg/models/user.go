@@ -6,17 +6,19 @@ import (
 
 // User 用户模型
 type User struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	UID       int64          `gorm:"uniqueIndex;not null;comment:用户ID" json:"uid"`
-	Phone     string         `gorm:"uniqueIndex;size:20;not null;comment:手机号" json:"phone"`
-	Password  string         `gorm:"size:255;not null;comment:密码(加密后)" json:"-"`
-	Nickname  string         `gorm:"size:50;not null;default:'';comment:昵称" json:"nickname"`
-	Avatar    string         `gorm:"size:255;default:'';comment:头像" json:"avatar"`
-	Balance   float64        `gorm:"type:decimal(10,2);default:0;comment:余额" json:"balance"`
-	Status    int8           `gorm:"default:1;comment:状态:1正常,2封禁" json:"status"`
-	CreatedAt int64          `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
-	UpdatedAt int64          `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primarykey" json:"id"`
+	UID           int64          `gorm:"uniqueIndex;not null;comment:用户ID" json:"uid"`
+	Phone         string         `gorm:"uniqueIndex;size:20;not null;comment:手机号" json:"phone"`
+	Password      string         `gorm:"size:255;not null;comment:密码(加密后)" json:"-"`
+	Email         string         `gorm:"size:100;default:'';comment:邮箱" json:"email"`
+	EmailVerified int8           `gorm:"default:0;comment:邮箱验证:0未验证,1已验证" json:"email_verified"`
+	Nickname      string         `gorm:"size:50;not null;default:'';comment:昵称" json:"nickname"`
+	Avatar        string         `gorm:"size:255;default:'';comment:头像" json:"avatar"`
+	Balance       float64        `gorm:"type:decimal(10,2);default:0;comment:余额" json:"balance"`
+	Status        int8           `gorm:"default:1;comment:状态:1正常,2封禁" json:"status"`
+	CreatedAt     int64          `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt     int64          `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // BeforeCreate GORM创建前钩子
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// WebhookDelivery 订单状态回调推送记录
+type WebhookDelivery struct {
+	ID          uint    `gorm:"primarykey" json:"id"`
+	OrderID     string  `gorm:"index;size:64;not null;comment:订单号" json:"order_id"`
+	UserID      uint    `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	OrderType   string  `gorm:"size:20;not null;comment:订单类型:recharge/withdraw" json:"order_type"`
+	OrderStatus string  `gorm:"size:20;not null;comment:订单状态:paid/approved/rejected" json:"order_status"`
+	Amount      float64 `gorm:"type:decimal(10,2);not null;comment:金额" json:"amount"`
+	TxHash      string  `gorm:"size:128;comment:交易哈希" json:"tx_hash"`
+	URL         string  `gorm:"size:255;not null;comment:回调地址" json:"url"`
+	Payload     string  `gorm:"type:text;comment:回调载荷(JSON)" json:"payload"`
+	Status      int8    `gorm:"default:1;comment:状态:1待推送,2成功,3失败" json:"status"`
+	Attempts    int     `gorm:"default:0;comment:尝试次数" json:"attempts"`
+	LastError   string  `gorm:"size:255;comment:最近一次错误" json:"last_error"`
+	DeliveredAt *int64  `gorm:"type:bigint;comment:推送成功时间" json:"delivered_at"`
+	CreatedAt   int64   `gorm:"type:bigint;not null;default:0;comment:创建时间" json:"created_at"`
+	UpdatedAt   int64   `gorm:"type:bigint;not null;default:0;comment:更新时间" json:"updated_at"`
+}
+
+// BeforeCreate GORM创建前钩子
+func (w *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	now := tx.Statement.DB.NowFunc().Unix()
+	if w.CreatedAt == 0 {
+		w.CreatedAt = now
+	}
+	if w.UpdatedAt == 0 {
+		w.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate GORM更新前钩子
+func (w *WebhookDelivery) BeforeUpdate(tx *gorm.DB) error {
+	w.UpdatedAt = tx.Statement.DB.NowFunc().Unix()
+	return nil
+}
+
+// TableName 表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -2,17 +2,28 @@ package services
 
 import (
 	"errors"
-	"math/rand"
 	"sort"
-	"time"
+
+	"github.com/kaifa/game-platform/pkg/models"
 )
 
 // BullGame 牛牛游戏引擎
-type BullGame struct{}
+type BullGame struct {
+	shuffler Shuffler
+}
+
+func init() {
+	RegisterEngine("bull", func() GameEngine { return NewBullGame() })
+}
 
-// NewBullGame 创建牛牛游戏引擎
+// NewBullGame 创建牛牛游戏引擎（使用生产默认洗牌器）
 func NewBullGame() *BullGame {
-	return &BullGame{}
+	return NewBullGameWithShuffler(newDefaultShuffler())
+}
+
+// NewBullGameWithShuffler 创建指定洗牌器的牛牛引擎（测试注入固定种子用）
+func NewBullGameWithShuffler(shuffler Shuffler) *BullGame {
+	return &BullGame{shuffler: shuffler}
 }
 
 // GetGameName 获取游戏名称
@@ -25,6 +36,11 @@ func (g *BullGame) GetGameType() string {
 	return "bull"
 }
 
+// PlayerRange 获取支持的玩家数量范围
+func (g *BullGame) PlayerRange() (int, int) {
+	return 2, 5
+}
+
 // DealCards 发牌（牛牛：每人5张牌）
 func (g *BullGame) DealCards(playerCount int) (map[uint][]int, error) {
 	if playerCount < 2 || playerCount > 5 {
@@ -41,8 +57,7 @@ func (g *BullGame) DealCards(playerCount int) (map[uint][]int, error) {
 	}
 
 	// 洗牌
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	r.Shuffle(len(deck), func(i, j int) {
+	g.shuffler.Shuffle(len(deck), func(i, j int) {
 		deck[i], deck[j] = deck[j], deck[i]
 	})
 
@@ -237,6 +252,27 @@ func (g *BullGame) isFiveSmall(cards []int) bool {
 	return sum <= 10
 }
 
+// PayoutMultiplier 返回牌型的赔付倍数（翻倍规则）：
+// 无牛~牛六1倍，牛七/牛八2倍，牛九3倍，牛牛4倍，四花/五花5倍，炸弹6倍，五小牛8倍
+func (g *BullGame) PayoutMultiplier(bullType int) float64 {
+	switch {
+	case bullType >= 14: // 五小牛
+		return 8
+	case bullType == 13: // 炸弹
+		return 6
+	case bullType >= 11: // 四花/五花
+		return 5
+	case bullType == 10: // 牛牛
+		return 4
+	case bullType == 9: // 牛九
+		return 3
+	case bullType >= 7: // 牛七/牛八
+		return 2
+	default: // 无牛~牛六
+		return 1
+	}
+}
+
 // CompareBull 比较两个牛牛牌型
 // 返回: >0表示card1大于card2, <0表示card1小于card2, 0表示相等
 func (g *BullGame) CompareBull(cards1, cards2 []int) int {
@@ -262,3 +298,117 @@ func (g *BullGame) CompareBull(cards1, cards2 []int) int {
 	// 牛数相同，比较最大牌
 	return maxCard1 - maxCard2
 }
+
+// compareBullPlayers 比较两个玩家已存储的牛牛结果
+// 返回: >0表示a大于b, <0表示a小于b, 0表示相等（比较规则与CompareBull一致）
+func compareBullPlayers(a, b *models.PlayerGameInfo) int {
+	if a.BullType != b.BullType {
+		return a.BullType - b.BullType
+	}
+	if a.BullType == 10 || a.BullType == 0 {
+		return a.MaxCard - b.MaxCard
+	}
+	if a.BullNum != b.BullNum {
+		return a.BullNum - b.BullNum
+	}
+	return a.MaxCard - b.MaxCard
+}
+
+// Setup 开局初始化：牌最大的玩家坐庄，庄家先亮牌
+func (g *BullGame) Setup(gameState *models.GameState) error {
+	// 按位置顺序遍历，平局时位置靠前者坐庄，保证结果确定
+	playerIDs := make([]uint, 0, len(gameState.Players))
+	for playerID := range gameState.Players {
+		playerIDs = append(playerIDs, playerID)
+	}
+	sort.Slice(playerIDs, func(i, j int) bool {
+		return gameState.Players[playerIDs[i]].Position < gameState.Players[playerIDs[j]].Position
+	})
+
+	banker := uint(0)
+	for _, playerID := range playerIDs {
+		if banker == 0 || g.CompareBull(gameState.Players[playerID].Cards, gameState.Players[banker].Cards) > 0 {
+			banker = playerID
+		}
+	}
+
+	// 记录庄家：结算时庄家与每个闲家单独比牌
+	gameState.CurrentPlayer = banker
+	gameState.Banker = banker
+	if bankerInfo, ok := gameState.Players[banker]; ok {
+		bankerInfo.IsBanker = true
+	}
+	return nil
+}
+
+// Settle 结算：补全未亮牌玩家的牛牛结果、按牌型大小分配名次，
+// 然后庄家与每个闲家单独比牌计算输赢。
+// 闲家赢拿 底注*押注倍数*闲家牌型倍数，闲家输赔 底注*押注倍数*庄家牌型倍数，
+// 平局算庄家赢（庄家通吃）。庄家的输赢是与所有闲家结算的总和，整体零和。
+func (g *BullGame) Settle(gameState *models.GameState, baseBet float64) map[uint]float64 {
+	// 补全未亮牌玩家的牛牛结果（超时未出牌时用原始手牌计算）
+	settled := make([]*models.PlayerGameInfo, 0, len(gameState.Players))
+	for _, playerInfo := range gameState.Players {
+		cards := playerInfo.PlayedCards
+		if len(cards) == 0 {
+			cards = playerInfo.Cards
+		}
+		if len(cards) == 0 {
+			continue
+		}
+
+		if playerInfo.BullType == 0 && playerInfo.BullNum == 0 && playerInfo.MaxCard == 0 {
+			playerInfo.BullType, playerInfo.BullNum, playerInfo.MaxCard = g.CalculateBull(cards)
+			playerInfo.PlayedCards = cards
+		}
+		settled = append(settled, playerInfo)
+	}
+
+	// 按牌型大小分配名次（从大到小）
+	sort.Slice(settled, func(i, j int) bool {
+		return compareBullPlayers(settled[i], settled[j]) > 0
+	})
+	for i, playerInfo := range settled {
+		playerInfo.Rank = i + 1
+	}
+
+	bankerID := gameState.Banker
+	if _, ok := gameState.Players[bankerID]; !ok {
+		// 兼容没有记录庄家的旧局：牌最大的玩家坐庄
+		for uid, p := range gameState.Players {
+			if bankerID == 0 || compareBullPlayers(p, gameState.Players[bankerID]) > 0 {
+				bankerID = uid
+			}
+		}
+	}
+
+	balances := make(map[uint]float64)
+	banker := gameState.Players[bankerID]
+	if banker == nil {
+		return balances
+	}
+	balances[bankerID] = 0
+
+	for uid, p := range gameState.Players {
+		if uid == bankerID {
+			continue
+		}
+
+		betMultiplier := float64(p.BetMultiplier)
+		if betMultiplier < 1 {
+			betMultiplier = 1 // 未选择押注倍数时按1倍计
+		}
+
+		var amount float64
+		if compareBullPlayers(p, banker) > 0 {
+			amount = baseBet * betMultiplier * g.PayoutMultiplier(p.BullType)
+		} else {
+			amount = -baseBet * betMultiplier * g.PayoutMultiplier(banker.BullType)
+		}
+
+		balances[uid] = amount
+		balances[bankerID] -= amount
+	}
+
+	return balances
+}
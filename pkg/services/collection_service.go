@@ -17,6 +17,7 @@ import (
 
 	"github.com/kaifa/game-platform/internal/database"
 	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/worker"
 	"github.com/kaifa/game-platform/pkg/models"
 )
 
@@ -95,7 +96,7 @@ func (cs *CollectionService) GetTRC20USDTBalance(address string) (*big.Float, er
 }
 
 // CollectUSDT 归集USDT（从派生地址归集到主钱包）
-func (cs *CollectionService) CollectUSDT(userID uint, chainType string) (string, error) {
+func (cs *CollectionService) CollectUSDT(userID uint, chainType string) (txHash string, err error) {
 	// 1. 获取用户充值地址
 	var depositAddr models.UserDepositAddress
 	if err := database.DB.Where("user_id = ? AND chain_type = ?", userID, chainType).First(&depositAddr).Error; err != nil {
@@ -104,7 +105,6 @@ func (cs *CollectionService) CollectUSDT(userID uint, chainType string) (string,
 
 	// 2. 检查USDT余额
 	var usdtBalance *big.Float
-	var err error
 
 	if chainType == "erc20" {
 		addr := common.HexToAddress(depositAddr.Address)
@@ -127,9 +127,15 @@ func (cs *CollectionService) CollectUSDT(userID uint, chainType string) (string,
 		return "", errors.New("余额不足，无需归集")
 	}
 
-	// 3. 估算Gas费用
+	// 余额确认后记录归集尝试（成功/失败都入库，供审计）
+	collectAmount, _ := usdtBalance.Float64()
+	defer func() {
+		cs.recordCollection(userID, chainType, depositAddr.Address, collectAmount, txHash, err)
+	}()
+
+	// 3. 估算Gas费用（使用缓存，避免批量归集时频繁请求RPC）
 	gasLimit := uint64(100000) // ERC20转账通常需要约100000 gas
-	requiredGas, err := cs.gasManager.EstimateGasFee(chainType, gasLimit)
+	requiredGas, err := cs.gasManager.GetCachedGasFee(chainType, gasLimit)
 	if err != nil {
 		return "", fmt.Errorf("估算Gas费用失败: %w", err)
 	}
@@ -179,7 +185,6 @@ func (cs *CollectionService) CollectUSDT(userID uint, chainType string) (string,
 	usdtBalance.Mul(usdtBalance, big.NewFloat(1e6)).Int(amountInt)
 
 	// 8. 执行USDT转账
-	var txHash string
 	if chainType == "erc20" {
 		toAddr := common.HexToAddress(masterAddr)
 		txHash, err = cs.transferService.TransferERC20USDT(fromAddr, toAddr, amountInt, privateKey)
@@ -233,6 +238,138 @@ func (cs *CollectionService) getMasterPrivateKey(chainType string) *ecdsa.Privat
 	return privateKey
 }
 
+// recordCollection 记录归集尝试（成功/失败）
+func (cs *CollectionService) recordCollection(userID uint, chainType string, address string, amount float64, txHash string, collectErr error) {
+	record := models.CollectionRecord{
+		UserID:    userID,
+		ChainType: chainType,
+		Address:   address,
+		Amount:    amount,
+		TxHash:    txHash,
+		Status:    2, // 成功
+	}
+	if collectErr != nil {
+		record.Status = 3 // 失败
+		record.ErrorMsg = collectErr.Error()
+	}
+
+	if err := database.DB.Create(&record).Error; err != nil {
+		logger.Logger.Warn("保存归集记录失败",
+			zap.Uint("user_id", userID),
+			zap.String("chain_type", chainType),
+			zap.Error(err),
+		)
+	}
+}
+
+// getUSDTBalance 查询指定链上地址的USDT余额
+func (cs *CollectionService) getUSDTBalance(chainType string, address string) (*big.Float, error) {
+	if chainType == "erc20" {
+		return cs.GetERC20USDTBalance(common.HexToAddress(address))
+	}
+	if chainType == "trc20" {
+		return cs.GetTRC20USDTBalance(address)
+	}
+	return nil, fmt.Errorf("不支持的链类型: %s", chainType)
+}
+
+// StartAutoCollect 启动自动归集调度
+// interval: 扫描间隔
+// threshold: 最小归集余额（USDT）
+// concurrency: 并发归集数（通过 Worker Pool 限制）
+func (cs *CollectionService) StartAutoCollect(interval time.Duration, threshold float64, concurrency int) {
+	if concurrency <= 0 {
+		concurrency = 3
+	}
+	pool := worker.NewPool(concurrency, 100)
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			for _, chainType := range []string{"trc20", "erc20"} {
+				cs.autoCollect(chainType, threshold, pool)
+			}
+		}
+	}()
+
+	logger.Logger.Info("自动归集调度已启动",
+		zap.Duration("interval", interval),
+		zap.Float64("threshold", threshold),
+		zap.Int("concurrency", concurrency),
+	)
+}
+
+// autoCollect 执行一轮自动归集
+func (cs *CollectionService) autoCollect(chainType string, threshold float64, pool *worker.Pool) {
+	var depositAddrs []models.UserDepositAddress
+	if err := database.DB.Where("chain_type = ?", chainType).Find(&depositAddrs).Error; err != nil {
+		logger.Logger.Warn("查询充值地址失败",
+			zap.String("chain_type", chainType),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// 1. 筛选余额达到阈值的地址
+	var eligible []models.UserDepositAddress
+	for _, addr := range depositAddrs {
+		balance, err := cs.getUSDTBalance(chainType, addr.Address)
+		if err != nil {
+			logger.Logger.Debug("查询余额失败",
+				zap.String("address", addr.Address),
+				zap.Error(err),
+			)
+			continue
+		}
+		if b, _ := balance.Float64(); b >= threshold {
+			eligible = append(eligible, addr)
+		}
+	}
+
+	if len(eligible) == 0 {
+		return
+	}
+
+	// 2. 批量预打Gas（归集前统一补足，避免逐个归集时串行等待）
+	gasLimit := uint64(100000)
+	requiredGas, err := cs.gasManager.GetCachedGasFee(chainType, gasLimit)
+	if err != nil {
+		logger.Logger.Warn("估算Gas费用失败",
+			zap.String("chain_type", chainType),
+			zap.Error(err),
+		)
+	} else {
+		masterKey := cs.getMasterPrivateKey(chainType)
+		for _, addr := range eligible {
+			if _, err := cs.gasManager.EnsureGasBalance(addr.Address, chainType, requiredGas, masterKey); err != nil {
+				logger.Logger.Warn("预打Gas失败",
+					zap.String("address", addr.Address),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	// 3. 通过 Worker Pool 有界并发归集
+	for _, addr := range eligible {
+		a := addr
+		if err := pool.Submit(func(ctx context.Context) error {
+			_, err := cs.CollectUSDT(a.UserID, chainType)
+			return err
+		}); err != nil {
+			logger.Logger.Warn("提交归集任务失败",
+				zap.Uint("user_id", a.UserID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	logger.Logger.Info("自动归集任务已提交",
+		zap.String("chain_type", chainType),
+		zap.Int("count", len(eligible)),
+	)
+}
+
 // BatchCollectUSDT 批量归集USDT
 func (cs *CollectionService) BatchCollectUSDT(chainType string, limit int) error {
 	// 查询有余额的充值地址
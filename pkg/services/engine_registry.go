@@ -0,0 +1,44 @@
+package services
+
+import "sync"
+
+// EngineFactory 游戏引擎工厂函数
+type EngineFactory func() GameEngine
+
+// 全局引擎注册表：游戏类型 → 工厂。各引擎在自己的文件里通过init()
+// 自注册，新增游戏只需实现GameEngine并注册，无需改动Manager
+var (
+	engineRegistryMu sync.RWMutex
+	engineRegistry   = make(map[string]EngineFactory)
+)
+
+// RegisterEngine 注册游戏引擎工厂（重复注册时后者覆盖前者）
+func RegisterEngine(gameType string, factory EngineFactory) {
+	engineRegistryMu.Lock()
+	defer engineRegistryMu.Unlock()
+	engineRegistry[gameType] = factory
+}
+
+// NewRegisteredEngines 实例化所有已注册的引擎（游戏类型 → 引擎实例）
+func NewRegisteredEngines() map[string]GameEngine {
+	engineRegistryMu.RLock()
+	defer engineRegistryMu.RUnlock()
+
+	engines := make(map[string]GameEngine, len(engineRegistry))
+	for gameType, factory := range engineRegistry {
+		engines[gameType] = factory()
+	}
+	return engines
+}
+
+// RegisteredGameTypes 已注册的游戏类型列表（校验/调试用）
+func RegisteredGameTypes() []string {
+	engineRegistryMu.RLock()
+	defer engineRegistryMu.RUnlock()
+
+	types := make([]string, 0, len(engineRegistry))
+	for gameType := range engineRegistry {
+		types = append(types, gameType)
+	}
+	return types
+}
@@ -1,5 +1,7 @@
 package services
 
+import "github.com/kaifa/game-platform/pkg/models"
+
 // GameEngine 游戏引擎接口
 type GameEngine interface {
 	// DealCards 发牌
@@ -13,9 +15,26 @@ type GameEngine interface {
 	// 返回: (是否合法, 错误信息)
 	ValidateCards(cards []int, lastCards []int) (bool, string)
 
+	// PlayerRange 获取支持的玩家数量范围
+	// 返回: (最小人数, 最大人数)
+	PlayerRange() (int, int)
+
 	// GetGameName 获取游戏名称
 	GetGameName() string
 
 	// GetGameType 获取游戏类型
 	GetGameType() string
+
+	// Settle 结算：补全各玩家名次并按本游戏的规则计算输赢金额。
+	// 赔付规则（跑得快按名次、牛牛庄闲对赌等）由各引擎自己实现，
+	// 余额落库、对局记录和排行榜更新仍由Manager负责。
+	// baseBet: 房间底注
+	// 返回: map[用户ID]输赢金额
+	Settle(gameState *models.GameState, baseBet float64) map[uint]float64
+}
+
+// GameSetup 可选扩展：开局发牌后的游戏特有初始化（确定首出玩家、庄家等）。
+// 未实现时Manager按默认规则（位置最靠前的玩家先出）开局。
+type GameSetup interface {
+	Setup(gameState *models.GameState) error
 }
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -20,11 +22,23 @@ import (
 	"github.com/kaifa/game-platform/internal/logger"
 )
 
+// gasPriceEntry Gas价格缓存条目
+type gasPriceEntry struct {
+	gasPrice  *big.Int   // ERC20: 每单位Gas价格（Wei）
+	flatFee   *big.Float // TRC20: 每笔固定估算费用（TRX）
+	updatedAt time.Time
+}
+
 // GasManager Gas费用管理器
 type GasManager struct {
 	ethClient  *ethclient.Client
 	tronClient *client.GrpcClient
 	hdWallet   *HDWallet
+
+	// Gas价格缓存（chainType -> 缓存条目）
+	cacheMu  sync.RWMutex
+	gasCache map[string]*gasPriceEntry
+	cacheTTL time.Duration
 }
 
 // NewGasManager 创建Gas管理器
@@ -33,7 +47,122 @@ func NewGasManager(ethClient *ethclient.Client, tronClient *client.GrpcClient, h
 		ethClient:  ethClient,
 		tronClient: tronClient,
 		hdWallet:   hdWallet,
+		gasCache:   make(map[string]*gasPriceEntry),
+		cacheTTL:   60 * time.Second,
+	}
+}
+
+// SetGasCacheTTL 设置Gas价格缓存TTL
+func (gm *GasManager) SetGasCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	gm.cacheMu.Lock()
+	gm.cacheTTL = ttl
+	gm.cacheMu.Unlock()
+}
+
+// StartGasPriceRefresher 启动Gas价格后台刷新协程（按TTL周期刷新）
+func (gm *GasManager) StartGasPriceRefresher() {
+	go func() {
+		for {
+			for _, chainType := range []string{"erc20", "trc20"} {
+				if err := gm.refreshGasPrice(chainType); err != nil {
+					logger.Logger.Debug("刷新Gas价格失败",
+						zap.String("chain_type", chainType),
+						zap.Error(err),
+					)
+				}
+			}
+
+			gm.cacheMu.RLock()
+			interval := gm.cacheTTL
+			gm.cacheMu.RUnlock()
+			time.Sleep(interval)
+		}
+	}()
+
+	logger.Logger.Info("Gas价格后台刷新已启动",
+		zap.Duration("ttl", gm.cacheTTL),
+	)
+}
+
+// GetCachedGasFee 获取缓存的Gas费用估算
+// 缓存未命中或过期时回退到实时查询；实时查询失败但存在过期缓存时降级使用过期值
+func (gm *GasManager) GetCachedGasFee(chainType string, gasLimit uint64) (*big.Float, error) {
+	gm.cacheMu.RLock()
+	entry, ok := gm.gasCache[chainType]
+	ttl := gm.cacheTTL
+	gm.cacheMu.RUnlock()
+
+	if ok {
+		age := time.Since(entry.updatedAt)
+		if age <= ttl {
+			return gm.feeFromEntry(chainType, entry, gasLimit)
+		}
+		logger.Logger.Warn("Gas价格缓存已过期，回退实时查询",
+			zap.String("chain_type", chainType),
+			zap.Duration("age", age),
+			zap.Duration("ttl", ttl),
+		)
+	}
+
+	// 缓存未命中或过期：实时刷新
+	if err := gm.refreshGasPrice(chainType); err != nil {
+		if ok {
+			// 刷新失败时降级使用过期缓存
+			logger.Logger.Warn("刷新Gas价格失败，降级使用过期缓存",
+				zap.String("chain_type", chainType),
+				zap.Error(err),
+			)
+			return gm.feeFromEntry(chainType, entry, gasLimit)
+		}
+		return nil, err
+	}
+
+	gm.cacheMu.RLock()
+	entry = gm.gasCache[chainType]
+	gm.cacheMu.RUnlock()
+	return gm.feeFromEntry(chainType, entry, gasLimit)
+}
+
+// refreshGasPrice 实时查询Gas价格并写入缓存
+func (gm *GasManager) refreshGasPrice(chainType string) error {
+	entry := &gasPriceEntry{updatedAt: time.Now()}
+
+	if chainType == "erc20" {
+		if gm.ethClient == nil {
+			return errors.New("以太坊客户端未初始化")
+		}
+		gasPrice, err := gm.ethClient.SuggestGasPrice(context.Background())
+		if err != nil {
+			return fmt.Errorf("获取Gas价格失败: %w", err)
+		}
+		entry.gasPrice = gasPrice
+	} else if chainType == "trc20" {
+		// TRC20转账费用估算（以TRX为单位），与 EstimateGasFee 保持一致
+		entry.flatFee = big.NewFloat(0.01)
+	} else {
+		return fmt.Errorf("不支持的链类型: %s", chainType)
+	}
+
+	gm.cacheMu.Lock()
+	gm.gasCache[chainType] = entry
+	gm.cacheMu.Unlock()
+	return nil
+}
+
+// feeFromEntry 根据缓存条目计算Gas费用
+func (gm *GasManager) feeFromEntry(chainType string, entry *gasPriceEntry, gasLimit uint64) (*big.Float, error) {
+	if entry == nil {
+		return nil, errors.New("Gas价格缓存为空")
+	}
+
+	if chainType == "erc20" {
+		totalGas := new(big.Int).Mul(big.NewInt(int64(gasLimit)), entry.gasPrice)
+		return new(big.Float).Quo(new(big.Float).SetInt(totalGas), big.NewFloat(1e18)), nil
 	}
+	return new(big.Float).Copy(entry.flatFee), nil
 }
 
 // GetETHBalance 获取ETH余额（以太坊主币）
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/kaifa/game-platform/internal/logger"
+	"go.uber.org/zap"
+)
+
+// 固定助记词（BIP39标准测试助记词），用于生成确定性测试向量
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func init() {
+	// 测试环境不初始化完整日志系统
+	logger.Logger = zap.NewNop()
+	logger.Sugar = logger.Logger.Sugar()
+}
+
+func newTestWallet(t *testing.T) *HDWallet {
+	t.Helper()
+	w, err := NewHDWallet(testMnemonic)
+	if err != nil {
+		t.Fatalf("NewHDWallet: %v", err)
+	}
+	return w
+}
+
+// TestDeriveAddressVectors 校验固定助记词下的派生地址与路径
+// 充值地址是持久化数据，派生逻辑的任何变更都会导致用户地址漂移，
+// 因此这些向量一旦写定就不允许改变
+func TestDeriveAddressVectors(t *testing.T) {
+	w := newTestWallet(t)
+
+	vectors := []struct {
+		userID   uint32
+		ethAddr  string
+		tronAddr string
+		ethPath  string
+		tronPath string
+	}{
+		{
+			userID:   0,
+			ethAddr:  "0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+			tronAddr: "TUEZSdKsoDHQMeZwihtdoBiN46zxggErJz",
+			ethPath:  "m/44'/60'/0'/0/0",
+			tronPath: "m/44'/195'/0'/0/0",
+		},
+		{
+			userID:   1,
+			ethAddr:  "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+			tronAddr: "TSeJkUh4Qv67VNFwY8LaAxERygNdxBhGpR",
+			ethPath:  "m/44'/60'/0'/0/1",
+			tronPath: "m/44'/195'/0'/0/1",
+		},
+		{
+			userID:   1000,
+			ethAddr:  "0xaC2Dbb7Dc8Fa82F47021e01255fBD767a2Ec4EaD",
+			tronAddr: "TTsXdXbiYdVbcUh6LhWAh41wy4pv7qHh7t",
+			ethPath:  "m/44'/60'/0'/0/1000",
+			tronPath: "m/44'/195'/0'/0/1000",
+		},
+	}
+
+	for _, v := range vectors {
+		ethAddr, _, err := w.DeriveEthereumAddress(0, v.userID)
+		if err != nil {
+			t.Fatalf("DeriveEthereumAddress(0, %d): %v", v.userID, err)
+		}
+		if ethAddr.Hex() != v.ethAddr {
+			t.Errorf("以太坊地址不匹配 userID=%d: got %s, want %s", v.userID, ethAddr.Hex(), v.ethAddr)
+		}
+
+		tronAddr, _, err := w.DeriveTronAddress(0, v.userID)
+		if err != nil {
+			t.Fatalf("DeriveTronAddress(0, %d): %v", v.userID, err)
+		}
+		if tronAddr != v.tronAddr {
+			t.Errorf("波场地址不匹配 userID=%d: got %s, want %s", v.userID, tronAddr, v.tronAddr)
+		}
+
+		if got := GetEthereumPath(0, v.userID); got != v.ethPath {
+			t.Errorf("以太坊路径不匹配 userID=%d: got %s, want %s", v.userID, got, v.ethPath)
+		}
+		if got := GetTronPath(0, v.userID); got != v.tronPath {
+			t.Errorf("波场路径不匹配 userID=%d: got %s, want %s", v.userID, got, v.tronPath)
+		}
+	}
+}
+
+// TestDeriveMasterAddressVectors 校验主钱包地址（account=0, index=0）
+// cmd/export-private-key 依赖主钱包派生保持稳定
+func TestDeriveMasterAddressVectors(t *testing.T) {
+	w := newTestWallet(t)
+
+	ethAddr, _, err := w.DeriveMasterEthereumAddress()
+	if err != nil {
+		t.Fatalf("DeriveMasterEthereumAddress: %v", err)
+	}
+	if want := "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"; ethAddr.Hex() != want {
+		t.Errorf("主钱包以太坊地址不匹配: got %s, want %s", ethAddr.Hex(), want)
+	}
+
+	tronAddr, _, err := w.DeriveMasterTronAddress()
+	if err != nil {
+		t.Fatalf("DeriveMasterTronAddress: %v", err)
+	}
+	if want := "TUEZSdKsoDHQMeZwihtdoBiN46zxggErJz"; tronAddr != want {
+		t.Errorf("主钱包波场地址不匹配: got %s, want %s", tronAddr, want)
+	}
+}
+
+// TestDeriveByUserIDMatchesIndexed 校验ByUserID变体与带索引的派生一致
+func TestDeriveByUserIDMatchesIndexed(t *testing.T) {
+	w := newTestWallet(t)
+
+	for _, userID := range []uint{0, 1, 1000, 123456} {
+		ethByID, err := w.DeriveEthereumAddressByUserID(userID)
+		if err != nil {
+			t.Fatalf("DeriveEthereumAddressByUserID(%d): %v", userID, err)
+		}
+		ethIndexed, _, err := w.DeriveEthereumAddress(0, uint32(userID))
+		if err != nil {
+			t.Fatalf("DeriveEthereumAddress(0, %d): %v", userID, err)
+		}
+		if ethByID != ethIndexed {
+			t.Errorf("以太坊派生不一致 userID=%d: ByUserID=%s, indexed=%s", userID, ethByID.Hex(), ethIndexed.Hex())
+		}
+
+		tronByID, err := w.DeriveTronAddressByUserID(userID)
+		if err != nil {
+			t.Fatalf("DeriveTronAddressByUserID(%d): %v", userID, err)
+		}
+		tronIndexed, _, err := w.DeriveTronAddress(0, uint32(userID))
+		if err != nil {
+			t.Fatalf("DeriveTronAddress(0, %d): %v", userID, err)
+		}
+		if tronByID != tronIndexed {
+			t.Errorf("波场派生不一致 userID=%d: ByUserID=%s, indexed=%s", userID, tronByID, tronIndexed)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// loginFailureKeyPrefix 登录失败计数的Redis键前缀
+const loginFailureKeyPrefix = "admin:login:failures:"
+
+// LoginLimiter 管理员登录失败限制器。
+// 连续失败达到阈值后锁定账号一段时间，计数存储在Redis（带过期时间），
+// Redis不可用时降级为本地内存计数（仅对当前实例生效）。
+type LoginLimiter struct {
+	rdb         *redis.Client
+	maxFailures int
+	window      time.Duration
+
+	mu    sync.Mutex
+	local map[string]*localFailState
+}
+
+// localFailState 本地降级时的失败计数
+type localFailState struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewLoginLimiter 创建登录限制器。
+// maxFailures 小于1时取默认值5，window 小于等于0时取默认值15分钟。
+func NewLoginLimiter(rdb *redis.Client, maxFailures int, window time.Duration) *LoginLimiter {
+	if maxFailures < 1 {
+		maxFailures = 5
+	}
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	return &LoginLimiter{
+		rdb:         rdb,
+		maxFailures: maxFailures,
+		window:      window,
+		local:       make(map[string]*localFailState),
+	}
+}
+
+// IsLocked 检查账号是否已锁定，返回锁定状态和剩余锁定时间
+func (l *LoginLimiter) IsLocked(ctx context.Context, username string) (bool, time.Duration) {
+	key := loginFailureKeyPrefix + username
+
+	if l.rdb != nil {
+		count, err := l.rdb.Get(ctx, key).Int()
+		if err == nil {
+			if count >= l.maxFailures {
+				ttl, _ := l.rdb.TTL(ctx, key).Result()
+				return true, ttl
+			}
+			return false, 0
+		}
+		if err == redis.Nil {
+			return false, 0
+		}
+		logger.Logger.Warn("读取登录失败计数失败，使用本地计数", zap.Error(err))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state, ok := l.local[username]
+	if !ok || time.Now().After(state.expiresAt) {
+		return false, 0
+	}
+	if state.count >= l.maxFailures {
+		return true, time.Until(state.expiresAt)
+	}
+	return false, 0
+}
+
+// RecordFailure 记录一次登录失败（首次失败时开始计时）
+func (l *LoginLimiter) RecordFailure(ctx context.Context, username string) {
+	key := loginFailureKeyPrefix + username
+
+	if l.rdb != nil {
+		count, err := l.rdb.Incr(ctx, key).Result()
+		if err == nil {
+			if count == 1 {
+				l.rdb.Expire(ctx, key, l.window)
+			}
+			return
+		}
+		logger.Logger.Warn("记录登录失败计数失败，使用本地计数", zap.Error(err))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state, ok := l.local[username]
+	if !ok || time.Now().After(state.expiresAt) {
+		l.local[username] = &localFailState{count: 1, expiresAt: time.Now().Add(l.window)}
+		return
+	}
+	state.count++
+}
+
+// Reset 清除失败计数（登录成功或管理员手动解锁时调用）
+func (l *LoginLimiter) Reset(ctx context.Context, username string) {
+	if l.rdb != nil {
+		if err := l.rdb.Del(ctx, loginFailureKeyPrefix+username).Err(); err != nil {
+			logger.Logger.Warn("清除登录失败计数失败", zap.Error(err))
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.local, username)
+}
+
+// LockedMessage 生成锁定提示信息
+func (l *LoginLimiter) LockedMessage(remaining time.Duration) string {
+	minutes := int(remaining.Minutes()) + 1
+	return fmt.Sprintf("登录失败次数过多，账号已锁定，请 %d 分钟后重试", minutes)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 系统维护相关配置键。维护开关打开后新建房间/加入房间/开始游戏会被拒绝，
+// 进行中的对局不受影响（出牌、过牌、结算照常），让平台自然排空。
+const (
+	// MaintenanceModeKey 维护模式开关（bool）
+	MaintenanceModeKey = "maintenance_mode"
+	// MaintenanceWhitelistKey 维护期豁免用户ID列表（逗号分隔，管理员/测试账号用）
+	MaintenanceWhitelistKey = "maintenance_whitelist"
+)
+
+// IsMaintenanceMode 当前是否处于维护模式
+func IsMaintenanceMode() bool {
+	return systemConfigService.GetBool(MaintenanceModeKey, false)
+}
+
+// IsMaintenanceExempt 用户是否在维护豁免名单内
+func IsMaintenanceExempt(userID uint) bool {
+	raw := systemConfigService.GetString(MaintenanceWhitelistKey, "")
+	if raw == "" {
+		return false
+	}
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			continue
+		}
+		if uint(id) == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// MaintenanceBlocked 用户是否应被维护模式拦截（开关开启且不在豁免名单）
+func MaintenanceBlocked(userID uint) bool {
+	return IsMaintenanceMode() && !IsMaintenanceExempt(userID)
+}
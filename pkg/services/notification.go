@@ -1,17 +1,192 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/messaging"
 	"github.com/kaifa/game-platform/pkg/models"
+	"go.uber.org/zap"
 )
 
-// SendOrderNotification 发送订单状态通知
-func SendOrderNotification(userID uint, orderType string, orderID string, status string, amount float64, message string) {
-	now := time.Now().Unix()
+// OrderNotification 订单状态通知内容。
+// 标题/类型/正文在分发前统一生成，各渠道不重复维护文案规则
+type OrderNotification struct {
+	UserID    uint    `json:"user_id"`
+	OrderType string  `json:"order_type"` // recharge/withdraw
+	OrderID   string  `json:"order_id"`
+	Status    string  `json:"status"`
+	Amount    float64 `json:"amount"`
+	Title     string  `json:"title"`
+	MsgType   string  `json:"msg_type"` // success/error/warning/info
+	Content   string  `json:"content"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// OrderNotifier 订单通知渠道接口。
+// 实现方只负责投递，通知内容由SendOrderNotification统一构建
+type OrderNotifier interface {
+	// Name 渠道名（用于配置选择和日志）
+	Name() string
+
+	// NotifyOrder 投递一条订单通知
+	NotifyOrder(n *OrderNotification) error
+}
+
+// orderNotifiers 已启用的通知渠道（为空时退回站内信，保持旧行为）
+var (
+	orderNotifiersMu sync.RWMutex
+	orderNotifiers   []OrderNotifier
+)
+
+// RegisterOrderNotifier 追加一个通知渠道
+func RegisterOrderNotifier(n OrderNotifier) {
+	orderNotifiersMu.Lock()
+	defer orderNotifiersMu.Unlock()
+	orderNotifiers = append(orderNotifiers, n)
+}
+
+// InitOrderNotifiers 按配置初始化通知渠道（覆盖已注册的渠道）。
+// channels可组合: user_message/websocket/webhook；
+// websocket渠道需要消息总线，webhook渠道需要URL，缺依赖时跳过并告警
+func InitOrderNotifiers(channels []string, webhookURL, webhookSecret string, bus messaging.MessageBus) {
+	var notifiers []OrderNotifier
+	for _, channel := range channels {
+		switch channel {
+		case "user_message":
+			notifiers = append(notifiers, &UserMessageNotifier{})
+		case "websocket":
+			if bus == nil {
+				if logger.Logger != nil {
+					logger.Logger.Warn("websocket通知渠道需要消息总线，已跳过")
+				}
+				continue
+			}
+			notifiers = append(notifiers, NewWebSocketNotifier(bus))
+		case "webhook":
+			if webhookURL == "" {
+				if logger.Logger != nil {
+					logger.Logger.Warn("webhook通知渠道未配置URL，已跳过")
+				}
+				continue
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(webhookURL, webhookSecret))
+		default:
+			if logger.Logger != nil {
+				logger.Logger.Warn("未知的通知渠道", zap.String("channel", channel))
+			}
+		}
+	}
+
+	orderNotifiersMu.Lock()
+	orderNotifiers = notifiers
+	orderNotifiersMu.Unlock()
+}
+
+// UserMessageNotifier 站内信渠道：写入用户消息表
+type UserMessageNotifier struct{}
+
+// Name 渠道名
+func (n *UserMessageNotifier) Name() string { return "user_message" }
+
+// NotifyOrder 创建用户消息
+func (n *UserMessageNotifier) NotifyOrder(notification *OrderNotification) error {
+	userMessage := models.UserMessage{
+		UserID:    notification.UserID,
+		Type:      notification.MsgType,
+		Title:     notification.Title,
+		Content:   notification.Content,
+		RelatedID: notification.OrderID,
+		IsRead:    false,
+		CreatedAt: notification.Timestamp,
+		UpdatedAt: notification.Timestamp,
+	}
+	return database.DB.Create(&userMessage).Error
+}
+
+// orderNotifyTopic WebSocket渠道使用的消息总线主题，
+// 由持有WebSocket连接的实例消费后推送给在线用户
+const orderNotifyTopic = "game-order-notify"
+
+// WebSocketNotifier WebSocket推送渠道：通知发布到消息总线，
+// 跨实例投递到用户所在的连接实例
+type WebSocketNotifier struct {
+	bus messaging.MessageBus
+}
+
+// NewWebSocketNotifier 创建WebSocket推送渠道
+func NewWebSocketNotifier(bus messaging.MessageBus) *WebSocketNotifier {
+	return &WebSocketNotifier{bus: bus}
+}
 
+// Name 渠道名
+func (n *WebSocketNotifier) Name() string { return "websocket" }
+
+// NotifyOrder 发布订单通知到消息总线
+func (n *WebSocketNotifier) NotifyOrder(notification *OrderNotification) error {
+	return n.bus.Publish(context.Background(), orderNotifyTopic, map[string]interface{}{
+		"type": "order_notification",
+		"data": notification,
+	})
+}
+
+// WebhookNotifier Webhook渠道：通知以JSON POST到运营方配置的地址
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建Webhook渠道
+// secret不为空时附加在X-Notify-Token请求头，供接收方校验来源
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Name 渠道名
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+// NotifyOrder POST通知到Webhook地址
+func (n *WebhookNotifier) NotifyOrder(notification *OrderNotification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("构建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		req.Header.Set("X-Notify-Token", n.secret)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求Webhook失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// buildOrderNotification 根据订单类型和状态生成通知文案
+func buildOrderNotification(userID uint, orderType, orderID, status string, amount float64, message string) *OrderNotification {
 	var title string
 	var msgType string
 
@@ -70,20 +245,43 @@ func SendOrderNotification(userID uint, orderType string, orderID string, status
 		}
 	}
 
-	// 创建用户消息
-	userMessage := models.UserMessage{
+	return &OrderNotification{
 		UserID:    userID,
-		Type:      msgType,
+		OrderType: orderType,
+		OrderID:   orderID,
+		Status:    status,
+		Amount:    amount,
 		Title:     title,
+		MsgType:   msgType,
 		Content:   content,
-		RelatedID: orderID,
-		IsRead:    false,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// SendOrderNotification 发送订单状态通知。
+// 通知异步分发到所有已配置的渠道；未初始化渠道时退回站内信
+func SendOrderNotification(userID uint, orderType string, orderID string, status string, amount float64, message string) {
+	notification := buildOrderNotification(userID, orderType, orderID, status, amount, message)
+
+	orderNotifiersMu.RLock()
+	notifiers := make([]OrderNotifier, len(orderNotifiers))
+	copy(notifiers, orderNotifiers)
+	orderNotifiersMu.RUnlock()
+
+	if len(notifiers) == 0 {
+		notifiers = []OrderNotifier{&UserMessageNotifier{}}
 	}
 
-	// 异步保存消息
+	// 异步分发，不阻塞订单状态流转
 	go func() {
-		database.DB.Create(&userMessage)
+		for _, notifier := range notifiers {
+			if err := notifier.NotifyOrder(notification); err != nil && logger.Logger != nil {
+				logger.Logger.Error("订单通知发送失败",
+					zap.String("channel", notifier.Name()),
+					zap.String("order_id", notification.OrderID),
+					zap.Error(err),
+				)
+			}
+		}
 	}()
 }
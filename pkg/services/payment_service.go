@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -18,6 +20,7 @@ import (
 	"github.com/kaifa/game-platform/internal/config"
 	"github.com/kaifa/game-platform/internal/database"
 	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/metrics"
 	"github.com/kaifa/game-platform/pkg/models"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -32,6 +35,8 @@ type PaymentService struct {
 	transferService   *USDTTransferService // USDT转账服务
 	gasManager        *GasManager          // Gas费用管理器
 	collectionService *CollectionService   // USDT归集服务
+
+	monitorRunning int32 // 交易监控是否正在执行（防止单次检查超过间隔时重叠）
 }
 
 var paymentServiceInstance *PaymentService
@@ -77,6 +82,12 @@ func NewPaymentService() *PaymentService {
 					hdWallet,
 				)
 
+				// 配置Gas价格缓存TTL并启动后台刷新
+				if cfg.Payment.GasCacheTTL > 0 {
+					ps.gasManager.SetGasCacheTTL(time.Duration(cfg.Payment.GasCacheTTL) * time.Second)
+				}
+				ps.gasManager.StartGasPriceRefresher()
+
 				// 初始化归集服务
 				ps.collectionService = NewCollectionService(
 					ps.transferService.ethClient,
@@ -90,9 +101,29 @@ func NewPaymentService() *PaymentService {
 
 		paymentServiceInstance = ps
 
-		// 启动交易监控
-		ps.StartTransactionMonitor()
+		// 启动交易监控（该单例无独立的关停路径，随进程退出）
+		monitorInterval := 30 * time.Second
+		monitorConcurrency := 0
+		if cfg != nil {
+			if cfg.Payment.MonitorInterval > 0 {
+				monitorInterval = time.Duration(cfg.Payment.MonitorInterval) * time.Second
+			}
+			monitorConcurrency = cfg.Payment.MonitorConcurrency
+			if cfg.Payment.ProviderRateLimit > 0 {
+				SetChainAPIRate(cfg.Payment.ProviderRateLimit)
+			}
+		}
+		ps.StartTransactionMonitor(context.Background(), monitorInterval, monitorConcurrency)
 		logger.Logger.Info("支付服务交易监控已启动")
+
+		// 启动自动归集调度（配置了间隔才启用）
+		if cfg != nil && cfg.Payment.CollectInterval > 0 && ps.collectionService != nil {
+			ps.collectionService.StartAutoCollect(
+				time.Duration(cfg.Payment.CollectInterval)*time.Second,
+				cfg.Payment.CollectThreshold,
+				cfg.Payment.CollectConcurrency,
+			)
+		}
 	})
 	return paymentServiceInstance
 }
@@ -113,21 +144,52 @@ func (ps *PaymentService) BatchCollectUSDT(chainType string, limit int) error {
 	return ps.collectionService.BatchCollectUSDT(chainType, limit)
 }
 
-// getSystemConfigFloat 获取系统配置浮点数值
-func getSystemConfigFloat(key string, defaultValue float64) float64 {
-	var config models.SystemConfig
-	if err := database.DB.Where("config_key = ?", key).First(&config).Error; err == nil {
-		value, err := strconv.ParseFloat(config.ConfigValue, 64)
-		if err == nil {
-			return value
-		}
-		logger.Logger.Warn("解析系统配置失败",
-			zap.String("key", key),
-			zap.String("value", config.ConfigValue),
-			zap.Error(err),
-		)
+// GetMasterWalletBalance 查询主钱包链上USDT余额（用于对账）
+func (ps *PaymentService) GetMasterWalletBalance(chainType string) (string, float64, error) {
+	if ps.collectionService == nil || ps.hdWallet == nil {
+		return "", 0, errors.New("归集服务未初始化")
 	}
-	return defaultValue
+
+	switch chainType {
+	case "erc20":
+		addr, _, err := ps.hdWallet.DeriveMasterEthereumAddress()
+		if err != nil {
+			return "", 0, fmt.Errorf("派生主钱包地址失败: %w", err)
+		}
+		balance, err := ps.collectionService.GetERC20USDTBalance(addr)
+		if err != nil {
+			return addr.Hex(), 0, fmt.Errorf("查询主钱包余额失败: %w", err)
+		}
+		f, _ := balance.Float64()
+		return addr.Hex(), f, nil
+	case "trc20":
+		addr, _, err := ps.hdWallet.DeriveMasterTronAddress()
+		if err != nil {
+			return "", 0, fmt.Errorf("派生主钱包地址失败: %w", err)
+		}
+		balance, err := ps.collectionService.GetTRC20USDTBalance(addr)
+		if err != nil {
+			return addr, 0, fmt.Errorf("查询主钱包余额失败: %w", err)
+		}
+		f, _ := balance.Float64()
+		return addr, f, nil
+	}
+	return "", 0, fmt.Errorf("不支持的链类型: %s", chainType)
+}
+
+// getSystemConfigFloat 获取系统配置浮点数值（经过缓存，见 SystemConfigService）
+func getSystemConfigFloat(key string, defaultValue float64) float64 {
+	return systemConfigService.GetFloat(key, defaultValue)
+}
+
+// GetSystemConfigFloat 获取系统配置浮点数值（供其他服务使用）
+func GetSystemConfigFloat(key string, defaultValue float64) float64 {
+	return getSystemConfigFloat(key, defaultValue)
+}
+
+// GetSystemConfigInt 获取系统配置整数值
+func GetSystemConfigInt(key string, defaultValue int) int {
+	return systemConfigService.GetInt(key, defaultValue)
 }
 
 // CreateRechargeOrder 创建充值订单
@@ -167,10 +229,11 @@ func (ps *PaymentService) CreateRechargeOrder(userID uint, amount float64, chain
 	// 确定渠道
 	channel := fmt.Sprintf("usdt_%s", chainType)
 
-	// 确定需要确认次数
-	requiredConf := 12
+	// 确定需要确认次数（从系统配置读取，可由运营调整）
+	// 注意：调整只影响新建订单，已有订单保留其存储的 RequiredConf
+	requiredConf := GetSystemConfigInt("required_conf_erc20", 12)
 	if chainType == "trc20" {
-		requiredConf = 20 // TRC20需要20个确认
+		requiredConf = GetSystemConfigInt("required_conf_trc20", 20)
 	}
 
 	order := &models.RechargeOrder{
@@ -252,12 +315,13 @@ func (ps *PaymentService) CheckTransaction(orderID string) error {
 	// 根据链类型检查交易
 	var txHash string
 	var confirmCount int
+	var receivedAmount float64
 	var err error
 
 	if order.ChainType == "trc20" {
-		txHash, confirmCount, err = ps.checkTRC20Transaction(order.DepositAddr, order.Amount)
+		txHash, confirmCount, receivedAmount, err = ps.checkTRC20Transaction(order.DepositAddr, order.CreatedAt)
 	} else if order.ChainType == "erc20" {
-		txHash, confirmCount, err = ps.checkERC20Transaction(order.DepositAddr, order.Amount)
+		txHash, confirmCount, receivedAmount, err = ps.checkERC20Transaction(order.DepositAddr, order.CreatedAt)
 	} else {
 		return errors.New("不支持的链类型")
 	}
@@ -276,6 +340,17 @@ func (ps *PaymentService) CheckTransaction(orderID string) error {
 		order.TxHash = txHash
 		order.ChannelID = txHash
 		order.ConfirmCount = confirmCount
+		order.ReceivedAmount = receivedAmount
+
+		// 到账金额与订单金额不一致时记录差异，便于后台核对
+		if receivedAmount < order.Amount*0.99 || receivedAmount > order.Amount*1.01 {
+			logger.Logger.Warn("充值到账金额与订单金额不一致",
+				zap.String("order_id", orderID),
+				zap.Float64("amount", order.Amount),
+				zap.Float64("received_amount", receivedAmount),
+				zap.String("tx_hash", txHash),
+			)
+		}
 
 		// 如果确认次数足够，标记为已支付
 		if confirmCount >= order.RequiredConf {
@@ -306,6 +381,12 @@ func (ps *PaymentService) completeRecharge(order models.RechargeOrder) error {
 
 	now := time.Now().Unix()
 
+	// 以链上实际到账金额入账，未记录到账金额时回退到订单金额
+	creditAmount := order.ReceivedAmount
+	if creditAmount <= 0 {
+		creditAmount = order.Amount
+	}
+
 	// 更新订单状态
 	order.Status = 2 // 已支付
 	order.PaidAt = &now
@@ -321,7 +402,7 @@ func (ps *PaymentService) completeRecharge(order models.RechargeOrder) error {
 		return fmt.Errorf("用户不存在: %w", err)
 	}
 
-	newBalance := user.Balance + order.Amount
+	newBalance := user.Balance + creditAmount
 	if err := tx.Model(&user).Update("balance", newBalance).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("更新用户余额失败: %w", err)
@@ -337,7 +418,7 @@ func (ps *PaymentService) completeRecharge(order models.RechargeOrder) error {
 	}
 
 	wallet.Balance = newBalance
-	wallet.TotalIn += order.Amount
+	wallet.TotalIn += creditAmount
 	if err := tx.Save(&wallet).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("更新钱包统计失败: %w", err)
@@ -348,7 +429,7 @@ func (ps *PaymentService) completeRecharge(order models.RechargeOrder) error {
 		OrderID:   order.OrderID,
 		UserID:    order.UserID,
 		Type:      "recharge",
-		Amount:    order.Amount,
+		Amount:    creditAmount,
 		Status:    2, // 成功
 		Channel:   order.Channel,
 		ChannelID: order.TxHash,
@@ -364,12 +445,16 @@ func (ps *PaymentService) completeRecharge(order models.RechargeOrder) error {
 	}
 
 	// 发送充值成功通知
-	SendOrderNotification(order.UserID, "recharge", order.OrderID, "paid", order.Amount, "")
+	SendOrderNotification(order.UserID, "recharge", order.OrderID, "paid", creditAmount, "")
+
+	// 推送外部回调
+	SendOrderWebhook(order.UserID, "recharge", order.OrderID, "paid", creditAmount, order.TxHash)
 
 	logger.Logger.Info("充值完成",
 		zap.String("order_id", order.OrderID),
 		zap.Uint("user_id", order.UserID),
 		zap.Float64("amount", order.Amount),
+		zap.Float64("credit_amount", creditAmount),
 		zap.String("tx_hash", order.TxHash),
 	)
 
@@ -466,16 +551,23 @@ func (ps *PaymentService) getDepositAddress(userID uint, chainType string) (stri
 }
 
 // checkTRC20Transaction 检查TRC20交易
-func (ps *PaymentService) checkTRC20Transaction(depositAddr string, amount float64) (string, int, error) {
+// 返回订单创建后最新一笔转入该地址的USDT交易，不按金额过滤，
+// 实际到账金额由调用方与订单金额比对处理
+func (ps *PaymentService) checkTRC20Transaction(depositAddr string, sinceTs int64) (string, int, float64, error) {
 	// TRC20 USDT 合约地址
 	usdtContract := "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
 
 	// 调用TronGrid API查询账户的TRC20交易
 	url := fmt.Sprintf("%s/v1/accounts/%s/transactions/trc20?limit=10&only_confirmed=true", ps.tronAPIURL, depositAddr)
 
+	// 客户端限流，避免批量检查时触发提供商封禁
+	if err := WaitChainAPI(context.Background(), "trongrid"); err != nil {
+		return "", 0, 0, err
+	}
+
 	resp, err := http.Get(url)
 	if err != nil {
-		return "", 0, err
+		return "", 0, 0, err
 	}
 	defer resp.Body.Close()
 
@@ -497,34 +589,33 @@ func (ps *PaymentService) checkTRC20Transaction(depositAddr string, amount float
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", 0, err
+		return "", 0, 0, err
 	}
 
 	if !result.Success {
-		return "", 0, errors.New("API返回失败")
+		return "", 0, 0, errors.New("API返回失败")
 	}
 
-	// 查找匹配的交易（USDT合约、转入地址、金额匹配）
+	// 查找匹配的交易（USDT合约、转入地址、订单创建之后）
 	for _, tx := range result.Data {
 		if tx.TokenInfo.Address == usdtContract &&
 			strings.EqualFold(tx.To, depositAddr) &&
-			tx.Type == "Transfer" {
+			tx.Type == "Transfer" &&
+			tx.BlockTimestamp >= sinceTs*1000 { // BlockTimestamp为毫秒
 			// 解析金额（TRC20使用6位小数）
 			value, _ := strconv.ParseFloat(tx.Value, 64)
 			usdtAmount := value / 1000000
 
-			// 金额匹配（允许小误差）
-			if usdtAmount >= amount*0.99 && usdtAmount <= amount*1.01 {
-				return tx.TransactionID, tx.Confirmations, nil
-			}
+			return tx.TransactionID, tx.Confirmations, usdtAmount, nil
 		}
 	}
 
-	return "", 0, errors.New("未找到匹配的交易")
+	return "", 0, 0, errors.New("未找到匹配的交易")
 }
 
 // checkERC20Transaction 检查ERC20交易
-func (ps *PaymentService) checkERC20Transaction(depositAddr string, amount float64) (string, int, error) {
+// 返回订单创建后最新一笔转入该地址的USDT交易，不按金额过滤
+func (ps *PaymentService) checkERC20Transaction(depositAddr string, sinceTs int64) (string, int, float64, error) {
 	// ERC20 USDT 合约地址（主网）
 	usdtContract := "0xdAC17F958D2ee523a2206206994597C13D831ec7"
 
@@ -532,9 +623,14 @@ func (ps *PaymentService) checkERC20Transaction(depositAddr string, amount float
 	url := fmt.Sprintf("%s?module=account&action=tokentx&contractaddress=%s&address=%s&page=1&offset=10&startblock=0&endblock=99999999&sort=desc&apikey=%s",
 		ps.etherscanAPIURL, usdtContract, depositAddr, ps.etherscanAPIKey)
 
+	// 客户端限流，避免批量检查时触发提供商封禁
+	if err := WaitChainAPI(context.Background(), "etherscan"); err != nil {
+		return "", 0, 0, err
+	}
+
 	resp, err := http.Get(url)
 	if err != nil {
-		return "", 0, err
+		return "", 0, 0, err
 	}
 	defer resp.Body.Close()
 
@@ -553,50 +649,81 @@ func (ps *PaymentService) checkERC20Transaction(depositAddr string, amount float
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", 0, err
+		return "", 0, 0, err
 	}
 
 	if result.Status != "1" {
-		return "", 0, errors.New("API返回失败: " + result.Message)
+		return "", 0, 0, errors.New("API返回失败: " + result.Message)
 	}
 
-	// 查找匹配的交易（转入地址、金额匹配）
+	// 查找匹配的交易（转入地址、订单创建之后）
 	for _, tx := range result.Result {
 		if strings.EqualFold(tx.To, depositAddr) {
+			txTs, _ := strconv.ParseInt(tx.TimeStamp, 10, 64)
+			if txTs < sinceTs {
+				continue
+			}
+
 			// 解析金额（ERC20使用6位小数）
 			value, _ := strconv.ParseFloat(tx.Value, 64)
 			usdtAmount := value / 1000000
 
-			// 金额匹配（允许小误差）
-			if usdtAmount >= amount*0.99 && usdtAmount <= amount*1.01 {
-				confirmCount, _ := strconv.Atoi(tx.Confirmations)
-				return tx.Hash, confirmCount, nil
-			}
+			confirmCount, _ := strconv.Atoi(tx.Confirmations)
+			return tx.Hash, confirmCount, usdtAmount, nil
 		}
 	}
 
-	return "", 0, errors.New("未找到匹配的交易")
+	return "", 0, 0, errors.New("未找到匹配的交易")
 }
 
-// StartTransactionMonitor 启动交易监控（定时检查待支付订单）
-func (ps *PaymentService) StartTransactionMonitor() {
-	ticker := time.NewTicker(30 * time.Second) // 每30秒检查一次
+// StartTransactionMonitor 启动交易监控（定时检查待支付订单）。
+// ctx取消后监控goroutine退出；interval非正值时使用30秒；
+// 单次检查耗时超过间隔时跳过本轮，执行情况记录到全局监控指标
+func (ps *PaymentService) StartTransactionMonitor(ctx context.Context, interval time.Duration, maxConcurrency int) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = 5
+	}
 	go func() {
-		for range ticker.C {
-			ps.checkPendingOrders()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Logger.Info("交易监控已停止")
+				return
+			case <-ticker.C:
+				if !atomic.CompareAndSwapInt32(&ps.monitorRunning, 0, 1) {
+					metrics.GetGlobalMetrics().RecordTxMonitorSkip()
+					continue
+				}
+				err := ps.checkPendingOrders(maxConcurrency)
+				atomic.StoreInt32(&ps.monitorRunning, 0)
+				metrics.GetGlobalMetrics().RecordTxMonitorRun(err)
+			}
 		}
 	}()
 }
 
-// checkPendingOrders 检查待支付的订单
-func (ps *PaymentService) checkPendingOrders() {
+// checkPendingOrders 检查待支付的订单。
+// 并发检查数由信号量限制，且等待本轮全部完成后才返回，
+// 配合调用方的重叠保护，慢提供商不会导致请求量成倍堆积
+func (ps *PaymentService) checkPendingOrders(maxConcurrency int) error {
 	var orders []models.RechargeOrder
 	if err := database.DB.Where("status = ? AND expire_at > ?", 1, time.Now().Unix()).Find(&orders).Error; err != nil {
-		return
+		return fmt.Errorf("查询待支付订单失败: %w", err)
 	}
 
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
 	for _, order := range orders {
+		wg.Add(1)
+		sem <- struct{}{}
 		go func(o models.RechargeOrder) {
+			defer wg.Done()
+			defer func() { <-sem }()
 			if err := ps.CheckTransaction(o.OrderID); err != nil {
 				logger.Logger.Debug("检查交易失败",
 					zap.String("order_id", o.OrderID),
@@ -605,6 +732,8 @@ func (ps *PaymentService) checkPendingOrders() {
 			}
 		}(order)
 	}
+	wg.Wait()
+	return nil
 }
 
 // ==================== 提现相关功能 ====================
@@ -846,6 +975,9 @@ func (ps *PaymentService) AuditWithdrawOrder(auditorID uint, orderID string, app
 		// 发送提现审核通过通知
 		SendOrderNotification(order.UserID, "withdraw", orderID, "approved", order.Amount, "")
 
+		// 推送外部回调
+		SendOrderWebhook(order.UserID, "withdraw", orderID, "approved", order.Amount, order.TxHash)
+
 		logger.Logger.Info("提现订单审核通过",
 			zap.String("order_id", orderID),
 			zap.Uint("auditor_id", auditorID),
@@ -869,6 +1001,9 @@ func (ps *PaymentService) AuditWithdrawOrder(auditorID uint, orderID string, app
 		// 发送提现审核拒绝通知
 		SendOrderNotification(order.UserID, "withdraw", orderID, "rejected", order.Amount, remark)
 
+		// 推送外部回调
+		SendOrderWebhook(order.UserID, "withdraw", orderID, "rejected", order.Amount, "")
+
 		logger.Logger.Info("提现订单审核拒绝",
 			zap.String("order_id", orderID),
 			zap.Uint("auditor_id", auditorID),
@@ -8,4 +8,11 @@ type PlayerInfo struct {
 	Avatar   string `json:"avatar"`
 	Position int    `json:"position"`
 	Ready    bool   `json:"ready"`
+
+	// ReadyAt 点击准备的时间（Unix秒），取消准备时清零。
+	// 准备超时检查据此判断未准备玩家拖了多久
+	ReadyAt int64 `json:"ready_at,omitempty"`
+
+	// UnreadyWarnedAt 因迟迟未准备被提醒的时间（Unix秒），准备后清零
+	UnreadyWarnedAt int64 `json:"unready_warned_at,omitempty"`
 }
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProviderLimiter 链上API的客户端限流器。
+// 按提供商（trongrid/etherscan）维护最小请求间隔，
+// 并发调用方在Wait处排队，避免突发请求触发提供商的限流封禁
+type ProviderLimiter struct {
+	mu       sync.Mutex
+	next     map[string]time.Time // 各提供商下一次允许请求的时间
+	interval time.Duration        // 同一提供商两次请求的最小间隔
+}
+
+// NewProviderLimiter 创建限流器
+// rps: 每个提供商每秒允许的请求数（非正值时不限流）
+func NewProviderLimiter(rps float64) *ProviderLimiter {
+	var interval time.Duration
+	if rps > 0 {
+		interval = time.Duration(float64(time.Second) / rps)
+	}
+	return &ProviderLimiter{
+		next:     make(map[string]time.Time),
+		interval: interval,
+	}
+}
+
+// Wait 阻塞直到对该提供商的下一次请求被允许（或ctx取消）
+func (l *ProviderLimiter) Wait(ctx context.Context, provider string) error {
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[provider]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[provider] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// chainAPILimiter 全局链上API限流器（默认每提供商5请求/秒）
+var (
+	chainAPILimiterMu sync.RWMutex
+	chainAPILimiter   = NewProviderLimiter(5)
+)
+
+// SetChainAPIRate 调整链上API的客户端限流速率（每提供商每秒请求数）
+func SetChainAPIRate(rps float64) {
+	chainAPILimiterMu.Lock()
+	chainAPILimiter = NewProviderLimiter(rps)
+	chainAPILimiterMu.Unlock()
+}
+
+// WaitChainAPI 在调用链上API前排队等待限流放行
+func WaitChainAPI(ctx context.Context, provider string) error {
+	chainAPILimiterMu.RLock()
+	limiter := chainAPILimiter
+	chainAPILimiterMu.RUnlock()
+	return limiter.Wait(ctx, provider)
+}
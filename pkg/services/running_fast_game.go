@@ -3,19 +3,53 @@ package services
 import (
 	"errors"
 	"fmt"
-	"math/rand"
 	"sort"
-	"time"
 
 	"github.com/kaifa/game-platform/pkg/models"
 )
 
+// 跑得快首出规则
+const (
+	FirstPlayerRuleLowestCard     = "lowest_card"     // 最小牌先出（默认）
+	FirstPlayerRuleSeatOne        = "seat_one"        // 固定1号位先出
+	FirstPlayerRulePreviousWinner = "previous_winner" // 上一局赢家先出（由Manager解析对局记录）
+)
+
+// RunningFastConfig 跑得快玩法配置：牌组构成与每人牌数。
+// 各地变体差异较大（有的带大小王、有的每人16张、有的3人打15/16/16），
+// 零值配置等同默认玩法（52张不含王、每人17张）
+type RunningFastConfig struct {
+	IncludeJokers    bool  // 牌组是否含大小王
+	CardsPerPlayer   int   // 每人牌数（0表示默认17）
+	PlayerCardCounts []int // 按座位指定每人牌数（可选，覆盖CardsPerPlayer，如3人15/16/16）
+}
+
 // RunningFastGame 跑得快游戏引擎
-type RunningFastGame struct{}
+type RunningFastGame struct {
+	shuffler Shuffler
+	config   RunningFastConfig
+}
+
+func init() {
+	RegisterEngine("running", func() GameEngine { return NewRunningFastGame() })
+}
 
-// NewRunningFastGame 创建跑得快游戏引擎
+// NewRunningFastGame 创建跑得快游戏引擎（使用生产默认洗牌器和默认玩法）
 func NewRunningFastGame() *RunningFastGame {
-	return &RunningFastGame{}
+	return NewRunningFastGameWithShuffler(newDefaultShuffler())
+}
+
+// NewRunningFastGameWithShuffler 创建指定洗牌器的跑得快引擎（测试注入固定种子用）
+func NewRunningFastGameWithShuffler(shuffler Shuffler) *RunningFastGame {
+	return NewRunningFastGameWithConfig(shuffler, RunningFastConfig{})
+}
+
+// NewRunningFastGameWithConfig 创建指定玩法配置的跑得快引擎
+func NewRunningFastGameWithConfig(shuffler Shuffler, config RunningFastConfig) *RunningFastGame {
+	if config.CardsPerPlayer <= 0 {
+		config.CardsPerPlayer = 17
+	}
+	return &RunningFastGame{shuffler: shuffler, config: config}
 }
 
 // GetGameName 获取游戏名称
@@ -28,39 +62,138 @@ func (g *RunningFastGame) GetGameType() string {
 	return "running"
 }
 
-// DealCards 发牌
+// PlayerRange 获取支持的玩家数量范围（每人17张，最多4人）
+func (g *RunningFastGame) PlayerRange() (int, int) {
+	return 2, 4
+}
+
+// Setup 开局初始化：按首出规则确定第一个出牌的玩家。
+// 默认（lowest_card）手牌中最小牌（大小王除外）的玩家先出；seat_one固定
+// 1号位先出；previous_winner由Manager读取上一局记录后覆盖，首局没有
+// 记录时这里同样按最小牌兜底。
+func (g *RunningFastGame) Setup(gameState *models.GameState) error {
+	if gameState.FirstPlayerRule == FirstPlayerRuleSeatOne {
+		for playerID, playerInfo := range gameState.Players {
+			if playerInfo.Position == 1 {
+				gameState.CurrentPlayer = playerID
+				return nil
+			}
+		}
+		// 没有1号位时走Manager的位置兜底
+		return nil
+	}
+
+	firstPlayer := uint(0)
+	firstPosition := 0
+	minCard := 999
+
+	for playerID, playerInfo := range gameState.Players {
+		for _, card := range playerInfo.Cards {
+			val := models.GetCardValue(card)
+			if val == models.CardJoker || val == models.CardKing {
+				continue
+			}
+			// 同样的最小牌以位置靠前者优先，保证结果确定
+			if val < minCard || (val == minCard && playerInfo.Position < firstPosition) {
+				minCard = val
+				firstPlayer = playerID
+				firstPosition = playerInfo.Position
+			}
+		}
+	}
+
+	if firstPlayer != 0 {
+		gameState.CurrentPlayer = firstPlayer
+	}
+	return nil
+}
+
+// Settle 按名次结算：第一名赢 (人数-1)*底注，第k名赔 (k-1)*底注
+func (g *RunningFastGame) Settle(gameState *models.GameState, baseBet float64) map[uint]float64 {
+	// 按出完牌的先后名次排序
+	rankedPlayers := make([]*models.PlayerGameInfo, 0, len(gameState.Players))
+	for _, playerInfo := range gameState.Players {
+		rankedPlayers = append(rankedPlayers, playerInfo)
+	}
+	sort.Slice(rankedPlayers, func(i, j int) bool {
+		return rankedPlayers[i].Rank < rankedPlayers[j].Rank
+	})
+
+	// 计算每个玩家的输赢
+	balances := make(map[uint]float64, len(rankedPlayers))
+	playerCount := len(rankedPlayers)
+	for i, playerInfo := range rankedPlayers {
+		rank := i + 1
+		playerInfo.Rank = rank
+
+		if rank == 1 {
+			balances[playerInfo.UserID] = float64(playerCount-1) * baseBet
+		} else {
+			balances[playerInfo.UserID] = -float64(rank-1) * baseBet
+		}
+	}
+
+	return balances
+}
+
+// DealCards 发牌（牌组构成与每人牌数由玩法配置决定，默认52张每人17张）
 func (g *RunningFastGame) DealCards(playerCount int) (map[uint][]int, error) {
 	if playerCount < 2 || playerCount > 4 {
 		return nil, errors.New("玩家数量必须在2-4之间")
 	}
 
-	// 生成一副牌（不含大小王）
-	deck := make([]int, 0, 52)
+	// 每人牌数：按座位配置优先，否则统一CardsPerPlayer
+	counts := make([]int, playerCount)
+	if len(g.config.PlayerCardCounts) > 0 {
+		if len(g.config.PlayerCardCounts) != playerCount {
+			return nil, fmt.Errorf("按座位的牌数配置有%d项，与玩家数%d不符",
+				len(g.config.PlayerCardCounts), playerCount)
+		}
+		copy(counts, g.config.PlayerCardCounts)
+	} else {
+		for i := range counts {
+			counts[i] = g.config.CardsPerPlayer
+		}
+	}
+
+	// 生成牌组
+	deck := make([]int, 0, 54)
 	for suit := 0; suit < 4; suit++ {
 		for value := 3; value <= 15; value++ { // 3到2
 			card := suit*100 + value
 			deck = append(deck, card)
 		}
 	}
+	if g.config.IncludeJokers {
+		deck = append(deck, models.CardJoker, models.CardKing)
+	}
+
+	// 校验牌组够发
+	totalCards := 0
+	for _, count := range counts {
+		if count <= 0 {
+			return nil, errors.New("每人牌数必须大于0")
+		}
+		totalCards += count
+	}
+	if totalCards > len(deck) {
+		return nil, fmt.Errorf("牌组共%d张，不够%d名玩家发%d张", len(deck), playerCount, totalCards)
+	}
 
 	// 洗牌
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	r.Shuffle(len(deck), func(i, j int) {
+	g.shuffler.Shuffle(len(deck), func(i, j int) {
 		deck[i], deck[j] = deck[j], deck[i]
 	})
 
-	// 发牌（每人17张）
-	cardsPerPlayer := 17
-
 	hands := make(map[uint][]int)
 	currentCard := 0
 
 	// 为每个玩家发牌
 	for i := 0; i < playerCount; i++ {
 		playerID := uint(i + 1)
-		hands[playerID] = make([]int, 0, cardsPerPlayer)
+		hands[playerID] = make([]int, 0, counts[i])
 
-		for j := 0; j < cardsPerPlayer && currentCard < len(deck); j++ {
+		for j := 0; j < counts[i]; j++ {
 			hands[playerID] = append(hands[playerID], deck[currentCard])
 			currentCard++
 		}
@@ -268,16 +401,17 @@ func (g *RunningFastGame) validateMultiCards(cards []int) (bool, string) {
 
 // canBeatLastCards 判断能否压过上家
 func (g *RunningFastGame) canBeatLastCards(cards []int, lastCards []int) (bool, string) {
+	// 王炸不能被压（先于己方王炸判断：一副牌只有一组王炸，
+	// 不存在用王炸压王炸的情况）
+	if g.isKingBomb(lastCards) {
+		return false, "上家出的是王炸，不能压"
+	}
+
 	// 王炸最大
 	if g.isKingBomb(cards) {
 		return true, "王炸"
 	}
 
-	// 王炸不能被打（除了新的王炸）
-	if g.isKingBomb(lastCards) {
-		return false, "上家出的是王炸，不能压"
-	}
-
 	// 普通炸弹压普通牌型
 	if g.isBomb(cards) && !g.isBomb(lastCards) {
 		return true, "炸弹"
@@ -464,20 +598,27 @@ func (g *RunningFastGame) getCardType(cards []int) string {
 	}
 }
 
+// getMainCardValue 获取牌型的主牌值（用于同类型比较）。
+// 主牌是出现次数最多的牌值：三带一/三带二取三张、炸弹取四张，
+// 带的散牌不参与比较；次数相同时（单张/对子/顺子/连对/飞机）
+// 取最小牌值，顺子连对即按起始牌比较
 func (g *RunningFastGame) getMainCardValue(cards []int) int {
 	if len(cards) == 0 {
 		return 0
 	}
 
-	// 对于单张、对子等，返回最大牌值
-	values := make([]int, 0, len(cards))
+	values := make(map[int]int)
 	for _, card := range cards {
-		val := models.GetCardValue(card)
-		values = append(values, val)
+		values[models.GetCardValue(card)]++
 	}
-	sort.Ints(values)
 
-	// 返回最小值的牌（因为要比较是否大于上家）
-	// 对于单张、对子、三张等，比较最小牌即可
-	return values[0]
+	mainValue := 0
+	mainCount := 0
+	for val, count := range values {
+		if count > mainCount || (count == mainCount && val < mainValue) {
+			mainValue = val
+			mainCount = count
+		}
+	}
+	return mainValue
 }
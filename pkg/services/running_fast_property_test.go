@@ -0,0 +1,310 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/kaifa/game-platform/pkg/models"
+)
+
+// 跑得快验证的性质测试：随机生成合法/非法手牌，检查验证分支间的
+// 不变量（类型可重识别、同类型严格大压小、炸弹/王炸排序）
+
+// propertyRounds 每条性质的随机采样次数
+const propertyRounds = 500
+
+// makeCards 按牌值生成指定张数的牌（花色依次取0-3）
+func makeCards(value, count int) []int {
+	cards := make([]int, 0, count)
+	for suit := 0; suit < count; suit++ {
+		cards = append(cards, suit*100+value)
+	}
+	return cards
+}
+
+// randValue 随机取3到max之间的牌值
+func randValue(rng *rand.Rand, max int) int {
+	return 3 + rng.Intn(max-2)
+}
+
+// randDistinctValue 随机取一个与exclude不同的牌值
+func randDistinctValue(rng *rand.Rand, max int, exclude int) int {
+	for {
+		if v := randValue(rng, max); v != exclude {
+			return v
+		}
+	}
+}
+
+// makeStraight 生成从start开始的length张顺子
+func makeStraight(rng *rand.Rand, start, length int) []int {
+	cards := make([]int, 0, length)
+	for i := 0; i < length; i++ {
+		cards = append(cards, rng.Intn(4)*100+start+i)
+	}
+	return cards
+}
+
+// makeTripleWith 生成三张value带kicker（kickerCount为1或2，2时带对子）
+func makeTripleWith(rng *rand.Rand, value, kickerValue, kickerCount int) []int {
+	cards := makeCards(value, 3)
+	return append(cards, makeCards(kickerValue, kickerCount)...)
+}
+
+// TestRunningFastTypeReidentification 验证通过的牌型必须能被getCardType重识别
+func TestRunningFastTypeReidentification(t *testing.T) {
+	g := NewRunningFastGame()
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < propertyRounds; i++ {
+		var cards []int
+		var wantType string
+
+		switch rng.Intn(7) {
+		case 0:
+			cards = makeCards(randValue(rng, 15), 1)
+			wantType = "单张"
+		case 1:
+			cards = makeCards(randValue(rng, 15), 2)
+			wantType = "对子"
+		case 2:
+			cards = makeCards(randValue(rng, 15), 3)
+			wantType = "三张"
+		case 3:
+			v := randValue(rng, 15)
+			cards = makeTripleWith(rng, v, randDistinctValue(rng, 15, v), 1)
+			wantType = "三带一"
+		case 4:
+			v := randValue(rng, 14)
+			cards = makeTripleWith(rng, v, randDistinctValue(rng, 14, v), 2)
+			wantType = "三带二"
+		case 5:
+			cards = makeCards(randValue(rng, 15), 4)
+			wantType = "炸弹"
+		case 6:
+			length := 5 + rng.Intn(4)
+			cards = makeStraight(rng, 3+rng.Intn(14-3-length+2), length)
+			wantType = "顺子"
+		}
+
+		ok, msg := g.ValidateCards(cards, nil)
+		if !ok {
+			t.Fatalf("合法的%s被判非法: %v (%s)", wantType, cards, msg)
+		}
+		if got := g.getCardType(cards); got != wantType {
+			t.Fatalf("牌型重识别不一致: %v 验证为%s, getCardType=%s", cards, wantType, got)
+		}
+	}
+}
+
+// TestRunningFastRandomHandsNeverUnknown 随机手牌只要通过验证就必须有已知牌型
+func TestRunningFastRandomHandsNeverUnknown(t *testing.T) {
+	g := NewRunningFastGame()
+	rng := rand.New(rand.NewSource(2))
+
+	// 完整牌池（不含大小王，与发牌一致）
+	deck := make([]int, 0, 52)
+	for suit := 0; suit < 4; suit++ {
+		for value := 3; value <= 15; value++ {
+			deck = append(deck, suit*100+value)
+		}
+	}
+
+	for i := 0; i < propertyRounds; i++ {
+		rng.Shuffle(len(deck), func(a, b int) { deck[a], deck[b] = deck[b], deck[a] })
+		cards := append([]int(nil), deck[:1+rng.Intn(10)]...)
+
+		ok, _ := g.ValidateCards(cards, nil)
+		if ok && g.getCardType(cards) == "未知" {
+			t.Fatalf("验证通过的手牌无法识别牌型: %v", cards)
+		}
+	}
+}
+
+// sameTypeCase 同类型比较的一组采样：main值不同的两手牌
+type sameTypeCase struct {
+	name    string
+	bigger  []int
+	smaller []int
+}
+
+// randSameTypePair 随机生成一对主牌值严格不同的同类型手牌
+func randSameTypePair(rng *rand.Rand) sameTypeCase {
+	switch rng.Intn(6) {
+	case 0:
+		hi := randValue(rng, 15)
+		lo := randDistinctValue(rng, 15, hi)
+		if lo > hi {
+			hi, lo = lo, hi
+		}
+		return sameTypeCase{"单张", makeCards(hi, 1), makeCards(lo, 1)}
+	case 1:
+		hi := randValue(rng, 15)
+		lo := randDistinctValue(rng, 15, hi)
+		if lo > hi {
+			hi, lo = lo, hi
+		}
+		return sameTypeCase{"对子", makeCards(hi, 2), makeCards(lo, 2)}
+	case 2:
+		hi := randValue(rng, 15)
+		lo := randDistinctValue(rng, 15, hi)
+		if lo > hi {
+			hi, lo = lo, hi
+		}
+		return sameTypeCase{"三张", makeCards(hi, 3), makeCards(lo, 3)}
+	case 3:
+		// 三带一：带牌随机，比较只看三张（曾因取最小牌值比较出错）
+		hi := randValue(rng, 15)
+		lo := randDistinctValue(rng, 15, hi)
+		if lo > hi {
+			hi, lo = lo, hi
+		}
+		return sameTypeCase{"三带一",
+			makeTripleWith(rng, hi, randDistinctValue(rng, 15, hi), 1),
+			makeTripleWith(rng, lo, randDistinctValue(rng, 15, lo), 1)}
+	case 4:
+		hi := randValue(rng, 15)
+		lo := randDistinctValue(rng, 15, hi)
+		if lo > hi {
+			hi, lo = lo, hi
+		}
+		return sameTypeCase{"炸弹", makeCards(hi, 4), makeCards(lo, 4)}
+	default:
+		hiStart := 4 + rng.Intn(7) // 4..10
+		loStart := 3 + rng.Intn(hiStart-3)
+		return sameTypeCase{"顺子", makeStraight(rng, hiStart, 5), makeStraight(rng, loStart, 5)}
+	}
+}
+
+// TestRunningFastSameTypeOrdering 同类型牌严格大压小、小不压大
+func TestRunningFastSameTypeOrdering(t *testing.T) {
+	g := NewRunningFastGame()
+	rng := rand.New(rand.NewSource(3))
+
+	for i := 0; i < propertyRounds; i++ {
+		tc := randSameTypePair(rng)
+
+		if ok, msg := g.ValidateCards(tc.bigger, tc.smaller); !ok {
+			t.Fatalf("%s大压小失败: %v 应压过 %v (%s)", tc.name, tc.bigger, tc.smaller, msg)
+		}
+		if ok, _ := g.ValidateCards(tc.smaller, tc.bigger); ok {
+			t.Fatalf("%s小不应压大: %v 不应压过 %v", tc.name, tc.smaller, tc.bigger)
+		}
+	}
+}
+
+// TestRunningFastBombOrdering 炸弹压一切普通牌型、大炸压小炸、王炸最大
+func TestRunningFastBombOrdering(t *testing.T) {
+	g := NewRunningFastGame()
+	rng := rand.New(rand.NewSource(4))
+	kingBomb := []int{models.CardJoker, models.CardKing}
+
+	for i := 0; i < propertyRounds; i++ {
+		bombValue := randValue(rng, 15)
+		bomb := makeCards(bombValue, 4)
+
+		// 随机普通牌型（非炸弹）
+		var normal []int
+		switch rng.Intn(4) {
+		case 0:
+			normal = makeCards(randValue(rng, 15), 1)
+		case 1:
+			normal = makeCards(randValue(rng, 15), 2)
+		case 2:
+			normal = makeCards(randValue(rng, 15), 3)
+		default:
+			normal = makeStraight(rng, 3+rng.Intn(7), 5)
+		}
+
+		// 炸弹压任意普通牌型
+		if ok, msg := g.ValidateCards(bomb, normal); !ok {
+			t.Fatalf("炸弹应压过普通牌型: %v vs %v (%s)", bomb, normal, msg)
+		}
+		// 普通牌型不能压炸弹
+		if ok, _ := g.ValidateCards(normal, bomb); ok {
+			t.Fatalf("普通牌型不应压过炸弹: %v vs %v", normal, bomb)
+		}
+
+		// 大炸压小炸
+		if bombValue < 15 {
+			biggerBomb := makeCards(bombValue+1, 4)
+			if ok, msg := g.ValidateCards(biggerBomb, bomb); !ok {
+				t.Fatalf("大炸弹应压过小炸弹: %v vs %v (%s)", biggerBomb, bomb, msg)
+			}
+			if ok, _ := g.ValidateCards(bomb, biggerBomb); ok {
+				t.Fatalf("小炸弹不应压过大炸弹: %v vs %v", bomb, biggerBomb)
+			}
+		}
+
+		// 王炸压炸弹和普通牌型
+		if ok, msg := g.ValidateCards(kingBomb, bomb); !ok {
+			t.Fatalf("王炸应压过炸弹: %v (%s)", bomb, msg)
+		}
+		if ok, msg := g.ValidateCards(kingBomb, normal); !ok {
+			t.Fatalf("王炸应压过普通牌型: %v (%s)", normal, msg)
+		}
+		// 任何牌都压不过王炸
+		if ok, _ := g.ValidateCards(bomb, kingBomb); ok {
+			t.Fatalf("炸弹不应压过王炸: %v", bomb)
+		}
+	}
+}
+
+// FuzzRunningFastValidateCards 原生fuzz入口：任意字节序列映射为手牌，
+// 检查验证器不崩溃且与getCardType保持一致
+func FuzzRunningFastValidateCards(f *testing.F) {
+	f.Add([]byte{3, 3, 3})
+	f.Add([]byte{5, 6, 7, 8, 9})
+	f.Add([]byte{16, 17})
+
+	g := NewRunningFastGame()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 || len(data) > 17 {
+			return
+		}
+		cards := make([]int, 0, len(data))
+		for i, b := range data {
+			value := 3 + int(b)%13 // 3..15
+			cards = append(cards, (i%4)*100+value)
+		}
+
+		ok, msg := g.ValidateCards(cards, nil)
+		if ok {
+			if cardType := g.getCardType(cards); cardType == "未知" {
+				t.Fatalf("验证通过但牌型未知: %v (%s)", cards, msg)
+			}
+		}
+		_ = fmt.Sprintf("%v", msg)
+	})
+}
+
+// TestRunningFastKickerDoesNotDecideTriple 回归：三带一比较不受带牌影响
+func TestRunningFastKickerDoesNotDecideTriple(t *testing.T) {
+	g := NewRunningFastGame()
+
+	// 999带3 应压过 888带7（按最小牌比较时曾误判 3 < 7）
+	bigger := []int{9, 109, 209, 3}
+	smaller := []int{8, 108, 208, 7}
+
+	if ok, msg := g.ValidateCards(bigger, smaller); !ok {
+		t.Fatalf("三张9带3应压过三张8带7: %s", msg)
+	}
+	if ok, _ := g.ValidateCards(smaller, bigger); ok {
+		t.Fatal("三张8带7不应压过三张9带3")
+	}
+}
+
+// TestRunningFastCompareStrings 确认比较失败时给出提示文案
+func TestRunningFastCompareStrings(t *testing.T) {
+	g := NewRunningFastGame()
+
+	ok, msg := g.ValidateCards([]int{5}, []int{7})
+	if ok {
+		t.Fatal("5不应压过7")
+	}
+	if !strings.Contains(msg, "牌值不够大") {
+		t.Fatalf("提示文案不符: %s", msg)
+	}
+}
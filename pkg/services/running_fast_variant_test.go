@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/kaifa/game-platform/pkg/models"
+)
+
+// collectCards 汇总所有手牌并检查没有重复发牌
+func collectCards(t *testing.T, hands map[uint][]int) map[int]bool {
+	t.Helper()
+	seen := make(map[int]bool)
+	for playerID, cards := range hands {
+		for _, card := range cards {
+			if seen[card] {
+				t.Fatalf("牌%d重复发给了玩家%d", card, playerID)
+			}
+			seen[card] = true
+		}
+	}
+	return seen
+}
+
+// TestRunningFastDefaultDeal 默认玩法保持52张不含王、每人17张
+func TestRunningFastDefaultDeal(t *testing.T) {
+	g := NewRunningFastGameWithShuffler(NewSeededShuffler(7))
+
+	hands, err := g.DealCards(3)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+	for playerID, cards := range hands {
+		if len(cards) != 17 {
+			t.Errorf("玩家%d应有17张牌, got %d", playerID, len(cards))
+		}
+	}
+	seen := collectCards(t, hands)
+	if seen[models.CardJoker] || seen[models.CardKing] {
+		t.Error("默认玩法不应发出大小王")
+	}
+}
+
+// TestRunningFastThreePlayerSixteenVariant 3人16张变体
+func TestRunningFastThreePlayerSixteenVariant(t *testing.T) {
+	g := NewRunningFastGameWithConfig(NewSeededShuffler(7), RunningFastConfig{
+		CardsPerPlayer: 16,
+	})
+
+	hands, err := g.DealCards(3)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+	if len(hands) != 3 {
+		t.Fatalf("应发3手牌, got %d", len(hands))
+	}
+	for playerID, cards := range hands {
+		if len(cards) != 16 {
+			t.Errorf("玩家%d应有16张牌, got %d", playerID, len(cards))
+		}
+	}
+	collectCards(t, hands)
+}
+
+// TestRunningFastUnevenCountsVariant 3人15/16/16变体（按座位指定牌数）
+func TestRunningFastUnevenCountsVariant(t *testing.T) {
+	g := NewRunningFastGameWithConfig(NewSeededShuffler(7), RunningFastConfig{
+		PlayerCardCounts: []int{15, 16, 16},
+	})
+
+	hands, err := g.DealCards(3)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+	want := map[uint]int{1: 15, 2: 16, 3: 16}
+	for playerID, count := range want {
+		if len(hands[playerID]) != count {
+			t.Errorf("玩家%d应有%d张牌, got %d", playerID, count, len(hands[playerID]))
+		}
+	}
+	collectCards(t, hands)
+
+	// 配置项数与玩家数不符时报错
+	if _, err := g.DealCards(4); err == nil {
+		t.Error("按座位配置与玩家数不符时应报错")
+	}
+}
+
+// TestRunningFastJokerVariant 带大小王的变体：王可进牌组且牌组54张
+func TestRunningFastJokerVariant(t *testing.T) {
+	g := NewRunningFastGameWithConfig(NewSeededShuffler(7), RunningFastConfig{
+		IncludeJokers:    true,
+		PlayerCardCounts: []int{18, 18, 18},
+	})
+
+	hands, err := g.DealCards(3)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+	seen := collectCards(t, hands)
+	if len(seen) != 54 {
+		t.Fatalf("54张牌组应全部发完, got %d", len(seen))
+	}
+	if !seen[models.CardJoker] || !seen[models.CardKing] {
+		t.Error("带王变体应发出大小王")
+	}
+}
+
+// TestRunningFastDeckTooSmall 牌组不够发时报错而不是发出短缺的手牌
+func TestRunningFastDeckTooSmall(t *testing.T) {
+	g := NewRunningFastGameWithConfig(NewSeededShuffler(7), RunningFastConfig{
+		CardsPerPlayer: 17,
+	})
+
+	if _, err := g.DealCards(4); err == nil {
+		t.Error("4人×17张超过52张牌组，应报错")
+	}
+}
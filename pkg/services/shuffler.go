@@ -0,0 +1,68 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Shuffler 洗牌器：游戏引擎通过它打乱牌堆。
+// 生产环境使用crypto/rand的实现，测试可注入固定种子得到确定性发牌。
+type Shuffler interface {
+	// Shuffle 语义同 rand.Shuffle：对 [0,n) 做随机置换，通过 swap 交换元素
+	Shuffle(n int, swap func(i, j int))
+}
+
+// randShuffler 基于 math/rand 的洗牌器。
+// rand.Rand 非并发安全，而引擎实例在多个房间间共享，因此加锁保护。
+type randShuffler struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (s *randShuffler) Shuffle(n int, swap func(i, j int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Shuffle(n, swap)
+}
+
+func (s *randShuffler) intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Intn(n)
+}
+
+// NewSeededShuffler 创建固定种子的洗牌器（测试用，发牌结果可复现）
+func NewSeededShuffler(seed int64) Shuffler {
+	return &randShuffler{r: rand.New(rand.NewSource(seed))}
+}
+
+// cryptoShuffler 基于crypto/rand的Fisher-Yates洗牌器。
+// 真金游戏不能用时钟种子的math/rand：知道发牌时间即可推算整副牌。
+type cryptoShuffler struct {
+	fallback *randShuffler // crypto/rand读取失败时的兜底随机源（基本不会用到）
+}
+
+func (s *cryptoShuffler) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, s.intn(i+1))
+	}
+}
+
+// intn 返回[0,n)内的均匀随机数（crand.Int内部做拒绝采样，无取模偏差）
+func (s *cryptoShuffler) intn(n int) int {
+	v, err := crand.Int(crand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return s.fallback.intn(n)
+	}
+	return int(v.Int64())
+}
+
+// newDefaultShuffler 生产默认洗牌器（crypto/rand，不可预测）
+func newDefaultShuffler() Shuffler {
+	return &cryptoShuffler{
+		fallback: &randShuffler{r: rand.New(rand.NewSource(time.Now().UnixNano()))},
+	}
+}
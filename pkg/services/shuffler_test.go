@@ -0,0 +1,116 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSeededShufflerDeterministic 相同种子的洗牌器应产生完全相同的发牌结果
+func TestSeededShufflerDeterministic(t *testing.T) {
+	const seed = 42
+
+	g1 := NewRunningFastGameWithShuffler(NewSeededShuffler(seed))
+	g2 := NewRunningFastGameWithShuffler(NewSeededShuffler(seed))
+
+	hands1, err := g1.DealCards(3)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+	hands2, err := g2.DealCards(3)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+
+	if !reflect.DeepEqual(hands1, hands2) {
+		t.Errorf("相同种子的发牌结果不一致:\n%v\n%v", hands1, hands2)
+	}
+
+	b1 := NewBullGameWithShuffler(NewSeededShuffler(seed))
+	b2 := NewBullGameWithShuffler(NewSeededShuffler(seed))
+
+	bullHands1, err := b1.DealCards(4)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+	bullHands2, err := b2.DealCards(4)
+	if err != nil {
+		t.Fatalf("DealCards失败: %v", err)
+	}
+
+	if !reflect.DeepEqual(bullHands1, bullHands2) {
+		t.Errorf("相同种子的牛牛发牌结果不一致:\n%v\n%v", bullHands1, bullHands2)
+	}
+}
+
+// TestSeededShufflerDifferentSeeds 不同种子应产生不同的发牌结果（碰巧相同的概率可忽略）
+func TestSeededShufflerDifferentSeeds(t *testing.T) {
+	g1 := NewRunningFastGameWithShuffler(NewSeededShuffler(1))
+	g2 := NewRunningFastGameWithShuffler(NewSeededShuffler(2))
+
+	hands1, _ := g1.DealCards(3)
+	hands2, _ := g2.DealCards(3)
+
+	if reflect.DeepEqual(hands1, hands2) {
+		t.Error("不同种子产生了相同的发牌结果")
+	}
+}
+
+// TestCryptoShufflerUniformity 公平性检验：大量洗牌后，固定元素落在每个位置
+// 的频次应接近均匀分布。阈值放得较宽（±20%），避免随机波动导致偶发失败。
+func TestCryptoShufflerUniformity(t *testing.T) {
+	const (
+		deckSize = 13
+		trials   = 20000
+	)
+
+	s := newDefaultShuffler()
+	positionCounts := make([]int, deckSize)
+
+	for trial := 0; trial < trials; trial++ {
+		deck := make([]int, deckSize)
+		for i := range deck {
+			deck[i] = i
+		}
+		s.Shuffle(deckSize, func(i, j int) {
+			deck[i], deck[j] = deck[j], deck[i]
+		})
+		for pos, card := range deck {
+			if card == 0 {
+				positionCounts[pos]++
+				break
+			}
+		}
+	}
+
+	expected := float64(trials) / deckSize
+	for pos, count := range positionCounts {
+		if float64(count) < expected*0.8 || float64(count) > expected*1.2 {
+			t.Errorf("位置%d的频次%d偏离期望值%.0f超过20%%，分布可能不均匀", pos, count, expected)
+		}
+	}
+}
+
+// TestCryptoShufflerIsPermutation 洗牌结果必须仍是原牌堆的一个排列（不丢牌、不重牌）
+func TestCryptoShufflerIsPermutation(t *testing.T) {
+	const deckSize = 52
+
+	s := newDefaultShuffler()
+	deck := make([]int, deckSize)
+	for i := range deck {
+		deck[i] = i
+	}
+	s.Shuffle(deckSize, func(i, j int) {
+		deck[i], deck[j] = deck[j], deck[i]
+	})
+
+	seen := make(map[int]bool, deckSize)
+	for _, card := range deck {
+		if card < 0 || card >= deckSize {
+			t.Fatalf("洗牌后出现非法元素: %d", card)
+		}
+		if seen[card] {
+			t.Fatalf("洗牌后出现重复元素: %d", card)
+		}
+		seen[card] = true
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/pkg/models"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// ConfigChangedChannel 配置变更事件频道（payload 为配置键，"*" 表示全部失效）
+const ConfigChangedChannel = "system-config:changed"
+
+// systemConfigCacheTTL 本地缓存TTL（兜底：即使错过失效事件也最多延迟这么久）
+const systemConfigCacheTTL = 5 * time.Minute
+
+// cachedConfig 单条缓存项（found=false 为负缓存，避免反复查询不存在的键）
+type cachedConfig struct {
+	value    string
+	found    bool
+	loadedAt time.Time
+}
+
+// SystemConfigService 系统配置缓存服务。
+// 首次读取时从数据库加载并缓存，订阅 Redis 配置变更频道实现跨实例失效；
+// 未绑定 Redis 时退化为纯本地TTL缓存。
+type SystemConfigService struct {
+	mu    sync.RWMutex
+	cache map[string]cachedConfig
+	ttl   time.Duration
+	rdb   *redis.Client
+}
+
+// NewSystemConfigService 创建系统配置缓存服务
+func NewSystemConfigService(ttl time.Duration) *SystemConfigService {
+	return &SystemConfigService{
+		cache: make(map[string]cachedConfig),
+		ttl:   ttl,
+	}
+}
+
+// 全局单例：getSystemConfigFloat 等包级函数统一经过此缓存
+var systemConfigService = NewSystemConfigService(systemConfigCacheTTL)
+
+// GetSystemConfigService 获取全局系统配置缓存服务
+func GetSystemConfigService() *SystemConfigService {
+	return systemConfigService
+}
+
+// InitSystemConfigCache 绑定 Redis 并启动跨实例失效订阅。
+// rdb 为 nil 时仅使用本地TTL缓存（Redis 降级场景）。
+func InitSystemConfigCache(rdb *redis.Client) {
+	systemConfigService.StartInvalidation(rdb)
+}
+
+// StartInvalidation 订阅配置变更频道，收到事件后失效对应缓存
+func (s *SystemConfigService) StartInvalidation(rdb *redis.Client) {
+	if rdb == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.rdb = rdb
+	s.mu.Unlock()
+
+	go func() {
+		sub := rdb.Subscribe(context.Background(), ConfigChangedChannel)
+		for msg := range sub.Channel() {
+			s.Invalidate(msg.Payload)
+			logger.Logger.Info("系统配置缓存已失效",
+				zap.String("key", msg.Payload),
+			)
+		}
+	}()
+}
+
+// Invalidate 失效指定键的缓存（空或 "*" 失效全部）
+func (s *SystemConfigService) Invalidate(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key = strings.TrimSpace(key)
+	if key == "" || key == "*" {
+		s.cache = make(map[string]cachedConfig)
+		return
+	}
+	delete(s.cache, key)
+}
+
+// PublishConfigChanged 发布配置变更事件，通知所有实例失效缓存。
+// 管理端更新配置后调用；本实例缓存会立即失效，不依赖事件回流。
+func (s *SystemConfigService) PublishConfigChanged(ctx context.Context, key string) error {
+	s.Invalidate(key)
+
+	s.mu.RLock()
+	rdb := s.rdb
+	s.mu.RUnlock()
+
+	if rdb == nil {
+		return nil
+	}
+	return rdb.Publish(ctx, ConfigChangedChannel, key).Err()
+}
+
+// getRaw 读取配置原始值（优先缓存，未命中或过期时回源数据库）
+func (s *SystemConfigService) getRaw(key string) (string, bool) {
+	s.mu.RLock()
+	entry, ok := s.cache[key]
+	s.mu.RUnlock()
+
+	if ok && time.Since(entry.loadedAt) < s.ttl {
+		return entry.value, entry.found
+	}
+
+	entry = cachedConfig{loadedAt: time.Now()}
+	if database.DB != nil {
+		var config models.SystemConfig
+		if err := database.DB.Where("config_key = ?", key).First(&config).Error; err == nil {
+			entry.value = config.ConfigValue
+			entry.found = true
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[key] = entry
+	s.mu.Unlock()
+
+	return entry.value, entry.found
+}
+
+// GetString 获取字符串配置
+func (s *SystemConfigService) GetString(key, defaultValue string) string {
+	if value, found := s.getRaw(key); found {
+		return value
+	}
+	return defaultValue
+}
+
+// GetFloat 获取浮点数配置
+func (s *SystemConfigService) GetFloat(key string, defaultValue float64) float64 {
+	raw, found := s.getRaw(key)
+	if !found {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		logger.Logger.Warn("解析系统配置失败",
+			zap.String("key", key),
+			zap.String("value", raw),
+			zap.Error(err),
+		)
+		return defaultValue
+	}
+	return value
+}
+
+// GetInt 获取整数配置
+func (s *SystemConfigService) GetInt(key string, defaultValue int) int {
+	raw, found := s.getRaw(key)
+	if !found {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		logger.Logger.Warn("解析系统配置失败",
+			zap.String("key", key),
+			zap.String("value", raw),
+			zap.Error(err),
+		)
+		return defaultValue
+	}
+	return value
+}
+
+// GetBool 获取布尔配置
+func (s *SystemConfigService) GetBool(key string, defaultValue bool) bool {
+	raw, found := s.getRaw(key)
+	if !found {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		logger.Logger.Warn("解析系统配置失败",
+			zap.String("key", key),
+			zap.String("value", raw),
+			zap.Error(err),
+		)
+		return defaultValue
+	}
+	return value
+}
+
+// GetSystemConfigBool 获取系统配置布尔值（供其他服务使用）
+func GetSystemConfigBool(key string, defaultValue bool) bool {
+	return systemConfigService.GetBool(key, defaultValue)
+}
+
+// GetSystemConfigString 获取系统配置字符串值（供其他服务使用）
+func GetSystemConfigString(key, defaultValue string) string {
+	return systemConfigService.GetString(key, defaultValue)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kaifa/game-platform/internal/config"
+	"github.com/kaifa/game-platform/internal/database"
+	"github.com/kaifa/game-platform/internal/logger"
+	"github.com/kaifa/game-platform/internal/worker"
+	"github.com/kaifa/game-platform/pkg/models"
+	"go.uber.org/zap"
+)
+
+// OrderWebhookPayload 订单状态回调载荷
+type OrderWebhookPayload struct {
+	OrderID string  `json:"order_id"`
+	UserID  uint    `json:"user_id"`
+	Type    string  `json:"type"`   // recharge/withdraw
+	Status  string  `json:"status"` // paid/approved/rejected
+	Amount  float64 `json:"amount"`
+	TxHash  string  `json:"tx_hash"`
+}
+
+var (
+	webhookPool     *worker.Pool
+	webhookPoolOnce sync.Once
+	webhookClient   = &http.Client{Timeout: 10 * time.Second}
+)
+
+// getWebhookPool 获取回调推送 Worker Pool（延迟初始化）
+func getWebhookPool() *worker.Pool {
+	webhookPoolOnce.Do(func() {
+		webhookPool = worker.NewPool(3, 100)
+	})
+	return webhookPool
+}
+
+// SendOrderWebhook 推送订单状态回调到外部系统
+// 未配置 payment.webhook_url 时直接跳过
+func SendOrderWebhook(userID uint, orderType string, orderID string, status string, amount float64, txHash string) {
+	cfg := config.Get()
+	if cfg.Payment.WebhookURL == "" {
+		return
+	}
+
+	payload := OrderWebhookPayload{
+		OrderID: orderID,
+		UserID:  userID,
+		Type:    orderType,
+		Status:  status,
+		Amount:  amount,
+		TxHash:  txHash,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Logger.Error("序列化回调载荷失败",
+			zap.String("order_id", orderID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// 记录推送记录
+	delivery := &models.WebhookDelivery{
+		OrderID:     orderID,
+		UserID:      userID,
+		OrderType:   orderType,
+		OrderStatus: status,
+		Amount:      amount,
+		TxHash:      txHash,
+		URL:         cfg.Payment.WebhookURL,
+		Payload:     string(body),
+		Status:      1, // 待推送
+	}
+	if err := database.DB.Create(delivery).Error; err != nil {
+		logger.Logger.Error("创建回调推送记录失败",
+			zap.String("order_id", orderID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	submitWebhookDelivery(delivery)
+}
+
+// ResendWebhookDelivery 重新推送回调（管理后台使用）
+func ResendWebhookDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := database.DB.First(&delivery, id).Error; err != nil {
+		return nil, errors.New("回调记录不存在")
+	}
+
+	if delivery.Status == 2 {
+		return nil, errors.New("回调已推送成功，无需重发")
+	}
+
+	delivery.Status = 1 // 待推送
+	delivery.LastError = ""
+	if err := database.DB.Save(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("更新回调记录失败: %w", err)
+	}
+
+	submitWebhookDelivery(&delivery)
+	return &delivery, nil
+}
+
+// submitWebhookDelivery 提交推送任务到 Worker Pool
+func submitWebhookDelivery(delivery *models.WebhookDelivery) {
+	err := getWebhookPool().Submit(func(ctx context.Context) error {
+		return deliverWebhook(ctx, delivery)
+	})
+	if err != nil {
+		logger.Logger.Warn("提交回调推送任务失败",
+			zap.String("order_id", delivery.OrderID),
+			zap.Error(err),
+		)
+	}
+}
+
+// deliverWebhook 执行回调推送（带退避重试）
+func deliverWebhook(ctx context.Context, delivery *models.WebhookDelivery) error {
+	cfg := config.Get()
+	maxRetries := cfg.Payment.WebhookMaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	backoff := time.Second
+	var lastErr error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			// 退避等待（1s、2s、4s...）
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		err := postWebhook(ctx, delivery.URL, []byte(delivery.Payload), cfg.Payment.WebhookSecret)
+		delivery.Attempts++
+
+		if err == nil {
+			now := time.Now().Unix()
+			delivery.Status = 2 // 成功
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+			database.DB.Save(delivery)
+
+			logger.Logger.Info("回调推送成功",
+				zap.String("order_id", delivery.OrderID),
+				zap.Int("attempts", delivery.Attempts),
+			)
+			return nil
+		}
+
+		lastErr = err
+		delivery.LastError = err.Error()
+		database.DB.Save(delivery)
+	}
+
+	delivery.Status = 3 // 失败
+	database.DB.Save(delivery)
+
+	logger.Logger.Warn("回调推送失败",
+		zap.String("order_id", delivery.OrderID),
+		zap.Int("attempts", delivery.Attempts),
+		zap.Error(lastErr),
+	)
+	return lastErr
+}
+
+// postWebhook 发送单次HTTP回调请求（带HMAC签名）
+func postWebhook(ctx context.Context, url string, body []byte, secret string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Signature", signWebhookPayload(body, secret))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("回调返回非成功状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookPayload 计算回调签名（HMAC-SHA256，十六进制编码）
+func signWebhookPayload(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -34,6 +34,9 @@ const (
 	PermissionRolesDelete           = "admin:roles:delete"
 	PermissionRolesAssignPermission = "admin:roles:assign_permission"
 
+	// 游戏房间管理
+	PermissionGameRoomsForceEnd = "admin:game_rooms:force_end"
+
 	// 系统管理 - 管理员
 	PermissionAdminsList       = "admin:admins:list"
 	PermissionAdminsCreate     = "admin:admins:create"
@@ -71,6 +74,7 @@ func GetAllPermissions() []string {
 		PermissionRolesUpdate,
 		PermissionRolesDelete,
 		PermissionRolesAssignPermission,
+		PermissionGameRoomsForceEnd,
 		PermissionAdminsList,
 		PermissionAdminsCreate,
 		PermissionAdminsUpdate,
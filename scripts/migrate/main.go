@@ -1,51 +1,83 @@
 package main
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/kaifa/game-platform/internal/config"
 )
 
 func main() {
-	// 加载配置
-	cfg, err := config.Load("")
+	// 命令行参数（便于在CI中对不同环境/目录执行）
+	configPath := flag.String("config", "", "配置文件路径（默认按 configs/ 目录查找）")
+	migrationsDir := flag.String("dir", filepath.Join("../../migrations"), "迁移文件目录")
+	env := flag.String("env", "", "环境名（加载 config.<env>.yaml，默认取 APP_ENV）")
+	dryRun := flag.Bool("dry-run", false, "只打印SQL语句，不实际执行")
+	rollback := flag.Int("rollback", 0, "回滚最近N个已应用的迁移（需要配对的 .down.sql 文件）")
+	flag.Parse()
+
+	// 加载配置：-config 优先于 -env
+	var cfg *config.Config
+	var err error
+	if *configPath != "" {
+		cfg, err = config.Load(*configPath)
+	} else if *env != "" {
+		cfg, err = config.LoadWithEnv(*env)
+	} else {
+		cfg, err = config.Load("")
+	}
 	if err != nil {
 		panic(fmt.Sprintf("加载配置失败: %v", err))
 	}
 
-	// 构建数据库连接字符串
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Database,
-	)
+	var db *sql.DB
+	if *dryRun && *rollback == 0 {
+		fmt.Println("Dry-run 模式：只打印SQL语句，不连接数据库")
+	} else {
+		// 构建数据库连接字符串
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.Database.User,
+			cfg.Database.Password,
+			cfg.Database.Host,
+			cfg.Database.Port,
+			cfg.Database.Database,
+		)
 
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		panic(fmt.Sprintf("连接数据库失败: %v", err))
-	}
-	defer db.Close()
+		db, err = sql.Open("mysql", dsn)
+		if err != nil {
+			panic(fmt.Sprintf("连接数据库失败: %v", err))
+		}
+		defer db.Close()
 
-	// 测试连接
-	if err := db.Ping(); err != nil {
-		panic(fmt.Sprintf("数据库连接失败: %v", err))
-	}
+		// 测试连接
+		if err := db.Ping(); err != nil {
+			panic(fmt.Sprintf("数据库连接失败: %v", err))
+		}
+
+		fmt.Println("数据库连接成功，开始执行迁移...")
 
-	fmt.Println("数据库连接成功，开始执行迁移...")
+		// 迁移跟踪表：记录已应用的文件和校验和
+		if err := ensureMigrationsTable(db); err != nil {
+			panic(fmt.Sprintf("创建 schema_migrations 表失败: %v", err))
+		}
+	}
 
-	// 获取迁移文件目录
-	migrationsDir := filepath.Join("../../migrations")
+	// 回滚模式：逆序撤销最近N个迁移后退出
+	if *rollback > 0 {
+		rollbackMigrations(db, *migrationsDir, *rollback, *dryRun)
+		return
+	}
 
 	// 读取所有SQL文件
-	files, err := os.ReadDir(migrationsDir)
+	files, err := os.ReadDir(*migrationsDir)
 	if err != nil {
 		panic(fmt.Sprintf("读取迁移目录失败: %v", err))
 	}
@@ -58,6 +90,10 @@ func main() {
 			if file.Name() == "add_password_field.sql" {
 				continue
 			}
+			// down 文件只在回滚时使用
+			if strings.HasSuffix(file.Name(), ".down.sql") {
+				continue
+			}
 			sqlFiles = append(sqlFiles, file.Name())
 		}
 	}
@@ -67,7 +103,7 @@ func main() {
 
 	// 执行每个迁移文件
 	for _, fileName := range sqlFiles {
-		migrationFile := filepath.Join(migrationsDir, fileName)
+		migrationFile := filepath.Join(*migrationsDir, fileName)
 		fmt.Printf("\n执行迁移文件: %s\n", fileName)
 
 		sqlBytes, err := os.ReadFile(migrationFile)
@@ -76,6 +112,25 @@ func main() {
 			continue
 		}
 
+		// 根据迁移记录判断是否需要执行
+		checksum := fmt.Sprintf("%x", sha256.Sum256(sqlBytes))
+		if !*dryRun {
+			var appliedChecksum string
+			err := db.QueryRow("SELECT checksum FROM schema_migrations WHERE filename = ?", fileName).Scan(&appliedChecksum)
+			if err == nil {
+				if appliedChecksum != checksum {
+					fmt.Printf("  ⚠️  文件在应用后被修改（校验和不一致），请新建迁移文件而不是改旧文件\n")
+				} else {
+					fmt.Printf("  ℹ️  已应用，跳过\n")
+				}
+				continue
+			}
+			if err != sql.ErrNoRows {
+				fmt.Printf("  ⚠️  查询迁移记录失败: %v\n", err)
+				continue
+			}
+		}
+
 		// 分割SQL语句
 		content := string(sqlBytes)
 
@@ -98,32 +153,22 @@ func main() {
 		// 调试：显示分割后的语句数量
 		fmt.Printf("  检测到 %d 个SQL语句段\n", len(sqlStatements))
 
+		// 每个文件包一个事务（MySQL 的 DDL 会隐式提交，事务主要保护数据迁移语句）
+		var tx *sql.Tx
+		if !*dryRun {
+			tx, err = db.Begin()
+			if err != nil {
+				fmt.Printf("  ❌ 开启事务失败: %v\n", err)
+				continue
+			}
+		}
+
 		// 执行SQL
 		successCount := 0
 		errorCount := 0
 		skippedCount := 0
 		for i, statement := range sqlStatements {
-			statement = strings.TrimSpace(statement)
-			// 移除语句内的行注释（-- 之后的内容，但不在字符串内）
-			// 简单的处理：查找不在引号内的 --
-			var cleanedStatement strings.Builder
-			inSingleQuote := false
-			inDoubleQuote := false
-			for j, r := range statement {
-				if r == '\'' && (j == 0 || statement[j-1] != '\\') {
-					inSingleQuote = !inSingleQuote
-					cleanedStatement.WriteRune(r)
-				} else if r == '"' && (j == 0 || statement[j-1] != '\\') {
-					inDoubleQuote = !inDoubleQuote
-					cleanedStatement.WriteRune(r)
-				} else if r == '-' && j+1 < len(statement) && statement[j+1] == '-' && !inSingleQuote && !inDoubleQuote {
-					// 找到注释开始，停止处理
-					break
-				} else {
-					cleanedStatement.WriteRune(r)
-				}
-			}
-			statement = strings.TrimSpace(cleanedStatement.String())
+			statement = cleanStatement(statement)
 
 			// 跳过空语句
 			if statement == "" {
@@ -131,6 +176,13 @@ func main() {
 				continue
 			}
 
+			// Dry-run：完整打印语句后跳过执行
+			if *dryRun {
+				fmt.Printf("  [%d] %s;\n", i+1, statement)
+				successCount++
+				continue
+			}
+
 			// 调试：显示要执行的SQL语句（仅前100字符）
 			preview := statement
 			if len(preview) > 100 {
@@ -139,7 +191,7 @@ func main() {
 			fmt.Printf("  [%d] 执行: %s\n", i+1, preview)
 
 			// 执行SQL
-			if _, err := db.Exec(statement); err != nil {
+			if _, err := tx.Exec(statement); err != nil {
 				// 对于某些错误（如表已存在），只显示警告
 				errMsg := err.Error()
 				if strings.Contains(errMsg, "already exists") ||
@@ -178,6 +230,22 @@ func main() {
 			}
 		}
 
+		// 提交或回滚，并记录已应用的文件
+		if !*dryRun {
+			if errorCount > 0 {
+				_ = tx.Rollback()
+				fmt.Printf("  ⚠️  存在失败语句，回滚且不记录该文件（DDL 部分可能已隐式提交）\n")
+			} else if err := tx.Commit(); err != nil {
+				fmt.Printf("  ❌ 提交事务失败: %v\n", err)
+			} else if _, err := db.Exec(
+				"INSERT INTO schema_migrations (filename, checksum, applied_at) VALUES (?, ?, ?) "+
+					"ON DUPLICATE KEY UPDATE checksum = VALUES(checksum), applied_at = VALUES(applied_at)",
+				fileName, checksum, time.Now().Unix(),
+			); err != nil {
+				fmt.Printf("  ⚠️  记录迁移状态失败: %v\n", err)
+			}
+		}
+
 		if skippedCount > 3 {
 			fmt.Printf("  ℹ️  共跳过 %d 条（已存在）\n", skippedCount)
 		}
@@ -192,3 +260,139 @@ func main() {
 
 	fmt.Println("\n✅ 所有迁移完成！")
 }
+
+// cleanStatement 移除语句内的行注释（-- 之后的内容，但不在字符串内）并去除首尾空白
+func cleanStatement(statement string) string {
+	statement = strings.TrimSpace(statement)
+	// 简单的处理：查找不在引号内的 --
+	var cleanedStatement strings.Builder
+	inSingleQuote := false
+	inDoubleQuote := false
+	for j, r := range statement {
+		if r == '\'' && (j == 0 || statement[j-1] != '\\') {
+			inSingleQuote = !inSingleQuote
+			cleanedStatement.WriteRune(r)
+		} else if r == '"' && (j == 0 || statement[j-1] != '\\') {
+			inDoubleQuote = !inDoubleQuote
+			cleanedStatement.WriteRune(r)
+		} else if r == '-' && j+1 < len(statement) && statement[j+1] == '-' && !inSingleQuote && !inDoubleQuote {
+			// 找到注释开始，停止处理
+			break
+		} else {
+			cleanedStatement.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(cleanedStatement.String())
+}
+
+// splitStatements 去掉整行注释后按分号拆分语句（空语句已过滤）
+func splitStatements(content string) []string {
+	lines := strings.Split(content, "\n")
+	var cleanLines []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+			cleanLines = append(cleanLines, line)
+		}
+	}
+	content = strings.Join(cleanLines, "\n")
+
+	var statements []string
+	for _, statement := range strings.Split(content, ";") {
+		statement = cleanStatement(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// rollbackMigrations 按 schema_migrations 逆序回滚最近 N 个已应用的迁移。
+// 只有 *.up.sql 迁移支持回滚（执行对应的 *.down.sql），
+// 单文件迁移或缺少 down 文件的迁移会明确提示并跳过。
+func rollbackMigrations(db *sql.DB, dir string, n int, dryRun bool) {
+	rows, err := db.Query(
+		"SELECT filename FROM schema_migrations ORDER BY applied_at DESC, filename DESC LIMIT ?", n)
+	if err != nil {
+		panic(fmt.Sprintf("查询迁移记录失败: %v", err))
+	}
+	var applied []string
+	for rows.Next() {
+		var fileName string
+		if err := rows.Scan(&fileName); err == nil {
+			applied = append(applied, fileName)
+		}
+	}
+	rows.Close()
+
+	if len(applied) == 0 {
+		fmt.Println("没有可回滚的迁移记录")
+		return
+	}
+
+	for _, fileName := range applied {
+		fmt.Printf("\n回滚迁移: %s\n", fileName)
+
+		if !strings.HasSuffix(fileName, ".up.sql") {
+			fmt.Printf("  ⚠️  %s 是单文件迁移（没有配对的 down 文件），无法回滚，跳过\n", fileName)
+			continue
+		}
+
+		downName := strings.TrimSuffix(fileName, ".up.sql") + ".down.sql"
+		sqlBytes, err := os.ReadFile(filepath.Join(dir, downName))
+		if err != nil {
+			fmt.Printf("  ⚠️  缺少 down 文件 %s，无法回滚 %s，跳过\n", downName, fileName)
+			continue
+		}
+
+		statements := splitStatements(string(sqlBytes))
+
+		if dryRun {
+			for i, statement := range statements {
+				fmt.Printf("  [%d] %s;\n", i+1, statement)
+			}
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			fmt.Printf("  ❌ 开启事务失败: %v\n", err)
+			continue
+		}
+
+		failed := false
+		for i, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				fmt.Printf("  ❌ 执行失败（第%d条）: %v\n", i+1, err)
+				failed = true
+				break
+			}
+		}
+
+		if failed {
+			_ = tx.Rollback()
+			fmt.Printf("  ⚠️  回滚 %s 失败，迁移记录保留\n", fileName)
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			fmt.Printf("  ❌ 提交事务失败: %v\n", err)
+			continue
+		}
+
+		if _, err := db.Exec("DELETE FROM schema_migrations WHERE filename = ?", fileName); err != nil {
+			fmt.Printf("  ⚠️  删除迁移记录失败: %v\n", err)
+			continue
+		}
+		fmt.Printf("  ✅ 已回滚\n")
+	}
+}
+
+// ensureMigrationsTable 创建迁移跟踪表（如果不存在）
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		filename VARCHAR(255) NOT NULL PRIMARY KEY COMMENT '迁移文件名',
+		checksum CHAR(64) NOT NULL COMMENT '文件内容SHA256',
+		applied_at BIGINT NOT NULL COMMENT '应用时间戳'
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='已应用的数据库迁移'`)
+	return err
+}